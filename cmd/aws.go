@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+	"github.com/ahmedfawzy/devops-toolkit/pkg/k8s"
 	"github.com/ahmedfawzy/devops-toolkit/pkg/notify"
 	"github.com/ahmedfawzy/devops-toolkit/pkg/reporter"
 	"github.com/spf13/cobra"
@@ -24,8 +28,28 @@ var (
 	alertThreshold float64
 
 	// Security command flags
-	securityRegion       string
-	securitySlackWebhook string
+	securityRegion          string
+	securityRegions         string
+	securitySlackWebhook    string
+	securityTrustedAccounts string
+	securitySuppressions    string
+	securityFormat          string
+	securityImportToHub     bool
+
+	// Compliance command flags
+	complianceRegion string
+	complianceFormat string
+
+	// Certificate command flags
+	certRegions      string
+	certExpiryDays   int
+	certIncludeIAM   bool
+	certSlackWebhook string
+
+	// Security group cleanup command flags
+	sgCleanupRegion string
+	sgCleanupDryRun bool
+	sgCleanupYes    bool
 )
 
 var awsCmd = &cobra.Command{
@@ -58,17 +82,111 @@ var awsSecurityCmd = &cobra.Command{
 
 - Public S3 buckets
 - Security groups with risky ports (22, 3389, 3306, 5432, 27017) exposed to 0.0.0.0/0
+- Unencrypted EBS volumes and snapshots
+- Unencrypted or publicly accessible RDS instances
+- EBS/RDS snapshots shared publicly or with unknown accounts
+- Public AMIs
+- EC2 instances without IMDSv2 enforced or with an IMDS hop limit above 1
+- EC2 user data containing AWS keys, private keys or passwords
+- Route 53 records pointing at deleted resources (subdomain takeover)
+- Load balancer listeners with outdated SSL policies, HTTP without an HTTPS
+  redirect or expiring certificates, and internet-facing ALBs without WAF
+- CloudFront distributions allowing outdated TLS or plain HTTP, with expiring
+  certificates or without WAF
+- Unused security groups, default groups with rules, rules referencing deleted
+  groups and groups with identical rules (see 'dtk aws sg-cleanup')
+
+Results from several regions are combined into one report; findings on global
+resources such as CloudFront distributions and Route 53 records are reported
+once. Output can be a table, JSON or CSV, exported as SARIF for GitHub code
+scanning or in the AWS Security Finding Format (ASFF), and imported directly
+into Security Hub in the first region.
 
 Example:
-  dtk aws security --region us-east-1
-  dtk aws security --region eu-west-1 --slack-webhook https://hooks.slack.com/...`,
+  dtk aws security --regions us-east-1
+  dtk aws security --regions us-east-1,eu-west-1 --format json > security.json
+  dtk aws security --regions us-east-1,eu-west-1 --format csv > security.csv
+  dtk aws security --regions us-east-1 --format sarif > dtk.sarif
+  dtk aws security --regions us-east-1 --format asff --import-to-securityhub
+  dtk aws security --regions us-east-1 --trusted-accounts 111111111111,222222222222
+  dtk aws security --regions us-east-1 --suppressions suppressions.yaml
+  dtk aws security --regions eu-west-1 --slack-webhook https://hooks.slack.com/...`,
 	RunE: runAWSSecurity,
 }
 
+var awsComplianceCmd = &cobra.Command{
+	Use:   "compliance",
+	Short: "Evaluate CIS AWS Foundations Benchmark compliance",
+	Long: `Run the security checks and map them to CIS AWS Foundations Benchmark controls:
+
+- 2.1.4 S3 block public access
+- 2.2.1 EBS volume encryption
+- 2.3.1 RDS encryption at rest
+- 2.3.3 RDS public access
+- 5.2 / 5.3 Security groups exposing admin ports to 0.0.0.0/0 and ::/0
+- 5.4 Default security groups restrict all traffic
+- 5.6 EC2 instances enforce IMDSv2
+
+Each control is reported as pass, fail, not-applicable (when there are no
+resources to evaluate, such as no RDS instances) or error (when its check could
+not run), with an overall compliance score. Controls that errored count against
+the score; not-applicable controls are left out of it.
+
+Example:
+  dtk aws compliance --region us-east-1
+  dtk aws compliance --region eu-west-1 --format csv > cis-evidence.csv`,
+	RunE: runAWSCompliance,
+}
+
+var awsCertsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Check ACM and IAM server certificate expiry",
+	Long: `List ACM certificates and IAM server certificates expiring soon.
+
+ACM certificates are listed per region with their in-use status and renewal
+eligibility. IAM server certificates are global and never renew automatically.
+
+Certificates use the same status buckets as 'dtk k8s certs':
+- expired: already expired
+- critical: expires in less than 7 days
+- expiring-soon: expires in less than 30 days
+
+Example:
+  dtk aws certs
+  dtk aws certs --regions us-east-1,eu-west-1 --expiry-days 60
+  dtk aws certs --iam=false --slack-webhook https://hooks.slack.com/xxx`,
+	RunE: runAWSCerts,
+}
+
+var awsSGCleanupCmd = &cobra.Command{
+	Use:   "sg-cleanup",
+	Short: "Delete security groups not attached to any network interface",
+	Long: `Find security groups that aren't attached to any network interface and
+delete them.
+
+Runs as a dry run by default: EC2 checks that each group could be deleted
+without changing anything. Pass --dry-run=false to delete the groups; the
+command lists them and asks for confirmation unless --yes is set.
+
+Default groups are never deleted. Groups still referenced by another group's
+rules are skipped since AWS refuses to delete them. Groups used by any launch
+template version or launch configuration are skipped too: they have no
+network interface until an instance starts.
+
+Example:
+  dtk aws sg-cleanup --region us-east-1
+  dtk aws sg-cleanup --region us-east-1 --dry-run=false
+  dtk aws sg-cleanup --region us-east-1 --dry-run=false --yes`,
+	RunE: runAWSSGCleanup,
+}
+
 func init() {
 	rootCmd.AddCommand(awsCmd)
 	awsCmd.AddCommand(awsAuditCmd)
 	awsCmd.AddCommand(awsSecurityCmd)
+	awsCmd.AddCommand(awsComplianceCmd)
+	awsCmd.AddCommand(awsCertsCmd)
+	awsCmd.AddCommand(awsSGCleanupCmd)
 
 	awsAuditCmd.Flags().StringVarP(&awsRegions, "regions", "r", "", "Comma-separated AWS regions (e.g., us-east-1,us-west-2,eu-west-1)")
 	awsAuditCmd.Flags().StringVarP(&outputFormat, "format", "f", "table", "Output format: table, json, csv")
@@ -81,30 +199,38 @@ func init() {
 	awsAuditCmd.Flags().Float64Var(&alertThreshold, "alert-threshold", 0, "Minimum savings threshold to trigger Slack alert (default 0)")
 
 	// Security command flags
-	awsSecurityCmd.Flags().StringVarP(&securityRegion, "region", "r", "", "AWS region to audit (e.g., us-east-1)")
+	awsSecurityCmd.Flags().StringVarP(&securityRegions, "regions", "r", "", "Comma-separated AWS regions to audit (e.g., us-east-1,eu-west-1)")
+	awsSecurityCmd.Flags().StringVar(&securityRegion, "region", "", "AWS region to audit")
+	awsSecurityCmd.Flags().MarkDeprecated("region", "use --regions instead")
 	awsSecurityCmd.Flags().StringVar(&securitySlackWebhook, "slack-webhook", "", "Slack webhook URL for sending security alerts")
+	awsSecurityCmd.Flags().StringVar(&securityTrustedAccounts, "trusted-accounts", "", "Comma-separated AWS account IDs that snapshots may be shared with")
+	awsSecurityCmd.Flags().StringVar(&securitySuppressions, "suppressions", "", "Path to a YAML file of accepted findings to suppress")
+	awsSecurityCmd.Flags().StringVarP(&securityFormat, "format", "f", "table", "Output format: table, json, csv, sarif, asff")
+	awsSecurityCmd.Flags().BoolVar(&securityImportToHub, "import-to-securityhub", false, "Import findings into AWS Security Hub with BatchImportFindings")
+
+	// Compliance command flags
+	awsComplianceCmd.Flags().StringVarP(&complianceRegion, "region", "r", "", "AWS region to evaluate (e.g., us-east-1)")
+	awsComplianceCmd.Flags().StringVarP(&complianceFormat, "format", "f", "table", "Output format: table, json, csv")
+	awsComplianceCmd.Flags().StringVar(&securityTrustedAccounts, "trusted-accounts", "", "Comma-separated AWS account IDs that snapshots may be shared with")
+	awsComplianceCmd.Flags().StringVar(&securitySuppressions, "suppressions", "", "Path to a YAML file of accepted findings to suppress")
+
+	// Certificate command flags
+	awsCertsCmd.Flags().StringVarP(&certRegions, "regions", "r", "", "Comma-separated AWS regions to scan for ACM certificates")
+	awsCertsCmd.Flags().IntVar(&certExpiryDays, "expiry-days", 30, "Show certificates expiring within N days")
+	awsCertsCmd.Flags().BoolVar(&certIncludeIAM, "iam", true, "Include IAM server certificates")
+	awsCertsCmd.Flags().StringVar(&certSlackWebhook, "slack-webhook", "", "Slack webhook URL for certificate expiry alerts")
+
+	// Security group cleanup command flags
+	awsSGCleanupCmd.Flags().StringVarP(&sgCleanupRegion, "region", "r", "", "AWS region to clean up (e.g., us-east-1)")
+	awsSGCleanupCmd.Flags().BoolVar(&sgCleanupDryRun, "dry-run", true, "Only check which groups would be deleted")
+	awsSGCleanupCmd.Flags().BoolVarP(&sgCleanupYes, "yes", "y", false, "Delete without asking for confirmation")
 }
 
 func runAWSAudit(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	// Parse regions from comma-separated string
-	var regions []string
-	if awsRegions == "" {
-		// Use environment variable if regions not specified
-		awsRegions = os.Getenv("AWS_REGION")
-		if awsRegions == "" {
-			awsRegions = "us-east-1"
-		}
-	}
-
-	// Split on comma and trim whitespace from each region
-	for _, region := range strings.Split(awsRegions, ",") {
-		trimmed := strings.TrimSpace(region)
-		if trimmed != "" {
-			regions = append(regions, trimmed)
-		}
-	}
+	regions := parseRegions(awsRegions)
 
 	// Display all regions being scanned
 	fmt.Printf("🔍 Auditing AWS resources in regions: %s\n", strings.Join(regions, ", "))
@@ -210,123 +336,557 @@ func runAWSAudit(cmd *cobra.Command, args []string) error {
 func runAWSSecurity(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	// Determine region
-	region := securityRegion
-	if region == "" {
-		region = os.Getenv("AWS_REGION")
-		if region == "" {
-			region = "us-east-1"
+	switch securityFormat {
+	case "table", "json", "csv", "sarif", "asff":
+	default:
+		return fmt.Errorf("unsupported format: %s", securityFormat)
+	}
+
+	// --region is deprecated but still honored when --regions isn't set
+	regions := parseRegions(securityRegions)
+	if securityRegions == "" && securityRegion != "" {
+		regions = parseRegions(securityRegion)
+	}
+
+	// Machine-readable formats keep stdout clean; progress and warnings go to stderr
+	out := io.Writer(os.Stdout)
+	if securityFormat != "table" {
+		out = os.Stderr
+	}
+
+	fmt.Fprintf(out, "🔒 Auditing AWS security in regions: %s\n", strings.Join(regions, ", "))
+
+	// The first region is where findings are imported into Security Hub
+	homeRegion := regions[0]
+	var homeAuditor *aws.SecurityAuditor
+	accountID := ""
+
+	// Route 53, CloudFront and S3 are global, list them once for all regions
+	globalDNS := aws.NewGlobalDNSInventory()
+
+	regional := make([]*aws.SecurityResults, 0, len(regions))
+	for _, region := range regions {
+		fmt.Fprintf(out, "\n═══ Region: %s ═══\n\n", region)
+
+		auditor, err := newSecurityAuditor(ctx, region)
+		if err != nil {
+			return err
 		}
+		auditor.SetGlobalDNSInventory(globalDNS)
+
+		// The account ID scopes suppressions and builds resource ARNs
+		if homeAuditor == nil {
+			homeAuditor = auditor
+			if securitySuppressions != "" || securityFormat == "sarif" || securityFormat == "asff" || securityImportToHub {
+				accountID = lookupAccountID(ctx, auditor, out)
+			}
+		}
+
+		results := collectSecurityResults(ctx, auditor, out)
+		results.Regions = []string{region}
+
+		if err := applySuppressionsFile(results, region, accountID); err != nil {
+			return err
+		}
+		regional = append(regional, results)
 	}
 
-	fmt.Println()
-	fmt.Println("\033[1m🔒 AWS Security Audit\033[0m")
-	fmt.Println("═══════════════════════════════════════════════════════════")
-	fmt.Printf("Region: %s\n\n", region)
+	results := aws.MergeSecurityResults(regional...)
 
-	auditor, err := aws.NewSecurityAuditor(ctx, region)
+	fmt.Fprintln(out)
+
+	rep := reporter.NewReporter(securityFormat)
+	if err := rep.RenderSecurityResults(results, homeRegion, accountID); err != nil {
+		return fmt.Errorf("failed to render results: %w", err)
+	}
+
+	if securityImportToHub {
+		if err := importToSecurityHub(ctx, homeAuditor, results, homeRegion, accountID, out); err != nil {
+			return err
+		}
+	}
+
+	sendSecuritySlackAlert(results, out)
+
+	return nil
+}
+
+// importToSecurityHub imports the active findings into Security Hub
+func importToSecurityHub(ctx context.Context, auditor *aws.SecurityAuditor, results *aws.SecurityResults, region, accountID string, out io.Writer) error {
+	if accountID == "" {
+		return fmt.Errorf("cannot import to Security Hub without the AWS account ID")
+	}
+
+	findings := aws.BuildASFFFindings(results, region, accountID, time.Now())
+	if len(findings) == 0 {
+		fmt.Fprintln(out, "\nℹ️  No security findings to import into Security Hub")
+		return nil
+	}
+
+	fmt.Fprintf(out, "\n📤 Importing %d findings into Security Hub...\n", len(findings))
+
+	imported, err := auditor.ImportToSecurityHub(ctx, findings)
 	if err != nil {
-		return fmt.Errorf("failed to create security auditor: %w", err)
+		return err
 	}
 
-	results := &aws.SecurityResults{}
+	fmt.Fprintf(out, "✅ Imported %d findings into Security Hub\n", imported)
+	return nil
+}
 
-	// Check S3 buckets
-	fmt.Println("🪣 \033[1mPublic S3 Buckets\033[0m")
-	fmt.Println("─────────────────────────────────────────────────────────────")
+// sendSecuritySlackAlert sends a Slack alert if a webhook is configured and findings exist
+func sendSecuritySlackAlert(results *aws.SecurityResults, out io.Writer) {
+	if securitySlackWebhook == "" {
+		return
+	}
 
-	publicBuckets, err := auditor.CheckPublicS3Buckets(ctx)
+	counts := results.CountBySeverity()
+	totalFindings := counts[aws.SeverityCritical] + counts[aws.SeverityHigh] + counts[aws.SeverityMedium]
+	if totalFindings == 0 {
+		fmt.Fprintln(out, "\nℹ️  Slack webhook configured but no security findings - no alert sent")
+		return
+	}
+
+	fmt.Fprintln(out, "\n📢 Sending Slack alert...")
+
+	notifier := notify.NewSlackNotifier(securitySlackWebhook)
+	slackMsg := buildSecuritySlackMessage(results)
+
+	if err := notifier.SendSlackMessage(slackMsg); err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to send Slack alert: %v\n", err)
+	} else {
+		fmt.Fprintln(out, "✅ Slack alert sent successfully!")
+	}
+}
+
+func runAWSCompliance(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	regions := []string{resolveRegion(complianceRegion)}
+
+	// Machine-readable formats keep stdout clean; progress and warnings go to stderr
+	out := io.Writer(os.Stdout)
+	if complianceFormat != "table" {
+		out = os.Stderr
+	}
+
+	fmt.Fprintf(out, "📋 Evaluating CIS AWS Foundations Benchmark in region: %s\n\n", regions[0])
+
+	auditor, err := newSecurityAuditor(ctx, regions[0])
 	if err != nil {
-		fmt.Printf("  ⚠️  Warning: Failed to check S3 buckets: %v\n", err)
+		return err
+	}
+
+	results := collectSecurityResults(ctx, auditor, out)
+
+	// Suppressed findings are accepted risks, not compliance: controls are
+	// evaluated before suppressions and suppressed resources still fail
+	report := aws.EvaluateCISBenchmark(results, regions)
+
+	if securitySuppressions != "" {
+		accountID := lookupAccountID(ctx, auditor, out)
+		if err := applySuppressionsFile(results, regions[0], accountID); err != nil {
+			return err
+		}
+		report.MarkSuppressed(results)
+	}
+
+	fmt.Fprintln(out)
+
+	rep := reporter.NewReporter(complianceFormat)
+	if err := rep.RenderComplianceReport(report); err != nil {
+		return fmt.Errorf("failed to render results: %w", err)
+	}
+
+	return nil
+}
+
+func runAWSCerts(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	regions := parseRegions(certRegions)
+
+	fmt.Printf("🔐 Checking ACM and IAM certificate expiry in regions: %s\n\n", strings.Join(regions, ", "))
+
+	certs := make([]aws.Certificate, 0)
+	for i, region := range regions {
+		auditor, err := aws.NewCertificateAuditor(ctx, region)
+		if err != nil {
+			return fmt.Errorf("failed to create certificate auditor for region %s: %w", region, err)
+		}
+
+		acmCerts, err := auditor.ListACMCertificates(ctx, now)
+		if err != nil {
+			return fmt.Errorf("failed to check ACM certificates in %s: %w", region, err)
+		}
+		certs = append(certs, acmCerts...)
+
+		// IAM is global, list server certificates once
+		if i == 0 && certIncludeIAM {
+			iamCerts, err := auditor.ListIAMServerCertificates(ctx, now)
+			if err != nil {
+				return fmt.Errorf("failed to check IAM server certificates: %w", err)
+			}
+			certs = append(certs, iamCerts...)
+		}
+	}
+
+	results := aws.NewCertificateResults(certs, certExpiryDays)
+
+	if len(results.Certificates) == 0 {
+		fmt.Printf("✅ No certificates expiring within %d days (scanned %d certificates)\n",
+			certExpiryDays, results.TotalScanned)
 	} else {
-		results.PublicS3Buckets = publicBuckets
-		if len(publicBuckets) == 0 {
-			fmt.Println("  No public buckets found ✅")
-		} else {
-			for _, bucket := range publicBuckets {
-				color := aws.GetSeverityColor(bucket.Severity)
-				reset := aws.ResetSecurityColor()
-				fmt.Printf("  %s• %s (%s) - %s%s\n", color, bucket.BucketName, bucket.PublicAccess, severityLabel(bucket.Severity), reset)
+		fmt.Printf("Found %d certificate(s) expiring within %d days (scanned %d certificates)\n\n",
+			len(results.Certificates), certExpiryDays, results.TotalScanned)
+
+		fmt.Printf("%-6s %-35s %-15s %-15s %-7s %-12s %-20s %s\n",
+			"SOURCE", "NAME", "REGION", "DAYS REMAINING", "IN USE", "RENEWAL", "EXPIRY DATE", "STATUS")
+		fmt.Println("────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────")
+
+		for _, cert := range results.Certificates {
+			color := k8s.StatusColorCode(cert.Status)
+			reset := k8s.ResetColor()
+
+			inUse := "no"
+			if cert.InUse {
+				inUse = "yes"
 			}
+
+			fmt.Printf("%s%-6s %-35s %-15s %-15d %-7s %-12s %-20s %s%s\n",
+				color,
+				cert.Source,
+				truncateString(cert.Name, 35),
+				cert.Region,
+				cert.DaysRemaining,
+				inUse,
+				strings.ToLower(cert.RenewalEligibility),
+				cert.ExpiryDate.Format("2006-01-02 15:04"),
+				cert.Status,
+				reset)
+		}
+
+		// Print summary
+		fmt.Println()
+		if results.ExpiredCount > 0 {
+			fmt.Printf("🔴 Expired: %d\n", results.ExpiredCount)
+		}
+		if results.CriticalCount > 0 {
+			fmt.Printf("🟠 Critical (<7 days): %d\n", results.CriticalCount)
+		}
+		if results.ExpiringCount > 0 {
+			fmt.Printf("🟡 Expiring Soon (<30 days): %d\n", results.ExpiringCount)
 		}
 	}
 
-	fmt.Println()
+	// Send Slack alert if configured and certificates are expiring
+	if certSlackWebhook != "" && len(results.Certificates) > 0 {
+		fmt.Println("\n📢 Sending Slack alert...")
 
-	// Check Security Groups
-	fmt.Println("🛡️ \033[1mOpen Security Groups (risky ports exposed to 0.0.0.0/0)\033[0m")
-	fmt.Println("─────────────────────────────────────────────────────────────")
+		notifier := notify.NewSlackNotifier(certSlackWebhook)
+		message := fmt.Sprintf("Found %d ACM/IAM certificate(s) expiring within %d days",
+			len(results.Certificates), certExpiryDays)
 
-	openSGs, err := auditor.CheckOpenSecurityGroups(ctx)
-	if err != nil {
-		fmt.Printf("  ⚠️  Warning: Failed to check security groups: %v\n", err)
-	} else {
-		results.OpenSecurityGroups = openSGs
-		if len(openSGs) == 0 {
-			fmt.Println("  No risky security groups found ✅")
+		counts := certAlertCounts{
+			Found:    len(results.Certificates),
+			Critical: results.CriticalCount,
+			Expiring: results.ExpiringCount,
+			Expired:  results.ExpiredCount,
+		}
+		slackMsg := buildCertSlackMessage(":lock: *AWS Certificate Expiry Alert*", message, formatAWSCertFindings(results), counts)
+
+		if err := notifier.SendSlackMessage(slackMsg); err != nil {
+			fmt.Printf("⚠️  Warning: Failed to send Slack alert: %v\n", err)
 		} else {
-			// Print table header
-			fmt.Printf("%-25s | %-5s | %-8s | %-10s | %s\n",
-				"SECURITY GROUP", "PORT", "PROTOCOL", "SOURCE", "SEVERITY")
-			fmt.Println("─────────────────────────┼───────┼──────────┼────────────┼──────────")
-
-			for _, sg := range openSGs {
-				color := aws.GetSeverityColor(sg.Severity)
-				reset := aws.ResetSecurityColor()
-				sgDisplay := fmt.Sprintf("%s (%s)", sg.GroupID, truncateString(sg.GroupName, 10))
-				fmt.Printf("%s%-25s | %-5d | %-8s | %-10s | %s%s\n",
-					color,
-					truncateString(sgDisplay, 25),
-					sg.Port,
-					sg.Protocol,
-					sg.Source,
-					severityLabel(sg.Severity),
-					reset)
-			}
+			fmt.Println("✅ Slack alert sent successfully!")
+		}
+	} else if certSlackWebhook != "" {
+		fmt.Printf("\nℹ️  Slack webhook configured but no certificates expiring within %d days - no alert sent\n", certExpiryDays)
+	}
+
+	return nil
+}
+
+// formatAWSCertFindings formats ACM and IAM certificates for a Slack alert
+func formatAWSCertFindings(results *aws.CertificateResults) string {
+	var text string
+
+	for _, cert := range results.Certificates {
+		usage := "not in use"
+		if cert.InUse {
+			usage = "in use"
 		}
+
+		text += fmt.Sprintf("%s *%s* (%s, %s) - %d days remaining (%s)\n",
+			certStatusLabel(cert.Status),
+			cert.Name,
+			cert.Source,
+			cert.Region,
+			cert.DaysRemaining,
+			usage)
+	}
+
+	if text == "" {
+		text = "✅ All certificates are valid"
 	}
 
+	return text
+}
+
+func runAWSSGCleanup(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	region := resolveRegion(sgCleanupRegion)
+
+	fmt.Println()
+	fmt.Println("\033[1m🧹 Security Group Cleanup\033[0m")
+	fmt.Println("═══════════════════════════════════════════════════════════")
+	fmt.Printf("Region: %s\n", region)
+	if sgCleanupDryRun {
+		fmt.Println("Mode: dry run (pass --dry-run=false to delete)")
+	} else {
+		fmt.Println("Mode: delete")
+	}
 	fmt.Println()
 
-	// Summary
-	counts := results.CountBySeverity()
-	fmt.Println("\033[1mSummary:\033[0m")
-	fmt.Printf("🔴 Critical: %d\n", counts[aws.SeverityCritical])
-	fmt.Printf("🟡 High: %d\n", counts[aws.SeverityHigh])
-	fmt.Printf("🟠 Medium: %d\n", counts[aws.SeverityMedium])
+	auditor, err := aws.NewSecurityAuditor(ctx, region)
+	if err != nil {
+		return fmt.Errorf("failed to create security auditor: %w", err)
+	}
 
-	// Send Slack alert if configured and findings exist
-	totalFindings := counts[aws.SeverityCritical] + counts[aws.SeverityHigh] + counts[aws.SeverityMedium]
-	if securitySlackWebhook != "" && totalFindings > 0 {
-		fmt.Println("\n📢 Sending Slack alert...")
+	groups, err := auditor.FindUnusedSecurityGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find unused security groups: %w", err)
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("✅ No unused security groups found")
+		return nil
+	}
+
+	var deletable []aws.UnusedSecurityGroup
+	for _, group := range groups {
+		if sgCleanupSkipReason(group) == "" {
+			deletable = append(deletable, group)
+		}
+	}
 
-		notifier := notify.NewSlackNotifier(securitySlackWebhook)
-		slackMsg := buildSecuritySlackMessage(region, results)
+	if !sgCleanupDryRun && !sgCleanupYes && len(deletable) > 0 {
+		fmt.Println("The following security groups will be deleted:")
+		for _, group := range deletable {
+			fmt.Printf("  %-22s %-30s %s\n", group.GroupID, truncateString(group.GroupName, 30), group.VpcID)
+		}
+		fmt.Println()
 
-		err := notifier.SendSlackMessage(slackMsg)
+		confirmed, err := confirm(cmd.InOrStdin(), fmt.Sprintf("Delete %d security group(s) in %s?", len(deletable), region))
 		if err != nil {
-			fmt.Printf("⚠️  Warning: Failed to send Slack alert: %v\n", err)
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Aborted, no security groups were deleted")
+			return nil
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%-22s %-30s %-22s %s\n", "GROUP ID", "NAME", "VPC", "RESULT")
+	fmt.Println("────────────────────────────────────────────────────────────────────────────────────────────")
+
+	var deleted, skipped, failed int
+	for _, group := range groups {
+		var result string
+		if reason := sgCleanupSkipReason(group); reason != "" {
+			result = fmt.Sprintf("⏭️  skipped: %s", reason)
+			skipped++
+		} else if err := auditor.DeleteSecurityGroup(ctx, group.GroupID, sgCleanupDryRun); err != nil {
+			result = fmt.Sprintf("❌ %v", err)
+			failed++
+		} else if sgCleanupDryRun {
+			result = "🔍 would delete"
+			deleted++
 		} else {
-			fmt.Println("✅ Slack alert sent successfully!")
+			result = "🗑️  deleted"
+			deleted++
 		}
-	} else if securitySlackWebhook != "" && totalFindings == 0 {
-		fmt.Println("\nℹ️  Slack webhook configured but no security findings - no alert sent")
+
+		fmt.Printf("%-22s %-30s %-22s %s\n", group.GroupID, truncateString(group.GroupName, 30), group.VpcID, result)
+	}
+
+	fmt.Println()
+	if sgCleanupDryRun {
+		fmt.Printf("Would delete: %d, skipped: %d, failed: %d\n", deleted, skipped, failed)
+	} else {
+		fmt.Printf("Deleted: %d, skipped: %d, failed: %d\n", deleted, skipped, failed)
 	}
 
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d security group(s)", failed)
+	}
 	return nil
 }
 
-func severityLabel(severity aws.Severity) string {
-	switch severity {
-	case aws.SeverityCritical:
-		return "🔴 CRITICAL"
-	case aws.SeverityHigh:
-		return "🟡 HIGH"
-	case aws.SeverityMedium:
-		return "🟠 MEDIUM"
+// sgCleanupSkipReason explains why an unused security group must not be
+// deleted, or returns an empty string when it can be
+func sgCleanupSkipReason(group aws.UnusedSecurityGroup) string {
+	switch {
+	case len(group.ReferencedBy) > 0:
+		return fmt.Sprintf("referenced by %s", strings.Join(group.ReferencedBy, ", "))
+	case len(group.UsedByLaunch) > 0:
+		return fmt.Sprintf("used by %s", strings.Join(group.UsedByLaunch, ", "))
+	default:
+		return ""
+	}
+}
+
+// confirm asks a yes/no question and reads the answer from in. Anything other
+// than y or yes, including no input at all, counts as no.
+func confirm(in io.Reader, question string) (bool, error) {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
 	default:
-		return string(severity)
+		return false, nil
+	}
+}
+
+// parseRegions splits a comma-separated region list, falling back to
+// AWS_REGION and then us-east-1 when it names no region
+func parseRegions(value string) []string {
+	var regions []string
+	for _, region := range strings.Split(value, ",") {
+		trimmed := strings.TrimSpace(region)
+		if trimmed != "" {
+			regions = append(regions, trimmed)
+		}
+	}
+
+	if len(regions) == 0 {
+		return []string{resolveRegion("")}
+	}
+	return regions
+}
+
+// resolveRegion falls back to AWS_REGION and then us-east-1 when region is empty
+func resolveRegion(region string) string {
+	if region != "" {
+		return region
+	}
+	if envRegion := os.Getenv("AWS_REGION"); envRegion != "" {
+		return envRegion
+	}
+	return "us-east-1"
+}
+
+// newSecurityAuditor creates a SecurityAuditor configured with the trusted accounts flag
+func newSecurityAuditor(ctx context.Context, region string) (*aws.SecurityAuditor, error) {
+	auditor, err := aws.NewSecurityAuditor(ctx, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create security auditor: %w", err)
+	}
+
+	var trustedAccounts []string
+	for _, account := range strings.Split(securityTrustedAccounts, ",") {
+		trimmed := strings.TrimSpace(account)
+		if trimmed != "" {
+			trustedAccounts = append(trustedAccounts, trimmed)
+		}
+	}
+	auditor.SetTrustedAccounts(trustedAccounts)
+
+	return auditor, nil
+}
+
+// collectSecurityResults runs every security check, recording which checks completed.
+// A failing check prints a warning to out and does not stop the remaining checks.
+func collectSecurityResults(ctx context.Context, auditor *aws.SecurityAuditor, out io.Writer) *aws.SecurityResults {
+	results := &aws.SecurityResults{}
+
+	publicBuckets, err := auditor.CheckPublicS3Buckets(ctx)
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to check S3 buckets: %v\n", err)
+	} else {
+		results.PublicS3Buckets = publicBuckets
+		results.MarkCompleted(aws.CheckIDPublicS3Buckets)
+	}
+
+	openSGs, err := auditor.CheckOpenSecurityGroups(ctx)
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to check security groups: %v\n", err)
+	} else {
+		results.OpenSecurityGroups = openSGs
+		results.MarkCompleted(aws.CheckIDOpenSecurityGroups)
+	}
+
+	findingChecks := []struct {
+		name     string
+		checkIDs []string
+		check    func(context.Context) ([]aws.SecurityFinding, error)
+	}{
+		{"unencrypted EBS volumes", []string{aws.CheckIDUnencryptedVolumes}, auditor.CheckUnencryptedVolumes},
+		{"unencrypted EBS snapshots", []string{aws.CheckIDUnencryptedSnapshots}, auditor.CheckUnencryptedSnapshots},
+		{"RDS instances", []string{aws.CheckIDRDSPublicAccess, aws.CheckIDRDSEncryption}, auditor.CheckRDSInstances},
+		{"shared snapshots", []string{aws.CheckIDSharedSnapshots}, auditor.CheckSharedSnapshots},
+		{"public AMIs", []string{aws.CheckIDPublicAMIs}, auditor.CheckPublicAMIs},
+		{"EC2 instance metadata options", []string{aws.CheckIDIMDSv2, aws.CheckIDIMDSHopLimit}, auditor.CheckInstanceMetadata},
+		{"EC2 user data", []string{aws.CheckIDUserDataSecrets}, auditor.CheckUserDataSecrets},
+		{"Route 53 dangling DNS", []string{aws.CheckIDDanglingDNS}, auditor.CheckDanglingDNS},
+		{"load balancer listeners", []string{aws.CheckIDELBOutdatedTLSPolicy, aws.CheckIDELBHTTPNoRedirect, aws.CheckIDELBExpiringCertificate}, auditor.CheckLoadBalancers},
+		{"load balancer WAF associations", []string{aws.CheckIDELBNoWAF}, auditor.CheckLoadBalancerWAF},
+		{"security group hygiene", []string{aws.CheckIDUnusedSecurityGroups, aws.CheckIDDefaultSecurityGroupRules, aws.CheckIDStaleSecurityGroupRefs, aws.CheckIDDuplicateSecurityGroups}, auditor.CheckSecurityGroupHygiene},
+		{"CloudFront distributions", []string{aws.CheckIDCloudFrontOutdatedTLS, aws.CheckIDCloudFrontHTTPAllowed, aws.CheckIDCloudFrontExpiringCertificate, aws.CheckIDCloudFrontNoWAF}, auditor.CheckCloudFrontDistributions},
+	}
+
+	for _, c := range findingChecks {
+		findings, err := c.check(ctx)
+		if err != nil {
+			fmt.Fprintf(out, "⚠️  Warning: Failed to check %s: %v\n", c.name, err)
+			continue
+		}
+		results.Findings = append(results.Findings, findings...)
+		results.MarkCompleted(c.checkIDs...)
+	}
+
+	results.ResourceCounts = make(map[string]int)
+	for checkID, count := range auditor.ResourceCounts() {
+		if results.IsCompleted(checkID) {
+			results.ResourceCounts[checkID] = count
+		}
+	}
+
+	return results
+}
+
+// lookupAccountID returns the audited account ID, or an empty string with a
+// warning when it cannot be determined
+func lookupAccountID(ctx context.Context, auditor *aws.SecurityAuditor, out io.Writer) string {
+	accountID, err := auditor.AccountID(ctx)
+	if err != nil {
+		// Account-scoped suppressions won't match and ARNs omit the account
+		fmt.Fprintf(out, "⚠️  Warning: Failed to determine AWS account ID: %v\n", err)
+		return ""
+	}
+	return accountID
+}
+
+// applySuppressionsFile loads the suppressions file, if configured, and applies it to the results
+func applySuppressionsFile(results *aws.SecurityResults, region, accountID string) error {
+	if securitySuppressions == "" {
+		return nil
+	}
+
+	suppressions, err := aws.LoadSuppressions(securitySuppressions)
+	if err != nil {
+		return err
 	}
+
+	results.ApplySuppressions(suppressions, region, accountID, time.Now())
+	return nil
 }
 
 func truncateString(s string, maxLen int) string {
@@ -336,7 +896,7 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-func buildSecuritySlackMessage(region string, results *aws.SecurityResults) notify.SlackMessage {
+func buildSecuritySlackMessage(results *aws.SecurityResults) notify.SlackMessage {
 	counts := results.CountBySeverity()
 
 	// Determine color based on findings
@@ -354,7 +914,7 @@ func buildSecuritySlackMessage(region string, results *aws.SecurityResults) noti
 	if len(results.PublicS3Buckets) > 0 {
 		findingsText += fmt.Sprintf(":bucket: *Public S3 Buckets:* %d\n", len(results.PublicS3Buckets))
 		for _, bucket := range results.PublicS3Buckets {
-			findingsText += fmt.Sprintf("  • `%s` (%s)\n", bucket.BucketName, bucket.PublicAccess)
+			findingsText += fmt.Sprintf("  • `%s` (%s, %s)\n", bucket.BucketName, bucket.Region, bucket.PublicAccess)
 		}
 	}
 
@@ -362,8 +922,16 @@ func buildSecuritySlackMessage(region string, results *aws.SecurityResults) noti
 		findingsText += fmt.Sprintf(":shield: *Open Security Groups:* %d\n", len(results.OpenSecurityGroups))
 		for _, sg := range results.OpenSecurityGroups {
 			portName := aws.RiskyPorts[sg.Port]
-			findingsText += fmt.Sprintf("  • `%s` - Port %d (%s) open to %s\n",
-				sg.GroupID, sg.Port, portName, sg.Source)
+			findingsText += fmt.Sprintf("  • `%s` (%s) - Port %d (%s) open to %s\n",
+				sg.GroupID, sg.Region, sg.Port, portName, sg.Source)
+		}
+	}
+
+	if len(results.Findings) > 0 {
+		findingsText += fmt.Sprintf(":closed_lock_with_key: *Resource Findings:* %d\n", len(results.Findings))
+		for _, finding := range results.Findings {
+			findingsText += fmt.Sprintf("  • %s `%s` - %s\n",
+				finding.ResourceType, finding.ResourceID, finding.Description)
 		}
 	}
 
@@ -372,7 +940,7 @@ func buildSecuritySlackMessage(region string, results *aws.SecurityResults) noti
 	}
 
 	return notify.SlackMessage{
-		Text: fmt.Sprintf(":lock: *AWS Security Audit Report*\nRegion: %s", region),
+		Text: fmt.Sprintf(":lock: *AWS Security Audit Report*\nRegions: %s", strings.Join(results.Regions, ", ")),
 		Attachments: []notify.Attachment{
 			{
 				Color: color,
@@ -388,6 +956,11 @@ func buildSecuritySlackMessage(region string, results *aws.SecurityResults) noti
 						Value: fmt.Sprintf("%d", counts[aws.SeverityHigh]),
 						Short: true,
 					},
+					{
+						Title: ":no_bell: Suppressed",
+						Value: fmt.Sprintf("%d", len(results.Suppressed)),
+						Short: true,
+					},
 				},
 			},
 		},
@@ -3,19 +3,75 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+	"github.com/ahmedfawzy/devops-toolkit/pkg/k8s"
+	"github.com/ahmedfawzy/devops-toolkit/pkg/notify"
 	"github.com/ahmedfawzy/devops-toolkit/pkg/reporter"
 	"github.com/spf13/cobra"
 )
 
 var (
-	days       int
-	groupBy    string
-	topN       int
-	costRegion string
+	days        int
+	groupBy     string
+	topN        int
+	costRegion  string
+	costNoCache bool
+
+	// Report query flags
+	costMetric         string
+	costGranularity    string
+	costFilters        []string
+	costExcludeCredits bool
+
+	// Forecast command flags
+	forecastBudget          float64
+	forecastQuarterlyBudget float64
+	forecastConfidence      int
+	forecastFormat          string
+	forecastSlackWebhook    string
+
+	// Anomaly command flags
+	anomalyDays         int
+	anomalyGroupBy      string
+	anomalyWindow       int
+	anomalyThreshold    float64
+	anomalyMinImpact    float64
+	anomalyCompareAWS   bool
+	anomalyFormat       string
+	anomalySlackWebhook string
+
+	// CUR command flags
+	curFiles   []string
+	curGroupBy string
+	curTopN    int
+	curMetric  string
+	curFormat  string
+
+	// Commitments command flags
+	commitmentDays           int
+	commitmentExpiringWithin int
+	commitmentMinUtilization float64
+	commitmentLookback       int
+	commitmentTerm           int
+	commitmentPayment        string
+	commitmentFormat         string
+	commitmentSlackWebhook   string
+
+	// Kubernetes allocation command flags
+	k8sCostDays   int
+	k8sCostLabels []string
+	k8sCostFormat string
+
+	// Budget check command flags
+	checkBudgetFile   string
+	checkFormat       string
+	checkJUnitFile    string
+	checkSlackWebhook string
 )
 
 var costCmd = &cobra.Command{
@@ -32,24 +88,263 @@ var costReportCmd = &cobra.Command{
 - Daily/weekly/monthly spending trends
 - Cost by service (EC2, S3, RDS, etc.)
 - Top spending resources
-- Month-over-month comparison
+- Comparison with the preceding period of the same length: change per item,
+  biggest increases and decreases, and new and disappeared items
+
+Group by one or two keys: dimensions such as SERVICE, LINKED_ACCOUNT, REGION
+or INSTANCE_TYPE, cost allocation tags as TAG:<key> and cost categories as
+COST_CATEGORY:<name>. With two keys, each item is broken down by the second.
+Spend without the tag or category is shown as "untagged" or "uncategorized".
+
+Choose the metric with --metric: unblended (default), amortized, net,
+net-unblended, net-amortized, blended or usage (usage quantity, which needs a
+usage-type filter or USAGE_TYPE grouping as units differ). Filter with
+repeatable --filter KEY=value[,value] or KEY!=value[,value] expressions, where
+KEY is service, region, account, usage-type, instance-type, record-type, any
+Cost Explorer dimension, TAG:<key> or COST_CATEGORY:<name>. All filters must
+match. --exclude-credits leaves out credits and refunds.
+
+Closed days are cached on disk per account and query, so repeated runs only
+query Cost Explorer for the last few days. Use --no-cache to query every day.
 
 Example:
   dtk cost report --days 7
   dtk cost report --days 30 --group-by SERVICE
-  dtk cost report --days 90 --format json`,
+  dtk cost report --days 30 --group-by TAG:team
+  dtk cost report --days 30 --group-by LINKED_ACCOUNT,SERVICE
+  dtk cost report --days 90 --format json
+  dtk cost report --days 90 --granularity monthly --metric amortized
+  dtk cost report --days 30 --filter region=us-east-1 --filter TAG:env=prod
+  dtk cost report --days 30 --filter "service!=Tax" --exclude-credits`,
 	RunE: runCostReport,
 }
 
+var costForecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Forecast month-end and quarter-end spend against budgets",
+	Long: `Forecast AWS spend to the end of the current month and quarter with
+Cost Explorer, including a prediction interval, and compare it against budgets:
+
+- Spend so far and average daily burn rate
+- Forecast daily rate and projected total with its confidence range
+- Projected overrun and the day each budget will be exhausted
+
+Budgets given with --budget and --quarterly-budget are used when set; otherwise
+the account's monthly and quarterly cost budgets are read from AWS Budgets.
+Each AWS budget is compared against spend measured the way it tracks it:
+amortized or blended costs, and without credits or refunds if it leaves them
+out. Command-line budgets and the forecast table use unblended costs.
+Periods are calendar months and quarters in UTC, like Cost Explorer.
+
+Example:
+  dtk cost forecast
+  dtk cost forecast --budget 5000 --quarterly-budget 15000
+  dtk cost forecast --confidence 95 --format json
+  dtk cost forecast --slack-webhook https://hooks.slack.com/...`,
+	RunE: runCostForecast,
+}
+
+var costAnomaliesCmd = &cobra.Command{
+	Use:   "anomalies",
+	Short: "Detect daily cost anomalies",
+	Long: `Detect days whose spend is well above a rolling baseline, in total and
+per service, account or other group:
+
+- A day is anomalous when it exceeds the mean of the preceding --window days
+  by more than --threshold standard deviations and by at least --min-impact
+- Anomalies in total spend list the groups that drove them
+- --compare-aws adds results from AWS Cost Anomaly Detection and marks the
+  ones the baseline also flagged
+
+Example:
+  dtk cost anomalies
+  dtk cost anomalies --days 60 --group-by LINKED_ACCOUNT
+  dtk cost anomalies --window 14 --threshold 2.5 --min-impact 50
+  dtk cost anomalies --compare-aws --format json
+  dtk cost anomalies --slack-webhook https://hooks.slack.com/...`,
+	RunE: runCostAnomalies,
+}
+
+var costCURCmd = &cobra.Command{
+	Use:   "cur",
+	Short: "Analyze Cost and Usage Report files",
+	Long: `Analyze Cost and Usage Report (CUR) exports locally, without Cost Explorer
+requests:
+
+- Legacy CUR and CUR 2.0 exports as CSV, gzipped CSV or Parquet
+- The same breakdown and daily trend as dtk cost report
+- Drill down to individual resources with --group-by RESOURCE_ID
+- Files are streamed, so multi-GB exports do not need to fit in memory
+
+Group by one or two keys: SERVICE, LINKED_ACCOUNT, REGION, USAGE_TYPE,
+RESOURCE_ID, INSTANCE_TYPE, OPERATION, RECORD_TYPE (line item type),
+TAG:<key> or COST_CATEGORY:<name>. Pass --file once per export part.
+
+Example:
+  dtk cost cur --file cur-00001.csv.gz
+  dtk cost cur --file cur.parquet --group-by RESOURCE_ID --top 20
+  dtk cost cur --file part1.csv.gz --file part2.csv.gz --group-by TAG:team,SERVICE
+  dtk cost cur --file cur.parquet --metric amortized --format json`,
+	RunE: runCostCUR,
+}
+
+var costCommitmentsCmd = &cobra.Command{
+	Use:   "commitments",
+	Short: "Report Reserved Instance and Savings Plans usage",
+	Long: `Report on Reserved Instances and Savings Plans:
+
+- Utilization, unused commitment and net savings over the last --days
+- Coverage of eligible usage by each commitment type
+- Commitments expiring within --expiring-within days
+- Commitments used less than --min-utilization percent
+- Purchase recommendations from Cost Explorer for the chosen term and payment
+
+With --slack-webhook, an alert is sent when a commitment is expiring or
+under-used.
+
+Example:
+  dtk cost commitments
+  dtk cost commitments --expiring-within 60 --min-utilization 90
+  dtk cost commitments --term 3 --payment all-upfront --lookback 60
+  dtk cost commitments --format json
+  dtk cost commitments --slack-webhook https://hooks.slack.com/...`,
+	RunE: runCostCommitments,
+}
+
+var costK8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Allocate Kubernetes node cost to namespaces and teams",
+	Long: `Show the cost of a Kubernetes cluster on EC2 (such as EKS) per namespace
+and label:
+
+- Nodes are mapped to EC2 instances through their provider ID and priced
+  with the same estimates as the resource audit; instance types without an
+  estimate are reported as unpriced and left out of the totals
+- Each node's cost is split between its pods by CPU and memory requests
+- Capacity no pod requested is reported as idle
+- With --label, cost is also shown per label value, taken from the pod or
+  else its namespace
+
+Costs are today's nodes and pod requests multiplied by --days, not the
+actual node lifetime or usage over the period.
+
+Example:
+  dtk cost k8s
+  dtk cost k8s --days 7 --label team
+  dtk cost k8s --label team --label app.kubernetes.io/part-of
+  dtk cost k8s --context prod-eks
+  dtk cost k8s --format json`,
+	RunE: runCostK8s,
+}
+
+var costCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check spend against the limits in a budget file",
+	Long: `Check spend against monthly and daily limits defined in a budget file, for
+use as a gate in CI pipelines:
+
+- Limits on all spend or per service, account, tag or filter
+- Warning and critical thresholds in percent of each limit
+- Monthly budgets check spend so far and the month-end forecast
+- Daily budgets check yesterday, the last complete day
+
+Exit codes: 0 when all budgets are within their limits, 2 when a budget
+reached its warning threshold and 3 when one reached its critical threshold.
+Other errors exit with 1. A forecast reaching the critical threshold is a
+warning.
+
+Example budget file:
+  metric: unblended
+  budgets:
+    - name: total
+      limit: 10000
+    - name: ec2-daily
+      period: daily
+      service: Amazon Elastic Compute Cloud - Compute
+      limit: 250
+      warning: 90
+    - name: payments-team
+      tag: team=payments
+      limit: 2000
+      critical: 110
+
+Example:
+  dtk cost check --budget-file budgets.yaml
+  dtk cost check --budget-file budgets.yaml --junit budget-report.xml
+  dtk cost check --budget-file budgets.yaml --slack-webhook https://hooks.slack.com/...`,
+	RunE: runCostCheck,
+}
+
 func init() {
 	rootCmd.AddCommand(costCmd)
 	costCmd.AddCommand(costReportCmd)
+	costCmd.AddCommand(costForecastCmd)
+	costCmd.AddCommand(costAnomaliesCmd)
+	costCmd.AddCommand(costCURCmd)
+	costCmd.AddCommand(costCommitmentsCmd)
+	costCmd.AddCommand(costK8sCmd)
+	costCmd.AddCommand(costCheckCmd)
 
 	costReportCmd.Flags().IntVarP(&days, "days", "d", 7, "Number of days to analyze")
-	costReportCmd.Flags().StringVarP(&groupBy, "group-by", "g", "SERVICE", "Group by: SERVICE, REGION, INSTANCE_TYPE")
+	costReportCmd.Flags().StringVarP(&groupBy, "group-by", "g", "SERVICE", "Group by up to two keys: SERVICE, LINKED_ACCOUNT, REGION, INSTANCE_TYPE, TAG:<key>, COST_CATEGORY:<name>")
 	costReportCmd.Flags().IntVarP(&topN, "top", "t", 10, "Show top N spending items")
 	costReportCmd.Flags().StringVarP(&costRegion, "region", "r", "", "AWS region")
 	costReportCmd.Flags().StringVarP(&outputFormat, "format", "f", "table", "Output format: table, json")
+	costReportCmd.Flags().StringVarP(&costMetric, "metric", "m", "unblended", "Cost metric: unblended, amortized, net, net-unblended, net-amortized, blended, usage")
+	costReportCmd.Flags().StringVar(&costGranularity, "granularity", "daily", "Trend granularity: daily, monthly")
+	costReportCmd.Flags().StringArrayVar(&costFilters, "filter", nil, "Filter as KEY=value[,value] or KEY!=value[,value] (repeatable)")
+	costReportCmd.Flags().BoolVar(&costExcludeCredits, "exclude-credits", false, "Exclude credits and refunds")
+	costReportCmd.Flags().BoolVar(&costNoCache, "no-cache", false, "Query Cost Explorer for every day instead of using the local cache")
+
+	costForecastCmd.Flags().Float64Var(&forecastBudget, "budget", 0, "Monthly budget (overrides AWS Budgets)")
+	costForecastCmd.Flags().Float64Var(&forecastQuarterlyBudget, "quarterly-budget", 0, "Quarterly budget (overrides AWS Budgets)")
+	costForecastCmd.Flags().IntVar(&forecastConfidence, "confidence", 80, "Prediction interval confidence level (51-99)")
+	costForecastCmd.Flags().StringVarP(&costRegion, "region", "r", "", "AWS region")
+	costForecastCmd.Flags().StringVarP(&forecastFormat, "format", "f", "table", "Output format: table, json")
+	costForecastCmd.Flags().StringVar(&forecastSlackWebhook, "slack-webhook", "", "Slack webhook URL for alerts when the projection exceeds a budget")
+	costForecastCmd.Flags().BoolVar(&costNoCache, "no-cache", false, "Query Cost Explorer for every day instead of using the local cache")
+
+	costAnomaliesCmd.Flags().IntVarP(&anomalyDays, "days", "d", 30, "Number of days to analyze")
+	costAnomaliesCmd.Flags().StringVarP(&anomalyGroupBy, "group-by", "g", "SERVICE", "Group by: SERVICE, LINKED_ACCOUNT, REGION, INSTANCE_TYPE, TAG:<key>, COST_CATEGORY:<name>")
+	costAnomaliesCmd.Flags().IntVar(&anomalyWindow, "window", aws.DefaultAnomalyOptions.Window, "Days in the rolling baseline")
+	costAnomaliesCmd.Flags().Float64Var(&anomalyThreshold, "threshold", aws.DefaultAnomalyOptions.Threshold, "Standard deviations above the baseline to flag a day")
+	costAnomaliesCmd.Flags().Float64Var(&anomalyMinImpact, "min-impact", aws.DefaultAnomalyOptions.MinImpact, "Minimum increase over the baseline to flag a day")
+	costAnomaliesCmd.Flags().BoolVar(&anomalyCompareAWS, "compare-aws", false, "Include results from AWS Cost Anomaly Detection")
+	costAnomaliesCmd.Flags().StringVarP(&costRegion, "region", "r", "", "AWS region")
+	costAnomaliesCmd.Flags().StringVarP(&anomalyFormat, "format", "f", "table", "Output format: table, json")
+	costAnomaliesCmd.Flags().StringVar(&anomalySlackWebhook, "slack-webhook", "", "Slack webhook URL for anomaly alerts")
+	costAnomaliesCmd.Flags().BoolVar(&costNoCache, "no-cache", false, "Query Cost Explorer for every day instead of using the local cache")
+
+	costCURCmd.Flags().StringArrayVar(&curFiles, "file", nil, "CUR file: CSV, gzipped CSV or Parquet (repeatable)")
+	costCURCmd.Flags().StringVarP(&curGroupBy, "group-by", "g", "SERVICE", "Group by up to two keys: SERVICE, LINKED_ACCOUNT, REGION, USAGE_TYPE, RESOURCE_ID, INSTANCE_TYPE, OPERATION, RECORD_TYPE, TAG:<key>, COST_CATEGORY:<name>")
+	costCURCmd.Flags().IntVarP(&curTopN, "top", "t", 10, "Show top N spending items")
+	costCURCmd.Flags().StringVarP(&curMetric, "metric", "m", "unblended", "Cost metric: unblended, amortized, net, blended, usage")
+	costCURCmd.Flags().StringVarP(&curFormat, "format", "f", "table", "Output format: table, json")
+	costCURCmd.MarkFlagRequired("file")
+
+	costCommitmentsCmd.Flags().IntVarP(&commitmentDays, "days", "d", 30, "Number of days of utilization and coverage to analyze")
+	costCommitmentsCmd.Flags().IntVar(&commitmentExpiringWithin, "expiring-within", 30, "Report commitments expiring within this many days")
+	costCommitmentsCmd.Flags().Float64Var(&commitmentMinUtilization, "min-utilization", 80, "Report commitments with utilization below this percentage")
+	costCommitmentsCmd.Flags().IntVar(&commitmentLookback, "lookback", 30, "Days of usage recommendations are based on: 7, 30, 60")
+	costCommitmentsCmd.Flags().IntVar(&commitmentTerm, "term", 1, "Recommendation term in years: 1, 3")
+	costCommitmentsCmd.Flags().StringVar(&commitmentPayment, "payment", "no-upfront", "Recommendation payment option: no-upfront, partial-upfront, all-upfront")
+	costCommitmentsCmd.Flags().StringVarP(&costRegion, "region", "r", "", "AWS region")
+	costCommitmentsCmd.Flags().StringVarP(&commitmentFormat, "format", "f", "table", "Output format: table, json")
+	costCommitmentsCmd.Flags().StringVar(&commitmentSlackWebhook, "slack-webhook", "", "Slack webhook URL for expiring and under-used commitment alerts")
+
+	costK8sCmd.Flags().IntVarP(&k8sCostDays, "days", "d", 30, "Number of days to allocate cost over")
+	costK8sCmd.Flags().StringArrayVarP(&k8sCostLabels, "label", "l", nil, "Also allocate cost by this pod or namespace label (repeatable)")
+	costK8sCmd.Flags().StringVarP(&costRegion, "region", "r", "", "AWS region of the nodes (default: from node labels)")
+	costK8sCmd.Flags().StringVarP(&k8sCostFormat, "format", "f", "table", "Output format: table, json")
+	addK8sClientFlags(costK8sCmd.Flags())
+
+	costCheckCmd.Flags().StringVar(&checkBudgetFile, "budget-file", "", "YAML file of budgets to check")
+	costCheckCmd.Flags().StringVarP(&costRegion, "region", "r", "", "AWS region")
+	costCheckCmd.Flags().StringVarP(&checkFormat, "format", "f", "table", "Output format: table, json")
+	costCheckCmd.Flags().StringVar(&checkJUnitFile, "junit", "", "Also write the results as a JUnit XML report to this file")
+	costCheckCmd.Flags().StringVar(&checkSlackWebhook, "slack-webhook", "", "Slack webhook URL for budgets over a threshold")
+	costCheckCmd.Flags().BoolVar(&costNoCache, "no-cache", false, "Query Cost Explorer for every day instead of using the local cache")
+	costCheckCmd.MarkFlagRequired("budget-file")
 }
 
 func runCostReport(cmd *cobra.Command, args []string) error {
@@ -62,27 +357,59 @@ func runCostReport(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	fmt.Printf("💰 Generating cost report for last %d days...\n\n", days)
+	// Keep stdout valid JSON for machine-readable output
+	var out io.Writer = os.Stdout
+	if outputFormat != "table" {
+		out = os.Stderr
+	}
+
+	fmt.Fprintf(out, "💰 Generating cost report for last %d days...\n\n", days)
 
 	analyzer, err := aws.NewCostAnalyzer(ctx, costRegion)
 	if err != nil {
 		return fmt.Errorf("failed to create cost analyzer: %w", err)
 	}
 
+	if err := analyzer.SetQueryOptions(aws.CostQueryOptions{
+		Metric:         costMetric,
+		Granularity:    costGranularity,
+		Filters:        costFilters,
+		ExcludeCredits: costExcludeCredits,
+	}); err != nil {
+		return err
+	}
+
+	enableCostCache(ctx, analyzer, out)
+
 	endDate := time.Now()
 	startDate := endDate.AddDate(0, 0, -days)
 
-	fmt.Printf("📅 Period: %s to %s\n", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
-	fmt.Printf("🏷️  Grouping: %s\n\n", groupBy)
+	fmt.Fprintf(out, "📅 Period: %s to %s\n", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	fmt.Fprintf(out, "🏷️  Grouping: %s\n", groupBy)
+	if len(costFilters) > 0 {
+		fmt.Fprintf(out, "🔎 Filters: %s\n", strings.Join(costFilters, "; "))
+	}
+	fmt.Fprintln(out)
 
 	results, err := analyzer.GetCostAndUsage(ctx, startDate, endDate, groupBy)
 	if err != nil {
 		return fmt.Errorf("failed to get cost data: %w", err)
 	}
 
-	// Limit to top N items
+	// Compare with the preceding period of the same length
+	previous, err := analyzer.GetCostAndUsage(ctx, startDate.AddDate(0, 0, -days), startDate, groupBy)
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to get previous period cost data: %v\n\n", err)
+	} else {
+		results.CompareWith(previous)
+	}
+
+	// Limit to top N items after comparing, so movers outside the top are kept
 	results.LimitToTopN(topN)
 
+	printCostQueryStats(out, analyzer)
+	fmt.Fprintln(out)
+
 	// Output results
 	rep := reporter.NewReporter(outputFormat)
 	if err := rep.RenderCostResults(results); err != nil {
@@ -91,3 +418,740 @@ func runCostReport(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// enableCostCache turns on the Cost Explorer cache unless --no-cache is set. The
+// report still runs without it.
+func enableCostCache(ctx context.Context, analyzer *aws.CostAnalyzer, out io.Writer) {
+	if costNoCache {
+		return
+	}
+
+	dir, err := aws.DefaultCostCacheDir()
+	if err == nil {
+		err = analyzer.EnableCache(ctx, dir)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Cost Explorer cache disabled: %v\n", err)
+	}
+}
+
+// printCostQueryStats prints the Cost Explorer requests made, their cost and
+// how many periods came from the cache
+func printCostQueryStats(out io.Writer, analyzer *aws.CostAnalyzer) {
+	stats := analyzer.QueryStats()
+	fmt.Fprintf(out, "🗄️  Cost Explorer: %d request(s) (~$%.2f)", stats.Requests, float64(stats.Requests)*aws.CostExplorerRequestCost)
+	if costNoCache {
+		fmt.Fprintln(out, ", cache disabled")
+		return
+	}
+	fmt.Fprintf(out, ", %d of %d period(s) from cache\n", stats.CachedPeriods, stats.CachedPeriods+stats.FetchedPeriods)
+}
+
+func runCostForecast(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if costRegion == "" {
+		costRegion = os.Getenv("AWS_REGION")
+		if costRegion == "" {
+			costRegion = "us-east-1"
+		}
+	}
+	if forecastBudget < 0 || forecastQuarterlyBudget < 0 {
+		return fmt.Errorf("budgets must be positive")
+	}
+
+	// Keep stdout valid JSON for machine-readable output
+	var out io.Writer = os.Stdout
+	if forecastFormat != "table" {
+		out = os.Stderr
+	}
+
+	fmt.Fprintln(out, "🔮 Forecasting month-end and quarter-end spend...")
+
+	analyzer, err := aws.NewCostAnalyzer(ctx, costRegion)
+	if err != nil {
+		return fmt.Errorf("failed to create cost analyzer: %w", err)
+	}
+	enableCostCache(ctx, analyzer, out)
+
+	results, err := analyzer.GetCostForecast(ctx, time.Now(), int32(forecastConfidence))
+	if err != nil {
+		return fmt.Errorf("failed to forecast costs: %w", err)
+	}
+
+	budgets := forecastBudgets()
+	if len(budgets) == 0 {
+		budgets, err = analyzer.GetBudgets(ctx)
+		if err != nil {
+			fmt.Fprintf(out, "⚠️  Warning: Failed to read AWS Budgets: %v\n", err)
+		}
+	}
+	if err := analyzer.ApplyBudgets(ctx, results, budgets); err != nil {
+		return fmt.Errorf("failed to compare budgets: %w", err)
+	}
+
+	printCostQueryStats(out, analyzer)
+	fmt.Fprintln(out)
+
+	rep := reporter.NewReporter(forecastFormat)
+	if err := rep.RenderForecastResults(results); err != nil {
+		return fmt.Errorf("failed to render results: %w", err)
+	}
+
+	sendForecastSlackAlert(results, out)
+
+	return nil
+}
+
+// forecastBudgets returns the budgets given on the command line
+func forecastBudgets() []aws.CostBudget {
+	budgets := make([]aws.CostBudget, 0)
+	if forecastBudget > 0 {
+		budgets = append(budgets, aws.CostBudget{
+			Name:     "monthly",
+			Source:   aws.BudgetSourceCommandLine,
+			TimeUnit: aws.BudgetPeriodMonthly,
+			Limit:    forecastBudget,
+		})
+	}
+	if forecastQuarterlyBudget > 0 {
+		budgets = append(budgets, aws.CostBudget{
+			Name:     "quarterly",
+			Source:   aws.BudgetSourceCommandLine,
+			TimeUnit: aws.BudgetPeriodQuarterly,
+			Limit:    forecastQuarterlyBudget,
+		})
+	}
+	return budgets
+}
+
+func sendForecastSlackAlert(results *aws.ForecastResults, out io.Writer) {
+	if forecastSlackWebhook == "" {
+		return
+	}
+
+	over := results.OverBudget()
+	if len(over) == 0 {
+		fmt.Fprintln(out, "\nℹ️  Slack webhook configured but spend is projected within budget - no alert sent")
+		return
+	}
+
+	fmt.Fprintln(out, "\n📢 Sending Slack alert...")
+
+	notifier := notify.NewSlackNotifier(forecastSlackWebhook)
+	if err := notifier.SendSlackMessage(buildForecastSlackMessage(results, over)); err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to send Slack alert: %v\n", err)
+	} else {
+		fmt.Fprintln(out, "✅ Slack alert sent successfully!")
+	}
+}
+
+func buildForecastSlackMessage(results *aws.ForecastResults, over []aws.BudgetStatus) notify.SlackMessage {
+	var text string
+	for _, status := range over {
+		text += fmt.Sprintf(":chart_with_upwards_trend: *%s* (%s): projected %.0f%% of $%.2f, overrun *$%.2f*\n",
+			status.Name, status.Period, status.ProjectedPercent, status.Limit, status.ProjectedOverrun)
+		if status.ExhaustionDate != "" {
+			text += fmt.Sprintf("  • Budget exhausted on %s\n", status.ExhaustionDate)
+		}
+	}
+
+	fields := make([]notify.Field, 0, len(results.Periods))
+	for _, period := range results.Periods {
+		fields = append(fields, notify.Field{
+			Title: fmt.Sprintf(":moneybag: Projected %s-end", period.Name),
+			Value: fmt.Sprintf("$%.2f ($%.2f - $%.2f)", period.ProjectedTotal, period.ProjectedLower, period.ProjectedUpper),
+			Short: true,
+		})
+	}
+
+	return notify.SlackMessage{
+		Text: fmt.Sprintf(":rotating_light: *AWS Cost Forecast: projected to exceed %d budget(s)*", len(over)),
+		Attachments: []notify.Attachment{
+			{
+				Color:  "danger",
+				Text:   text,
+				Fields: fields,
+			},
+		},
+	}
+}
+
+func runCostAnomalies(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if costRegion == "" {
+		costRegion = os.Getenv("AWS_REGION")
+		if costRegion == "" {
+			costRegion = "us-east-1"
+		}
+	}
+	if anomalyWindow < 2 {
+		return fmt.Errorf("window must be at least 2 days")
+	}
+	if anomalyDays <= anomalyWindow {
+		return fmt.Errorf("days (%d) must be greater than the window (%d)", anomalyDays, anomalyWindow)
+	}
+
+	// Keep stdout valid JSON for machine-readable output
+	var out io.Writer = os.Stdout
+	if anomalyFormat != "table" {
+		out = os.Stderr
+	}
+
+	fmt.Fprintf(out, "🚨 Detecting cost anomalies over the last %d days...\n", anomalyDays)
+
+	analyzer, err := aws.NewCostAnalyzer(ctx, costRegion)
+	if err != nil {
+		return fmt.Errorf("failed to create cost analyzer: %w", err)
+	}
+	enableCostCache(ctx, analyzer, out)
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -anomalyDays)
+
+	costs, err := analyzer.GetCostAndUsage(ctx, startDate, endDate, anomalyGroupBy)
+	if err != nil {
+		return fmt.Errorf("failed to get cost data: %w", err)
+	}
+
+	results := costs.DetectAnomalies(aws.AnomalyOptions{
+		Window:    anomalyWindow,
+		Threshold: anomalyThreshold,
+		MinImpact: anomalyMinImpact,
+	})
+
+	if anomalyCompareAWS {
+		awsAnomalies, err := analyzer.GetCostAnomalies(ctx, startDate, endDate)
+		if err != nil {
+			fmt.Fprintf(out, "⚠️  Warning: Failed to get AWS Cost Anomaly Detection results: %v\n", err)
+		} else {
+			results.AddAWSAnomalies(awsAnomalies)
+		}
+	}
+	printCostQueryStats(out, analyzer)
+	fmt.Fprintln(out)
+
+	rep := reporter.NewReporter(anomalyFormat)
+	if err := rep.RenderAnomalyResults(results); err != nil {
+		return fmt.Errorf("failed to render results: %w", err)
+	}
+
+	sendAnomalySlackAlert(results, out)
+
+	return nil
+}
+
+func sendAnomalySlackAlert(results *aws.AnomalyResults, out io.Writer) {
+	if anomalySlackWebhook == "" {
+		return
+	}
+
+	if !results.HasAnomalies() {
+		fmt.Fprintln(out, "\nℹ️  Slack webhook configured but no cost anomalies found - no alert sent")
+		return
+	}
+
+	fmt.Fprintln(out, "\n📢 Sending Slack alert...")
+
+	notifier := notify.NewSlackNotifier(anomalySlackWebhook)
+	if err := notifier.SendSlackMessage(buildAnomalySlackMessage(results)); err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to send Slack alert: %v\n", err)
+	} else {
+		fmt.Fprintln(out, "✅ Slack alert sent successfully!")
+	}
+}
+
+func buildAnomalySlackMessage(results *aws.AnomalyResults) notify.SlackMessage {
+	var text string
+
+	if len(results.Total) > 0 {
+		text += fmt.Sprintf(":chart_with_upwards_trend: *Total Spend Anomalies:* %d\n", len(results.Total))
+		for _, anomaly := range results.Total {
+			drivers := make([]string, 0, len(anomaly.Drivers))
+			for _, driver := range anomaly.Drivers {
+				drivers = append(drivers, driver.Name)
+			}
+			text += fmt.Sprintf("  • %s: $%.2f vs $%.2f baseline (+$%.2f)", anomaly.Date, anomaly.Amount, anomaly.Baseline, anomaly.Impact)
+			if len(drivers) > 0 {
+				text += fmt.Sprintf(" - driven by %s", strings.Join(drivers, ", "))
+			}
+			text += "\n"
+		}
+	}
+
+	if len(results.Groups) > 0 {
+		text += fmt.Sprintf(":label: *Anomalies by %s:* %d\n", results.GroupBy, len(results.Groups))
+		for _, anomaly := range results.Groups {
+			text += fmt.Sprintf("  • %s `%s`: $%.2f vs $%.2f baseline (+$%.2f)\n",
+				anomaly.Date, anomaly.Scope, anomaly.Amount, anomaly.Baseline, anomaly.Impact)
+		}
+	}
+
+	if len(results.AWSAnomalies) > 0 {
+		text += fmt.Sprintf(":mag: *AWS Cost Anomaly Detection:* %d\n", len(results.AWSAnomalies))
+		for _, anomaly := range results.AWSAnomalies {
+			text += fmt.Sprintf("  • %s %s: +$%.2f\n", anomaly.StartDate, anomaly.DimensionValue, anomaly.TotalImpact)
+		}
+	}
+
+	totalImpact := 0.0
+	for _, anomaly := range results.Total {
+		totalImpact += anomaly.Impact
+	}
+
+	return notify.SlackMessage{
+		Text: fmt.Sprintf(":rotating_light: *AWS Cost Anomalies*\n%s to %s",
+			results.StartDate.Format("2006-01-02"), results.EndDate.Format("2006-01-02")),
+		Attachments: []notify.Attachment{
+			{
+				Color: "warning",
+				Text:  text,
+				Fields: []notify.Field{
+					{
+						Title: ":moneybag: Impact Above Baseline",
+						Value: fmt.Sprintf("$%.2f", totalImpact),
+						Short: true,
+					},
+					{
+						Title: ":calendar: Anomalous Days",
+						Value: fmt.Sprintf("%d", len(results.Total)),
+						Short: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func runCostCUR(cmd *cobra.Command, args []string) error {
+	var out io.Writer = os.Stdout
+	if curFormat != "table" {
+		out = os.Stderr
+	}
+
+	fmt.Fprintf(out, "💰 Analyzing %d CUR file(s)...\n", len(curFiles))
+	for _, file := range curFiles {
+		fmt.Fprintf(out, "   • %s\n", file)
+	}
+	fmt.Fprintf(out, "🏷️  Grouping: %s\n\n", curGroupBy)
+
+	started := time.Now()
+	results, err := aws.AnalyzeCURFiles(curFiles, aws.CUROptions{
+		GroupBy: curGroupBy,
+		Metric:  curMetric,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to analyze CUR files: %w", err)
+	}
+	fmt.Fprintf(out, "⏱️  Read in %s\n\n", time.Since(started).Round(time.Millisecond))
+
+	results.LimitToTopN(curTopN)
+
+	rep := reporter.NewReporter(curFormat)
+	if err := rep.RenderCostResults(results); err != nil {
+		return fmt.Errorf("failed to render results: %w", err)
+	}
+
+	return nil
+}
+
+func runCostCommitments(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if costRegion == "" {
+		costRegion = os.Getenv("AWS_REGION")
+		if costRegion == "" {
+			costRegion = "us-east-1"
+		}
+	}
+	if commitmentDays < 1 {
+		return fmt.Errorf("days must be at least 1")
+	}
+	recommendationOpts := aws.RecommendationOptions{
+		LookbackDays:  commitmentLookback,
+		TermYears:     commitmentTerm,
+		PaymentOption: commitmentPayment,
+	}
+	if err := recommendationOpts.Validate(); err != nil {
+		return err
+	}
+
+	// Keep stdout valid JSON for machine-readable output
+	var out io.Writer = os.Stdout
+	if commitmentFormat != "table" {
+		out = os.Stderr
+	}
+
+	fmt.Fprintf(out, "🤝 Analyzing commitments over the last %d days...\n", commitmentDays)
+
+	analyzer, err := aws.NewCostAnalyzer(ctx, costRegion)
+	if err != nil {
+		return fmt.Errorf("failed to create cost analyzer: %w", err)
+	}
+
+	now := time.Now()
+	endDate := now
+	startDate := endDate.AddDate(0, 0, -commitmentDays)
+	results := aws.NewCommitmentResults(now, startDate, endDate, aws.CommitmentOptions{
+		ExpiringWithin: commitmentExpiringWithin,
+		MinUtilization: commitmentMinUtilization,
+	})
+
+	// Each section is reported on its own, so one failing does not hide the rest
+	utilization, commitments, err := analyzer.GetReservationCommitments(ctx, startDate, endDate)
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to get Reserved Instances: %v\n", err)
+	} else {
+		results.AddCommitments(utilization, commitments)
+	}
+
+	utilization, commitments, err = analyzer.GetSavingsPlansCommitments(ctx, startDate, endDate)
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to get Savings Plans: %v\n", err)
+	} else {
+		results.AddCommitments(utilization, commitments)
+	}
+
+	coverage, err := analyzer.GetCommitmentCoverage(ctx, startDate, endDate)
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to get coverage: %v\n", err)
+	} else {
+		results.Coverage = coverage
+	}
+
+	recommendations, err := analyzer.GetPurchaseRecommendations(ctx, recommendationOpts)
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to get purchase recommendations: %v\n", err)
+	} else {
+		results.AddRecommendations(recommendations)
+	}
+
+	printCostQueryStats(out, analyzer)
+	fmt.Fprintln(out)
+
+	rep := reporter.NewReporter(commitmentFormat)
+	if err := rep.RenderCommitmentResults(results); err != nil {
+		return fmt.Errorf("failed to render results: %w", err)
+	}
+
+	sendCommitmentSlackAlert(results, out)
+
+	return nil
+}
+
+func sendCommitmentSlackAlert(results *aws.CommitmentResults, out io.Writer) {
+	if commitmentSlackWebhook == "" {
+		return
+	}
+
+	if !results.NeedsAttention() {
+		fmt.Fprintln(out, "\nℹ️  Slack webhook configured but no commitments need attention - no alert sent")
+		return
+	}
+
+	fmt.Fprintln(out, "\n📢 Sending Slack alert...")
+
+	notifier := notify.NewSlackNotifier(commitmentSlackWebhook)
+	if err := notifier.SendSlackMessage(buildCommitmentSlackMessage(results)); err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to send Slack alert: %v\n", err)
+	} else {
+		fmt.Fprintln(out, "✅ Slack alert sent successfully!")
+	}
+}
+
+func buildCommitmentSlackMessage(results *aws.CommitmentResults) notify.SlackMessage {
+	var text string
+
+	expiring := results.Expiring()
+	if len(expiring) > 0 {
+		text += fmt.Sprintf(":hourglass: *Expiring Within %d Days:* %d\n", results.ExpiringWithin, len(expiring))
+		for _, commitment := range expiring {
+			text += fmt.Sprintf("  • %s `%s` %s - ends %s (%d days)\n",
+				commitment.Type, commitment.ID, commitment.Description,
+				commitment.End.Format("2006-01-02"), commitment.DaysRemaining)
+		}
+	}
+
+	for _, utilization := range results.Utilization {
+		if utilization.Low {
+			text += fmt.Sprintf(":chart_with_downwards_trend: *%s Utilization:* %.1f%% ($%.2f unused)\n",
+				utilization.Type, utilization.UtilizationPercent, utilization.UnusedCommitment)
+		}
+	}
+
+	low := results.LowUtilization()
+	if len(low) > 0 {
+		text += fmt.Sprintf(":warning: *Under %.0f%% Utilization:* %d\n", results.MinUtilization, len(low))
+		for _, commitment := range low {
+			text += fmt.Sprintf("  • %s `%s` %s: %.1f%% ($%.2f unused)\n",
+				commitment.Type, commitment.ID, commitment.Description,
+				commitment.UtilizationPercent, commitment.UnusedCommitment)
+		}
+	}
+
+	unused := 0.0
+	for _, utilization := range results.Utilization {
+		unused += utilization.UnusedCommitment
+	}
+
+	color := "warning"
+	for _, commitment := range expiring {
+		if commitment.DaysRemaining <= 7 {
+			color = "danger"
+		}
+	}
+
+	return notify.SlackMessage{
+		Text: fmt.Sprintf(":handshake: *AWS Commitments Need Attention*\n%s to %s",
+			results.StartDate.Format("2006-01-02"), results.EndDate.Format("2006-01-02")),
+		Attachments: []notify.Attachment{
+			{
+				Color: color,
+				Text:  text,
+				Fields: []notify.Field{
+					{
+						Title: ":hourglass: Expiring",
+						Value: fmt.Sprintf("%d", len(expiring)),
+						Short: true,
+					},
+					{
+						Title: ":money_with_wings: Unused Commitment",
+						Value: fmt.Sprintf("$%.2f", unused),
+						Short: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func runCostK8s(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if k8sCostDays < 1 {
+		return fmt.Errorf("days must be at least 1")
+	}
+
+	// Keep stdout valid JSON for machine-readable output
+	var out io.Writer = os.Stdout
+	if k8sCostFormat != "table" {
+		out = os.Stderr
+	}
+
+	fmt.Fprintf(out, "☸️  Allocating node cost over %d days...\n", k8sCostDays)
+
+	checker, err := k8s.NewHealthChecker(k8sClientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create health checker: %w", err)
+	}
+
+	cluster, err := checker.GetClusterRequests(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster requests: %w", err)
+	}
+
+	instanceIDs := make([]string, 0, len(cluster.Nodes))
+	for _, node := range cluster.Nodes {
+		if node.InstanceID != "" {
+			instanceIDs = append(instanceIDs, node.InstanceID)
+		}
+		if costRegion == "" {
+			costRegion = node.Region
+		}
+	}
+	if costRegion == "" {
+		costRegion = os.Getenv("AWS_REGION")
+		if costRegion == "" {
+			costRegion = "us-east-1"
+		}
+	}
+	fmt.Fprintf(out, "🖥️  %d node(s), %d EC2 instance(s) in %s\n", len(cluster.Nodes), len(instanceIDs), costRegion)
+
+	instances := make(map[string]aws.InstanceCost)
+	if len(instanceIDs) > 0 {
+		auditor, err := aws.NewAuditor(ctx, costRegion)
+		if err != nil {
+			return fmt.Errorf("failed to create auditor: %w", err)
+		}
+		instances, err = auditor.GetInstanceCosts(ctx, instanceIDs)
+		if err != nil {
+			fmt.Fprintf(out, "⚠️  Warning: Failed to look up EC2 instances, pricing nodes by their instance type label: %v\n", err)
+			instances = make(map[string]aws.InstanceCost)
+		}
+	}
+
+	// Nodes whose instance type has no price estimate are left unpriced
+	hourlyCosts := make(map[string]float64, len(cluster.Nodes))
+	for _, node := range cluster.Nodes {
+		if instance, ok := instances[node.InstanceID]; ok {
+			if instance.Priced {
+				hourlyCosts[node.Name] = instance.HourlyCost
+			}
+			continue
+		}
+		if node.InstanceType != "" && node.InstanceID != "" {
+			if hourly, ok := aws.InstanceHourlyCost(node.InstanceType); ok {
+				hourlyCosts[node.Name] = hourly
+			}
+		}
+	}
+	fmt.Fprintln(out)
+
+	results := k8s.AllocateCosts(cluster, hourlyCosts, k8s.AllocationOptions{
+		Days:      k8sCostDays,
+		LabelKeys: k8sCostLabels,
+	})
+
+	rep := reporter.NewReporter(k8sCostFormat)
+	if err := rep.RenderAllocationResults(results); err != nil {
+		return fmt.Errorf("failed to render results: %w", err)
+	}
+
+	return nil
+}
+
+// Exit codes of dtk cost check
+const (
+	exitCodeBudgetWarning  = 2
+	exitCodeBudgetCritical = 3
+)
+
+func runCostCheck(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if costRegion == "" {
+		costRegion = os.Getenv("AWS_REGION")
+		if costRegion == "" {
+			costRegion = "us-east-1"
+		}
+	}
+
+	budgetFile, err := aws.LoadBudgetFile(checkBudgetFile)
+	if err != nil {
+		return err
+	}
+
+	// Keep stdout valid JSON for machine-readable output
+	var out io.Writer = os.Stdout
+	if checkFormat != "table" {
+		out = os.Stderr
+	}
+
+	fmt.Fprintf(out, "🚦 Checking %d budget(s) from %s...\n", len(budgetFile.Budgets), checkBudgetFile)
+
+	analyzer, err := aws.NewCostAnalyzer(ctx, costRegion)
+	if err != nil {
+		return fmt.Errorf("failed to create cost analyzer: %w", err)
+	}
+	enableCostCache(ctx, analyzer, out)
+
+	results, err := analyzer.CheckBudgets(ctx, time.Now(), budgetFile)
+	if err != nil {
+		return fmt.Errorf("failed to check budgets: %w", err)
+	}
+	printCostQueryStats(out, analyzer)
+	fmt.Fprintln(out)
+
+	rep := reporter.NewReporter(checkFormat)
+	if err := rep.RenderBudgetCheckResults(results); err != nil {
+		return fmt.Errorf("failed to render results: %w", err)
+	}
+
+	if checkJUnitFile != "" {
+		if err := writeBudgetJUnit(checkJUnitFile, results); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\n📝 JUnit report written to %s\n", checkJUnitFile)
+	}
+
+	sendBudgetCheckSlackAlert(results, out)
+
+	switch results.Status() {
+	case aws.BudgetCheckCritical:
+		return silentExit(cmd, exitCodeBudgetCritical)
+	case aws.BudgetCheckWarning:
+		return silentExit(cmd, exitCodeBudgetWarning)
+	}
+	return nil
+}
+
+func writeBudgetJUnit(path string, results *aws.BudgetCheckResults) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create JUnit report: %w", err)
+	}
+	if err := reporter.WriteBudgetJUnit(file, results); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+func sendBudgetCheckSlackAlert(results *aws.BudgetCheckResults, out io.Writer) {
+	if checkSlackWebhook == "" {
+		return
+	}
+
+	if results.Status() == aws.BudgetCheckOK {
+		fmt.Fprintln(out, "\nℹ️  Slack webhook configured but all budgets are within their limits - no alert sent")
+		return
+	}
+
+	fmt.Fprintln(out, "\n📢 Sending Slack alert...")
+
+	notifier := notify.NewSlackNotifier(checkSlackWebhook)
+	if err := notifier.SendSlackMessage(buildBudgetCheckSlackMessage(results)); err != nil {
+		fmt.Fprintf(out, "⚠️  Warning: Failed to send Slack alert: %v\n", err)
+	} else {
+		fmt.Fprintln(out, "✅ Slack alert sent successfully!")
+	}
+}
+
+func buildBudgetCheckSlackMessage(results *aws.BudgetCheckResults) notify.SlackMessage {
+	var text string
+	critical, warning := 0, 0
+
+	for _, check := range results.Failing() {
+		emoji := ":warning:"
+		if check.Status == aws.BudgetCheckCritical {
+			emoji = ":red_circle:"
+			critical++
+		} else {
+			warning++
+		}
+		text += fmt.Sprintf("%s *%s* (%s, %s): $%.2f of $%.2f - %s\n",
+			emoji, check.Name, check.Period, check.Scope, check.Actual, check.Limit, check.Reason)
+	}
+
+	color := "warning"
+	if critical > 0 {
+		color = "danger"
+	}
+
+	return notify.SlackMessage{
+		Text: fmt.Sprintf(":vertical_traffic_light: *AWS Budget Check*\n%d of %d budget(s) over a threshold",
+			critical+warning, len(results.Checks)),
+		Attachments: []notify.Attachment{
+			{
+				Color: color,
+				Text:  text,
+				Fields: []notify.Field{
+					{
+						Title: ":red_circle: Critical",
+						Value: fmt.Sprintf("%d", critical),
+						Short: true,
+					},
+					{
+						Title: ":warning: Warning",
+						Value: fmt.Sprintf("%d", warning),
+						Short: true,
+					},
+				},
+			},
+		},
+	}
+}
@@ -2,26 +2,58 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/ahmedfawzy/devops-toolkit/pkg/k8s"
 	"github.com/ahmedfawzy/devops-toolkit/pkg/notify"
 	"github.com/ahmedfawzy/devops-toolkit/pkg/reporter"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
 	namespace         string
 	allNamespaces     bool
 	checkNodes        bool
+	problemsOnly      bool
 	expiryDays        int
 	k8sSlackWebhook   string
+
+	// Cluster connection, shared by every command that talks to Kubernetes
+	kubeconfig         string
+	kubeContext        string
+	kubeQPS            float32
+	kubeBurst          int
+	kubeRequestTimeout time.Duration
+
+	// Clusters checked by the dtk k8s commands
+	kubeContexts    string
+	kubeAllContexts bool
 )
 
 var k8sCmd = &cobra.Command{
 	Use:   "k8s",
 	Short: "Kubernetes cluster operations",
-	Long:  "Health checks and diagnostics for Kubernetes clusters",
+	Long: `Health checks and diagnostics for Kubernetes clusters.
+
+The cluster is taken from --kubeconfig, then $KUBECONFIG, then
+~/.kube/config. Without any kubeconfig, dtk uses the pod's service account
+when running inside a cluster.
+
+With --contexts or --all-contexts, the health, certs and pdb checks run
+concurrently against several kubeconfig contexts and are combined into one
+report with a cluster column.
+
+Example:
+  dtk k8s health --context staging
+  dtk k8s certs --kubeconfig ~/.kube/prod.yaml --request-timeout 10s
+  dtk k8s health --all-contexts
+  dtk k8s pdb --contexts prod-eu,prod-us --slack-webhook https://hooks.slack.com/xxx`,
 }
 
 var k8sHealthCmd = &cobra.Command{
@@ -29,8 +61,14 @@ var k8sHealthCmd = &cobra.Command{
 	Short: "Check Kubernetes cluster health",
 	Long: `Perform comprehensive health check on your Kubernetes cluster:
 
-- Pod status across namespaces
-- Failed deployments and statefulsets
+- Pod status across namespaces, with CrashLoopBackOff, image pull,
+  OOMKilled, unschedulable and evicted pods classified as degraded or
+  failing with a reason
+- Failed deployments and statefulsets, including stuck rollouts
+- DaemonSets with unavailable or misscheduled pods
+- Failed jobs and jobs past their active deadline
+- CronJobs that are suspended, missed a schedule or have not succeeded
+  in their last runs
 - Resource usage and limits
 - Node status and capacity
 - Recent warning events
@@ -39,7 +77,9 @@ Example:
   dtk k8s health
   dtk k8s health --namespace default
   dtk k8s health --all-namespaces
-  dtk k8s health --format json`,
+  dtk k8s health --format json
+  dtk k8s health --problems-only
+  dtk k8s health --all-contexts --slack-webhook https://hooks.slack.com/xxx`,
 	RunE: runK8sHealth,
 }
 
@@ -84,10 +124,16 @@ func init() {
 	k8sCmd.AddCommand(k8sCertsCmd)
 	k8sCmd.AddCommand(k8sPDBCmd)
 
+	addK8sClientFlags(k8sCmd.PersistentFlags())
+	k8sCmd.PersistentFlags().StringVar(&kubeContexts, "contexts", "", "Comma-separated kubeconfig contexts to check concurrently")
+	k8sCmd.PersistentFlags().BoolVar(&kubeAllContexts, "all-contexts", false, "Check every context in the kubeconfig concurrently")
+
 	k8sHealthCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace (default: all namespaces)")
 	k8sHealthCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", true, "Check all namespaces")
 	k8sHealthCmd.Flags().BoolVar(&checkNodes, "nodes", true, "Include node health check")
+	k8sHealthCmd.Flags().BoolVar(&problemsOnly, "problems-only", false, "Hide healthy pods and workloads")
 	k8sHealthCmd.Flags().StringVarP(&outputFormat, "format", "f", "table", "Output format: table, json")
+	k8sHealthCmd.Flags().StringVar(&k8sSlackWebhook, "slack-webhook", "", "Slack webhook URL for a per-cluster health summary")
 
 	k8sCertsCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace (default: all namespaces)")
 	k8sCertsCmd.Flags().IntVar(&expiryDays, "expiry-days", 30, "Show certificates expiring within N days")
@@ -97,78 +143,316 @@ func init() {
 	k8sPDBCmd.Flags().StringVar(&k8sSlackWebhook, "slack-webhook", "", "Slack webhook URL for PDB alerts")
 }
 
+// addK8sClientFlags registers the flags selecting and tuning the cluster
+// connection
+func addK8sClientFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
+	flags.StringVar(&kubeContext, "context", "", "Kubeconfig context to use (default: current context)")
+	flags.Float32Var(&kubeQPS, "qps", 0, "Maximum requests per second to the API server (default: client-go default)")
+	flags.IntVar(&kubeBurst, "burst", 0, "Maximum request burst to the API server (default: client-go default)")
+	flags.DurationVar(&kubeRequestTimeout, "request-timeout", 0, "Timeout for each API server request, e.g. 30s (default: no timeout)")
+}
+
+// k8sClientOptions returns the cluster connection options set on the command line
+func k8sClientOptions() k8s.ClientOptions {
+	return k8s.ClientOptions{
+		Kubeconfig: kubeconfig,
+		Context:    kubeContext,
+		QPS:        kubeQPS,
+		Burst:      kubeBurst,
+		Timeout:    kubeRequestTimeout,
+	}
+}
+
+// k8sContexts returns the kubeconfig contexts to check: every context with
+// --all-contexts, the listed ones with --contexts, or else the one selected by
+// --context. An empty name is the current context.
+func k8sContexts() ([]string, error) {
+	if kubeAllContexts && kubeContexts != "" {
+		return nil, fmt.Errorf("--all-contexts and --contexts cannot be used together")
+	}
+	if (kubeAllContexts || kubeContexts != "") && kubeContext != "" {
+		return nil, fmt.Errorf("--context cannot be used with --contexts or --all-contexts")
+	}
+
+	if kubeAllContexts {
+		contexts, err := k8s.ListContexts(k8sClientOptions())
+		if err != nil {
+			return nil, err
+		}
+		if len(contexts) == 0 {
+			return nil, fmt.Errorf("no contexts found in kubeconfig")
+		}
+		return contexts, nil
+	}
+
+	if kubeContexts != "" {
+		var contexts []string
+		for _, name := range strings.Split(kubeContexts, ",") {
+			trimmed := strings.TrimSpace(name)
+			if trimmed != "" {
+				contexts = append(contexts, trimmed)
+			}
+		}
+		if len(contexts) == 0 {
+			return nil, fmt.Errorf("no contexts given in --contexts")
+		}
+		return contexts, nil
+	}
+
+	return []string{kubeContext}, nil
+}
+
+// printClusterFailures lists the clusters that could not be checked
+func printClusterFailures(failures []k8s.ClusterFailure) {
+	for _, failure := range failures {
+		fmt.Printf("⚠️  Could not check cluster %s: %s\n", failure.Cluster, failure.Error)
+	}
+	if len(failures) > 0 {
+		fmt.Println()
+	}
+}
+
+// clusterFailuresError returns an error naming the clusters that could not be
+// checked, or nil when every cluster was checked
+func clusterFailuresError(failures []k8s.ClusterFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	// A single cluster fails with its own error, as before fan-out existed
+	if len(failures) == 1 && failures[0].Cluster == "" {
+		return errors.New(failures[0].Error)
+	}
+
+	names := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		names = append(names, failure.Cluster)
+	}
+	return fmt.Errorf("failed to check %d cluster(s): %s", len(failures), strings.Join(names, ", "))
+}
+
 func runK8sHealth(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	fmt.Println("🏥 Checking Kubernetes cluster health...\n")
-
-	checker, err := k8s.NewHealthChecker()
+	contexts, err := k8sContexts()
 	if err != nil {
-		return fmt.Errorf("failed to create k8s client: %w", err)
+		return err
 	}
 
-	results := &k8s.HealthResults{}
+	fmt.Print("🏥 Checking Kubernetes cluster health...\n\n")
 
-	// Check pod health
-	fmt.Println("🔍 Checking pods...")
 	ns := namespace
 	if allNamespaces {
 		ns = ""
 	}
-	
+
+	// Per-check progress would interleave across clusters
+	progress := io.Writer(os.Stdout)
+	if len(contexts) > 1 {
+		fmt.Printf("☸️  Checking %d clusters: %s\n\n", len(contexts), strings.Join(contexts, ", "))
+		progress = io.Discard
+	}
+
+	clusters, failures := k8s.CheckClusters(ctx, k8sClientOptions(), contexts,
+		func(ctx context.Context, checker *k8s.HealthChecker) (*k8s.HealthResults, error) {
+			return collectHealthResults(ctx, checker, ns, progress)
+		})
+	if len(contexts) > 1 {
+		printClusterFailures(failures)
+	}
+	if len(clusters) == 0 {
+		return clusterFailuresError(failures)
+	}
+
+	results := k8s.MergeHealthResults(clusters...)
+	results.FailedClusters = failures
+
+	fmt.Println()
+
+	// Output results
+	rep := reporter.NewReporter(outputFormat)
+	if err := rep.RenderHealthResults(results); err != nil {
+		return fmt.Errorf("failed to render results: %w", err)
+	}
+
+	if k8sSlackWebhook != "" {
+		fmt.Println("\n📢 Sending Slack summary...")
+
+		notifier := notify.NewSlackNotifier(k8sSlackWebhook)
+		if err := notifier.SendSlackMessage(buildHealthSlackMessage(results)); err != nil {
+			fmt.Printf("⚠️  Warning: Failed to send Slack summary: %v\n", err)
+		} else {
+			fmt.Println("✅ Slack summary sent successfully!")
+		}
+	}
+
+	return clusterFailuresError(failures)
+}
+
+// collectHealthResults runs the health checks against one cluster
+func collectHealthResults(ctx context.Context, checker *k8s.HealthChecker, ns string, progress io.Writer) (*k8s.HealthResults, error) {
+	results := &k8s.HealthResults{
+		Clusters: []string{checker.Cluster()},
+	}
+
+	// Check pod health
+	fmt.Fprintln(progress, "🔍 Checking pods...")
 	podHealth, err := checker.CheckPods(ctx, ns)
 	if err != nil {
-		return fmt.Errorf("failed to check pods: %w", err)
+		return nil, fmt.Errorf("failed to check pods: %w", err)
 	}
 	results.Pods = podHealth
 
 	// Check deployments
-	fmt.Println("📦 Checking deployments...")
+	fmt.Fprintln(progress, "📦 Checking deployments...")
 	deployments, err := checker.CheckDeployments(ctx, ns)
 	if err != nil {
-		return fmt.Errorf("failed to check deployments: %w", err)
+		return nil, fmt.Errorf("failed to check deployments: %w", err)
 	}
 	results.Deployments = deployments
 
+	// Check other workloads
+	fmt.Fprintln(progress, "🗄️  Checking statefulsets...")
+	statefulSets, err := checker.CheckStatefulSets(ctx, ns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check statefulsets: %w", err)
+	}
+	results.StatefulSets = statefulSets
+
+	fmt.Fprintln(progress, "🧩 Checking daemonsets...")
+	daemonSets, err := checker.CheckDaemonSets(ctx, ns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check daemonsets: %w", err)
+	}
+	results.DaemonSets = daemonSets
+
+	fmt.Fprintln(progress, "⚙️  Checking jobs and cronjobs...")
+	jobs, err := checker.CheckJobs(ctx, ns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check jobs: %w", err)
+	}
+	results.Jobs = jobs
+
+	cronJobs, err := checker.CheckCronJobs(ctx, ns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cronjobs: %w", err)
+	}
+	results.CronJobs = cronJobs
+
 	// Check nodes
 	if checkNodes {
-		fmt.Println("🖥️  Checking nodes...")
+		fmt.Fprintln(progress, "🖥️  Checking nodes...")
 		nodes, err := checker.CheckNodes(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to check nodes: %w", err)
+			return nil, fmt.Errorf("failed to check nodes: %w", err)
 		}
 		results.Nodes = nodes
 	}
 
 	// Check recent events
-	fmt.Println("📋 Checking recent events...")
+	fmt.Fprintln(progress, "📋 Checking recent events...")
 	events, err := checker.GetRecentWarningEvents(ctx, ns)
 	if err != nil {
-		return fmt.Errorf("failed to check events: %w", err)
+		return nil, fmt.Errorf("failed to check events: %w", err)
 	}
 	results.WarningEvents = events
 
-	fmt.Println()
+	results.Summaries = results.ClusterSummaries()
+	if problemsOnly {
+		results.HideHealthy()
+	}
 
-	// Output results
-	rep := reporter.NewReporter(outputFormat)
-	if err := rep.RenderHealthResults(results); err != nil {
-		return fmt.Errorf("failed to render results: %w", err)
+	return results, nil
+}
+
+// buildHealthSlackMessage builds a Slack summary of the health of each cluster
+func buildHealthSlackMessage(results *k8s.HealthResults) notify.SlackMessage {
+	summaries := results.Summaries
+
+	var text string
+	problemClusters := 0
+	var podsFailing, deploymentsUnavailable, workloadsFailing, nodesNotReady int
+	for _, summary := range summaries {
+		podsFailing += summary.PodsFailing
+		workloadsFailing += summary.WorkloadsFailing
+		deploymentsUnavailable += summary.DeploymentsUnavailable
+		nodesNotReady += summary.NodesNotReady
+
+		if !summary.HasProblems() {
+			text += fmt.Sprintf("🟢 *%s* - healthy (%d pods, %d nodes)\n", summary.Cluster, summary.Pods, summary.Nodes)
+			continue
+		}
+
+		problemClusters++
+		label := "🟡"
+		if summary.PodsFailing > 0 || summary.WorkloadsFailing > 0 || summary.NodesNotReady > 0 {
+			label = "🔴"
+		}
+		text += fmt.Sprintf("%s *%s* - %d pod(s) failing, %d degraded, %d deployment(s) unavailable, %d workload(s) failing, %d degraded, %d node(s) not ready\n",
+			label, summary.Cluster, summary.PodsFailing, summary.PodsDegraded, summary.DeploymentsUnavailable,
+			summary.WorkloadsFailing, summary.WorkloadsDegraded, summary.NodesNotReady)
+	}
+	for _, failure := range results.FailedClusters {
+		text += fmt.Sprintf("⚪ *%s* - could not be checked: %s\n", failure.Cluster, failure.Error)
 	}
 
-	return nil
+	color := "good"
+	if podsFailing > 0 || workloadsFailing > 0 || nodesNotReady > 0 || len(results.FailedClusters) > 0 {
+		color = "danger"
+	} else if problemClusters > 0 {
+		color = "warning"
+	}
+
+	return notify.SlackMessage{
+		Text: fmt.Sprintf(":hospital: *Kubernetes Health Summary*\n%d of %d cluster(s) need attention",
+			problemClusters+len(results.FailedClusters), len(summaries)+len(results.FailedClusters)),
+		Attachments: []notify.Attachment{
+			{
+				Color: color,
+				Text:  text,
+				Fields: []notify.Field{
+					{
+						Title: ":package: Pods Failing",
+						Value: fmt.Sprintf("%d", podsFailing),
+						Short: true,
+					},
+					{
+						Title: ":warning: Deployments Unavailable",
+						Value: fmt.Sprintf("%d", deploymentsUnavailable),
+						Short: true,
+					},
+					{
+						Title: ":gear: Workloads Failing",
+						Value: fmt.Sprintf("%d", workloadsFailing),
+						Short: true,
+					},
+					{
+						Title: ":computer: Nodes Not Ready",
+						Value: fmt.Sprintf("%d", nodesNotReady),
+						Short: true,
+					},
+					{
+						Title: ":x: Unreachable Clusters",
+						Value: fmt.Sprintf("%d", len(results.FailedClusters)),
+						Short: true,
+					},
+				},
+			},
+		},
+	}
 }
 
 func runK8sCerts(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	fmt.Println("🔐 Checking TLS certificate expiry in Kubernetes...\n")
-
-	checker, err := k8s.NewHealthChecker()
+	contexts, err := k8sContexts()
 	if err != nil {
-		return fmt.Errorf("failed to create k8s client: %w", err)
+		return err
 	}
 
+	fmt.Print("🔐 Checking TLS certificate expiry in Kubernetes...\n\n")
+
 	// Determine namespace
 	ns := namespace
 	if ns == "" {
@@ -179,10 +463,24 @@ func runK8sCerts(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check certificate expiry
-	results, err := checker.CheckCertificateExpiry(ctx, ns, expiryDays)
-	if err != nil {
-		return fmt.Errorf("failed to check certificates: %w", err)
+	clusters, failures := k8s.CheckClusters(ctx, k8sClientOptions(), contexts,
+		func(ctx context.Context, checker *k8s.HealthChecker) (*k8s.CertificateResults, error) {
+			results, err := checker.CheckCertificateExpiry(ctx, ns, expiryDays)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check certificates: %w", err)
+			}
+			return results, nil
+		})
+	multiCluster := len(contexts) > 1
+	if multiCluster {
+		printClusterFailures(failures)
 	}
+	if len(clusters) == 0 {
+		return clusterFailuresError(failures)
+	}
+
+	results := k8s.MergeCertificateResults(clusters...)
+	results.FailedClusters = failures
 
 	// Display results with color coding
 	if len(results.Certificates) == 0 {
@@ -193,6 +491,9 @@ func runK8sCerts(cmd *cobra.Command, args []string) error {
 			len(results.Certificates), expiryDays, results.TotalScanned)
 
 		// Print header
+		if multiCluster {
+			fmt.Printf("%-20s ", "CLUSTER")
+		}
 		fmt.Printf("%-30s %-20s %-15s %-40s %-20s %s\n",
 			"SECRET", "NAMESPACE", "DAYS REMAINING", "DNS NAMES", "EXPIRY DATE", "STATUS")
 		fmt.Println("─────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────")
@@ -221,8 +522,11 @@ func runK8sCerts(cmd *cobra.Command, args []string) error {
 
 			expiryStr := cert.ExpiryDate.Format("2006-01-02 15:04")
 
-			fmt.Printf("%s%-30s %-20s %-15d %-40s %-20s %s%s\n",
-				color,
+			fmt.Print(color)
+			if multiCluster {
+				fmt.Printf("%-20s ", truncateCluster(cert.Cluster))
+			}
+			fmt.Printf("%-30s %-20s %-15d %-40s %-20s %s%s\n",
 				secretName,
 				ns,
 				cert.DaysRemaining,
@@ -246,7 +550,7 @@ func runK8sCerts(cmd *cobra.Command, args []string) error {
 	}
 
 	// Send Slack alert if configured and certificates are expiring
-	if k8sSlackWebhook != "" && len(results.Certificates) > 0 {
+	if k8sSlackWebhook != "" && (len(results.Certificates) > 0 || len(results.FailedClusters) > 0) {
 		fmt.Println("\n📢 Sending Slack alert...")
 
 		notifier := notify.NewSlackNotifier(k8sSlackWebhook)
@@ -254,6 +558,9 @@ func runK8sCerts(cmd *cobra.Command, args []string) error {
 		// Build alert message
 		message := fmt.Sprintf("Found %d TLS certificate(s) expiring within %d days",
 			len(results.Certificates), expiryDays)
+		if multiCluster {
+			message += fmt.Sprintf(" across %d cluster(s)", len(results.Clusters))
+		}
 
 		// Create a pseudo-AWS AuditResults for Slack formatting
 		// We'll format it as a custom message instead
@@ -267,78 +574,124 @@ func runK8sCerts(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\nℹ️  Slack webhook configured but no certificates expiring within %d days - no alert sent\n", expiryDays)
 	}
 
-	return nil
+	return clusterFailuresError(failures)
+}
+
+// truncateCluster shortens a cluster name to fit the cluster column
+func truncateCluster(cluster string) string {
+	if len(cluster) > 18 {
+		return cluster[:15] + "..."
+	}
+	return cluster
+}
+
+// findingName returns the namespace/name shown in a Slack alert, prefixed
+// with the cluster when several clusters were checked
+func findingName(clusters []string, cluster, namespace, name string) string {
+	if len(clusters) > 1 {
+		return fmt.Sprintf("%s: %s/%s", cluster, namespace, name)
+	}
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// formatClusterFailures lists the clusters that could not be checked for a
+// Slack alert
+func formatClusterFailures(failures []k8s.ClusterFailure) string {
+	var text string
+	for _, failure := range failures {
+		text += fmt.Sprintf("⚪ *%s* - could not be checked: %s\n", failure.Cluster, failure.Error)
+	}
+	return text
+}
+
+// certAlertCounts holds the certificate counts shown in a certificate expiry Slack alert
+type certAlertCounts struct {
+	Found    int
+	Critical int
+	Expiring int
+	Expired  int
 }
 
 // sendCertSlackAlert sends a certificate expiry alert to Slack
 func sendCertSlackAlert(notifier *notify.SlackNotifier, message string, results *k8s.CertificateResults) error {
-	// For now, we'll use a simple text message
-	// TODO: Implement proper Slack message formatting for certificates
-	slackMsg := notify.SlackMessage{
-		Text: fmt.Sprintf(":lock: *Kubernetes TLS Certificate Expiry Alert*\n%s", message),
+	counts := certAlertCounts{
+		Found:    len(results.Certificates),
+		Critical: results.CriticalCount,
+		Expiring: results.ExpiringCount,
+		Expired:  results.ExpiredCount,
+	}
+
+	slackMsg := buildCertSlackMessage(":lock: *Kubernetes TLS Certificate Expiry Alert*", message, formatCertFindings(results), counts)
+
+	// Send using the notifier's internal HTTP client
+	return notifier.SendSlackMessage(slackMsg)
+}
+
+// buildCertSlackMessage builds the Slack message used for all certificate expiry alerts
+func buildCertSlackMessage(title, message, findings string, counts certAlertCounts) notify.SlackMessage {
+	return notify.SlackMessage{
+		Text: fmt.Sprintf("%s\n%s", title, message),
 		Attachments: []notify.Attachment{
 			{
-				Color: determineCertColor(results),
-				Text:  formatCertFindings(results),
+				Color: determineCertColor(counts),
+				Text:  findings,
 				Fields: []notify.Field{
 					{
 						Title: ":calendar: Certificates Found",
-						Value: fmt.Sprintf("%d", len(results.Certificates)),
+						Value: fmt.Sprintf("%d", counts.Found),
 						Short: true,
 					},
 					{
 						Title: ":warning: Critical (<7 days)",
-						Value: fmt.Sprintf("%d", results.CriticalCount),
+						Value: fmt.Sprintf("%d", counts.Critical),
 						Short: true,
 					},
 					{
 						Title: ":hourglass: Expiring Soon (<30 days)",
-						Value: fmt.Sprintf("%d", results.ExpiringCount),
+						Value: fmt.Sprintf("%d", counts.Expiring),
 						Short: true,
 					},
 					{
 						Title: ":x: Expired",
-						Value: fmt.Sprintf("%d", results.ExpiredCount),
+						Value: fmt.Sprintf("%d", counts.Expired),
 						Short: true,
 					},
 				},
 			},
 		},
 	}
-
-	// Send using the notifier's internal HTTP client
-	return notifier.SendSlackMessage(slackMsg)
 }
 
-func determineCertColor(results *k8s.CertificateResults) string {
-	if results.ExpiredCount > 0 || results.CriticalCount > 0 {
+func determineCertColor(counts certAlertCounts) string {
+	if counts.Expired > 0 || counts.Critical > 0 {
 		return "danger" // Red
-	} else if results.ExpiringCount > 0 {
+	} else if counts.Expiring > 0 {
 		return "warning" // Yellow
 	}
 	return "good" // Green
 }
 
+// certStatusLabel returns the Slack label for a certificate status
+func certStatusLabel(status string) string {
+	switch status {
+	case "expired":
+		return "🔴 EXPIRED"
+	case "critical":
+		return "🟠 CRITICAL"
+	case "expiring-soon":
+		return "🟡 EXPIRING SOON"
+	default:
+		return "🟢 VALID"
+	}
+}
+
 func formatCertFindings(results *k8s.CertificateResults) string {
 	var text string
 
 	for _, cert := range results.Certificates {
-		status := ""
-		switch cert.Status {
-		case "expired":
-			status = "🔴 EXPIRED"
-		case "critical":
-			status = "🟠 CRITICAL"
-		case "expiring-soon":
-			status = "🟡 EXPIRING SOON"
-		default:
-			status = "🟢 VALID"
-		}
-
-		text += fmt.Sprintf("%s *%s/%s* - %d days remaining (%s)\n",
-			status,
-			cert.Namespace,
-			cert.SecretName,
+		text += fmt.Sprintf("%s *%s* - %d days remaining (%s)\n",
+			certStatusLabel(cert.Status),
+			findingName(results.Clusters, cert.Cluster, cert.Namespace, cert.SecretName),
 			cert.DaysRemaining,
 			cert.FormatDNSNames())
 	}
@@ -347,32 +700,46 @@ func formatCertFindings(results *k8s.CertificateResults) string {
 		text = "✅ All certificates are valid"
 	}
 
-	return text
+	return text + formatClusterFailures(results.FailedClusters)
 }
 
 func runK8sPDB(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	fmt.Println("🛡️  Checking PodDisruptionBudget status...\n")
-
-	checker, err := k8s.NewHealthChecker()
+	contexts, err := k8sContexts()
 	if err != nil {
-		return fmt.Errorf("failed to create k8s client: %w", err)
+		return err
 	}
 
+	fmt.Print("🛡️  Checking PodDisruptionBudget status...\n\n")
+
 	// Determine namespace
 	ns := namespace
 	if ns == "" {
-		fmt.Println("Scanning all namespaces for PodDisruptionBudgets...\n")
+		fmt.Print("Scanning all namespaces for PodDisruptionBudgets...\n\n")
 	} else {
 		fmt.Printf("Scanning namespace '%s' for PodDisruptionBudgets...\n\n", ns)
 	}
 
 	// Check PDB status
-	results, err := checker.CheckPDBStatus(ctx, ns)
-	if err != nil {
-		return fmt.Errorf("failed to check PDBs: %w", err)
+	clusters, failures := k8s.CheckClusters(ctx, k8sClientOptions(), contexts,
+		func(ctx context.Context, checker *k8s.HealthChecker) (*k8s.PDBResults, error) {
+			results, err := checker.CheckPDBStatus(ctx, ns)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check PDBs: %w", err)
+			}
+			return results, nil
+		})
+	multiCluster := len(contexts) > 1
+	if multiCluster {
+		printClusterFailures(failures)
 	}
+	if len(clusters) == 0 {
+		return clusterFailuresError(failures)
+	}
+
+	results := k8s.MergePDBResults(clusters...)
+	results.FailedClusters = failures
 
 	// Display results
 	if len(results.PDBs) == 0 {
@@ -380,8 +747,14 @@ func runK8sPDB(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Printf("🛡️  PodDisruptionBudget Status\n")
 		fmt.Println("─────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────")
+		if multiCluster {
+			fmt.Printf("%-20s ", "CLUSTER")
+		}
 		fmt.Printf("%-20s %-25s %-15s %-10s %-10s %s\n",
 			"NAMESPACE", "NAME", "MIN AVAIL", "CURRENT", "ALLOWED", "STATUS")
+		if multiCluster {
+			fmt.Print("────────────────────┼")
+		}
 		fmt.Println("────────────────────┼─────────────────────────┼───────────────┼──────────┼──────────┼────────────────────────────────────")
 
 		// Print each PDB with color coding
@@ -405,8 +778,11 @@ func runK8sPDB(cmd *cobra.Command, args []string) error {
 				minAvail = minAvail[:10] + "..."
 			}
 
-			fmt.Printf("%s%-20s %-25s %-15s %-10d %-10d %s%s\n",
-				color,
+			fmt.Print(color)
+			if multiCluster {
+				fmt.Printf("%-20s ", truncateCluster(pdb.Cluster))
+			}
+			fmt.Printf("%-20s %-25s %-15s %-10d %-10d %s%s\n",
 				ns,
 				name,
 				minAvail,
@@ -433,7 +809,7 @@ func runK8sPDB(cmd *cobra.Command, args []string) error {
 	}
 
 	// Send Slack alert if configured and issues found
-	if k8sSlackWebhook != "" && (results.AtRiskCount > 0 || results.CriticalCount > 0 || results.NoPodsCount > 0) {
+	if k8sSlackWebhook != "" && (results.AtRiskCount > 0 || results.CriticalCount > 0 || results.NoPodsCount > 0 || len(results.FailedClusters) > 0) {
 		fmt.Println("\n📢 Sending Slack alert...")
 
 		notifier := notify.NewSlackNotifier(k8sSlackWebhook)
@@ -441,6 +817,9 @@ func runK8sPDB(cmd *cobra.Command, args []string) error {
 		// Build alert message
 		message := fmt.Sprintf("Found %d PodDisruptionBudget issue(s)",
 			results.AtRiskCount+results.CriticalCount+results.NoPodsCount)
+		if multiCluster {
+			message += fmt.Sprintf(" across %d cluster(s)", len(results.Clusters))
+		}
 
 		err := sendPDBSlackAlert(notifier, message, results)
 		if err != nil {
@@ -452,7 +831,7 @@ func runK8sPDB(cmd *cobra.Command, args []string) error {
 		fmt.Println("\nℹ️  Slack webhook configured but all PDBs are healthy - no alert sent")
 	}
 
-	return nil
+	return clusterFailuresError(failures)
 }
 
 // sendPDBSlackAlert sends a PDB status alert to Slack
@@ -520,10 +899,9 @@ func formatPDBFindings(results *k8s.PDBResults) string {
 			status = "⚪ NO-PODS"
 		}
 
-		text += fmt.Sprintf("%s *%s/%s* - %s\n",
+		text += fmt.Sprintf("%s *%s* - %s\n",
 			status,
-			pdb.Namespace,
-			pdb.Name,
+			findingName(results.Clusters, pdb.Cluster, pdb.Namespace, pdb.Name),
 			pdb.StatusMessage)
 	}
 
@@ -531,6 +909,6 @@ func formatPDBFindings(results *k8s.PDBResults) string {
 		text = "✅ All PDBs are healthy"
 	}
 
-	return text
+	return text + formatClusterFailures(results.FailedClusters)
 }
 
@@ -30,6 +30,23 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// ExitError asks main to exit with Code without printing an error, for
+// commands whose result is reported through the exit code
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// silentExit returns an ExitError after stopping cobra from printing it
+func silentExit(cmd *cobra.Command, code int) error {
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &ExitError{Code: code}
+}
+
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	
@@ -0,0 +1,344 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// AnomalyScopeTotal is the scope of anomalies in the total daily cost
+const AnomalyScopeTotal = "Total"
+
+// maxAnomalyDrivers is the number of groups reported as drivers of a total anomaly
+const maxAnomalyDrivers = 5
+
+// AnomalyOptions configures daily cost anomaly detection
+type AnomalyOptions struct {
+	// Window is the number of preceding days in the rolling baseline
+	Window int
+	// Threshold is how many standard deviations above the baseline a day must be
+	Threshold float64
+	// MinImpact is the minimum increase over the baseline worth reporting
+	MinImpact float64
+}
+
+// DefaultAnomalyOptions are the defaults of dtk cost anomalies, also used to
+// mark anomalies in cost report charts
+var DefaultAnomalyOptions = AnomalyOptions{Window: 7, Threshold: 3, MinImpact: 10}
+
+// CostAnomaly is a day whose cost is well above its rolling baseline
+type CostAnomaly struct {
+	Date          string
+	Scope         string // AnomalyScopeTotal or a group key
+	Amount        float64
+	Baseline      float64
+	StdDev        float64
+	Impact        float64
+	ImpactPercent float64
+	// Score is the number of standard deviations above the baseline, 0 if the baseline is flat
+	Score   float64
+	Drivers []AnomalyDriver
+}
+
+// AnomalyDriver is a group whose increase contributed to a total anomaly
+type AnomalyDriver struct {
+	Name     string
+	Amount   float64
+	Baseline float64
+	Impact   float64
+}
+
+// AWSCostAnomaly is an anomaly reported by AWS Cost Anomaly Detection
+type AWSCostAnomaly struct {
+	ID             string
+	MonitorARN     string
+	StartDate      string
+	EndDate        string
+	DimensionValue string
+	TotalImpact    float64
+	MaxImpact      float64
+	ExpectedSpend  float64
+	ActualSpend    float64
+	Score          float64
+	RootCauses     []string
+	// Detected is true when the rolling baseline flagged one of the same days
+	Detected bool
+}
+
+// AnomalyResults holds the anomalies found in a cost report
+type AnomalyResults struct {
+	StartDate    time.Time
+	EndDate      time.Time
+	GroupBy      string
+	Currency     string
+	Options      AnomalyOptions
+	Total        []CostAnomaly
+	Groups       []CostAnomaly
+	AWSAnomalies []AWSCostAnomaly
+}
+
+// DetectAnomalies flags days whose cost exceeds the mean of the preceding
+// window by more than the threshold in standard deviations and by at least
+// the minimum impact. Only increases are reported. Anomalies in the total
+// list the groups that drove them.
+func (r *CostResults) DetectAnomalies(opts AnomalyOptions) *AnomalyResults {
+	results := &AnomalyResults{
+		StartDate: r.StartDate,
+		EndDate:   r.EndDate,
+		GroupBy:   r.GroupByLabels()[0],
+		Currency:  r.Currency,
+		Options:   opts,
+		Total:     detectAnomalies(r.DailyTrend, AnomalyScopeTotal, opts),
+		Groups:    make([]CostAnomaly, 0),
+	}
+
+	for i := range results.Total {
+		results.Total[i].Drivers = anomalyDrivers(r.DailyByGroup, results.Total[i].Date, opts.Window)
+	}
+
+	for key, series := range r.DailyByGroup {
+		results.Groups = append(results.Groups, detectAnomalies(series, key, opts)...)
+	}
+	sort.Slice(results.Groups, func(i, j int) bool {
+		if results.Groups[i].Date != results.Groups[j].Date {
+			return results.Groups[i].Date < results.Groups[j].Date
+		}
+		if results.Groups[i].Impact != results.Groups[j].Impact {
+			return results.Groups[i].Impact > results.Groups[j].Impact
+		}
+		return results.Groups[i].Scope < results.Groups[j].Scope
+	})
+
+	return results
+}
+
+// HasAnomalies reports whether any anomaly was detected or reported by AWS
+func (r *AnomalyResults) HasAnomalies() bool {
+	return len(r.Total) > 0 || len(r.Groups) > 0 || len(r.AWSAnomalies) > 0
+}
+
+// AddAWSAnomalies records anomalies from AWS Cost Anomaly Detection and marks
+// those that overlap a day flagged by the rolling baseline
+func (r *AnomalyResults) AddAWSAnomalies(anomalies []AWSCostAnomaly) {
+	flagged := make(map[string]bool)
+	for _, anomalies := range [][]CostAnomaly{r.Total, r.Groups} {
+		for _, anomaly := range anomalies {
+			flagged[anomaly.Date] = true
+		}
+	}
+
+	if r.AWSAnomalies == nil {
+		r.AWSAnomalies = make([]AWSCostAnomaly, 0, len(anomalies))
+	}
+
+	lastDay := r.EndDate.AddDate(0, 0, -1).Format(costDateLayout)
+	for _, anomaly := range anomalies {
+		end := anomaly.EndDate
+		if end == "" {
+			// Anomalies still in progress have no end date
+			end = lastDay
+		}
+		for date := range flagged {
+			if date >= anomaly.StartDate && date <= end {
+				anomaly.Detected = true
+				break
+			}
+		}
+		r.AWSAnomalies = append(r.AWSAnomalies, anomaly)
+	}
+}
+
+// GetCostAnomalies returns the anomalies AWS Cost Anomaly Detection found
+// between start and end
+func (c *CostAnalyzer) GetCostAnomalies(ctx context.Context, startDate, endDate time.Time) ([]AWSCostAnomaly, error) {
+	input := &costexplorer.GetAnomaliesInput{
+		DateInterval: &cetypes.AnomalyDateInterval{
+			StartDate: aws.String(startDate.Format(costDateLayout)),
+			EndDate:   aws.String(endDate.Format(costDateLayout)),
+		},
+	}
+
+	anomalies := make([]AWSCostAnomaly, 0)
+	for {
+		result, err := c.client.GetAnomalies(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cost anomalies: %w", err)
+		}
+		c.stats.Requests++
+		for _, anomaly := range result.Anomalies {
+			anomalies = append(anomalies, toAWSCostAnomaly(anomaly))
+		}
+
+		if result.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = result.NextPageToken
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		return anomalies[i].StartDate < anomalies[j].StartDate
+	})
+
+	return anomalies, nil
+}
+
+// detectAnomalies compares each day with the mean and standard deviation of
+// the preceding window
+func detectAnomalies(series []DailyCost, scope string, opts AnomalyOptions) []CostAnomaly {
+	anomalies := make([]CostAnomaly, 0)
+	if opts.Window < 1 {
+		return anomalies
+	}
+
+	for i := opts.Window; i < len(series); i++ {
+		baseline, stdDev := meanStdDev(series[i-opts.Window : i])
+		impact := series[i].Amount - baseline
+		if impact <= 0 || impact < opts.MinImpact {
+			continue
+		}
+
+		// A flat baseline has no deviation, so any increase above the minimum impact counts
+		score := 0.0
+		if stdDev > 0 {
+			score = impact / stdDev
+			if score < opts.Threshold {
+				continue
+			}
+		}
+
+		anomaly := CostAnomaly{
+			Date:     series[i].Date,
+			Scope:    scope,
+			Amount:   series[i].Amount,
+			Baseline: baseline,
+			StdDev:   stdDev,
+			Impact:   impact,
+			Score:    score,
+		}
+		if baseline > 0 {
+			anomaly.ImpactPercent = impact / baseline * 100
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+
+	return anomalies
+}
+
+// anomalyDrivers returns the groups with the largest increase over their own
+// baseline on the given day
+func anomalyDrivers(dailyByGroup map[string][]DailyCost, date string, window int) []AnomalyDriver {
+	drivers := make([]AnomalyDriver, 0)
+	for key, series := range dailyByGroup {
+		for i, day := range series {
+			if day.Date != date {
+				continue
+			}
+			start := i - window
+			if start < 0 {
+				start = 0
+			}
+			baseline := 0.0
+			if i > start {
+				baseline, _ = meanStdDev(series[start:i])
+			}
+			if impact := day.Amount - baseline; impact > 0 {
+				drivers = append(drivers, AnomalyDriver{
+					Name:     key,
+					Amount:   day.Amount,
+					Baseline: baseline,
+					Impact:   impact,
+				})
+			}
+			break
+		}
+	}
+
+	sort.Slice(drivers, func(i, j int) bool {
+		if drivers[i].Impact != drivers[j].Impact {
+			return drivers[i].Impact > drivers[j].Impact
+		}
+		return drivers[i].Name < drivers[j].Name
+	})
+	if len(drivers) > maxAnomalyDrivers {
+		drivers = drivers[:maxAnomalyDrivers]
+	}
+
+	return drivers
+}
+
+// meanStdDev returns the mean and population standard deviation of the daily amounts
+func meanStdDev(days []DailyCost) (float64, float64) {
+	if len(days) == 0 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for _, day := range days {
+		sum += day.Amount
+	}
+	mean := sum / float64(len(days))
+
+	variance := 0.0
+	for _, day := range days {
+		variance += (day.Amount - mean) * (day.Amount - mean)
+	}
+
+	return mean, math.Sqrt(variance / float64(len(days)))
+}
+
+// toAWSCostAnomaly converts a Cost Anomaly Detection anomaly
+func toAWSCostAnomaly(anomaly cetypes.Anomaly) AWSCostAnomaly {
+	result := AWSCostAnomaly{
+		ID:             aws.ToString(anomaly.AnomalyId),
+		MonitorARN:     aws.ToString(anomaly.MonitorArn),
+		StartDate:      anomalyDate(anomaly.AnomalyStartDate),
+		EndDate:        anomalyDate(anomaly.AnomalyEndDate),
+		DimensionValue: aws.ToString(anomaly.DimensionValue),
+		RootCauses:     make([]string, 0, len(anomaly.RootCauses)),
+	}
+
+	if anomaly.Impact != nil {
+		result.TotalImpact = anomaly.Impact.TotalImpact
+		result.MaxImpact = anomaly.Impact.MaxImpact
+		result.ExpectedSpend = aws.ToFloat64(anomaly.Impact.TotalExpectedSpend)
+		result.ActualSpend = aws.ToFloat64(anomaly.Impact.TotalActualSpend)
+	}
+	if anomaly.AnomalyScore != nil {
+		result.Score = anomaly.AnomalyScore.MaxScore
+	}
+
+	for _, cause := range anomaly.RootCauses {
+		account := aws.ToString(cause.LinkedAccountName)
+		if account == "" {
+			account = aws.ToString(cause.LinkedAccount)
+		}
+
+		parts := make([]string, 0, 4)
+		for _, part := range []string{aws.ToString(cause.Service), aws.ToString(cause.Region), account, aws.ToString(cause.UsageType)} {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) > 0 {
+			result.RootCauses = append(result.RootCauses, strings.Join(parts, " / "))
+		}
+	}
+
+	return result
+}
+
+// anomalyDate returns the day of a Cost Anomaly Detection timestamp
+func anomalyDate(value *string) string {
+	date := aws.ToString(value)
+	if len(date) > len(costDateLayout) {
+		return date[:len(costDateLayout)]
+	}
+	return date
+}
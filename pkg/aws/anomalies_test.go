@@ -0,0 +1,155 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+func dailySeries(amounts ...float64) []DailyCost {
+	series := make([]DailyCost, 0, len(amounts))
+	for i, amount := range amounts {
+		series = append(series, DailyCost{Date: fmt.Sprintf("2026-09-%02d", i+1), Amount: amount})
+	}
+	return series
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	opts := AnomalyOptions{Window: 5, Threshold: 3, MinImpact: 10}
+
+	tests := []struct {
+		name      string
+		series    []DailyCost
+		wantDates []string
+	}{
+		{
+			name:      "steady spend",
+			series:    dailySeries(100, 102, 98, 101, 99, 100, 103),
+			wantDates: []string{},
+		},
+		{
+			name:      "spike above baseline",
+			series:    dailySeries(100, 102, 98, 101, 99, 180, 100),
+			wantDates: []string{"2026-09-06"},
+		},
+		{
+			name:      "drop is not an anomaly",
+			series:    dailySeries(100, 102, 98, 101, 99, 20),
+			wantDates: []string{},
+		},
+		{
+			name:      "spike below minimum impact",
+			series:    dailySeries(1, 1.1, 0.9, 1, 1, 5),
+			wantDates: []string{},
+		},
+		{
+			name:      "increase over a flat baseline",
+			series:    dailySeries(0, 0, 0, 0, 0, 25),
+			wantDates: []string{"2026-09-06"},
+		},
+		{
+			name:      "not enough history",
+			series:    dailySeries(100, 500),
+			wantDates: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := detectAnomalies(tt.series, AnomalyScopeTotal, opts)
+
+			got := make([]string, 0, len(anomalies))
+			for _, anomaly := range anomalies {
+				got = append(got, anomaly.Date)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantDates, ",") {
+				t.Errorf("detectAnomalies() dates = %v, want %v", got, tt.wantDates)
+			}
+		})
+	}
+}
+
+func TestCostResultsDetectAnomalies(t *testing.T) {
+	results := &CostResults{
+		GroupBy:    "SERVICE",
+		DailyTrend: dailySeries(150, 152, 148, 151, 149, 300),
+		DailyByGroup: map[string][]DailyCost{
+			"Amazon EC2": dailySeries(100, 101, 99, 100, 100, 240),
+			"Amazon S3":  dailySeries(50, 51, 49, 51, 49, 55),
+			"AWS Lambda": dailySeries(0, 0, 0, 0, 0, 5),
+		},
+	}
+
+	anomalies := results.DetectAnomalies(AnomalyOptions{Window: 5, Threshold: 3, MinImpact: 10})
+
+	if len(anomalies.Total) != 1 || anomalies.Total[0].Date != "2026-09-06" {
+		t.Fatalf("Total = %+v, want one anomaly on 2026-09-06", anomalies.Total)
+	}
+	if anomalies.Total[0].Impact != 150 || anomalies.Total[0].ImpactPercent != 100 {
+		t.Errorf("Total impact = %.2f (%.1f%%), want 150 (100%%)", anomalies.Total[0].Impact, anomalies.Total[0].ImpactPercent)
+	}
+
+	drivers := anomalies.Total[0].Drivers
+	if len(drivers) != 3 || drivers[0].Name != "Amazon EC2" || drivers[0].Impact != 140 {
+		t.Errorf("Drivers = %+v, want Amazon EC2 first with impact 140", drivers)
+	}
+
+	// S3 and Lambda increases are below the minimum impact
+	if len(anomalies.Groups) != 1 || anomalies.Groups[0].Scope != "Amazon EC2" {
+		t.Errorf("Groups = %+v, want one Amazon EC2 anomaly", anomalies.Groups)
+	}
+}
+
+func TestAddAWSAnomalies(t *testing.T) {
+	results := &AnomalyResults{
+		EndDate: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		Total:   []CostAnomaly{{Date: "2026-09-06"}},
+		Groups:  []CostAnomaly{{Date: "2026-09-20", Scope: "Amazon S3"}},
+	}
+
+	results.AddAWSAnomalies([]AWSCostAnomaly{
+		{ID: "a", StartDate: "2026-09-05", EndDate: "2026-09-07"},
+		{ID: "b", StartDate: "2026-09-10", EndDate: "2026-09-11"},
+		{ID: "c", StartDate: "2026-09-18"},
+	})
+
+	want := map[string]bool{"a": true, "b": false, "c": true}
+	for _, anomaly := range results.AWSAnomalies {
+		if anomaly.Detected != want[anomaly.ID] {
+			t.Errorf("anomaly %s Detected = %v, want %v", anomaly.ID, anomaly.Detected, want[anomaly.ID])
+		}
+	}
+}
+
+func TestToAWSCostAnomaly(t *testing.T) {
+	anomaly := toAWSCostAnomaly(cetypes.Anomaly{
+		AnomalyId:        aws.String("anomaly-1"),
+		AnomalyStartDate: aws.String("2026-09-05T00:00:00Z"),
+		DimensionValue:   aws.String("Amazon Elastic Compute Cloud - Compute"),
+		Impact: &cetypes.Impact{
+			TotalImpact:        420.5,
+			MaxImpact:          210,
+			TotalExpectedSpend: aws.Float64(100),
+			TotalActualSpend:   aws.Float64(520.5),
+		},
+		RootCauses: []cetypes.RootCause{{
+			Service:       aws.String("Amazon Elastic Compute Cloud - Compute"),
+			Region:        aws.String("us-east-1"),
+			LinkedAccount: aws.String("111111111111"),
+		}},
+	})
+
+	if anomaly.StartDate != "2026-09-05" || anomaly.EndDate != "" {
+		t.Errorf("dates = %q to %q, want 2026-09-05 with no end", anomaly.StartDate, anomaly.EndDate)
+	}
+	if anomaly.TotalImpact != 420.5 || anomaly.ActualSpend != 520.5 {
+		t.Errorf("impact = %.2f, actual = %.2f", anomaly.TotalImpact, anomaly.ActualSpend)
+	}
+	if len(anomaly.RootCauses) != 1 || anomaly.RootCauses[0] != "Amazon Elastic Compute Cloud - Compute / us-east-1 / 111111111111" {
+		t.Errorf("RootCauses = %v", anomaly.RootCauses)
+	}
+}
@@ -106,15 +106,13 @@ func (a *Auditor) FindUnattachedVolumes(ctx context.Context) ([]UnattachedVolume
 }
 
 func (a *Auditor) FindUnusedElasticIPs(ctx context.Context) ([]UnusedElasticIP, error) {
-	input := &ec2.DescribeAddressesInput{}
-
-	result, err := a.ec2Client.DescribeAddresses(ctx, input)
+	addresses, err := describeAddresses(ctx, a.ec2Client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to describe addresses: %w", err)
+		return nil, err
 	}
 
 	elasticIPs := make([]UnusedElasticIP, 0)
-	for _, addr := range result.Addresses {
+	for _, addr := range addresses {
 		// Check if the EIP is not associated with any instance
 		if addr.AssociationId == nil || aws.ToString(addr.AssociationId) == "" {
 			elasticIPs = append(elasticIPs, UnusedElasticIP{
@@ -128,6 +126,16 @@ func (a *Auditor) FindUnusedElasticIPs(ctx context.Context) ([]UnusedElasticIP,
 	return elasticIPs, nil
 }
 
+// describeAddresses returns the Elastic IPs of the region. DescribeAddresses
+// is not paginated and returns every address at once.
+func describeAddresses(ctx context.Context, client *ec2.Client) ([]ec2types.Address, error) {
+	result, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe addresses: %w", err)
+	}
+	return result.Addresses, nil
+}
+
 func (a *Auditor) FindUnderutilizedInstances(ctx context.Context) ([]UnderutilizedInstance, error) {
 	// Get all running instances
 	input := &ec2.DescribeInstancesInput{
@@ -304,20 +312,21 @@ func calculateSnapshotCost(sizeGB int32) float64 {
 	return float64(sizeGB) * 0.05 // $0.05 per GB-month
 }
 
-func estimateEC2Cost(instanceType string) float64 {
-	// Simplified cost estimation (actual costs vary by region)
-	costs := map[string]float64{
-		"t2.micro":  10.00,
-		"t2.small":  20.00,
-		"t2.medium": 40.00,
-		"t3.micro":  9.00,
-		"t3.small":  18.00,
-		"t3.medium": 36.00,
-		"m5.large":  88.00,
-		"m5.xlarge": 176.00,
-	}
+// ec2MonthlyCosts are simplified monthly on-demand estimates of instance types
+// (actual costs vary by region)
+var ec2MonthlyCosts = map[string]float64{
+	"t2.micro":  10.00,
+	"t2.small":  20.00,
+	"t2.medium": 40.00,
+	"t3.micro":  9.00,
+	"t3.small":  18.00,
+	"t3.medium": 36.00,
+	"m5.large":  88.00,
+	"m5.xlarge": 176.00,
+}
 
-	if cost, ok := costs[instanceType]; ok {
+func estimateEC2Cost(instanceType string) float64 {
+	if cost, ok := ec2MonthlyCosts[instanceType]; ok {
 		return cost
 	}
 
@@ -109,6 +109,18 @@ func TestEstimateEC2Cost(t *testing.T) {
 	}
 }
 
+func TestInstanceHourlyCost(t *testing.T) {
+	hourly, ok := InstanceHourlyCost("m5.large")
+	if !ok || hourly != 88.0/HoursPerMonth {
+		t.Errorf("InstanceHourlyCost(m5.large) = %v, %v, want %v, true", hourly, ok, 88.0/HoursPerMonth)
+	}
+
+	// Unknown types are unpriced rather than given the audit's default estimate
+	if hourly, ok := InstanceHourlyCost("c6i.2xlarge"); ok || hourly != 0 {
+		t.Errorf("InstanceHourlyCost(c6i.2xlarge) = %v, %v, want 0, false", hourly, ok)
+	}
+}
+
 func TestAuditResultsCalculateSavings(t *testing.T) {
 	results := &AuditResults{
 		UnattachedVolumes: []UnattachedVolume{
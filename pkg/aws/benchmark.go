@@ -0,0 +1,276 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ControlStatus is the outcome of evaluating a benchmark control
+type ControlStatus string
+
+const (
+	ControlPass          ControlStatus = "pass"
+	ControlFail          ControlStatus = "fail"
+	ControlNotApplicable ControlStatus = "not-applicable"
+	ControlError         ControlStatus = "error"
+)
+
+// CISBenchmarkName identifies the benchmark version controls are mapped to
+const CISBenchmarkName = "CIS Amazon Web Services Foundations Benchmark v3.0.0"
+
+// CISControl maps a benchmark control to the security checks that evaluate it
+type CISControl struct {
+	ID    string
+	Title string
+	// Checks lists the check IDs that must complete for the control to be evaluated
+	Checks []string
+	// Evaluate returns the IDs of resources that fail the control
+	Evaluate func(results *SecurityResults) []string
+}
+
+// ControlResult holds the evaluation result of a single control
+type ControlResult struct {
+	ControlID        string
+	Title            string
+	Status           ControlStatus
+	FailingResources []string
+	// SuppressedResources lists the failing resources whose findings are
+	// suppressed as accepted risks. They still fail the control.
+	SuppressedResources []string
+}
+
+// ComplianceReport summarizes benchmark compliance for a set of security results
+type ComplianceReport struct {
+	Benchmark     string
+	Regions       []string
+	Controls      []ControlResult
+	Passed        int
+	Failed        int
+	NotApplicable int
+	Errors        int
+	Score         float64
+}
+
+// adminPorts are the remote server administration ports covered by CIS 5.2 and 5.3
+var adminPorts = map[int32]bool{
+	22:   true,
+	3389: true,
+}
+
+// CISControls lists the CIS AWS Foundations controls evaluated by the security audit
+var CISControls = []CISControl{
+	{
+		ID:     "2.1.4",
+		Title:  "Ensure that S3 Buckets are configured with 'Block public access (bucket settings)'",
+		Checks: []string{CheckIDPublicS3Buckets},
+		Evaluate: func(results *SecurityResults) []string {
+			resources := make([]string, 0, len(results.PublicS3Buckets))
+			for _, bucket := range results.PublicS3Buckets {
+				resources = append(resources, bucket.BucketName)
+			}
+			return resources
+		},
+	},
+	{
+		ID:       "2.2.1",
+		Title:    "Ensure EBS volume encryption is enabled",
+		Checks:   []string{CheckIDUnencryptedVolumes},
+		Evaluate: findingResources(CheckIDUnencryptedVolumes),
+	},
+	{
+		ID:       "2.3.1",
+		Title:    "Ensure that encryption-at-rest is enabled for RDS Instances",
+		Checks:   []string{CheckIDRDSEncryption},
+		Evaluate: findingResources(CheckIDRDSEncryption),
+	},
+	{
+		ID:       "2.3.3",
+		Title:    "Ensure that public access is not given to RDS Instance",
+		Checks:   []string{CheckIDRDSPublicAccess},
+		Evaluate: findingResources(CheckIDRDSPublicAccess),
+	},
+	{
+		ID:       "5.2",
+		Title:    "Ensure no security groups allow ingress from 0.0.0.0/0 to remote server administration ports",
+		Checks:   []string{CheckIDOpenSecurityGroups},
+		Evaluate: adminPortGroups("0.0.0.0/0"),
+	},
+	{
+		ID:       "5.3",
+		Title:    "Ensure no security groups allow ingress from ::/0 to remote server administration ports",
+		Checks:   []string{CheckIDOpenSecurityGroups},
+		Evaluate: adminPortGroups("::/0"),
+	},
+	{
+		ID:       "5.4",
+		Title:    "Ensure the default security group of every VPC restricts all traffic",
+		Checks:   []string{CheckIDDefaultSecurityGroupRules},
+		Evaluate: findingResources(CheckIDDefaultSecurityGroupRules),
+	},
+	{
+		ID:       "5.6",
+		Title:    "Ensure that EC2 Metadata Service only allows IMDSv2",
+		Checks:   []string{CheckIDIMDSv2},
+		Evaluate: findingResources(CheckIDIMDSv2),
+	},
+}
+
+// EvaluateCISBenchmark evaluates every CIS control against the security results
+func EvaluateCISBenchmark(results *SecurityResults, regions []string) *ComplianceReport {
+	report := &ComplianceReport{
+		Benchmark: CISBenchmarkName,
+		Regions:   regions,
+		Controls:  make([]ControlResult, 0, len(CISControls)),
+	}
+
+	for _, control := range CISControls {
+		result := evaluateControl(control, results)
+
+		switch result.Status {
+		case ControlPass:
+			report.Passed++
+		case ControlFail:
+			report.Failed++
+		case ControlNotApplicable:
+			report.NotApplicable++
+		case ControlError:
+			report.Errors++
+		}
+
+		report.Controls = append(report.Controls, result)
+	}
+
+	// A control that could not be evaluated is not evidence of compliance;
+	// controls with no resources to evaluate are left out
+	report.Score = complianceScore(report.Passed, report.Failed+report.Errors)
+
+	return report
+}
+
+// evaluateControl evaluates a single control, marking it as an error when any
+// of its checks did not complete and as not applicable when its checks found
+// no resources to evaluate
+func evaluateControl(control CISControl, results *SecurityResults) ControlResult {
+	result := ControlResult{
+		ControlID:           control.ID,
+		Title:               control.Title,
+		FailingResources:    make([]string, 0),
+		SuppressedResources: make([]string, 0),
+	}
+
+	for _, check := range control.Checks {
+		if !results.IsCompleted(check) {
+			result.Status = ControlError
+			return result
+		}
+	}
+
+	if !hasResources(control, results) {
+		result.Status = ControlNotApplicable
+		return result
+	}
+
+	result.FailingResources = uniqueSorted(control.Evaluate(results))
+	if len(result.FailingResources) > 0 {
+		result.Status = ControlFail
+	} else {
+		result.Status = ControlPass
+	}
+
+	return result
+}
+
+// hasResources reports whether the checks of a control evaluated any resource.
+// Checks that don't count their resources are assumed to have some.
+func hasResources(control CISControl, results *SecurityResults) bool {
+	for _, check := range control.Checks {
+		if count, ok := results.ResourceCount(check); !ok || count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkSuppressed lists the failing resources of each control that no longer
+// fail it once suppressions are applied. The report must have been evaluated
+// against the results before suppressions were applied: accepted risks are
+// exceptions, not evidence of compliance.
+func (r *ComplianceReport) MarkSuppressed(suppressed *SecurityResults) {
+	controls := make(map[string]CISControl, len(CISControls))
+	for _, control := range CISControls {
+		controls[control.ID] = control
+	}
+
+	for i, result := range r.Controls {
+		control, ok := controls[result.ControlID]
+		if !ok || result.Status != ControlFail {
+			continue
+		}
+
+		remaining := make(map[string]bool)
+		for _, resource := range control.Evaluate(suppressed) {
+			remaining[resource] = true
+		}
+
+		exceptions := make([]string, 0)
+		for _, resource := range result.FailingResources {
+			if !remaining[resource] {
+				exceptions = append(exceptions, resource)
+			}
+		}
+		r.Controls[i].SuppressedResources = exceptions
+	}
+}
+
+// complianceScore returns the percentage of controls that passed
+func complianceScore(passed, notPassed int) float64 {
+	total := passed + notPassed
+	if total == 0 {
+		return 0
+	}
+	return float64(passed) / float64(total) * 100
+}
+
+// findingResources returns an evaluator listing the resources reported by a check
+func findingResources(checkID string) func(results *SecurityResults) []string {
+	return func(results *SecurityResults) []string {
+		resources := make([]string, 0)
+		for _, finding := range results.FindingsForCheck(checkID) {
+			resources = append(resources, finding.ResourceID)
+		}
+		return resources
+	}
+}
+
+// adminPortGroups returns an evaluator listing security groups that expose
+// administration ports to the given source
+func adminPortGroups(source string) func(results *SecurityResults) []string {
+	return func(results *SecurityResults) []string {
+		resources := make([]string, 0)
+		for _, sg := range results.OpenSecurityGroups {
+			if sg.Source == source && adminPorts[sg.Port] {
+				resources = append(resources, sg.GroupID)
+			}
+		}
+		return resources
+	}
+}
+
+// uniqueSorted removes duplicates and sorts a list of resource IDs
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// FormatScore returns the compliance score as a display string
+func (r *ComplianceReport) FormatScore() string {
+	return fmt.Sprintf("%.1f%%", r.Score)
+}
@@ -0,0 +1,259 @@
+package aws
+
+import (
+	"testing"
+	"time"
+)
+
+func allChecksCompleted() []string {
+	return []string{
+		CheckIDPublicS3Buckets,
+		CheckIDOpenSecurityGroups,
+		CheckIDUnencryptedVolumes,
+		CheckIDUnencryptedSnapshots,
+		CheckIDRDSPublicAccess,
+		CheckIDRDSEncryption,
+		CheckIDSharedSnapshots,
+		CheckIDPublicAMIs,
+		CheckIDIMDSv2,
+		CheckIDIMDSHopLimit,
+		CheckIDUserDataSecrets,
+		CheckIDDanglingDNS,
+		CheckIDELBOutdatedTLSPolicy,
+		CheckIDELBHTTPNoRedirect,
+		CheckIDELBExpiringCertificate,
+		CheckIDELBNoWAF,
+		CheckIDCloudFrontOutdatedTLS,
+		CheckIDCloudFrontHTTPAllowed,
+		CheckIDCloudFrontExpiringCertificate,
+		CheckIDCloudFrontNoWAF,
+		CheckIDUnusedSecurityGroups,
+		CheckIDDefaultSecurityGroupRules,
+		CheckIDStaleSecurityGroupRefs,
+		CheckIDDuplicateSecurityGroups,
+	}
+}
+
+func findControl(t *testing.T, report *ComplianceReport, id string) ControlResult {
+	t.Helper()
+	for _, c := range report.Controls {
+		if c.ControlID == id {
+			return c
+		}
+	}
+	t.Fatalf("control %s not found in report", id)
+	return ControlResult{}
+}
+
+func TestEvaluateCISBenchmarkAllPass(t *testing.T) {
+	results := &SecurityResults{CompletedChecks: allChecksCompleted()}
+
+	report := EvaluateCISBenchmark(results, []string{"us-east-1"})
+
+	if report.Benchmark != CISBenchmarkName {
+		t.Errorf("Benchmark = %q, want %q", report.Benchmark, CISBenchmarkName)
+	}
+	if len(report.Controls) != len(CISControls) {
+		t.Fatalf("Controls = %d, want %d", len(report.Controls), len(CISControls))
+	}
+	if report.Failed != 0 || report.Errors != 0 {
+		t.Errorf("Failed = %d, Errors = %d, want 0 and 0", report.Failed, report.Errors)
+	}
+	if report.Passed != len(CISControls) {
+		t.Errorf("Passed = %d, want %d", report.Passed, len(CISControls))
+	}
+	if report.Score != 100 {
+		t.Errorf("Score = %v, want 100", report.Score)
+	}
+}
+
+func TestEvaluateCISBenchmarkFailures(t *testing.T) {
+	results := &SecurityResults{
+		CompletedChecks: allChecksCompleted(),
+		PublicS3Buckets: []PublicS3Bucket{
+			{BucketName: "public-site", Severity: SeverityCritical},
+		},
+		OpenSecurityGroups: []OpenSecurityGroup{
+			{GroupID: "sg-ssh", Port: 22, Source: "0.0.0.0/0", Severity: SeverityCritical},
+			{GroupID: "sg-ssh", Port: 3389, Source: "0.0.0.0/0", Severity: SeverityCritical},
+			{GroupID: "sg-db", Port: 5432, Source: "0.0.0.0/0", Severity: SeverityCritical},
+			{GroupID: "sg-v6", Port: 3389, Source: "::/0", Severity: SeverityCritical},
+		},
+		Findings: []SecurityFinding{
+			{CheckID: CheckIDRDSPublicAccess, ResourceID: "db-public", Severity: SeverityCritical},
+			{CheckID: CheckIDIMDSv2, ResourceID: "i-legacy", Severity: SeverityHigh},
+			{CheckID: CheckIDIMDSHopLimit, ResourceID: "i-container-host", Severity: SeverityMedium},
+			{CheckID: CheckIDDefaultSecurityGroupRules, ResourceID: "sg-default", Severity: SeverityHigh},
+			{CheckID: CheckIDUnusedSecurityGroups, ResourceID: "sg-unused", Severity: SeverityMedium},
+		},
+	}
+
+	report := EvaluateCISBenchmark(results, []string{"us-east-1"})
+
+	tests := []struct {
+		controlID     string
+		wantStatus    ControlStatus
+		wantResources []string
+	}{
+		{"2.1.4", ControlFail, []string{"public-site"}},
+		{"2.2.1", ControlPass, []string{}},
+		{"2.3.1", ControlPass, []string{}},
+		{"2.3.3", ControlFail, []string{"db-public"}},
+		{"5.2", ControlFail, []string{"sg-ssh"}}, // database ports are not admin ports; duplicates collapsed
+		{"5.3", ControlFail, []string{"sg-v6"}},
+		{"5.4", ControlFail, []string{"sg-default"}}, // unused groups don't affect the control
+		{"5.6", ControlFail, []string{"i-legacy"}},   // hop limit findings don't affect the control
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.controlID, func(t *testing.T) {
+			control := findControl(t, report, tt.controlID)
+
+			if control.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", control.Status, tt.wantStatus)
+			}
+			if len(control.FailingResources) != len(tt.wantResources) {
+				t.Fatalf("FailingResources = %v, want %v", control.FailingResources, tt.wantResources)
+			}
+			for i, r := range tt.wantResources {
+				if control.FailingResources[i] != r {
+					t.Errorf("FailingResources[%d] = %q, want %q", i, control.FailingResources[i], r)
+				}
+			}
+		})
+	}
+
+	if report.Passed != 2 || report.Failed != 6 {
+		t.Errorf("Passed = %d, Failed = %d, want 2 and 6", report.Passed, report.Failed)
+	}
+}
+
+func TestEvaluateCISBenchmarkCheckErrors(t *testing.T) {
+	// Only the S3 check completed; every other control could not be evaluated
+	results := &SecurityResults{
+		CompletedChecks: []string{CheckIDPublicS3Buckets},
+	}
+
+	report := EvaluateCISBenchmark(results, []string{"us-east-1"})
+
+	if control := findControl(t, report, "2.1.4"); control.Status != ControlPass {
+		t.Errorf("2.1.4 Status = %v, want %v", control.Status, ControlPass)
+	}
+	if control := findControl(t, report, "2.3.1"); control.Status != ControlError {
+		t.Errorf("2.3.1 Status = %v, want %v", control.Status, ControlError)
+	}
+	if report.Errors != len(CISControls)-1 {
+		t.Errorf("Errors = %d, want %d", report.Errors, len(CISControls)-1)
+	}
+	if want := 100 / float64(len(CISControls)); report.Score != want {
+		t.Errorf("Score = %v, want %v (controls that errored count against the score)", report.Score, want)
+	}
+}
+
+func TestEvaluateCISBenchmarkNotApplicable(t *testing.T) {
+	// No RDS instances and no EC2 instances in the region
+	results := &SecurityResults{
+		CompletedChecks: allChecksCompleted(),
+		ResourceCounts: map[string]int{
+			CheckIDRDSEncryption:   0,
+			CheckIDRDSPublicAccess: 0,
+			CheckIDIMDSv2:          0,
+			CheckIDIMDSHopLimit:    0,
+		},
+		PublicS3Buckets: []PublicS3Bucket{
+			{BucketName: "public-site", Severity: SeverityCritical},
+		},
+	}
+
+	report := EvaluateCISBenchmark(results, []string{"us-east-1"})
+
+	for _, id := range []string{"2.3.1", "2.3.3", "5.6"} {
+		if control := findControl(t, report, id); control.Status != ControlNotApplicable {
+			t.Errorf("%s Status = %v, want %v", id, control.Status, ControlNotApplicable)
+		}
+	}
+	if control := findControl(t, report, "2.2.1"); control.Status != ControlPass {
+		t.Errorf("2.2.1 Status = %v, want %v (checks that don't count resources stay applicable)", control.Status, ControlPass)
+	}
+	if report.NotApplicable != 3 || report.Failed != 1 {
+		t.Errorf("NotApplicable = %d, Failed = %d, want 3 and 1", report.NotApplicable, report.Failed)
+	}
+	applicable := len(CISControls) - 3
+	if want := float64(applicable-1) / float64(applicable) * 100; report.Score != want {
+		t.Errorf("Score = %v, want %v (not-applicable controls are left out)", report.Score, want)
+	}
+}
+
+func TestComplianceReportMarkSuppressed(t *testing.T) {
+	results := &SecurityResults{
+		CompletedChecks: allChecksCompleted(),
+		Findings: []SecurityFinding{
+			{CheckID: CheckIDRDSPublicAccess, ResourceID: "db-accepted", Severity: SeverityCritical},
+			{CheckID: CheckIDIMDSv2, ResourceID: "i-accepted", Severity: SeverityHigh},
+			{CheckID: CheckIDIMDSv2, ResourceID: "i-legacy", Severity: SeverityHigh},
+		},
+	}
+
+	report := EvaluateCISBenchmark(results, []string{"us-east-1"})
+
+	results.ApplySuppressions([]Suppression{
+		{Check: CheckIDRDSPublicAccess, ResourceID: "db-accepted", Reason: "public read replica", Expires: "2099-12-31"},
+		{Check: CheckIDIMDSv2, ResourceID: "i-accepted", Reason: "legacy agent", Expires: "2099-12-31"},
+	}, "us-east-1", "", time.Now())
+	report.MarkSuppressed(results)
+
+	rds := findControl(t, report, "2.3.3")
+	if rds.Status != ControlFail {
+		t.Errorf("2.3.3 Status = %v, want %v (suppressed resources still fail)", rds.Status, ControlFail)
+	}
+	if len(rds.SuppressedResources) != 1 || rds.SuppressedResources[0] != "db-accepted" {
+		t.Errorf("2.3.3 SuppressedResources = %v, want [db-accepted]", rds.SuppressedResources)
+	}
+
+	imds := findControl(t, report, "5.6")
+	if len(imds.FailingResources) != 2 || len(imds.SuppressedResources) != 1 || imds.SuppressedResources[0] != "i-accepted" {
+		t.Errorf("5.6 FailingResources = %v, SuppressedResources = %v, want 2 failing and [i-accepted]",
+			imds.FailingResources, imds.SuppressedResources)
+	}
+	if report.Passed != len(CISControls)-2 || report.Failed != 2 {
+		t.Errorf("Passed = %d, Failed = %d, want %d and 2", report.Passed, report.Failed, len(CISControls)-2)
+	}
+}
+
+func TestComplianceScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		passed int
+		failed int
+		want   float64
+	}{
+		{"nothing evaluated", 0, 0, 0},
+		{"all passed", 4, 0, 100},
+		{"all failed", 0, 4, 0},
+		{"three of four", 3, 1, 75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := complianceScore(tt.passed, tt.failed); got != tt.want {
+				t.Errorf("complianceScore(%d, %d) = %v, want %v", tt.passed, tt.failed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecurityResultsMarkCompleted(t *testing.T) {
+	results := &SecurityResults{}
+
+	results.MarkCompleted(CheckIDPublicAMIs, CheckIDPublicAMIs, CheckIDSharedSnapshots)
+
+	if len(results.CompletedChecks) != 2 {
+		t.Errorf("CompletedChecks = %v, want 2 unique entries", results.CompletedChecks)
+	}
+	if !results.IsCompleted(CheckIDPublicAMIs) {
+		t.Error("IsCompleted(public-amis) = false, want true")
+	}
+	if results.IsCompleted(CheckIDRDSEncryption) {
+		t.Error("IsCompleted(rds-unencrypted) = true, want false")
+	}
+}
@@ -0,0 +1,355 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Budget check periods
+const (
+	BudgetCheckMonthly = "monthly"
+	BudgetCheckDaily   = "daily"
+)
+
+// Budget check statuses, from least to most severe
+const (
+	BudgetCheckOK       = "ok"
+	BudgetCheckWarning  = "warning"
+	BudgetCheckCritical = "critical"
+)
+
+// Default thresholds, in percent of the limit
+const (
+	defaultBudgetWarning  = 80
+	defaultBudgetCritical = 100
+)
+
+// forecastMetrics maps cost metrics to the metrics Cost Explorer forecasts
+var forecastMetrics = map[string]cetypes.Metric{
+	MetricUnblendedCost:    cetypes.MetricUnblendedCost,
+	MetricAmortizedCost:    cetypes.MetricAmortizedCost,
+	MetricNetUnblendedCost: cetypes.MetricNetUnblendedCost,
+	MetricNetAmortizedCost: cetypes.MetricNetAmortizedCost,
+	MetricBlendedCost:      cetypes.MetricBlendedCost,
+}
+
+// BudgetLimit is a spending limit for a month or a day, on all spend or the
+// part matching its scope
+type BudgetLimit struct {
+	Name string `yaml:"name" json:"name"`
+	// Period is monthly (default) or daily
+	Period string  `yaml:"period" json:"period"`
+	Limit  float64 `yaml:"limit" json:"limit"`
+	// Warning and Critical are percentages of the limit, 80 and 100 by default
+	Warning  float64 `yaml:"warning" json:"warning"`
+	Critical float64 `yaml:"critical" json:"critical"`
+	// Service, Account and Tag (key=value) narrow the scope, each accepting
+	// comma-separated values. Filters takes --filter expressions.
+	Service string   `yaml:"service,omitempty" json:"service,omitempty"`
+	Account string   `yaml:"account,omitempty" json:"account,omitempty"`
+	Tag     string   `yaml:"tag,omitempty" json:"tag,omitempty"`
+	Filters []string `yaml:"filters,omitempty" json:"filters,omitempty"`
+	// Forecast also checks the month-end forecast of monthly budgets, true by
+	// default
+	Forecast *bool `yaml:"forecast,omitempty" json:"forecast,omitempty"`
+}
+
+// BudgetFile is the on-disk format of a budget file
+type BudgetFile struct {
+	// Metric is a key of CostMetrics, unblended by default
+	Metric  string        `yaml:"metric"`
+	Budgets []BudgetLimit `yaml:"budgets"`
+}
+
+// BudgetCheck is the result of checking one budget
+type BudgetCheck struct {
+	Name   string
+	Period string
+	Scope  string
+	// Start and End (exclusive) are the days the actual spend covers
+	Start    time.Time
+	End      time.Time
+	Limit    float64
+	Warning  float64
+	Critical float64
+	Actual   float64
+	// ActualPercent and ForecastPercent are percentages of the limit
+	ActualPercent float64
+	// HasForecast is set when the projected period total was forecast
+	HasForecast     bool
+	Forecast        float64
+	ForecastPercent float64
+	Status          string
+	Reason          string
+}
+
+// BudgetCheckResults holds the result of every budget in a budget file
+type BudgetCheckResults struct {
+	GeneratedAt time.Time
+	Metric      string
+	Currency    string
+	Checks      []BudgetCheck
+}
+
+// LoadBudgetFile reads and validates a budget file
+func LoadBudgetFile(path string) (*BudgetFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget file: %w", err)
+	}
+
+	return parseBudgetFile(data)
+}
+
+// parseBudgetFile parses a budget file, filling in defaults and validating
+// every budget
+func parseBudgetFile(data []byte) (*BudgetFile, error) {
+	var file BudgetFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse budget file: %w", err)
+	}
+
+	if file.Metric == "" {
+		file.Metric = "unblended"
+	}
+	metric, ok := CostMetrics[strings.ToLower(file.Metric)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported metric %q (use one of %s)", file.Metric, strings.Join(costMetricNames(), ", "))
+	}
+	if _, ok := forecastMetrics[metric]; !ok {
+		return nil, fmt.Errorf("metric %q is not a cost and cannot be budgeted", file.Metric)
+	}
+
+	if len(file.Budgets) == 0 {
+		return nil, fmt.Errorf("budget file has no budgets")
+	}
+
+	names := make(map[string]bool, len(file.Budgets))
+	for i := range file.Budgets {
+		budget := &file.Budgets[i]
+		if err := budget.normalize(); err != nil {
+			return nil, fmt.Errorf("budget %d: %w", i+1, err)
+		}
+		if names[budget.Name] {
+			return nil, fmt.Errorf("budget %d: duplicate name %q", i+1, budget.Name)
+		}
+		names[budget.Name] = true
+	}
+
+	return &file, nil
+}
+
+// normalize fills in defaults and checks the budget is complete
+func (b *BudgetLimit) normalize() error {
+	if b.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	b.Period = strings.ToLower(b.Period)
+	switch b.Period {
+	case "":
+		b.Period = BudgetCheckMonthly
+	case BudgetCheckMonthly, BudgetCheckDaily:
+	default:
+		return fmt.Errorf("unsupported period %q (use monthly or daily)", b.Period)
+	}
+
+	if b.Limit <= 0 {
+		return fmt.Errorf("limit must be greater than 0")
+	}
+	if b.Warning == 0 {
+		b.Warning = defaultBudgetWarning
+	}
+	if b.Critical == 0 {
+		b.Critical = defaultBudgetCritical
+	}
+	if b.Warning < 0 || b.Warning > b.Critical {
+		return fmt.Errorf("warning threshold %.0f%% must be between 0 and the critical threshold %.0f%%", b.Warning, b.Critical)
+	}
+
+	if b.Tag != "" && !strings.Contains(b.Tag, "=") {
+		return fmt.Errorf("tag %q must be key=value", b.Tag)
+	}
+	if _, err := parseCostFilter(b.scopeFilters(), false); err != nil {
+		return err
+	}
+	return nil
+}
+
+// scopeFilters returns the budget's scope as filter expressions
+func (b BudgetLimit) scopeFilters() []string {
+	filters := make([]string, 0, len(b.Filters)+3)
+	if b.Service != "" {
+		filters = append(filters, "SERVICE="+b.Service)
+	}
+	if b.Account != "" {
+		filters = append(filters, "LINKED_ACCOUNT="+b.Account)
+	}
+	if b.Tag != "" {
+		filters = append(filters, GroupByTagPrefix+b.Tag)
+	}
+	return append(filters, b.Filters...)
+}
+
+// scope describes what the budget covers
+func (b BudgetLimit) scope() string {
+	filters := b.scopeFilters()
+	if len(filters) == 0 {
+		return "all spend"
+	}
+	return strings.Join(filters, " AND ")
+}
+
+// forecastEnabled reports whether the month-end forecast is checked
+func (b BudgetLimit) forecastEnabled() bool {
+	return b.Period == BudgetCheckMonthly && (b.Forecast == nil || *b.Forecast)
+}
+
+// CheckBudgets evaluates every budget in a budget file. Monthly budgets are
+// checked against spend so far this month and the month-end forecast, daily
+// budgets against yesterday's spend, the last complete day. This changes the
+// analyzer's query options.
+func (c *CostAnalyzer) CheckBudgets(ctx context.Context, now time.Time, file *BudgetFile) (*BudgetCheckResults, error) {
+	metric := CostMetrics[strings.ToLower(file.Metric)]
+	results := &BudgetCheckResults{
+		GeneratedAt: now,
+		Metric:      metric,
+		Currency:    "USD",
+		Checks:      make([]BudgetCheck, 0, len(file.Budgets)),
+	}
+
+	today := truncateToDay(now)
+	for _, budget := range file.Budgets {
+		check := BudgetCheck{
+			Name:     budget.Name,
+			Period:   budget.Period,
+			Scope:    budget.scope(),
+			Start:    today.AddDate(0, 0, -1),
+			End:      today,
+			Limit:    budget.Limit,
+			Warning:  budget.Warning,
+			Critical: budget.Critical,
+		}
+		if budget.Period == BudgetCheckMonthly {
+			check.Start = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}
+
+		if err := c.SetQueryOptions(CostQueryOptions{Metric: file.Metric, Filters: budget.scopeFilters()}); err != nil {
+			return nil, fmt.Errorf("budget %s: %w", budget.Name, err)
+		}
+
+		// Nothing has been billed yet on the first day of the month
+		if check.Start.Before(check.End) {
+			costs, err := c.GetCostAndUsage(ctx, check.Start, check.End, "SERVICE")
+			if err != nil {
+				return nil, fmt.Errorf("budget %s: %w", budget.Name, err)
+			}
+			check.Actual = costs.TotalCost
+			results.Currency = costs.Currency
+		}
+
+		if budget.forecastEnabled() {
+			monthEnd := check.Start.AddDate(0, 1, 0)
+			forecast, ok, err := c.forecastTotal(ctx, forecastMetrics[metric], today, monthEnd)
+			if err != nil {
+				return nil, fmt.Errorf("budget %s: %w", budget.Name, err)
+			}
+			if ok {
+				check.HasForecast = true
+				check.Forecast = check.Actual + forecast
+			}
+		}
+
+		evaluateBudgetCheck(&check)
+		results.Checks = append(results.Checks, check)
+	}
+
+	return results, nil
+}
+
+// forecastTotal forecasts spend matching the analyzer's filter from start
+// until end. It returns false if Cost Explorer has too little history to
+// forecast.
+func (c *CostAnalyzer) forecastTotal(ctx context.Context, metric cetypes.Metric, start, end time.Time) (float64, bool, error) {
+	result, err := c.client.GetCostForecast(ctx, &costexplorer.GetCostForecastInput{
+		TimePeriod: &cetypes.DateInterval{
+			Start: aws.String(start.Format(costDateLayout)),
+			End:   aws.String(end.Format(costDateLayout)),
+		},
+		Granularity: cetypes.GranularityMonthly,
+		Metric:      metric,
+		Filter:      c.filter,
+	})
+	c.stats.Requests++
+	if err != nil {
+		var unavailable *cetypes.DataUnavailableException
+		if errors.As(err, &unavailable) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to get cost forecast: %w", err)
+	}
+
+	if result.Total == nil {
+		return 0, false, nil
+	}
+	return parseFloat(aws.ToString(result.Total.Amount)), true, nil
+}
+
+// evaluateBudgetCheck sets the status of a check. Actual spend reaching a
+// threshold sets that status, while a forecast reaching the critical
+// threshold only warns, since the month can still come in under it.
+func evaluateBudgetCheck(check *BudgetCheck) {
+	check.ActualPercent = check.Actual / check.Limit * 100
+	if check.HasForecast {
+		check.ForecastPercent = check.Forecast / check.Limit * 100
+	}
+
+	switch {
+	case check.ActualPercent >= check.Critical:
+		check.Status = BudgetCheckCritical
+		check.Reason = fmt.Sprintf("spend is %.0f%% of the limit (critical at %.0f%%)", check.ActualPercent, check.Critical)
+	case check.ActualPercent >= check.Warning:
+		check.Status = BudgetCheckWarning
+		check.Reason = fmt.Sprintf("spend is %.0f%% of the limit (warning at %.0f%%)", check.ActualPercent, check.Warning)
+	case check.HasForecast && check.ForecastPercent >= check.Critical:
+		check.Status = BudgetCheckWarning
+		check.Reason = fmt.Sprintf("month-end forecast is %.0f%% of the limit (critical at %.0f%%)", check.ForecastPercent, check.Critical)
+	default:
+		check.Status = BudgetCheckOK
+		check.Reason = fmt.Sprintf("spend is %.0f%% of the limit", check.ActualPercent)
+	}
+}
+
+// Status returns the most severe status of all checks
+func (r *BudgetCheckResults) Status() string {
+	status := BudgetCheckOK
+	for _, check := range r.Checks {
+		switch check.Status {
+		case BudgetCheckCritical:
+			return BudgetCheckCritical
+		case BudgetCheckWarning:
+			status = BudgetCheckWarning
+		}
+	}
+	return status
+}
+
+// Failing returns the checks with a warning or critical status
+func (r *BudgetCheckResults) Failing() []BudgetCheck {
+	failing := make([]BudgetCheck, 0)
+	for _, check := range r.Checks {
+		if check.Status != BudgetCheckOK {
+			failing = append(failing, check)
+		}
+	}
+	return failing
+}
@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBudgetFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			yaml: `
+budgets:
+  - name: total
+    limit: 1000
+  - name: ec2
+    period: Daily
+    service: Amazon Elastic Compute Cloud - Compute
+    limit: 50
+    warning: 90
+    critical: 120
+`,
+		},
+		{name: "no budgets", yaml: "metric: amortized", wantErr: "no budgets"},
+		{name: "malformed yaml", yaml: "budgets: [", wantErr: "failed to parse"},
+		{name: "usage metric", yaml: "metric: usage\nbudgets: [{name: a, limit: 1}]", wantErr: "not a cost"},
+		{name: "unknown metric", yaml: "metric: list\nbudgets: [{name: a, limit: 1}]", wantErr: "unsupported metric"},
+		{name: "missing name", yaml: "budgets: [{limit: 1}]", wantErr: "name is required"},
+		{name: "missing limit", yaml: "budgets: [{name: a}]", wantErr: "limit must be greater than 0"},
+		{name: "bad period", yaml: "budgets: [{name: a, limit: 1, period: weekly}]", wantErr: "unsupported period"},
+		{name: "warning above critical", yaml: "budgets: [{name: a, limit: 1, warning: 120}]", wantErr: "warning threshold"},
+		{name: "bad tag", yaml: "budgets: [{name: a, limit: 1, tag: team}]", wantErr: "key=value"},
+		{name: "bad filter", yaml: "budgets: [{name: a, limit: 1, filters: [FOO=bar]}]", wantErr: "unknown filter key"},
+		{name: "duplicate name", yaml: "budgets: [{name: a, limit: 1}, {name: a, limit: 2}]", wantErr: "duplicate name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := parseBudgetFile([]byte(tt.yaml))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseBudgetFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseBudgetFile() error = %v", err)
+			}
+
+			total, ec2 := file.Budgets[0], file.Budgets[1]
+			if file.Metric != "unblended" || total.Period != BudgetCheckMonthly || total.Warning != 80 || total.Critical != 100 || !total.forecastEnabled() {
+				t.Errorf("defaults not applied: metric %q, %+v", file.Metric, total)
+			}
+			if ec2.Period != BudgetCheckDaily || ec2.Warning != 90 || ec2.Critical != 120 || ec2.forecastEnabled() {
+				t.Errorf("daily budget = %+v", ec2)
+			}
+		})
+	}
+}
+
+func TestBudgetLimitScope(t *testing.T) {
+	disabled := false
+	budget := BudgetLimit{
+		Service:  "AWS Lambda",
+		Account:  "111111111111",
+		Tag:      "team=payments",
+		Filters:  []string{"REGION=us-east-1"},
+		Period:   BudgetCheckMonthly,
+		Forecast: &disabled,
+	}
+
+	want := "SERVICE=AWS Lambda AND LINKED_ACCOUNT=111111111111 AND TAG:team=payments AND REGION=us-east-1"
+	if got := budget.scope(); got != want {
+		t.Errorf("scope() = %q, want %q", got, want)
+	}
+	if budget.forecastEnabled() {
+		t.Error("forecastEnabled() = true with forecast: false")
+	}
+	if got := (BudgetLimit{}).scope(); got != "all spend" {
+		t.Errorf("scope() = %q, want all spend", got)
+	}
+
+	filter, err := parseCostFilter(budget.scopeFilters(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filter.And) != 4 || filter.And[2].Tags == nil || filter.And[2].Tags.Values[0] != "payments" {
+		t.Errorf("filter = %+v", filter)
+	}
+}
+
+func TestEvaluateBudgetCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		actual      float64
+		forecast    float64
+		hasForecast bool
+		wantStatus  string
+	}{
+		{name: "under", actual: 500, forecast: 900, hasForecast: true, wantStatus: BudgetCheckOK},
+		{name: "warning", actual: 850, wantStatus: BudgetCheckWarning},
+		{name: "critical", actual: 1000, wantStatus: BudgetCheckCritical},
+		{name: "forecast over critical warns", actual: 300, forecast: 1100, hasForecast: true, wantStatus: BudgetCheckWarning},
+		{name: "forecast over warning only", actual: 300, forecast: 950, hasForecast: true, wantStatus: BudgetCheckOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := BudgetCheck{Limit: 1000, Warning: 80, Critical: 100, Actual: tt.actual, Forecast: tt.forecast, HasForecast: tt.hasForecast}
+			evaluateBudgetCheck(&check)
+			if check.Status != tt.wantStatus {
+				t.Errorf("Status = %s (%s), want %s", check.Status, check.Reason, tt.wantStatus)
+			}
+			if check.ActualPercent != tt.actual/10 {
+				t.Errorf("ActualPercent = %.1f, want %.1f", check.ActualPercent, tt.actual/10)
+			}
+		})
+	}
+}
+
+func TestBudgetCheckResultsStatus(t *testing.T) {
+	results := &BudgetCheckResults{Checks: []BudgetCheck{{Status: BudgetCheckOK}}}
+	if results.Status() != BudgetCheckOK || len(results.Failing()) != 0 {
+		t.Errorf("Status() = %s, want ok", results.Status())
+	}
+
+	results.Checks = append(results.Checks, BudgetCheck{Name: "a", Status: BudgetCheckWarning})
+	if results.Status() != BudgetCheckWarning {
+		t.Errorf("Status() = %s, want warning", results.Status())
+	}
+
+	results.Checks = append(results.Checks, BudgetCheck{Name: "b", Status: BudgetCheckCritical}, BudgetCheck{Status: BudgetCheckWarning})
+	if results.Status() != BudgetCheckCritical || len(results.Failing()) != 3 {
+		t.Errorf("Status() = %s with %d failing, want critical with 3", results.Status(), len(results.Failing()))
+	}
+}
@@ -0,0 +1,201 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/certexpiry"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	acmtypes "github.com/aws/aws-sdk-go-v2/service/acm/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+)
+
+// Certificate sources
+const (
+	CertificateSourceACM = "ACM"
+	CertificateSourceIAM = "IAM"
+)
+
+// Certificate expiry statuses, the same buckets used for Kubernetes certificates
+const (
+	CertificateValid        = certexpiry.Valid
+	CertificateExpiringSoon = certexpiry.ExpiringSoon
+	CertificateCritical     = certexpiry.Critical
+	CertificateExpired      = certexpiry.Expired
+)
+
+// GlobalRegion is reported for global resources such as IAM server certificates
+const GlobalRegion = "global"
+
+// Certificate holds expiry information about an ACM or IAM server certificate
+type Certificate struct {
+	Source             string
+	Name               string
+	ARN                string
+	Region             string
+	DNSNames           []string
+	Type               string
+	ExpiryDate         time.Time
+	DaysRemaining      int
+	Status             string // "valid", "expiring-soon", "critical", "expired"
+	InUse              bool
+	RenewalEligibility string
+}
+
+// CertificateResults holds the results of AWS certificate scanning
+type CertificateResults struct {
+	Certificates  []Certificate
+	TotalScanned  int
+	ExpiringCount int
+	CriticalCount int
+	ExpiredCount  int
+}
+
+// CertificateAuditor checks ACM and IAM server certificates for expiry
+type CertificateAuditor struct {
+	acmClient *acm.Client
+	iamClient *iam.Client
+	region    string
+}
+
+// NewCertificateAuditor creates a new CertificateAuditor for the given region
+func NewCertificateAuditor(ctx context.Context, region string) (*CertificateAuditor, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	return &CertificateAuditor{
+		acmClient: acm.NewFromConfig(cfg),
+		iamClient: iam.NewFromConfig(cfg),
+		region:    region,
+	}, nil
+}
+
+// ListACMCertificates returns the issued ACM certificates in the auditor's region
+func (c *CertificateAuditor) ListACMCertificates(ctx context.Context, now time.Time) ([]Certificate, error) {
+	// ListCertificates only returns RSA_2048 certificates unless key types are requested
+	paginator := acm.NewListCertificatesPaginator(c.acmClient, &acm.ListCertificatesInput{
+		Includes: &acmtypes.Filters{
+			KeyTypes: acmtypes.KeyAlgorithm("").Values(),
+		},
+	})
+
+	certs := make([]Certificate, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACM certificates: %w", err)
+		}
+
+		for _, summary := range page.CertificateSummaryList {
+			// Certificates pending validation or that failed have no expiry date
+			if summary.NotAfter == nil {
+				continue
+			}
+
+			certs = append(certs, newCertificate(Certificate{
+				Source:             CertificateSourceACM,
+				Name:               aws.ToString(summary.DomainName),
+				ARN:                aws.ToString(summary.CertificateArn),
+				Region:             c.region,
+				DNSNames:           summary.SubjectAlternativeNameSummaries,
+				Type:               string(summary.Type),
+				ExpiryDate:         aws.ToTime(summary.NotAfter),
+				InUse:              aws.ToBool(summary.InUse),
+				RenewalEligibility: string(summary.RenewalEligibility),
+			}, now))
+		}
+	}
+
+	return certs, nil
+}
+
+// ListIAMServerCertificates returns IAM server certificates. IAM is global, so
+// these only need to be listed once regardless of how many regions are scanned.
+func (c *CertificateAuditor) ListIAMServerCertificates(ctx context.Context, now time.Time) ([]Certificate, error) {
+	paginator := iam.NewListServerCertificatesPaginator(c.iamClient, &iam.ListServerCertificatesInput{})
+
+	certs := make([]Certificate, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list IAM server certificates: %w", err)
+		}
+
+		for _, metadata := range page.ServerCertificateMetadataList {
+			certs = append(certs, newCertificate(Certificate{
+				Source:     CertificateSourceIAM,
+				Name:       aws.ToString(metadata.ServerCertificateName),
+				ARN:        aws.ToString(metadata.Arn),
+				Region:     GlobalRegion,
+				Type:       "IAM_SERVER_CERTIFICATE",
+				ExpiryDate: aws.ToTime(metadata.Expiration),
+				// IAM server certificates are never renewed automatically
+				RenewalEligibility: string(acmtypes.RenewalEligibilityIneligible),
+			}, now))
+		}
+	}
+
+	return certs, nil
+}
+
+// newCertificate fills in days remaining and the expiry status bucket. The
+// status comes from the expiry date itself, as days remaining are truncated
+// and a certificate that expired hours ago still has 0 days remaining.
+func newCertificate(cert Certificate, now time.Time) Certificate {
+	cert.DaysRemaining = int(cert.ExpiryDate.Sub(now).Hours() / 24)
+	cert.Status = certexpiry.Status(cert.ExpiryDate, now)
+	return cert
+}
+
+// NewCertificateResults counts certificates by status and keeps those
+// expiring within expiryDays, soonest first
+func NewCertificateResults(certs []Certificate, expiryDays int) *CertificateResults {
+	results := &CertificateResults{
+		Certificates: make([]Certificate, 0),
+		TotalScanned: len(certs),
+	}
+
+	for _, cert := range certs {
+		switch cert.Status {
+		case CertificateExpired:
+			results.ExpiredCount++
+		case CertificateCritical:
+			results.CriticalCount++
+		case CertificateExpiringSoon:
+			results.ExpiringCount++
+		}
+
+		if cert.DaysRemaining <= expiryDays {
+			results.Certificates = append(results.Certificates, cert)
+		}
+	}
+
+	sort.SliceStable(results.Certificates, func(i, j int) bool {
+		return results.Certificates[i].DaysRemaining < results.Certificates[j].DaysRemaining
+	})
+
+	return results
+}
+
+// FormatDNSNames formats the DNS names as a comma-separated string
+func (c *Certificate) FormatDNSNames() string {
+	if len(c.DNSNames) == 0 {
+		return "<none>"
+	}
+
+	// Limit to first 3 DNS names to avoid cluttering output
+	if len(c.DNSNames) > 3 {
+		return fmt.Sprintf("%s (+ %d more)",
+			strings.Join(c.DNSNames[:3], ", "),
+			len(c.DNSNames)-3)
+	}
+
+	return strings.Join(c.DNSNames, ", ")
+}
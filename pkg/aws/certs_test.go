@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewCertificate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		expiry       time.Time
+		expectedDays int
+		expectedStat string
+	}{
+		{"expired yesterday", now.AddDate(0, 0, -1), -1, "expired"},
+		{"expired an hour ago", now.Add(-time.Hour), 0, "expired"},
+		{"expires in 3 days", now.AddDate(0, 0, 3), 3, "critical"},
+		{"expires in 7 days", now.AddDate(0, 0, 7), 7, "expiring-soon"},
+		{"expires in 20 days", now.AddDate(0, 0, 20), 20, "expiring-soon"},
+		{"expires in 30 days", now.AddDate(0, 0, 30), 30, "valid"},
+		{"expires in 90 days", now.AddDate(0, 0, 90), 90, "valid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := newCertificate(Certificate{Name: "example.com", ExpiryDate: tt.expiry}, now)
+
+			if cert.DaysRemaining != tt.expectedDays {
+				t.Errorf("DaysRemaining = %d, expected %d", cert.DaysRemaining, tt.expectedDays)
+			}
+			if cert.Status != tt.expectedStat {
+				t.Errorf("Status = %q, expected %q", cert.Status, tt.expectedStat)
+			}
+		})
+	}
+}
+
+func TestNewCertificateResults(t *testing.T) {
+	certs := []Certificate{
+		{Name: "valid.example.com", DaysRemaining: 200, Status: "valid"},
+		{Name: "soon.example.com", DaysRemaining: 20, Status: "expiring-soon"},
+		{Name: "legacy-iam", Source: CertificateSourceIAM, DaysRemaining: -5, Status: "expired"},
+		{Name: "critical.example.com", DaysRemaining: 2, Status: "critical"},
+		{Name: "later.example.com", DaysRemaining: 45, Status: "valid"},
+	}
+
+	results := NewCertificateResults(certs, 30)
+
+	if results.TotalScanned != 5 {
+		t.Errorf("TotalScanned = %d, expected 5", results.TotalScanned)
+	}
+	if results.ExpiredCount != 1 || results.CriticalCount != 1 || results.ExpiringCount != 1 {
+		t.Errorf("counts = expired %d, critical %d, expiring %d, expected 1 each",
+			results.ExpiredCount, results.CriticalCount, results.ExpiringCount)
+	}
+
+	expectedOrder := []string{"legacy-iam", "critical.example.com", "soon.example.com"}
+	if len(results.Certificates) != len(expectedOrder) {
+		t.Fatalf("Certificates = %d, expected %d", len(results.Certificates), len(expectedOrder))
+	}
+	for i, name := range expectedOrder {
+		if results.Certificates[i].Name != name {
+			t.Errorf("Certificates[%d] = %q, expected %q", i, results.Certificates[i].Name, name)
+		}
+	}
+}
+
+func TestCertificateFormatDNSNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		dnsNames []string
+		expected string
+	}{
+		{"no names", nil, "<none>"},
+		{"two names", []string{"a.example.com", "b.example.com"}, "a.example.com, b.example.com"},
+		{"more than three", []string{"a", "b", "c", "d", "e"}, "a, b, c (+ 2 more)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := Certificate{DNSNames: tt.dnsNames}
+			if got := cert.FormatDNSNames(); got != tt.expected {
+				t.Errorf("FormatDNSNames() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
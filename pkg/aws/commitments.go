@@ -0,0 +1,688 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/aws-sdk-go-v2/service/savingsplans"
+	sptypes "github.com/aws/aws-sdk-go-v2/service/savingsplans/types"
+)
+
+// Commitment types
+const (
+	CommitmentTypeReservation = "Reserved Instances"
+	CommitmentTypeSavingsPlan = "Savings Plans"
+)
+
+// reservationServices are the services Reserved Instance recommendations are
+// requested for
+var reservationServices = []string{
+	"Amazon Elastic Compute Cloud - Compute",
+	"Amazon Relational Database Service",
+	"Amazon ElastiCache",
+	"Amazon Redshift",
+	"Amazon OpenSearch Service",
+}
+
+// savingsPlansTypes are the Savings Plans types recommendations are requested for
+var savingsPlansTypes = []cetypes.SupportedSavingsPlansType{
+	cetypes.SupportedSavingsPlansTypeComputeSp,
+	cetypes.SupportedSavingsPlansTypeEc2InstanceSp,
+}
+
+// CommitmentOptions sets when commitments need attention
+type CommitmentOptions struct {
+	// ExpiringWithin is the number of days within which an expiring
+	// commitment is reported
+	ExpiringWithin int
+	// MinUtilization is the utilization percentage below which a commitment
+	// is reported
+	MinUtilization float64
+}
+
+// RecommendationOptions selects the purchase recommendations to request
+type RecommendationOptions struct {
+	// LookbackDays is 7, 30 or 60
+	LookbackDays int
+	// TermYears is 1 or 3
+	TermYears int
+	// PaymentOption is no-upfront, partial-upfront or all-upfront
+	PaymentOption string
+}
+
+// CommitmentUtilization is the utilization of all commitments of one type
+type CommitmentUtilization struct {
+	Type               string
+	UtilizationPercent float64
+	// TotalCommitment is the amortized cost of the commitments in the period
+	// and UnusedCommitment the part of it that was not used
+	TotalCommitment  float64
+	UnusedCommitment float64
+	NetSavings       float64
+	// Low is set when utilization is below the minimum
+	Low bool
+}
+
+// CommitmentCoverage is the share of eligible usage covered by one type of
+// commitment
+type CommitmentCoverage struct {
+	Type            string
+	CoveragePercent float64
+	OnDemandCost    float64
+}
+
+// Commitment is a single Reserved Instance subscription or Savings Plan
+type Commitment struct {
+	Type        string
+	ID          string
+	Description string
+	Account     string
+	Region      string
+	Start       time.Time
+	End         time.Time
+	// HasUtilization is false when there was no utilization data for the
+	// commitment in the period
+	HasUtilization     bool
+	UtilizationPercent float64
+	UnusedCommitment   float64
+	// Set by AddCommitments
+	DaysRemaining  int
+	Expiring       bool
+	LowUtilization bool
+}
+
+// PurchaseRecommendation is a Reserved Instance or Savings Plans purchase
+// recommended by Cost Explorer
+type PurchaseRecommendation struct {
+	Type    string
+	Service string
+	// Description is what to buy, such as "4 x m5.large (us-east-1)" or
+	// "$1.25/hour commitment"
+	Description             string
+	Account                 string
+	UpfrontCost             float64
+	EstimatedMonthlySavings float64
+	EstimatedSavingsPercent float64
+}
+
+// CommitmentResults holds Reserved Instance and Savings Plans utilization,
+// coverage, expirations and purchase recommendations
+type CommitmentResults struct {
+	GeneratedAt     time.Time
+	StartDate       time.Time
+	EndDate         time.Time
+	ExpiringWithin  int
+	MinUtilization  float64
+	Utilization     []CommitmentUtilization
+	Coverage        []CommitmentCoverage
+	Commitments     []Commitment
+	Recommendations []PurchaseRecommendation
+}
+
+// NewCommitmentResults returns empty results for the period from start to end
+func NewCommitmentResults(now, start, end time.Time, opts CommitmentOptions) *CommitmentResults {
+	return &CommitmentResults{
+		GeneratedAt:     now,
+		StartDate:       start,
+		EndDate:         end,
+		ExpiringWithin:  opts.ExpiringWithin,
+		MinUtilization:  opts.MinUtilization,
+		Utilization:     make([]CommitmentUtilization, 0),
+		Coverage:        make([]CommitmentCoverage, 0),
+		Commitments:     make([]Commitment, 0),
+		Recommendations: make([]PurchaseRecommendation, 0),
+	}
+}
+
+// AddCommitments adds the utilization and commitments of one type, flagging
+// low utilization and commitments that expire within ExpiringWithin days.
+// Commitments are kept sorted by end date.
+func (r *CommitmentResults) AddCommitments(utilization *CommitmentUtilization, commitments []Commitment) {
+	if utilization != nil {
+		utilization.Low = utilization.UtilizationPercent < r.MinUtilization
+		r.Utilization = append(r.Utilization, *utilization)
+	}
+
+	today := truncateToDay(r.GeneratedAt)
+	for _, commitment := range commitments {
+		if !commitment.End.IsZero() {
+			commitment.DaysRemaining = daysBetween(today, truncateToDay(commitment.End))
+			commitment.Expiring = commitment.DaysRemaining >= 0 && commitment.DaysRemaining <= r.ExpiringWithin
+		}
+		commitment.LowUtilization = commitment.HasUtilization && commitment.UtilizationPercent < r.MinUtilization
+		r.Commitments = append(r.Commitments, commitment)
+	}
+
+	// Soonest expiring first, commitments without an end date last
+	sort.SliceStable(r.Commitments, func(i, j int) bool {
+		a, b := r.Commitments[i], r.Commitments[j]
+		if a.End.IsZero() != b.End.IsZero() {
+			return b.End.IsZero()
+		}
+		if !a.End.Equal(b.End) {
+			return a.End.Before(b.End)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// AddRecommendations adds purchase recommendations, largest savings first
+func (r *CommitmentResults) AddRecommendations(recommendations []PurchaseRecommendation) {
+	r.Recommendations = append(r.Recommendations, recommendations...)
+	sort.SliceStable(r.Recommendations, func(i, j int) bool {
+		return r.Recommendations[i].EstimatedMonthlySavings > r.Recommendations[j].EstimatedMonthlySavings
+	})
+}
+
+// Expiring returns the commitments that expire within ExpiringWithin days
+func (r *CommitmentResults) Expiring() []Commitment {
+	expiring := make([]Commitment, 0)
+	for _, commitment := range r.Commitments {
+		if commitment.Expiring {
+			expiring = append(expiring, commitment)
+		}
+	}
+	return expiring
+}
+
+// LowUtilization returns the commitments used less than MinUtilization
+func (r *CommitmentResults) LowUtilization() []Commitment {
+	low := make([]Commitment, 0)
+	for _, commitment := range r.Commitments {
+		if commitment.LowUtilization {
+			low = append(low, commitment)
+		}
+	}
+	return low
+}
+
+// NeedsAttention reports whether a commitment is expiring or under-used
+func (r *CommitmentResults) NeedsAttention() bool {
+	for _, utilization := range r.Utilization {
+		if utilization.Low {
+			return true
+		}
+	}
+	return len(r.Expiring()) > 0 || len(r.LowUtilization()) > 0
+}
+
+// GetReservationCommitments returns the utilization of all Reserved Instances
+// from start to end and each subscription with its utilization and end date.
+// The utilization is nil if there are no Reserved Instances.
+func (c *CostAnalyzer) GetReservationCommitments(ctx context.Context, start, end time.Time) (*CommitmentUtilization, []Commitment, error) {
+	// Granularity cannot be set together with GroupBy
+	input := &costexplorer.GetReservationUtilizationInput{
+		TimePeriod: commitmentPeriod(start, end),
+		GroupBy: []cetypes.GroupDefinition{{
+			Type: cetypes.GroupDefinitionTypeDimension,
+			Key:  aws.String("SUBSCRIPTION_ID"),
+		}},
+	}
+
+	var total *cetypes.ReservationAggregates
+	commitments := make([]Commitment, 0)
+	for {
+		result, err := c.client.GetReservationUtilization(ctx, input)
+		if err != nil {
+			var unavailable *cetypes.DataUnavailableException
+			if errors.As(err, &unavailable) {
+				return nil, commitments, nil
+			}
+			return nil, nil, fmt.Errorf("failed to get reservation utilization: %w", err)
+		}
+		c.stats.Requests++
+
+		if result.Total != nil {
+			total = result.Total
+		}
+		for _, byTime := range result.UtilizationsByTime {
+			for _, group := range byTime.Groups {
+				commitments = append(commitments, toReservationCommitment(group))
+			}
+		}
+
+		if result.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = result.NextPageToken
+	}
+
+	if total == nil || len(commitments) == 0 {
+		return nil, commitments, nil
+	}
+	return &CommitmentUtilization{
+		Type:               CommitmentTypeReservation,
+		UtilizationPercent: parseFloat(aws.ToString(total.UtilizationPercentage)),
+		TotalCommitment:    parseFloat(aws.ToString(total.TotalAmortizedFee)),
+		UnusedCommitment:   parseFloat(aws.ToString(total.RICostForUnusedHours)),
+		NetSavings:         parseFloat(aws.ToString(total.NetRISavings)),
+	}, commitments, nil
+}
+
+// GetSavingsPlansCommitments returns the utilization of all Savings Plans from
+// start to end and each active plan with its utilization and end date. The
+// utilization is nil if there are no Savings Plans.
+func (c *CostAnalyzer) GetSavingsPlansCommitments(ctx context.Context, start, end time.Time) (*CommitmentUtilization, []Commitment, error) {
+	result, err := c.client.GetSavingsPlansUtilization(ctx, &costexplorer.GetSavingsPlansUtilizationInput{
+		TimePeriod:  commitmentPeriod(start, end),
+		Granularity: cetypes.GranularityMonthly,
+	})
+	if err != nil {
+		var unavailable *cetypes.DataUnavailableException
+		if errors.As(err, &unavailable) {
+			return nil, make([]Commitment, 0), nil
+		}
+		return nil, nil, fmt.Errorf("failed to get Savings Plans utilization: %w", err)
+	}
+	c.stats.Requests++
+
+	var utilization *CommitmentUtilization
+	if total := result.Total; total != nil && total.Utilization != nil {
+		utilization = &CommitmentUtilization{
+			Type:               CommitmentTypeSavingsPlan,
+			UtilizationPercent: parseFloat(aws.ToString(total.Utilization.UtilizationPercentage)),
+			TotalCommitment:    parseFloat(aws.ToString(total.Utilization.TotalCommitment)),
+			UnusedCommitment:   parseFloat(aws.ToString(total.Utilization.UnusedCommitment)),
+		}
+		if total.Savings != nil {
+			utilization.NetSavings = parseFloat(aws.ToString(total.Savings.NetSavings))
+		}
+	}
+
+	// Utilization of each plan, by ARN
+	byARN := make(map[string]Commitment)
+	order := make([]string, 0)
+	detailsInput := &costexplorer.GetSavingsPlansUtilizationDetailsInput{
+		TimePeriod: commitmentPeriod(start, end),
+	}
+	for {
+		details, err := c.client.GetSavingsPlansUtilizationDetails(ctx, detailsInput)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get Savings Plans utilization details: %w", err)
+		}
+		c.stats.Requests++
+
+		for _, detail := range details.SavingsPlansUtilizationDetails {
+			arn := aws.ToString(detail.SavingsPlanArn)
+			commitment := Commitment{
+				Type:        CommitmentTypeSavingsPlan,
+				ID:          savingsPlanID(arn),
+				Description: commitmentAttribute(detail.Attributes, "SavingsPlansType"),
+				Account:     commitmentAttribute(detail.Attributes, "AccountId"),
+				Region:      commitmentAttribute(detail.Attributes, "Region"),
+				Start:       parseCommitmentTime(commitmentAttribute(detail.Attributes, "StartDateTime")),
+				End:         parseCommitmentTime(commitmentAttribute(detail.Attributes, "EndDateTime")),
+			}
+			if detail.Utilization != nil {
+				commitment.HasUtilization = true
+				commitment.UtilizationPercent = parseFloat(aws.ToString(detail.Utilization.UtilizationPercentage))
+				commitment.UnusedCommitment = parseFloat(aws.ToString(detail.Utilization.UnusedCommitment))
+			}
+			if _, ok := byARN[arn]; !ok {
+				order = append(order, arn)
+			}
+			byARN[arn] = commitment
+		}
+
+		if details.NextToken == nil {
+			break
+		}
+		detailsInput.NextToken = details.NextToken
+	}
+
+	// Active plans of this account, with their commitment and end date, even
+	// if they were not used in the period
+	planInput := &savingsplans.DescribeSavingsPlansInput{
+		States: []sptypes.SavingsPlanState{sptypes.SavingsPlanStateActive},
+	}
+	for {
+		plans, err := c.savingsPlansClient.DescribeSavingsPlans(ctx, planInput)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to describe Savings Plans: %w", err)
+		}
+
+		for _, plan := range plans.SavingsPlans {
+			arn := aws.ToString(plan.SavingsPlanArn)
+			commitment, ok := byARN[arn]
+			if !ok {
+				order = append(order, arn)
+				commitment = Commitment{Type: CommitmentTypeSavingsPlan}
+			}
+			commitment.ID = aws.ToString(plan.SavingsPlanId)
+			commitment.Description = fmt.Sprintf("%s, $%s/hour", plan.SavingsPlanType, aws.ToString(plan.Commitment))
+			if region := aws.ToString(plan.Region); region != "" {
+				commitment.Region = region
+			}
+			commitment.Start = parseCommitmentTime(aws.ToString(plan.Start))
+			commitment.End = parseCommitmentTime(aws.ToString(plan.End))
+			byARN[arn] = commitment
+		}
+
+		if plans.NextToken == nil {
+			break
+		}
+		planInput.NextToken = plans.NextToken
+	}
+
+	commitments := make([]Commitment, 0, len(order))
+	for _, arn := range order {
+		commitments = append(commitments, byARN[arn])
+	}
+	if utilization != nil && utilization.TotalCommitment == 0 && len(commitments) == 0 {
+		utilization = nil
+	}
+	return utilization, commitments, nil
+}
+
+// GetCommitmentCoverage returns the Reserved Instance coverage of running
+// hours and the Savings Plans coverage of eligible spend from start to end
+func (c *CostAnalyzer) GetCommitmentCoverage(ctx context.Context, start, end time.Time) ([]CommitmentCoverage, error) {
+	coverage := make([]CommitmentCoverage, 0, 2)
+
+	reservations, err := c.client.GetReservationCoverage(ctx, &costexplorer.GetReservationCoverageInput{
+		TimePeriod:  commitmentPeriod(start, end),
+		Granularity: cetypes.GranularityMonthly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation coverage: %w", err)
+	}
+	c.stats.Requests++
+	if total := reservations.Total; total != nil && total.CoverageHours != nil {
+		item := CommitmentCoverage{
+			Type:            CommitmentTypeReservation,
+			CoveragePercent: parseFloat(aws.ToString(total.CoverageHours.CoverageHoursPercentage)),
+		}
+		if total.CoverageCost != nil {
+			item.OnDemandCost = parseFloat(aws.ToString(total.CoverageCost.OnDemandCost))
+		}
+		coverage = append(coverage, item)
+	}
+
+	// Savings Plans coverage is returned per month, so sum it
+	input := &costexplorer.GetSavingsPlansCoverageInput{
+		TimePeriod:  commitmentPeriod(start, end),
+		Granularity: cetypes.GranularityMonthly,
+	}
+	var covered, onDemand, total float64
+	for {
+		result, err := c.client.GetSavingsPlansCoverage(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Savings Plans coverage: %w", err)
+		}
+		c.stats.Requests++
+
+		for _, item := range result.SavingsPlansCoverages {
+			if item.Coverage == nil {
+				continue
+			}
+			covered += parseFloat(aws.ToString(item.Coverage.SpendCoveredBySavingsPlans))
+			onDemand += parseFloat(aws.ToString(item.Coverage.OnDemandCost))
+			total += parseFloat(aws.ToString(item.Coverage.TotalCost))
+		}
+
+		if result.NextToken == nil {
+			break
+		}
+		input.NextToken = result.NextToken
+	}
+	if total > 0 {
+		coverage = append(coverage, CommitmentCoverage{
+			Type:            CommitmentTypeSavingsPlan,
+			CoveragePercent: covered / total * 100,
+			OnDemandCost:    onDemand,
+		})
+	}
+
+	return coverage, nil
+}
+
+// GetPurchaseRecommendations returns Reserved Instance recommendations for each
+// supported service and a Savings Plans recommendation for each plan type
+func (c *CostAnalyzer) GetPurchaseRecommendations(ctx context.Context, opts RecommendationOptions) ([]PurchaseRecommendation, error) {
+	lookback, term, payment, err := parseRecommendationOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := make([]PurchaseRecommendation, 0)
+
+	for _, service := range reservationServices {
+		input := &costexplorer.GetReservationPurchaseRecommendationInput{
+			Service:              aws.String(service),
+			LookbackPeriodInDays: lookback,
+			TermInYears:          term,
+			PaymentOption:        payment,
+		}
+		for {
+			result, err := c.client.GetReservationPurchaseRecommendation(ctx, input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get reservation recommendations for %s: %w", service, err)
+			}
+			c.stats.Requests++
+
+			for _, recommendation := range result.Recommendations {
+				for _, detail := range recommendation.RecommendationDetails {
+					recommendations = append(recommendations, toReservationRecommendation(service, detail))
+				}
+			}
+
+			if result.NextPageToken == nil {
+				break
+			}
+			input.NextPageToken = result.NextPageToken
+		}
+	}
+
+	for _, planType := range savingsPlansTypes {
+		result, err := c.client.GetSavingsPlansPurchaseRecommendation(ctx, &costexplorer.GetSavingsPlansPurchaseRecommendationInput{
+			SavingsPlansType:     planType,
+			LookbackPeriodInDays: lookback,
+			TermInYears:          term,
+			PaymentOption:        payment,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s recommendations: %w", planType, err)
+		}
+		c.stats.Requests++
+
+		if recommendation := toSavingsPlansRecommendation(planType, result.SavingsPlansPurchaseRecommendation); recommendation != nil {
+			recommendations = append(recommendations, *recommendation)
+		}
+	}
+
+	return recommendations, nil
+}
+
+// Validate checks the lookback, term and payment option
+func (o RecommendationOptions) Validate() error {
+	_, _, _, err := parseRecommendationOptions(o)
+	return err
+}
+
+// parseRecommendationOptions converts recommendation options to Cost Explorer
+// values, defaulting to 30 days, one year and no upfront payment
+func parseRecommendationOptions(opts RecommendationOptions) (cetypes.LookbackPeriodInDays, cetypes.TermInYears, cetypes.PaymentOption, error) {
+	var lookback cetypes.LookbackPeriodInDays
+	switch opts.LookbackDays {
+	case 0, 30:
+		lookback = cetypes.LookbackPeriodInDaysThirtyDays
+	case 7:
+		lookback = cetypes.LookbackPeriodInDaysSevenDays
+	case 60:
+		lookback = cetypes.LookbackPeriodInDaysSixtyDays
+	default:
+		return "", "", "", fmt.Errorf("unsupported lookback of %d days (use 7, 30 or 60)", opts.LookbackDays)
+	}
+
+	var term cetypes.TermInYears
+	switch opts.TermYears {
+	case 0, 1:
+		term = cetypes.TermInYearsOneYear
+	case 3:
+		term = cetypes.TermInYearsThreeYears
+	default:
+		return "", "", "", fmt.Errorf("unsupported term of %d years (use 1 or 3)", opts.TermYears)
+	}
+
+	var payment cetypes.PaymentOption
+	switch strings.ToLower(opts.PaymentOption) {
+	case "", "no-upfront":
+		payment = cetypes.PaymentOptionNoUpfront
+	case "partial-upfront":
+		payment = cetypes.PaymentOptionPartialUpfront
+	case "all-upfront":
+		payment = cetypes.PaymentOptionAllUpfront
+	default:
+		return "", "", "", fmt.Errorf("unsupported payment option %q (use no-upfront, partial-upfront or all-upfront)", opts.PaymentOption)
+	}
+
+	return lookback, term, payment, nil
+}
+
+func toReservationCommitment(group cetypes.ReservationUtilizationGroup) Commitment {
+	attributes := group.Attributes
+	description := commitmentAttribute(attributes, "InstanceType")
+	if count := commitmentAttribute(attributes, "NumberOfInstances"); count != "" && description != "" {
+		description = count + " x " + description
+	}
+	if platform := commitmentAttribute(attributes, "Platform"); platform != "" {
+		description = strings.TrimSpace(description + " " + platform)
+	}
+
+	commitment := Commitment{
+		Type:        CommitmentTypeReservation,
+		ID:          aws.ToString(group.Value),
+		Description: description,
+		Account:     commitmentAttribute(attributes, "AccountId"),
+		Region:      commitmentAttribute(attributes, "Region"),
+		Start:       parseCommitmentTime(commitmentAttribute(attributes, "StartDateTime")),
+		End:         parseCommitmentTime(commitmentAttribute(attributes, "EndDateTime")),
+	}
+	if commitment.ID == "" {
+		commitment.ID = commitmentAttribute(attributes, "SubscriptionId")
+	}
+	if utilization := group.Utilization; utilization != nil && utilization.UtilizationPercentage != nil {
+		commitment.HasUtilization = true
+		commitment.UtilizationPercent = parseFloat(aws.ToString(utilization.UtilizationPercentage))
+		commitment.UnusedCommitment = parseFloat(aws.ToString(utilization.RICostForUnusedHours))
+	}
+	return commitment
+}
+
+func toReservationRecommendation(service string, detail cetypes.ReservationPurchaseRecommendationDetail) PurchaseRecommendation {
+	instanceType, region := recommendationInstance(detail.InstanceDetails)
+	description := instanceType
+	if count := aws.ToString(detail.RecommendedNumberOfInstancesToPurchase); count != "" {
+		description = count + " x " + description
+	}
+	if region != "" {
+		description += " (" + region + ")"
+	}
+
+	return PurchaseRecommendation{
+		Type:                    CommitmentTypeReservation,
+		Service:                 service,
+		Description:             description,
+		Account:                 aws.ToString(detail.AccountId),
+		UpfrontCost:             parseFloat(aws.ToString(detail.UpfrontCost)),
+		EstimatedMonthlySavings: parseFloat(aws.ToString(detail.EstimatedMonthlySavingsAmount)),
+		EstimatedSavingsPercent: parseFloat(aws.ToString(detail.EstimatedMonthlySavingsPercentage)),
+	}
+}
+
+// toSavingsPlansRecommendation returns the overall recommendation of one plan
+// type, or nil if there is none
+func toSavingsPlansRecommendation(planType cetypes.SupportedSavingsPlansType, recommendation *cetypes.SavingsPlansPurchaseRecommendation) *PurchaseRecommendation {
+	if recommendation == nil || recommendation.SavingsPlansPurchaseRecommendationSummary == nil {
+		return nil
+	}
+	summary := recommendation.SavingsPlansPurchaseRecommendationSummary
+	hourly := parseFloat(aws.ToString(summary.HourlyCommitmentToPurchase))
+	if hourly <= 0 {
+		return nil
+	}
+
+	upfront := 0.0
+	for _, detail := range recommendation.SavingsPlansPurchaseRecommendationDetails {
+		upfront += parseFloat(aws.ToString(detail.UpfrontCost))
+	}
+
+	return &PurchaseRecommendation{
+		Type:                    CommitmentTypeSavingsPlan,
+		Service:                 string(planType),
+		Description:             fmt.Sprintf("$%.2f/hour commitment", hourly),
+		UpfrontCost:             upfront,
+		EstimatedMonthlySavings: parseFloat(aws.ToString(summary.EstimatedMonthlySavingsAmount)),
+		EstimatedSavingsPercent: parseFloat(aws.ToString(summary.EstimatedSavingsPercentage)),
+	}
+}
+
+// recommendationInstance returns the instance or node type and region of a
+// reservation recommendation
+func recommendationInstance(details *cetypes.InstanceDetails) (string, string) {
+	switch {
+	case details == nil:
+		return "", ""
+	case details.EC2InstanceDetails != nil:
+		return aws.ToString(details.EC2InstanceDetails.InstanceType), aws.ToString(details.EC2InstanceDetails.Region)
+	case details.RDSInstanceDetails != nil:
+		instance := details.RDSInstanceDetails
+		return strings.TrimSpace(aws.ToString(instance.InstanceType) + " " + aws.ToString(instance.DatabaseEngine)), aws.ToString(instance.Region)
+	case details.ElastiCacheInstanceDetails != nil:
+		return aws.ToString(details.ElastiCacheInstanceDetails.NodeType), aws.ToString(details.ElastiCacheInstanceDetails.Region)
+	case details.RedshiftInstanceDetails != nil:
+		return aws.ToString(details.RedshiftInstanceDetails.NodeType), aws.ToString(details.RedshiftInstanceDetails.Region)
+	case details.ESInstanceDetails != nil:
+		return aws.ToString(details.ESInstanceDetails.InstanceSize), aws.ToString(details.ESInstanceDetails.Region)
+	case details.MemoryDBInstanceDetails != nil:
+		return aws.ToString(details.MemoryDBInstanceDetails.NodeType), aws.ToString(details.MemoryDBInstanceDetails.Region)
+	}
+	return "", ""
+}
+
+// commitmentAttribute looks up a Cost Explorer attribute, whose keys are not
+// consistently cased
+func commitmentAttribute(attributes map[string]string, name string) string {
+	if value, ok := attributes[name]; ok {
+		return value
+	}
+	for key, value := range attributes {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseCommitmentTime parses a commitment start or end time, or returns the
+// zero time
+func parseCommitmentTime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05.000Z", "2006-01-02 15:04:05", costDateLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// savingsPlanID returns the ID at the end of a Savings Plan ARN
+func savingsPlanID(arn string) string {
+	if i := strings.LastIndex(arn, "/"); i >= 0 {
+		return arn[i+1:]
+	}
+	return arn
+}
+
+func commitmentPeriod(start, end time.Time) *cetypes.DateInterval {
+	return &cetypes.DateInterval{
+		Start: aws.String(start.Format(costDateLayout)),
+		End:   aws.String(end.Format(costDateLayout)),
+	}
+}
@@ -0,0 +1,236 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+func TestAddCommitments(t *testing.T) {
+	now := time.Date(2024, 11, 20, 15, 0, 0, 0, time.UTC)
+	results := NewCommitmentResults(now, now.AddDate(0, 0, -30), now, CommitmentOptions{ExpiringWithin: 30, MinUtilization: 80})
+
+	results.AddCommitments(
+		&CommitmentUtilization{Type: CommitmentTypeReservation, UtilizationPercent: 92},
+		[]Commitment{
+			{ID: "ri-later", End: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), HasUtilization: true, UtilizationPercent: 95},
+			{ID: "ri-soon", End: time.Date(2024, 12, 5, 8, 0, 0, 0, time.UTC), HasUtilization: true, UtilizationPercent: 99},
+			{ID: "ri-idle", End: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), HasUtilization: true, UtilizationPercent: 40},
+		},
+	)
+	results.AddCommitments(
+		&CommitmentUtilization{Type: CommitmentTypeSavingsPlan, UtilizationPercent: 75},
+		[]Commitment{
+			{ID: "sp-unused", HasUtilization: false},
+			{ID: "sp-today", End: time.Date(2024, 11, 20, 23, 0, 0, 0, time.UTC), HasUtilization: true, UtilizationPercent: 100},
+			{ID: "sp-expired", End: time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	)
+
+	order := make([]string, 0, len(results.Commitments))
+	for _, commitment := range results.Commitments {
+		order = append(order, commitment.ID)
+	}
+	want := []string{"sp-expired", "sp-today", "ri-soon", "ri-idle", "ri-later", "sp-unused"}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("Commitments = %v, want %v", order, want)
+		}
+	}
+
+	expiring := results.Expiring()
+	if len(expiring) != 2 || expiring[0].ID != "sp-today" || expiring[0].DaysRemaining != 0 || expiring[1].ID != "ri-soon" || expiring[1].DaysRemaining != 15 {
+		t.Errorf("Expiring() = %+v, want sp-today in 0 days and ri-soon in 15", expiring)
+	}
+
+	low := results.LowUtilization()
+	if len(low) != 1 || low[0].ID != "ri-idle" {
+		t.Errorf("LowUtilization() = %+v, want ri-idle only", low)
+	}
+
+	if results.Utilization[0].Low || !results.Utilization[1].Low {
+		t.Errorf("Utilization = %+v, want only Savings Plans low", results.Utilization)
+	}
+	if !results.NeedsAttention() {
+		t.Error("NeedsAttention() = false, want true")
+	}
+}
+
+func TestNeedsAttention(t *testing.T) {
+	now := time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC)
+	opts := CommitmentOptions{ExpiringWithin: 30, MinUtilization: 80}
+
+	healthy := NewCommitmentResults(now, now, now, opts)
+	healthy.AddCommitments(&CommitmentUtilization{UtilizationPercent: 95}, []Commitment{
+		{ID: "ri", End: now.AddDate(1, 0, 0), HasUtilization: true, UtilizationPercent: 95},
+	})
+	if healthy.NeedsAttention() {
+		t.Error("NeedsAttention() = true for healthy commitments")
+	}
+
+	// Low overall utilization alone needs attention
+	underused := NewCommitmentResults(now, now, now, opts)
+	underused.AddCommitments(&CommitmentUtilization{UtilizationPercent: 60}, nil)
+	if !underused.NeedsAttention() {
+		t.Error("NeedsAttention() = false for low overall utilization")
+	}
+
+	empty := NewCommitmentResults(now, now, now, opts)
+	empty.AddCommitments(nil, nil)
+	if empty.NeedsAttention() || len(empty.Utilization) != 0 {
+		t.Errorf("empty results = %+v, want nothing to report", empty)
+	}
+}
+
+func TestAddRecommendations(t *testing.T) {
+	results := NewCommitmentResults(time.Now(), time.Now(), time.Now(), CommitmentOptions{})
+	results.AddRecommendations([]PurchaseRecommendation{{Description: "small", EstimatedMonthlySavings: 10}})
+	results.AddRecommendations([]PurchaseRecommendation{{Description: "large", EstimatedMonthlySavings: 250}})
+
+	if results.Recommendations[0].Description != "large" {
+		t.Errorf("Recommendations = %+v, want largest savings first", results.Recommendations)
+	}
+}
+
+func TestParseRecommendationOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         RecommendationOptions
+		wantLookback cetypes.LookbackPeriodInDays
+		wantTerm     cetypes.TermInYears
+		wantPayment  cetypes.PaymentOption
+		wantErr      bool
+	}{
+		{name: "defaults", wantLookback: cetypes.LookbackPeriodInDaysThirtyDays, wantTerm: cetypes.TermInYearsOneYear, wantPayment: cetypes.PaymentOptionNoUpfront},
+		{
+			name:         "three years all upfront",
+			opts:         RecommendationOptions{LookbackDays: 60, TermYears: 3, PaymentOption: "All-Upfront"},
+			wantLookback: cetypes.LookbackPeriodInDaysSixtyDays,
+			wantTerm:     cetypes.TermInYearsThreeYears,
+			wantPayment:  cetypes.PaymentOptionAllUpfront,
+		},
+		{name: "bad lookback", opts: RecommendationOptions{LookbackDays: 14}, wantErr: true},
+		{name: "bad term", opts: RecommendationOptions{TermYears: 2}, wantErr: true},
+		{name: "bad payment", opts: RecommendationOptions{PaymentOption: "heavy"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookback, term, payment, err := parseRecommendationOptions(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRecommendationOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (tt.opts.Validate() != nil) != tt.wantErr {
+				t.Errorf("Validate() disagrees with parseRecommendationOptions()")
+			}
+			if tt.wantErr {
+				return
+			}
+			if lookback != tt.wantLookback || term != tt.wantTerm || payment != tt.wantPayment {
+				t.Errorf("got %s, %s, %s", lookback, term, payment)
+			}
+		})
+	}
+}
+
+func TestToReservationCommitment(t *testing.T) {
+	commitment := toReservationCommitment(cetypes.ReservationUtilizationGroup{
+		Key:   aws.String("SUBSCRIPTION_ID"),
+		Value: aws.String("123456789"),
+		Attributes: map[string]string{
+			"accountId":         "111111111111",
+			"instanceType":      "m5.large",
+			"numberOfInstances": "4",
+			"platform":          "Linux/UNIX",
+			"region":            "us-east-1",
+			"endDateTime":       "2025-01-15T10:00:00.000Z",
+		},
+		Utilization: &cetypes.ReservationAggregates{
+			UtilizationPercentage: aws.String("62.5"),
+			RICostForUnusedHours:  aws.String("120.40"),
+		},
+	})
+
+	if commitment.ID != "123456789" || commitment.Description != "4 x m5.large Linux/UNIX" || commitment.Account != "111111111111" || commitment.Region != "us-east-1" {
+		t.Errorf("commitment = %+v", commitment)
+	}
+	if got := commitment.End.Format(time.RFC3339); got != "2025-01-15T10:00:00Z" {
+		t.Errorf("End = %s, want 2025-01-15T10:00:00Z", got)
+	}
+	if !commitment.HasUtilization || commitment.UtilizationPercent != 62.5 || commitment.UnusedCommitment != 120.40 {
+		t.Errorf("utilization = %v %.1f%% $%.2f", commitment.HasUtilization, commitment.UtilizationPercent, commitment.UnusedCommitment)
+	}
+}
+
+func TestToReservationRecommendation(t *testing.T) {
+	recommendation := toReservationRecommendation("Amazon Relational Database Service", cetypes.ReservationPurchaseRecommendationDetail{
+		AccountId:                              aws.String("222222222222"),
+		RecommendedNumberOfInstancesToPurchase: aws.String("2"),
+		EstimatedMonthlySavingsAmount:          aws.String("310.25"),
+		EstimatedMonthlySavingsPercentage:      aws.String("35.5"),
+		UpfrontCost:                            aws.String("0"),
+		InstanceDetails: &cetypes.InstanceDetails{
+			RDSInstanceDetails: &cetypes.RDSInstanceDetails{
+				InstanceType:   aws.String("db.r6g.large"),
+				DatabaseEngine: aws.String("PostgreSQL"),
+				Region:         aws.String("eu-west-1"),
+			},
+		},
+	})
+
+	if recommendation.Description != "2 x db.r6g.large PostgreSQL (eu-west-1)" {
+		t.Errorf("Description = %q", recommendation.Description)
+	}
+	if recommendation.EstimatedMonthlySavings != 310.25 || recommendation.EstimatedSavingsPercent != 35.5 || recommendation.Account != "222222222222" {
+		t.Errorf("recommendation = %+v", recommendation)
+	}
+}
+
+func TestToSavingsPlansRecommendation(t *testing.T) {
+	if got := toSavingsPlansRecommendation(cetypes.SupportedSavingsPlansTypeComputeSp, nil); got != nil {
+		t.Errorf("nil recommendation = %+v, want nil", got)
+	}
+
+	none := &cetypes.SavingsPlansPurchaseRecommendation{
+		SavingsPlansPurchaseRecommendationSummary: &cetypes.SavingsPlansPurchaseRecommendationSummary{HourlyCommitmentToPurchase: aws.String("0")},
+	}
+	if got := toSavingsPlansRecommendation(cetypes.SupportedSavingsPlansTypeComputeSp, none); got != nil {
+		t.Errorf("zero commitment = %+v, want nil", got)
+	}
+
+	got := toSavingsPlansRecommendation(cetypes.SupportedSavingsPlansTypeComputeSp, &cetypes.SavingsPlansPurchaseRecommendation{
+		SavingsPlansPurchaseRecommendationSummary: &cetypes.SavingsPlansPurchaseRecommendationSummary{
+			HourlyCommitmentToPurchase:    aws.String("1.254"),
+			EstimatedMonthlySavingsAmount: aws.String("420"),
+			EstimatedSavingsPercentage:    aws.String("22"),
+		},
+		SavingsPlansPurchaseRecommendationDetails: []cetypes.SavingsPlansPurchaseRecommendationDetail{
+			{UpfrontCost: aws.String("100")},
+			{UpfrontCost: aws.String("50")},
+		},
+	})
+	if got == nil || got.Description != "$1.25/hour commitment" || got.UpfrontCost != 150 || got.EstimatedMonthlySavings != 420 || got.Service != "COMPUTE_SP" {
+		t.Errorf("recommendation = %+v", got)
+	}
+}
+
+func TestParseCommitmentTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"2025-01-15T10:00:00Z", "2025-01-15"},
+		{"2025-01-15T10:00:00.000Z", "2025-01-15"},
+		{"2025-01-15", "2025-01-15"},
+		{"", "0001-01-01"},
+		{"soon", "0001-01-01"},
+	}
+
+	for _, tt := range tests {
+		if got := parseCommitmentTime(tt.value).Format(costDateLayout); got != tt.want {
+			t.Errorf("parseCommitmentTime(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -3,24 +3,47 @@ package aws
 import (
 	"context"
 	"fmt"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/budgets"
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
 	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/aws-sdk-go-v2/service/savingsplans"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 type CostAnalyzer struct {
-	client *costexplorer.Client
-	region string
+	client        *costexplorer.Client
+	budgetsClient *budgets.Client
+	stsClient     *sts.Client
+	// savingsPlansClient describes Savings Plans for their end dates
+	savingsPlansClient *savingsplans.Client
+	region             string
+	// Query options, see SetQueryOptions
+	metric      string
+	granularity cetypes.Granularity
+	filter      *cetypes.Expression
+	// usageTypeFiltered is set when the filter selects usage types
+	usageTypeFiltered bool
+	// cache is set by EnableCache
+	cache *costCache
+	stats CostQueryStats
 }
 
 type CostItem struct {
 	Name   string
 	Amount float64
 	Unit   string
+	// Children break the item down by the second group-by key
+	Children []CostItem
+	// Set by CompareWith
+	PreviousAmount float64
+	Delta          float64
+	PercentChange  float64
+	Status         string
 }
 
 type CostResults struct {
@@ -28,9 +51,20 @@ type CostResults struct {
 	EndDate      time.Time
 	TotalCost    float64
 	Currency     string
+	Metric       string
+	Granularity  string
 	GroupBy      string
+	GroupByKeys  []string
 	Items        []CostItem
 	DailyTrend   []DailyCost
+	// DailyTrend holds one entry per day, or per month with monthly granularity.
+	// DailyByGroup holds the cost of each group key, aligned with DailyTrend.
+	DailyByGroup map[string][]DailyCost
+	// Comparison is set by CompareWith
+	Comparison *CostComparison
+	// UsageUnit is the unit of the usage metric, such as Hrs, or empty when
+	// the usage types have different units. Currency is empty for usage.
+	UsageUnit string
 }
 
 type DailyCost struct {
@@ -45,113 +79,210 @@ func NewCostAnalyzer(ctx context.Context, region string) (*CostAnalyzer, error)
 	}
 
 	return &CostAnalyzer{
-		client: costexplorer.NewFromConfig(cfg),
-		region: region,
+		client:             costexplorer.NewFromConfig(cfg),
+		budgetsClient:      budgets.NewFromConfig(cfg),
+		stsClient:          sts.NewFromConfig(cfg),
+		savingsPlansClient: savingsplans.NewFromConfig(cfg),
+		region:             region,
+		metric:             MetricUnblendedCost,
+		granularity:        cetypes.GranularityDaily,
 	}, nil
 }
 
+// GetCostAndUsage returns costs grouped by up to two comma-separated keys:
+// dimensions, TAG:<key> or COST_CATEGORY:<name>. With two keys, each item is
+// broken down by the second key in its children. All result pages are fetched,
+// and closed periods are read from the cache if it is enabled.
 func (c *CostAnalyzer) GetCostAndUsage(ctx context.Context, startDate, endDate time.Time, groupBy string) (*CostResults, error) {
-	// Format dates for Cost Explorer API
-	start := startDate.Format("2006-01-02")
-	end := endDate.Format("2006-01-02")
+	groupDefinitions, groupByKeys, err := parseGroupBy(groupBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUsageScope(c.metric, c.usageTypeFiltered, groupByKeys); err != nil {
+		return nil, err
+	}
 
 	input := &costexplorer.GetCostAndUsageInput{
-		TimePeriod: &cetypes.DateInterval{
-			Start: aws.String(start),
-			End:   aws.String(end),
-		},
-		Granularity: cetypes.GranularityDaily,
-		Metrics:     []string{"UnblendedCost"},
-		GroupBy: []cetypes.GroupDefinition{
-			{
-				Type: cetypes.GroupDefinitionTypeDimension,
-				Key:  aws.String(groupBy),
-			},
-		},
-	}
-
-	result, err := c.client.GetCostAndUsage(ctx, input)
+		Granularity: c.granularity,
+		Metrics:     []string{c.metric},
+		GroupBy:     groupDefinitions,
+		Filter:      c.filter,
+	}
+
+	periods, err := c.costPeriods(ctx, input, startDate, endDate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cost and usage: %w", err)
+		return nil, err
 	}
 
 	// Process results
-	costMap := make(map[string]float64)
-	dailyCosts := make([]DailyCost, 0)
-	totalCost := 0.0
+	acc := newCostAccumulator(true)
 	currency := "USD"
+	units := make(map[string]bool)
+
+	for _, period := range periods {
+		acc.addDay(period.Start)
+
+		for _, group := range period.Groups {
+			if len(group.Keys) == 0 {
+				continue
+			}
+
+			child := ""
+			if len(group.Keys) > 1 && len(groupDefinitions) > 1 {
+				child = groupKeyName(groupDefinitions[1], group.Keys[1])
+			}
+			acc.add(period.Start, groupKeyName(groupDefinitions[0], group.Keys[0]), child, group.Amount)
+
+			if group.Unit != "" {
+				currency = group.Unit
+				units[group.Unit] = true
+			}
+		}
+	}
+
+	// Usage quantities are in units such as Hrs or GB, not a currency
+	usageUnit := ""
+	if c.metric == MetricUsageQuantity {
+		currency = ""
+		if len(units) == 1 {
+			for unit := range units {
+				usageUnit = unit
+			}
+		}
+	}
+
+	results := &CostResults{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		TotalCost:   acc.total,
+		Currency:    currency,
+		Metric:      c.metric,
+		Granularity: string(c.granularity),
+		GroupBy:     strings.Join(groupByKeys, ","),
+		GroupByKeys: groupByKeys,
+		UsageUnit:   usageUnit,
+	}
+	results.Items, results.DailyTrend, results.DailyByGroup = acc.results(results.AmountUnit())
+
+	return results, nil
+}
+
+// AmountUnit returns the unit of the amounts: the currency, or the usage unit
+// for the usage metric
+func (r *CostResults) AmountUnit() string {
+	if r.Metric == MetricUsageQuantity {
+		return r.UsageUnit
+	}
+	return r.Currency
+}
+
+// queryCostPeriods fetches all pages of a query from start to end and returns
+// its periods in order. A query without GroupBy returns each period's total as
+// a single group without keys.
+func (c *CostAnalyzer) queryCostPeriods(ctx context.Context, query *costexplorer.GetCostAndUsageInput, start, end string) ([]costPeriod, error) {
+	input := *query
+	input.TimePeriod = &cetypes.DateInterval{
+		Start: aws.String(start),
+		End:   aws.String(end),
+	}
+	metricName := c.metric
+	if len(query.Metrics) > 0 {
+		metricName = query.Metrics[0]
+	}
+
+	periods := make([]costPeriod, 0)
+	index := make(map[string]int)
+
+	for {
+		result, err := c.client.GetCostAndUsage(ctx, &input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cost and usage: %w", err)
+		}
+		c.stats.Requests++
+
+		// Groups of one period can be split across pages
+		for _, resultByTime := range result.ResultsByTime {
+			periodStart := aws.ToString(resultByTime.TimePeriod.Start)
+			i, ok := index[periodStart]
+			if !ok {
+				i = len(periods)
+				index[periodStart] = i
+				periods = append(periods, costPeriod{
+					Start:     periodStart,
+					End:       aws.ToString(resultByTime.TimePeriod.End),
+					Estimated: resultByTime.Estimated,
+					Groups:    make([]costGroup, 0, len(resultByTime.Groups)),
+				})
+			}
+
+			for _, group := range resultByTime.Groups {
+				metric, ok := group.Metrics[metricName]
+				if !ok {
+					continue
+				}
+				periods[i].Groups = append(periods[i].Groups, costGroup{
+					Keys:   group.Keys,
+					Amount: parseFloat(aws.ToString(metric.Amount)),
+					Unit:   aws.ToString(metric.Unit),
+				})
+			}
 
-	for _, resultByTime := range result.ResultsByTime {
-		dailyTotal := 0.0
-		
-		for _, group := range resultByTime.Groups {
-			if len(group.Keys) > 0 && len(group.Metrics) > 0 {
-				key := group.Keys[0]
-				
-				if amountStr, ok := group.Metrics["UnblendedCost"]; ok {
-					amount := parseFloat(aws.ToString(amountStr.Amount))
-					costMap[key] += amount
-					totalCost += amount
-					dailyTotal += amount
-					
-					if amountStr.Unit != nil {
-						currency = aws.ToString(amountStr.Unit)
-					}
+			if len(input.GroupBy) == 0 {
+				if metric, ok := resultByTime.Total[metricName]; ok {
+					periods[i].Groups = append(periods[i].Groups, costGroup{
+						Amount: parseFloat(aws.ToString(metric.Amount)),
+						Unit:   aws.ToString(metric.Unit),
+					})
 				}
 			}
 		}
 
-		dailyCosts = append(dailyCosts, DailyCost{
-			Date:   aws.ToString(resultByTime.TimePeriod.Start),
-			Amount: dailyTotal,
-		})
+		if result.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = result.NextPageToken
 	}
 
-	// Convert map to sorted slice
-	items := make([]CostItem, 0, len(costMap))
-	for name, amount := range costMap {
-		items = append(items, CostItem{
-			Name:   name,
-			Amount: amount,
-			Unit:   currency,
-		})
-	}
-
-	// Sort by amount descending
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].Amount > items[j].Amount
-	})
-
-	return &CostResults{
-		StartDate:  startDate,
-		EndDate:    endDate,
-		TotalCost:  totalCost,
-		Currency:   currency,
-		GroupBy:    groupBy,
-		Items:      items,
-		DailyTrend: dailyCosts,
-	}, nil
+	c.stats.FetchedPeriods += len(periods)
+	return periods, nil
 }
 
+// LimitToTopN keeps the top N items, and the top N children of each, and
+// sums the rest as "Other"
 func (r *CostResults) LimitToTopN(n int) {
-	if len(r.Items) > n {
-		// Keep top N and sum the rest as "Other"
-		topN := r.Items[:n]
-		other := 0.0
-		for i := n; i < len(r.Items); i++ {
-			other += r.Items[i].Amount
+	r.Items = limitItems(r.Items, n, r.AmountUnit(), r.Comparison != nil)
+	for i := range r.Items {
+		r.Items[i].Children = limitItems(r.Items[i].Children, n, r.AmountUnit(), r.Comparison != nil)
+	}
+}
+
+func limitItems(items []CostItem, n int, currency string, compared bool) []CostItem {
+	if len(items) <= n {
+		return items
+	}
+
+	// Keep top N and sum the rest as "Other"
+	topN := items[:n]
+	other := 0.0
+	otherPrevious := 0.0
+	for i := n; i < len(items); i++ {
+		other += items[i].Amount
+		otherPrevious += items[i].PreviousAmount
+	}
+
+	if other > 0 || otherPrevious > 0 {
+		item := CostItem{
+			Name:   "Other",
+			Amount: other,
+			Unit:   currency,
 		}
-		
-		if other > 0 {
-			topN = append(topN, CostItem{
-				Name:   "Other",
-				Amount: other,
-				Unit:   r.Currency,
-			})
+		if compared {
+			item = compareItem(item, otherPrevious)
 		}
-		
-		r.Items = topN
+		topN = append(topN, item)
 	}
+
+	return topN
 }
 
 func parseFloat(s string) float64 {
@@ -0,0 +1,298 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// costCacheRefreshDays is the number of recent days that are always queried,
+// as Cost Explorer keeps revising them
+const costCacheRefreshDays = 3
+
+// costCacheVersion changes whenever the cache file format does
+const costCacheVersion = 1
+
+// CostExplorerRequestCost is the price of one Cost Explorer API request in USD
+const CostExplorerRequestCost = 0.01
+
+// CostQueryStats counts Cost Explorer requests and where periods came from
+type CostQueryStats struct {
+	Requests       int
+	CachedPeriods  int
+	FetchedPeriods int
+}
+
+// costPeriod is the cost of each group in one period (day or month) of a query
+type costPeriod struct {
+	Start     string
+	End       string
+	Estimated bool
+	Groups    []costGroup
+}
+
+type costGroup struct {
+	Keys   []string
+	Amount float64
+	Unit   string
+}
+
+func (p costPeriod) key() string {
+	return p.Start + "/" + p.End
+}
+
+// costCache stores the closed periods of Cost Explorer queries on disk, one
+// file per account and query
+type costCache struct {
+	dir         string
+	account     string
+	refreshDays int
+	now         func() time.Time
+}
+
+type costCacheFile struct {
+	Version int
+	Periods map[string]costPeriod
+}
+
+// DefaultCostCacheDir returns the Cost Explorer cache directory in the user's
+// cache directory
+func DefaultCostCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "dtk", "cost-explorer"), nil
+}
+
+// EnableCache caches Cost Explorer results in dir. Days older than the last
+// few, and months that ended before them, are read from the cache once they
+// are no longer estimated; recent days are always queried. Entries are kept
+// per account, which is looked up here.
+func (c *CostAnalyzer) EnableCache(ctx context.Context, dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	identity, err := c.stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return fmt.Errorf("failed to get caller identity: %w", err)
+	}
+
+	c.cache = &costCache{
+		dir:         dir,
+		account:     aws.ToString(identity.Account),
+		refreshDays: costCacheRefreshDays,
+		now:         time.Now,
+	}
+	return nil
+}
+
+// QueryStats returns the Cost Explorer requests made so far and how many
+// periods were read from the cache
+func (c *CostAnalyzer) QueryStats() CostQueryStats {
+	return c.stats
+}
+
+// costPeriods returns the periods of a query from start to end, reading closed
+// periods from the cache when it is enabled
+func (c *CostAnalyzer) costPeriods(ctx context.Context, query *costexplorer.GetCostAndUsageInput, startDate, endDate time.Time) ([]costPeriod, error) {
+	start := startDate.Format(costDateLayout)
+	end := endDate.Format(costDateLayout)
+	if c.cache == nil {
+		return c.queryCostPeriods(ctx, query, start, end)
+	}
+
+	periods, cached, err := c.cache.periods(query, start, end, func(from, to string) ([]costPeriod, error) {
+		return c.queryCostPeriods(ctx, query, from, to)
+	})
+	c.stats.CachedPeriods += cached
+	return periods, err
+}
+
+// periods returns the periods of a query from start to end and the number read
+// from the cache. Missing closed periods are fetched in one query, and the
+// periods still open in another.
+func (c *costCache) periods(query *costexplorer.GetCostAndUsageInput, start, end string, fetch func(from, to string) ([]costPeriod, error)) ([]costPeriod, int, error) {
+	chunks := costChunks(start, end, query.Granularity)
+	key, err := c.key(query)
+	if len(chunks) == 0 || err != nil {
+		periods, err := fetch(start, end)
+		return periods, 0, err
+	}
+
+	file := c.load(key)
+	cutoff := truncateToDay(c.now()).AddDate(0, 0, -c.refreshDays).Format(costDateLayout)
+
+	firstMissing, lastMissing, firstOpen := -1, -1, len(chunks)
+	for i, chunk := range chunks {
+		if chunk.End > cutoff {
+			firstOpen = i
+			break
+		}
+		if _, ok := file.Periods[chunk.key()]; !ok {
+			if firstMissing < 0 {
+				firstMissing = i
+			}
+			lastMissing = i
+		}
+	}
+
+	fetched := make(map[string]costPeriod)
+	changed := false
+	fetchRange := func(from, to string) error {
+		periods, err := fetch(from, to)
+		if err != nil {
+			return err
+		}
+		for _, period := range periods {
+			fetched[period.key()] = period
+			if !period.Estimated && period.End <= cutoff {
+				file.Periods[period.key()] = period
+				changed = true
+			}
+		}
+		return nil
+	}
+
+	if firstMissing >= 0 {
+		if err := fetchRange(chunks[firstMissing].Start, chunks[lastMissing].End); err != nil {
+			return nil, 0, err
+		}
+	}
+	if firstOpen < len(chunks) {
+		if err := fetchRange(chunks[firstOpen].Start, end); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	periods := make([]costPeriod, 0, len(chunks))
+	matched := 0
+	cached := 0
+	for _, chunk := range chunks {
+		if period, ok := fetched[chunk.key()]; ok {
+			periods = append(periods, period)
+			matched++
+			continue
+		}
+		if period, ok := file.Periods[chunk.key()]; ok {
+			periods = append(periods, period)
+			cached++
+			continue
+		}
+		periods = append(periods, costPeriod{Start: chunk.Start, End: chunk.End, Groups: make([]costGroup, 0)})
+	}
+
+	// Periods that do not line up with the expected days or months would be
+	// lost, so query the whole range instead
+	if matched != len(fetched) {
+		periods, err := fetch(start, end)
+		return periods, 0, err
+	}
+
+	if changed {
+		// A cache that cannot be written only costs requests
+		_ = c.save(key, file)
+	}
+	return periods, cached, nil
+}
+
+// key identifies a query, apart from its time period, within the account
+func (c *costCache) key(query *costexplorer.GetCostAndUsageInput) (string, error) {
+	data, err := json.Marshal(struct {
+		Version     int
+		Account     string
+		Granularity cetypes.Granularity
+		Metrics     []string
+		GroupBy     []cetypes.GroupDefinition
+		Filter      *cetypes.Expression
+	}{
+		Version:     costCacheVersion,
+		Account:     c.account,
+		Granularity: query.Granularity,
+		Metrics:     query.Metrics,
+		GroupBy:     query.GroupBy,
+		Filter:      query.Filter,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// load reads the cache file of a query, or returns an empty one
+func (c *costCache) load(key string) *costCacheFile {
+	empty := &costCacheFile{Version: costCacheVersion, Periods: make(map[string]costPeriod)}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return empty
+	}
+	var file costCacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != costCacheVersion || file.Periods == nil {
+		return empty
+	}
+	return &file
+}
+
+// save writes the cache file of a query through a rename, so concurrent runs
+// never read a partial file
+func (c *costCache) save(key string, file *costCacheFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, key+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.dir, key+".json"))
+}
+
+// costChunks splits start to end into the periods Cost Explorer returns: days,
+// or calendar months cut at start and end
+func costChunks(start, end string, granularity cetypes.Granularity) []costPeriod {
+	from, err := time.Parse(costDateLayout, start)
+	if err != nil {
+		return nil
+	}
+	to, err := time.Parse(costDateLayout, end)
+	if err != nil {
+		return nil
+	}
+
+	chunks := make([]costPeriod, 0)
+	for from.Before(to) {
+		next := from.AddDate(0, 0, 1)
+		if granularity == cetypes.GranularityMonthly {
+			next = time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		}
+		if next.After(to) {
+			next = to
+		}
+		chunks = append(chunks, costPeriod{Start: from.Format(costDateLayout), End: next.Format(costDateLayout)})
+		from = next
+	}
+	return chunks
+}
@@ -0,0 +1,174 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// fakeCostExplorer returns one group per period costing the day of the month,
+// with periods from estimatedFrom on marked as estimated
+type fakeCostExplorer struct {
+	granularity   cetypes.Granularity
+	estimatedFrom string
+	calls         []string
+}
+
+func (f *fakeCostExplorer) fetch(from, to string) ([]costPeriod, error) {
+	f.calls = append(f.calls, from+"/"+to)
+	periods := costChunks(from, to, f.granularity)
+	for i := range periods {
+		day, _ := time.Parse(costDateLayout, periods[i].Start)
+		periods[i].Estimated = f.estimatedFrom != "" && periods[i].Start >= f.estimatedFrom
+		periods[i].Groups = []costGroup{{Keys: []string{"EC2"}, Amount: float64(day.Day()), Unit: "USD"}}
+	}
+	return periods, nil
+}
+
+func newTestCostCache(t *testing.T, now string) *costCache {
+	t.Helper()
+	day, err := time.Parse(costDateLayout, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &costCache{
+		dir:         t.TempDir(),
+		account:     "111111111111",
+		refreshDays: costCacheRefreshDays,
+		now:         func() time.Time { return day.Add(15 * time.Hour) },
+	}
+}
+
+func TestCostCachePeriods(t *testing.T) {
+	query := &costexplorer.GetCostAndUsageInput{
+		Granularity: cetypes.GranularityDaily,
+		Metrics:     []string{MetricUnblendedCost},
+		GroupBy:     []cetypes.GroupDefinition{{Type: cetypes.GroupDefinitionTypeDimension, Key: aws.String("SERVICE")}},
+	}
+	cache := newTestCostCache(t, "2024-11-20")
+	explorer := &fakeCostExplorer{granularity: cetypes.GranularityDaily, estimatedFrom: "2024-11-16"}
+
+	// First run: everything is queried, closed days are stored
+	periods, cached, err := cache.periods(query, "2024-11-10", "2024-11-20", explorer.fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(periods) != 10 || cached != 0 {
+		t.Fatalf("first run: %d periods, %d cached; want 10 and 0", len(periods), cached)
+	}
+	if got := strings.Join(explorer.calls, " "); got != "2024-11-10/2024-11-17 2024-11-17/2024-11-20" {
+		t.Errorf("first run calls = %s", got)
+	}
+
+	// Second run: only the estimated day and the last few days are queried
+	explorer.calls = nil
+	periods, cached, err = cache.periods(query, "2024-11-10", "2024-11-20", explorer.fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached != 6 {
+		t.Errorf("second run cached = %d, want 6", cached)
+	}
+	if got := strings.Join(explorer.calls, " "); got != "2024-11-16/2024-11-17 2024-11-17/2024-11-20" {
+		t.Errorf("second run calls = %s", got)
+	}
+	for i, period := range periods {
+		if want := float64(10 + i); len(period.Groups) != 1 || period.Groups[0].Amount != want {
+			t.Errorf("periods[%d] = %+v, want amount %.0f", i, period, want)
+		}
+	}
+
+	// A longer range only queries the days before the cached ones
+	explorer.calls = nil
+	explorer.estimatedFrom = ""
+	if _, _, err := cache.periods(query, "2024-11-05", "2024-11-20", explorer.fetch); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(explorer.calls, " "); got != "2024-11-05/2024-11-17 2024-11-17/2024-11-20" {
+		t.Errorf("longer range calls = %s", got)
+	}
+
+	// Another query does not share entries
+	explorer.calls = nil
+	other := *query
+	other.Metrics = []string{MetricAmortizedCost}
+	if _, cached, _ := cache.periods(&other, "2024-11-10", "2024-11-17", explorer.fetch); cached != 0 || len(explorer.calls) != 1 {
+		t.Errorf("other query: cached = %d, calls = %v; want a fresh query", cached, explorer.calls)
+	}
+}
+
+func TestCostCacheMonthly(t *testing.T) {
+	query := &costexplorer.GetCostAndUsageInput{Granularity: cetypes.GranularityMonthly, Metrics: []string{MetricUnblendedCost}}
+	cache := newTestCostCache(t, "2024-11-02")
+	explorer := &fakeCostExplorer{granularity: cetypes.GranularityMonthly}
+
+	for run := 0; run < 2; run++ {
+		explorer.calls = nil
+		periods, cached, err := cache.periods(query, "2024-08-15", "2024-11-02", explorer.fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(periods) != 4 || periods[0].Start != "2024-08-15" || periods[3].End != "2024-11-02" {
+			t.Fatalf("run %d periods = %+v", run, periods)
+		}
+		// October ends within the refresh window, so it stays open
+		wantCached, wantCalls := 0, "2024-08-15/2024-10-01 2024-10-01/2024-11-02"
+		if run == 1 {
+			wantCached, wantCalls = 2, "2024-10-01/2024-11-02"
+		}
+		if cached != wantCached || strings.Join(explorer.calls, " ") != wantCalls {
+			t.Errorf("run %d: cached = %d, calls = %v; want %d, %s", run, cached, explorer.calls, wantCached, wantCalls)
+		}
+	}
+}
+
+func TestCostCacheCorruptFile(t *testing.T) {
+	query := &costexplorer.GetCostAndUsageInput{Granularity: cetypes.GranularityDaily, Metrics: []string{MetricUnblendedCost}}
+	cache := newTestCostCache(t, "2024-11-20")
+	key, err := cache.key(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cache.dir, key+".json"), []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	explorer := &fakeCostExplorer{granularity: cetypes.GranularityDaily}
+	periods, cached, err := cache.periods(query, "2024-11-01", "2024-11-05", explorer.fetch)
+	if err != nil || len(periods) != 4 || cached != 0 {
+		t.Errorf("periods() = %d periods, %d cached, %v; want 4 queried periods", len(periods), cached, err)
+	}
+	if _, cached, _ := cache.periods(query, "2024-11-01", "2024-11-05", explorer.fetch); cached != 4 {
+		t.Errorf("cached = %d after rewriting the file, want 4", cached)
+	}
+}
+
+func TestCostChunks(t *testing.T) {
+	tests := []struct {
+		start, end  string
+		granularity cetypes.Granularity
+		want        string
+	}{
+		{"2024-11-01", "2024-11-04", cetypes.GranularityDaily, "2024-11-01/2024-11-02 2024-11-02/2024-11-03 2024-11-03/2024-11-04"},
+		{"2024-11-20", "2025-01-10", cetypes.GranularityMonthly, "2024-11-20/2024-12-01 2024-12-01/2025-01-01 2025-01-01/2025-01-10"},
+		{"2024-11-04", "2024-11-04", cetypes.GranularityDaily, ""},
+		{"bad", "2024-11-04", cetypes.GranularityDaily, ""},
+	}
+
+	for _, tt := range tests {
+		chunks := costChunks(tt.start, tt.end, tt.granularity)
+		got := make([]string, 0, len(chunks))
+		for _, chunk := range chunks {
+			got = append(got, chunk.key())
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("costChunks(%s, %s, %s) = %v, want %s", tt.start, tt.end, tt.granularity, got, tt.want)
+		}
+	}
+}
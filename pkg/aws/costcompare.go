@@ -0,0 +1,142 @@
+package aws
+
+import (
+	"sort"
+	"time"
+)
+
+// Cost item statuses relative to the previous period
+const (
+	CostItemStatusNew     = "new"
+	CostItemStatusRemoved = "removed"
+)
+
+// maxCostMovers is the number of biggest increases and decreases reported
+const maxCostMovers = 5
+
+// CostComparison summarizes the change from the previous period of equal length
+type CostComparison struct {
+	PreviousStartDate time.Time
+	PreviousEndDate   time.Time
+	PreviousTotal     float64
+	Delta             float64
+	PercentChange     float64
+	// Biggest changes first
+	Increases []CostItem
+	Decreases []CostItem
+	// NewItems had no cost in the previous period, RemovedItems have none in this one
+	NewItems     []CostItem
+	RemovedItems []CostItem
+}
+
+// CompareWith sets the previous amount, delta and percent change of each item
+// and summarizes the biggest movers. Items that only had cost in the previous
+// period are listed in RemovedItems rather than Items, so LimitToTopN does not
+// fold them into "Other". Call it before LimitToTopN so movers outside the top
+// items are still reported.
+func (r *CostResults) CompareWith(previous *CostResults) {
+	comparison := &CostComparison{
+		PreviousStartDate: previous.StartDate,
+		PreviousEndDate:   previous.EndDate,
+		PreviousTotal:     previous.TotalCost,
+		Delta:             r.TotalCost - previous.TotalCost,
+		Increases:         make([]CostItem, 0),
+		Decreases:         make([]CostItem, 0),
+		NewItems:          make([]CostItem, 0),
+		RemovedItems:      make([]CostItem, 0),
+	}
+	if previous.TotalCost > 0 {
+		comparison.PercentChange = comparison.Delta / previous.TotalCost * 100
+	}
+
+	r.Items, comparison.RemovedItems = compareItems(r.Items, previous.Items, r.AmountUnit())
+
+	for _, item := range r.Items {
+		switch {
+		case item.Status == CostItemStatusNew:
+			comparison.NewItems = append(comparison.NewItems, item)
+		case item.Delta > 0:
+			comparison.Increases = append(comparison.Increases, item)
+		case item.Delta < 0:
+			comparison.Decreases = append(comparison.Decreases, item)
+		}
+	}
+
+	sort.SliceStable(comparison.Increases, func(i, j int) bool {
+		return comparison.Increases[i].Delta > comparison.Increases[j].Delta
+	})
+	sort.SliceStable(comparison.Decreases, func(i, j int) bool {
+		return comparison.Decreases[i].Delta < comparison.Decreases[j].Delta
+	})
+	if len(comparison.Increases) > maxCostMovers {
+		comparison.Increases = comparison.Increases[:maxCostMovers]
+	}
+	if len(comparison.Decreases) > maxCostMovers {
+		comparison.Decreases = comparison.Decreases[:maxCostMovers]
+	}
+
+	r.Comparison = comparison
+}
+
+// compareItems compares items, and their children, with the previous period.
+// It returns the items that only had cost in the previous period separately,
+// biggest first; removed children are reflected in their parent's change only.
+func compareItems(items, previous []CostItem, currency string) ([]CostItem, []CostItem) {
+	previousItems := make(map[string]CostItem, len(previous))
+	for _, item := range previous {
+		previousItems[item.Name] = item
+	}
+
+	current := make(map[string]bool, len(items))
+	for i := range items {
+		current[items[i].Name] = true
+		before := previousItems[items[i].Name]
+		items[i] = compareItem(items[i], before.Amount)
+		if len(items[i].Children) > 0 || len(before.Children) > 0 {
+			items[i].Children, _ = compareItems(items[i].Children, before.Children, currency)
+		}
+	}
+
+	removed := make([]CostItem, 0)
+	for _, item := range previous {
+		if current[item.Name] {
+			continue
+		}
+		removed = append(removed, removedItem(item, currency))
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].PreviousAmount > removed[j].PreviousAmount
+	})
+
+	return items, removed
+}
+
+// removedItem returns an item, and its children, that only had cost in the
+// previous period
+func removedItem(previous CostItem, currency string) CostItem {
+	item := compareItem(CostItem{Name: previous.Name, Unit: currency}, previous.Amount)
+	for _, child := range previous.Children {
+		item.Children = append(item.Children, removedItem(child, currency))
+	}
+	return item
+}
+
+// compareItem sets the change of an item from its previous amount
+func compareItem(item CostItem, previousAmount float64) CostItem {
+	item.PreviousAmount = previousAmount
+	item.Delta = item.Amount - previousAmount
+	item.PercentChange = 0
+	item.Status = ""
+
+	switch {
+	case previousAmount == 0 && item.Amount > 0:
+		item.Status = CostItemStatusNew
+	case previousAmount > 0 && item.Amount == 0:
+		item.Status = CostItemStatusRemoved
+		item.PercentChange = -100
+	case previousAmount > 0:
+		item.PercentChange = item.Delta / previousAmount * 100
+	}
+
+	return item
+}
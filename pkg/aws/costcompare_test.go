@@ -0,0 +1,121 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func costItems(amounts map[string]float64) *CostResults {
+	results := &CostResults{Currency: "USD", Items: make([]CostItem, 0, len(amounts))}
+	for name, amount := range amounts {
+		results.Items = append(results.Items, CostItem{Name: name, Amount: amount, Unit: "USD"})
+		results.TotalCost += amount
+	}
+	return results
+}
+
+func TestCompareWith(t *testing.T) {
+	current := costItems(map[string]float64{"EC2": 600, "S3": 80, "RDS": 300, "Lambda": 25})
+	previous := costItems(map[string]float64{"EC2": 400, "S3": 100, "RDS": 300, "Redshift": 150})
+
+	current.CompareWith(previous)
+
+	comparison := current.Comparison
+	if comparison == nil {
+		t.Fatal("CompareWith() did not set Comparison")
+	}
+	if comparison.PreviousTotal != 950 || comparison.Delta != 55 {
+		t.Errorf("PreviousTotal = %.2f, Delta = %.2f, want 950 and 55", comparison.PreviousTotal, comparison.Delta)
+	}
+
+	byName := make(map[string]CostItem)
+	for _, item := range current.Items {
+		byName[item.Name] = item
+	}
+	if _, ok := byName["Redshift"]; ok {
+		t.Error("Items contains the removed Redshift, want it only in RemovedItems")
+	}
+	for _, item := range comparison.RemovedItems {
+		byName[item.Name] = item
+	}
+
+	tests := []struct {
+		name        string
+		wantDelta   float64
+		wantPercent float64
+		wantStatus  string
+	}{
+		{name: "EC2", wantDelta: 200, wantPercent: 50},
+		{name: "S3", wantDelta: -20, wantPercent: -20},
+		{name: "RDS", wantDelta: 0, wantPercent: 0},
+		{name: "Lambda", wantDelta: 25, wantPercent: 0, wantStatus: CostItemStatusNew},
+		{name: "Redshift", wantDelta: -150, wantPercent: -100, wantStatus: CostItemStatusRemoved},
+	}
+	for _, tt := range tests {
+		item, ok := byName[tt.name]
+		if !ok {
+			t.Errorf("item %s missing", tt.name)
+			continue
+		}
+		if item.Delta != tt.wantDelta || item.PercentChange != tt.wantPercent || item.Status != tt.wantStatus {
+			t.Errorf("%s = delta %.2f, %.1f%%, status %q; want %.2f, %.1f%%, %q",
+				tt.name, item.Delta, item.PercentChange, item.Status, tt.wantDelta, tt.wantPercent, tt.wantStatus)
+		}
+	}
+
+	names := func(items []CostItem) string {
+		got := make([]string, 0, len(items))
+		for _, item := range items {
+			got = append(got, item.Name)
+		}
+		return strings.Join(got, ",")
+	}
+	if got := names(comparison.Increases); got != "EC2" {
+		t.Errorf("Increases = %s, want EC2", got)
+	}
+	if got := names(comparison.Decreases); got != "S3" {
+		t.Errorf("Decreases = %s, want S3", got)
+	}
+	if got := names(comparison.NewItems); got != "Lambda" {
+		t.Errorf("NewItems = %s, want Lambda", got)
+	}
+	if got := names(comparison.RemovedItems); got != "Redshift" {
+		t.Errorf("RemovedItems = %s, want Redshift", got)
+	}
+}
+
+func TestLimitToTopNWithComparison(t *testing.T) {
+	results := &CostResults{
+		Currency: "USD",
+		Items: []CostItem{
+			{Name: "EC2", Amount: 600},
+			{Name: "RDS", Amount: 300},
+			{Name: "S3", Amount: 80},
+			{Name: "Lambda", Amount: 20},
+		},
+	}
+	previous := &CostResults{
+		Items: []CostItem{
+			{Name: "EC2", Amount: 500},
+			{Name: "S3", Amount: 40},
+			{Name: "Lambda", Amount: 20},
+			{Name: "Redshift", Amount: 60},
+		},
+	}
+
+	results.CompareWith(previous)
+	results.LimitToTopN(2)
+
+	if len(results.Items) != 3 {
+		t.Fatalf("LimitToTopN() kept %d items, want 3", len(results.Items))
+	}
+	// The removed Redshift is not folded into Other
+	other := results.Items[2]
+	if other.Name != "Other" || other.Amount != 100 || other.PreviousAmount != 60 || other.Delta != 40 {
+		t.Errorf("Other = %+v, want amount 100, previous 60, delta 40", other)
+	}
+	// Movers outside the top items are still reported
+	if len(results.Comparison.RemovedItems) != 1 || results.Comparison.RemovedItems[0].Name != "Redshift" {
+		t.Errorf("RemovedItems = %+v, want Redshift", results.Comparison.RemovedItems)
+	}
+}
@@ -0,0 +1,217 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// maxGroupByKeys is the number of group-by keys Cost Explorer accepts
+const maxGroupByKeys = 2
+
+// Buckets for spend without a cost allocation tag or cost category value
+const (
+	UntaggedBucket      = "untagged"
+	UncategorizedBucket = "uncategorized"
+)
+
+// Group-by prefixes for cost allocation tags and cost categories
+const (
+	GroupByTagPrefix          = "TAG:"
+	GroupByCostCategoryPrefix = "COST_CATEGORY:"
+)
+
+// parseGroupBy parses up to two comma-separated group-by keys. A key is a
+// dimension such as SERVICE or LINKED_ACCOUNT, TAG:<key> or COST_CATEGORY:<name>.
+// It returns the group definitions and their normalized labels.
+func parseGroupBy(groupBy string) ([]cetypes.GroupDefinition, []string, error) {
+	definitions := make([]cetypes.GroupDefinition, 0, maxGroupByKeys)
+	labels := make([]string, 0, maxGroupByKeys)
+
+	for _, key := range strings.Split(groupBy, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		var definition cetypes.GroupDefinition
+		var label string
+		upper := strings.ToUpper(key)
+		switch {
+		case strings.HasPrefix(upper, GroupByTagPrefix):
+			// Tag keys and cost category names are case-sensitive
+			name := strings.TrimSpace(key[len(GroupByTagPrefix):])
+			definition = cetypes.GroupDefinition{Type: cetypes.GroupDefinitionTypeTag, Key: aws.String(name)}
+			label = GroupByTagPrefix + name
+		case strings.HasPrefix(upper, GroupByCostCategoryPrefix):
+			name := strings.TrimSpace(key[len(GroupByCostCategoryPrefix):])
+			definition = cetypes.GroupDefinition{Type: cetypes.GroupDefinitionTypeCostCategory, Key: aws.String(name)}
+			label = GroupByCostCategoryPrefix + name
+		default:
+			definition = cetypes.GroupDefinition{Type: cetypes.GroupDefinitionTypeDimension, Key: aws.String(upper)}
+			label = upper
+		}
+
+		if aws.ToString(definition.Key) == "" {
+			return nil, nil, fmt.Errorf("group-by key %q has no name", key)
+		}
+		definitions = append(definitions, definition)
+		labels = append(labels, label)
+	}
+
+	if len(definitions) == 0 {
+		return nil, nil, fmt.Errorf("at least one group-by key is required")
+	}
+	if len(definitions) > maxGroupByKeys {
+		return nil, nil, fmt.Errorf("at most %d group-by keys are supported, got %d", maxGroupByKeys, len(definitions))
+	}
+
+	return definitions, labels, nil
+}
+
+// groupKeyName returns the display name of a group key. Cost Explorer returns
+// tag and cost category keys as "<name>$<value>", with an empty value for
+// spend that has none.
+func groupKeyName(definition cetypes.GroupDefinition, key string) string {
+	switch definition.Type {
+	case cetypes.GroupDefinitionTypeTag:
+		if value := groupKeyValue(key); value != "" {
+			return value
+		}
+		return UntaggedBucket
+	case cetypes.GroupDefinitionTypeCostCategory:
+		if value := groupKeyValue(key); value != "" {
+			return value
+		}
+		return UncategorizedBucket
+	default:
+		return key
+	}
+}
+
+// groupKeyValue returns the value of a "<name>$<value>" group key
+func groupKeyValue(key string) string {
+	if i := strings.Index(key, "$"); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
+
+// costItemsFromMap converts amounts by name to items, largest first
+func costItemsFromMap(amounts map[string]float64, currency string) []CostItem {
+	items := make([]CostItem, 0, len(amounts))
+	for name, amount := range amounts {
+		items = append(items, CostItem{
+			Name:   name,
+			Amount: amount,
+			Unit:   currency,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Amount != items[j].Amount {
+			return items[i].Amount > items[j].Amount
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	return items
+}
+
+// costAccumulator sums costs by group key, child key and day
+type costAccumulator struct {
+	costs     map[string]float64
+	children  map[string]map[string]float64
+	groupDays map[string]map[string]float64
+	dayTotals map[string]float64
+	total     float64
+}
+
+// newCostAccumulator returns an empty accumulator. Daily costs per group key
+// are only kept with trackGroupDays, as there can be very many group keys.
+func newCostAccumulator(trackGroupDays bool) *costAccumulator {
+	acc := &costAccumulator{
+		costs:     make(map[string]float64),
+		children:  make(map[string]map[string]float64),
+		dayTotals: make(map[string]float64),
+	}
+	if trackGroupDays {
+		acc.groupDays = make(map[string]map[string]float64)
+	}
+	return acc
+}
+
+// addDay adds a day to the trend, even if nothing is spent on it
+func (a *costAccumulator) addDay(day string) {
+	if _, ok := a.dayTotals[day]; !ok {
+		a.dayTotals[day] = 0
+	}
+}
+
+// add adds an amount for a group key, and its child key if not empty
+func (a *costAccumulator) add(day, key, child string, amount float64) {
+	a.addDay(day)
+	a.costs[key] += amount
+	if child != "" {
+		if a.children[key] == nil {
+			a.children[key] = make(map[string]float64)
+		}
+		a.children[key][child] += amount
+	}
+	if a.groupDays != nil {
+		if a.groupDays[key] == nil {
+			a.groupDays[key] = make(map[string]float64)
+		}
+		a.groupDays[key][day] += amount
+	}
+	a.dayTotals[day] += amount
+	a.total += amount
+}
+
+// results returns the items largest first, the daily trend and, if tracked,
+// the daily cost of each group key aligned with the trend
+func (a *costAccumulator) results(currency string) ([]CostItem, []DailyCost, map[string][]DailyCost) {
+	days := make([]string, 0, len(a.dayTotals))
+	for day := range a.dayTotals {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	dailyCosts := make([]DailyCost, 0, len(days))
+	for _, day := range days {
+		dailyCosts = append(dailyCosts, DailyCost{Date: day, Amount: a.dayTotals[day]})
+	}
+
+	// Groups missing on a day cost nothing that day
+	var dailyByGroup map[string][]DailyCost
+	if a.groupDays != nil {
+		dailyByGroup = make(map[string][]DailyCost, len(a.groupDays))
+		for key, amounts := range a.groupDays {
+			series := make([]DailyCost, 0, len(dailyCosts))
+			for _, day := range dailyCosts {
+				series = append(series, DailyCost{Date: day.Date, Amount: amounts[day.Date]})
+			}
+			dailyByGroup[key] = series
+		}
+	}
+
+	items := costItemsFromMap(a.costs, currency)
+	for i := range items {
+		if children, ok := a.children[items[i].Name]; ok {
+			items[i].Children = costItemsFromMap(children, currency)
+		}
+	}
+
+	return items, dailyCosts, dailyByGroup
+}
+
+// GroupByLabels returns the group-by keys, outermost first
+func (r *CostResults) GroupByLabels() []string {
+	if len(r.GroupByKeys) > 0 {
+		return r.GroupByKeys
+	}
+	return []string{r.GroupBy}
+}
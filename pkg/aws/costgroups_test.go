@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		name       string
+		groupBy    string
+		wantTypes  []cetypes.GroupDefinitionType
+		wantLabels string
+		wantErr    bool
+	}{
+		{
+			name:       "single dimension",
+			groupBy:    "service",
+			wantTypes:  []cetypes.GroupDefinitionType{cetypes.GroupDefinitionTypeDimension},
+			wantLabels: "SERVICE",
+		},
+		{
+			name:       "service within linked account",
+			groupBy:    "LINKED_ACCOUNT, SERVICE",
+			wantTypes:  []cetypes.GroupDefinitionType{cetypes.GroupDefinitionTypeDimension, cetypes.GroupDefinitionTypeDimension},
+			wantLabels: "LINKED_ACCOUNT,SERVICE",
+		},
+		{
+			name:       "tag keeps its case",
+			groupBy:    "tag:Team,SERVICE",
+			wantTypes:  []cetypes.GroupDefinitionType{cetypes.GroupDefinitionTypeTag, cetypes.GroupDefinitionTypeDimension},
+			wantLabels: "TAG:Team,SERVICE",
+		},
+		{
+			name:       "cost category",
+			groupBy:    "COST_CATEGORY:Business Unit",
+			wantTypes:  []cetypes.GroupDefinitionType{cetypes.GroupDefinitionTypeCostCategory},
+			wantLabels: "COST_CATEGORY:Business Unit",
+		},
+		{name: "three keys", groupBy: "SERVICE,REGION,LINKED_ACCOUNT", wantErr: true},
+		{name: "empty tag key", groupBy: "TAG:", wantErr: true},
+		{name: "empty", groupBy: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, labels, err := parseGroupBy(tt.groupBy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGroupBy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(definitions) != len(tt.wantTypes) {
+				t.Fatalf("parseGroupBy() returned %d definitions, want %d", len(definitions), len(tt.wantTypes))
+			}
+			for i, definition := range definitions {
+				if definition.Type != tt.wantTypes[i] {
+					t.Errorf("definitions[%d].Type = %s, want %s", i, definition.Type, tt.wantTypes[i])
+				}
+			}
+			if got := strings.Join(labels, ","); got != tt.wantLabels {
+				t.Errorf("labels = %s, want %s", got, tt.wantLabels)
+			}
+		})
+	}
+}
+
+func TestGroupKeyName(t *testing.T) {
+	tag := cetypes.GroupDefinition{Type: cetypes.GroupDefinitionTypeTag, Key: aws.String("team")}
+	category := cetypes.GroupDefinition{Type: cetypes.GroupDefinitionTypeCostCategory, Key: aws.String("unit")}
+	dimension := cetypes.GroupDefinition{Type: cetypes.GroupDefinitionTypeDimension, Key: aws.String("SERVICE")}
+
+	tests := []struct {
+		definition cetypes.GroupDefinition
+		key        string
+		want       string
+	}{
+		{tag, "team$platform", "platform"},
+		{tag, "team$", UntaggedBucket},
+		{category, "unit$payments", "payments"},
+		{category, "unit$", UncategorizedBucket},
+		{dimension, "Amazon Simple Storage Service", "Amazon Simple Storage Service"},
+	}
+
+	for _, tt := range tests {
+		if got := groupKeyName(tt.definition, tt.key); got != tt.want {
+			t.Errorf("groupKeyName(%s, %q) = %q, want %q", tt.definition.Type, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestCompareWithChildren(t *testing.T) {
+	current := &CostResults{
+		TotalCost: 300,
+		Items: []CostItem{{
+			Name:   "111111111111",
+			Amount: 300,
+			Children: []CostItem{
+				{Name: "EC2", Amount: 250},
+				{Name: "S3", Amount: 50},
+			},
+		}},
+	}
+	previous := &CostResults{
+		TotalCost: 260,
+		Items: []CostItem{{
+			Name:   "111111111111",
+			Amount: 260,
+			Children: []CostItem{
+				{Name: "EC2", Amount: 200},
+				{Name: "RDS", Amount: 60},
+			},
+		}},
+	}
+
+	current.CompareWith(previous)
+
+	// The removed RDS only shows in the account's change
+	children := current.Items[0].Children
+	if len(children) != 2 {
+		t.Fatalf("children = %+v, want EC2 and S3", children)
+	}
+	if children[0].Delta != 50 || children[1].Status != CostItemStatusNew || current.Items[0].Delta != 40 {
+		t.Errorf("children = %+v, parent delta = %.2f", children, current.Items[0].Delta)
+	}
+}
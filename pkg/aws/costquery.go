@@ -0,0 +1,235 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// Cost Explorer metrics
+const (
+	MetricUnblendedCost    = "UnblendedCost"
+	MetricAmortizedCost    = "AmortizedCost"
+	MetricNetUnblendedCost = "NetUnblendedCost"
+	MetricNetAmortizedCost = "NetAmortizedCost"
+	MetricBlendedCost      = "BlendedCost"
+	MetricUsageQuantity    = "UsageQuantity"
+)
+
+// CostMetrics maps metric flag values to Cost Explorer metrics
+var CostMetrics = map[string]string{
+	"unblended":     MetricUnblendedCost,
+	"amortized":     MetricAmortizedCost,
+	"net":           MetricNetUnblendedCost,
+	"net-unblended": MetricNetUnblendedCost,
+	"net-amortized": MetricNetAmortizedCost,
+	"blended":       MetricBlendedCost,
+	"usage":         MetricUsageQuantity,
+}
+
+// costFilterDimensions maps filter key aliases to Cost Explorer dimensions
+var costFilterDimensions = map[string]cetypes.Dimension{
+	"service":       cetypes.DimensionService,
+	"region":        cetypes.DimensionRegion,
+	"account":       cetypes.DimensionLinkedAccount,
+	"usage-type":    cetypes.DimensionUsageType,
+	"instance-type": cetypes.DimensionInstanceType,
+	"record-type":   cetypes.DimensionRecordType,
+}
+
+// usageTypeDimensions are the dimensions that keep usage quantities in a
+// single unit, such as hours of one instance type
+var usageTypeDimensions = map[string]bool{
+	string(cetypes.DimensionUsageType):      true,
+	string(cetypes.DimensionUsageTypeGroup): true,
+}
+
+// creditRecordTypes are the record types excluded by ExcludeCredits
+var creditRecordTypes = []string{"Credit", "Refund"}
+
+// CostQueryOptions changes the metric, granularity and filter of cost queries
+type CostQueryOptions struct {
+	// Metric is a key of CostMetrics, unblended by default
+	Metric string
+	// Granularity is daily (default) or monthly
+	Granularity string
+	// Filters are KEY=value[,value] or KEY!=value[,value] expressions, all of
+	// which must match. KEY is a dimension such as SERVICE or an alias like
+	// account, TAG:<key> or COST_CATEGORY:<name>.
+	Filters []string
+	// ExcludeCredits leaves out credits and refunds
+	ExcludeCredits bool
+}
+
+// SetQueryOptions sets the metric, granularity and filter used by GetCostAndUsage
+func (c *CostAnalyzer) SetQueryOptions(opts CostQueryOptions) error {
+	metric := MetricUnblendedCost
+	if opts.Metric != "" {
+		var ok bool
+		metric, ok = CostMetrics[strings.ToLower(opts.Metric)]
+		if !ok {
+			return fmt.Errorf("unsupported metric %q (use one of %s)", opts.Metric, strings.Join(costMetricNames(), ", "))
+		}
+	}
+
+	granularity := cetypes.GranularityDaily
+	switch strings.ToLower(opts.Granularity) {
+	case "", "daily":
+	case "monthly":
+		granularity = cetypes.GranularityMonthly
+	default:
+		return fmt.Errorf("unsupported granularity %q (use daily or monthly)", opts.Granularity)
+	}
+
+	filter, err := parseCostFilter(opts.Filters, opts.ExcludeCredits)
+	if err != nil {
+		return err
+	}
+
+	c.metric = metric
+	c.granularity = granularity
+	c.filter = filter
+	c.usageTypeFiltered = filtersUsageType(opts.Filters)
+	return nil
+}
+
+// filtersUsageType reports whether the filters select usage types, which
+// keeps usage quantities in a single unit. Excluding usage types does not.
+func filtersUsageType(filters []string) bool {
+	for _, filter := range filters {
+		expression, err := parseFilterExpression(filter)
+		if err == nil && expression.Dimensions != nil && usageTypeDimensions[string(expression.Dimensions.Key)] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUsageScope rejects usage quantity queries that would add up different
+// units, such as hours and GB, unless they are filtered or grouped by usage type
+func checkUsageScope(metric string, usageTypeFiltered bool, groupByKeys []string) error {
+	if metric != MetricUsageQuantity || usageTypeFiltered {
+		return nil
+	}
+	for _, key := range groupByKeys {
+		if usageTypeDimensions[key] {
+			return nil
+		}
+	}
+	return fmt.Errorf("the usage metric would add up quantities of different units (hours, GB, requests): filter with usage-type=<type> or group by USAGE_TYPE")
+}
+
+// parseCostFilter combines filter expressions into a Cost Explorer expression,
+// or returns nil if there is nothing to filter on
+func parseCostFilter(filters []string, excludeCredits bool) (*cetypes.Expression, error) {
+	expressions := make([]cetypes.Expression, 0, len(filters)+1)
+	for _, filter := range filters {
+		expression, err := parseFilterExpression(filter)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expression)
+	}
+
+	if excludeCredits {
+		expressions = append(expressions, excludeRecordTypes(creditRecordTypes))
+	}
+
+	// Cost Explorer rejects an And with a single expression
+	switch len(expressions) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &expressions[0], nil
+	default:
+		return &cetypes.Expression{And: expressions}, nil
+	}
+}
+
+// excludeRecordTypes returns an expression leaving out charges of the given
+// record types, such as credits and refunds
+func excludeRecordTypes(recordTypes []string) cetypes.Expression {
+	return cetypes.Expression{
+		Not: &cetypes.Expression{
+			Dimensions: &cetypes.DimensionValues{
+				Key:    cetypes.DimensionRecordType,
+				Values: recordTypes,
+			},
+		},
+	}
+}
+
+// parseFilterExpression parses KEY=value[,value] or KEY!=value[,value]
+func parseFilterExpression(filter string) (cetypes.Expression, error) {
+	key, values, ok := strings.Cut(filter, "=")
+	if !ok {
+		return cetypes.Expression{}, fmt.Errorf("invalid filter %q (use KEY=value[,value] or KEY!=value[,value])", filter)
+	}
+
+	negate := strings.HasSuffix(key, "!")
+	key = strings.TrimSpace(strings.TrimSuffix(key, "!"))
+
+	valueList := make([]string, 0)
+	for _, value := range strings.Split(values, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			valueList = append(valueList, value)
+		}
+	}
+	if key == "" || len(valueList) == 0 {
+		return cetypes.Expression{}, fmt.Errorf("invalid filter %q: key and at least one value are required", filter)
+	}
+
+	var expression cetypes.Expression
+	upper := strings.ToUpper(key)
+	switch {
+	case strings.HasPrefix(upper, GroupByTagPrefix):
+		expression.Tags = &cetypes.TagValues{
+			Key:    aws.String(key[len(GroupByTagPrefix):]),
+			Values: valueList,
+		}
+	case strings.HasPrefix(upper, GroupByCostCategoryPrefix):
+		expression.CostCategories = &cetypes.CostCategoryValues{
+			Key:    aws.String(key[len(GroupByCostCategoryPrefix):]),
+			Values: valueList,
+		}
+	default:
+		dimension, err := filterDimension(key)
+		if err != nil {
+			return cetypes.Expression{}, err
+		}
+		expression.Dimensions = &cetypes.DimensionValues{
+			Key:    dimension,
+			Values: valueList,
+		}
+	}
+
+	if negate {
+		return cetypes.Expression{Not: &expression}, nil
+	}
+	return expression, nil
+}
+
+// filterDimension resolves a filter key alias or dimension name
+func filterDimension(key string) (cetypes.Dimension, error) {
+	if dimension, ok := costFilterDimensions[strings.ToLower(key)]; ok {
+		return dimension, nil
+	}
+	for _, dimension := range cetypes.Dimension("").Values() {
+		if string(dimension) == strings.ToUpper(key) {
+			return dimension, nil
+		}
+	}
+	return "", fmt.Errorf("unknown filter key %q", key)
+}
+
+func costMetricNames() []string {
+	names := make([]string, 0, len(CostMetrics))
+	for name := range CostMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
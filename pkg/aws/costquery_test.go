@@ -0,0 +1,172 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	tests := []struct {
+		name          string
+		filter        string
+		wantNot       bool
+		wantDimension cetypes.Dimension
+		wantTag       string
+		wantCategory  string
+		wantValues    int
+		wantErr       bool
+	}{
+		{name: "alias", filter: "region=us-east-1", wantDimension: cetypes.DimensionRegion, wantValues: 1},
+		{name: "account alias", filter: "account=111111111111, 222222222222", wantDimension: cetypes.DimensionLinkedAccount, wantValues: 2},
+		{name: "dimension name", filter: "PURCHASE_TYPE=Spot", wantDimension: cetypes.DimensionPurchaseType, wantValues: 1},
+		{name: "negated", filter: "service!=Tax", wantNot: true, wantDimension: cetypes.DimensionService, wantValues: 1},
+		{name: "tag", filter: "TAG:env=prod,staging", wantTag: "env", wantValues: 2},
+		{name: "cost category", filter: "cost_category:Business Unit=payments", wantCategory: "Business Unit", wantValues: 1},
+		{name: "no operator", filter: "region", wantErr: true},
+		{name: "no values", filter: "region= , ", wantErr: true},
+		{name: "unknown key", filter: "colour=blue", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := parseFilterExpression(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFilterExpression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if tt.wantNot {
+				if expression.Not == nil {
+					t.Fatalf("expression = %+v, want Not", expression)
+				}
+				expression = *expression.Not
+			}
+
+			var values []string
+			switch {
+			case tt.wantDimension != "":
+				if expression.Dimensions == nil || expression.Dimensions.Key != tt.wantDimension {
+					t.Fatalf("Dimensions = %+v, want key %s", expression.Dimensions, tt.wantDimension)
+				}
+				values = expression.Dimensions.Values
+			case tt.wantTag != "":
+				if expression.Tags == nil || aws.ToString(expression.Tags.Key) != tt.wantTag {
+					t.Fatalf("Tags = %+v, want key %s", expression.Tags, tt.wantTag)
+				}
+				values = expression.Tags.Values
+			case tt.wantCategory != "":
+				if expression.CostCategories == nil || aws.ToString(expression.CostCategories.Key) != tt.wantCategory {
+					t.Fatalf("CostCategories = %+v, want key %s", expression.CostCategories, tt.wantCategory)
+				}
+				values = expression.CostCategories.Values
+			}
+			if len(values) != tt.wantValues {
+				t.Errorf("values = %v, want %d values", values, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestParseCostFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		filters        []string
+		excludeCredits bool
+		wantNil        bool
+		wantAnd        int
+		wantErr        bool
+	}{
+		{name: "no filters", wantNil: true},
+		{name: "single filter is not wrapped", filters: []string{"region=us-east-1"}},
+		{name: "exclude credits only", excludeCredits: true},
+		{name: "filters and credits", filters: []string{"region=us-east-1", "TAG:env=prod"}, excludeCredits: true, wantAnd: 3},
+		{name: "invalid filter", filters: []string{"region=us-east-1", "bogus"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := parseCostFilter(tt.filters, tt.excludeCredits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCostFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (filter == nil) != tt.wantNil {
+				t.Fatalf("parseCostFilter() = %+v, wantNil %v", filter, tt.wantNil)
+			}
+			if filter != nil && len(filter.And) != tt.wantAnd {
+				t.Errorf("len(And) = %d, want %d", len(filter.And), tt.wantAnd)
+			}
+		})
+	}
+
+	filter, _ := parseCostFilter(nil, true)
+	if filter.Not == nil || filter.Not.Dimensions.Key != cetypes.DimensionRecordType {
+		t.Errorf("exclude credits = %+v, want Not RECORD_TYPE", filter)
+	}
+}
+
+func TestSetQueryOptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            CostQueryOptions
+		wantMetric      string
+		wantGranularity cetypes.Granularity
+		wantErr         bool
+	}{
+		{name: "defaults", wantMetric: MetricUnblendedCost, wantGranularity: cetypes.GranularityDaily},
+		{name: "amortized monthly", opts: CostQueryOptions{Metric: "Amortized", Granularity: "monthly"}, wantMetric: MetricAmortizedCost, wantGranularity: cetypes.GranularityMonthly},
+		{name: "net", opts: CostQueryOptions{Metric: "net"}, wantMetric: MetricNetUnblendedCost, wantGranularity: cetypes.GranularityDaily},
+		{name: "usage", opts: CostQueryOptions{Metric: "usage"}, wantMetric: MetricUsageQuantity, wantGranularity: cetypes.GranularityDaily},
+		{name: "unknown metric", opts: CostQueryOptions{Metric: "list"}, wantErr: true},
+		{name: "hourly", opts: CostQueryOptions{Granularity: "hourly"}, wantErr: true},
+		{name: "bad filter", opts: CostQueryOptions{Filters: []string{"nope"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := &CostAnalyzer{}
+			err := analyzer.SetQueryOptions(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetQueryOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if analyzer.metric != tt.wantMetric || analyzer.granularity != tt.wantGranularity {
+				t.Errorf("metric = %s, granularity = %s; want %s, %s", analyzer.metric, analyzer.granularity, tt.wantMetric, tt.wantGranularity)
+			}
+		})
+	}
+}
+
+func TestCheckUsageScope(t *testing.T) {
+	tests := []struct {
+		name        string
+		metric      string
+		filters     []string
+		groupByKeys []string
+		wantErr     bool
+	}{
+		{name: "cost metric", metric: MetricUnblendedCost, groupByKeys: []string{"SERVICE"}},
+		{name: "usage by service", metric: MetricUsageQuantity, groupByKeys: []string{"SERVICE"}, wantErr: true},
+		{name: "usage excluding a usage type", metric: MetricUsageQuantity, filters: []string{"usage-type!=DataTransfer-Out-Bytes"}, groupByKeys: []string{"SERVICE"}, wantErr: true},
+		{name: "usage filtered by usage type", metric: MetricUsageQuantity, filters: []string{"usage-type=BoxUsage:t3.micro"}, groupByKeys: []string{"SERVICE"}},
+		{name: "usage filtered by usage type group", metric: MetricUsageQuantity, filters: []string{"USAGE_TYPE_GROUP=EC2: Running Hours"}, groupByKeys: []string{"REGION"}},
+		{name: "usage grouped by usage type", metric: MetricUsageQuantity, groupByKeys: []string{"SERVICE", "USAGE_TYPE"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkUsageScope(tt.metric, filtersUsageType(tt.filters), tt.groupByKeys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkUsageScope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,598 @@
+package aws
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+)
+
+// CUR columns, normalized by curColumnName. Legacy CSV exports name columns
+// like "lineItem/UnblendedCost" and Parquet and CUR 2.0 exports like
+// "line_item_unblended_cost", which both normalize to "lineitemunblendedcost".
+const (
+	curUsageStartDate             = "lineitemusagestartdate"
+	curProductName                = "productproductname"
+	curProductCode                = "lineitemproductcode"
+	curUsageAccountID             = "lineitemusageaccountid"
+	curRegionCode                 = "productregioncode"
+	curRegion                     = "productregion"
+	curUsageType                  = "lineitemusagetype"
+	curResourceID                 = "lineitemresourceid"
+	curInstanceType               = "productinstancetype"
+	curOperation                  = "lineitemoperation"
+	curLineItemType               = "lineitemlineitemtype"
+	curCurrencyCode               = "lineitemcurrencycode"
+	curUsageAmount                = "lineitemusageamount"
+	curUnblendedCost              = "lineitemunblendedcost"
+	curBlendedCost                = "lineitemblendedcost"
+	curNetUnblendedCost           = "lineitemnetunblendedcost"
+	curSavingsPlanEffectiveCost   = "savingsplansavingsplaneffectivecost"
+	curSavingsPlanTotalCommitment = "savingsplantotalcommitmenttodate"
+	curSavingsPlanUsedCommitment  = "savingsplanusedcommitment"
+	curReservationEffectiveCost   = "reservationeffectivecost"
+	curReservationUnusedUpfront   = "reservationunusedamortizedupfrontfeeforbillingperiod"
+	curReservationUnusedRecurring = "reservationunusedrecurringfee"
+	curReservationARN             = "reservationreservationarn"
+	// Tags and cost categories are one column per key in legacy exports, and
+	// a map column (JSON in CSV) in CUR 2.0
+	curResourceTags = "resourcetags"
+	curCostCategory = "costcategory"
+)
+
+// NoValueBucket holds CUR spend without a value for a dimension, such as
+// line items without a resource ID
+const NoValueBucket = "none"
+
+// curDimensions maps group-by dimensions to CUR columns, first non-empty wins
+var curDimensions = map[string][]string{
+	"SERVICE":        {curProductName, curProductCode},
+	"LINKED_ACCOUNT": {curUsageAccountID},
+	"REGION":         {curRegionCode, curRegion},
+	"USAGE_TYPE":     {curUsageType},
+	"RESOURCE_ID":    {curResourceID},
+	"INSTANCE_TYPE":  {curInstanceType},
+	"OPERATION":      {curOperation},
+	"RECORD_TYPE":    {curLineItemType},
+}
+
+// curMetricColumns maps metrics to their CUR cost column. Amortized cost is
+// derived from several columns, see curSchema.amortizedCost.
+var curMetricColumns = map[string]string{
+	MetricUnblendedCost:    curUnblendedCost,
+	MetricBlendedCost:      curBlendedCost,
+	MetricNetUnblendedCost: curNetUnblendedCost,
+	MetricUsageQuantity:    curUsageAmount,
+	MetricAmortizedCost:    curUnblendedCost,
+}
+
+// curRowBatch is the number of Parquet rows read at a time
+const curRowBatch = 512
+
+// CUROptions selects the grouping and metric of a CUR analysis
+type CUROptions struct {
+	// GroupBy is up to two comma-separated keys: SERVICE, LINKED_ACCOUNT,
+	// REGION, USAGE_TYPE, RESOURCE_ID, INSTANCE_TYPE, OPERATION, RECORD_TYPE,
+	// TAG:<key> or COST_CATEGORY:<name>
+	GroupBy string
+	// Metric is a key of CostMetrics, unblended by default. Net amortized
+	// cost is not supported.
+	Metric string
+}
+
+// AnalyzeCURFiles reads Cost and Usage Report exports (CSV, gzipped CSV or
+// Parquet) and sums their line items into cost results, like GetCostAndUsage.
+// Files are streamed, so they can be larger than memory.
+func AnalyzeCURFiles(paths []string, opts CUROptions) (*CostResults, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("at least one CUR file is required")
+	}
+
+	groupDefinitions, groupByKeys, err := parseGroupBy(opts.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	for i, definition := range groupDefinitions {
+		if definition.Type != cetypes.GroupDefinitionTypeDimension {
+			continue
+		}
+		if _, ok := curDimensions[groupByKeys[i]]; !ok {
+			return nil, fmt.Errorf("group-by %s is not available in CUR files (use %s, TAG:<key> or COST_CATEGORY:<name>)",
+				groupByKeys[i], strings.Join(curDimensionNames(), ", "))
+		}
+	}
+
+	metric := MetricUnblendedCost
+	if opts.Metric != "" {
+		metric = CostMetrics[strings.ToLower(opts.Metric)]
+		if _, ok := curMetricColumns[metric]; !ok {
+			return nil, fmt.Errorf("unsupported metric %q for CUR files", opts.Metric)
+		}
+	}
+	if err := checkUsageScope(metric, false, groupByKeys); err != nil {
+		return nil, err
+	}
+
+	acc := newCostAccumulator(false)
+	currency := ""
+	var first, last string
+
+	for _, path := range paths {
+		var schema *curSchema
+		err := readCURFile(path, func(header []string) error {
+			var err error
+			schema, err = newCURSchema(header, groupByKeys, metric)
+			return err
+		}, func(row []string) error {
+			day, err := schema.day(row)
+			if err != nil {
+				return err
+			}
+			amount, err := schema.amount(row)
+			if err != nil {
+				return err
+			}
+
+			child := ""
+			if len(schema.groups) > 1 {
+				child = schema.groups[1](row)
+			}
+			acc.add(day, schema.groups[0](row), child, amount)
+
+			if currency == "" {
+				currency = schema.value(row, curCurrencyCode)
+			}
+			if first == "" || day < first {
+				first = day
+			}
+			if day > last {
+				last = day
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CUR file %s: %w", path, err)
+		}
+	}
+
+	if currency == "" {
+		currency = "USD"
+	}
+	// Usage quantities are not in a currency, and CUR files don't give a
+	// single unit for them
+	if metric == MetricUsageQuantity {
+		currency = ""
+	}
+	items, dailyCosts, _ := acc.results(currency)
+
+	results := &CostResults{
+		TotalCost:   acc.total,
+		Currency:    currency,
+		Metric:      metric,
+		Granularity: string(cetypes.GranularityDaily),
+		GroupBy:     strings.Join(groupByKeys, ","),
+		GroupByKeys: groupByKeys,
+		Items:       items,
+		DailyTrend:  dailyCosts,
+	}
+	// The end date is exclusive, as in Cost Explorer
+	if first != "" {
+		results.StartDate, _ = time.Parse(costDateLayout, first)
+		end, _ := time.Parse(costDateLayout, last)
+		results.EndDate = end.AddDate(0, 0, 1)
+	}
+	return results, nil
+}
+
+// curSchema resolves the columns of one CUR file
+type curSchema struct {
+	columns map[string]int
+	metric  string
+	// groups return the group key of a row, one per group-by key
+	groups []func(row []string) string
+}
+
+func newCURSchema(header []string, groupByKeys []string, metric string) (*curSchema, error) {
+	s := &curSchema{
+		columns: make(map[string]int, len(header)),
+		metric:  metric,
+	}
+	for i, name := range header {
+		if _, ok := s.columns[curColumnName(name)]; !ok {
+			s.columns[curColumnName(name)] = i
+		}
+	}
+
+	for _, column := range []string{curUsageStartDate, curMetricColumns[metric]} {
+		if _, ok := s.columns[column]; !ok {
+			return nil, fmt.Errorf("missing column %s, is this a Cost and Usage Report?", column)
+		}
+	}
+
+	for _, key := range groupByKeys {
+		s.groups = append(s.groups, s.groupKey(key))
+	}
+	return s, nil
+}
+
+// groupKey returns the function that reads a group-by key from a row
+func (s *curSchema) groupKey(key string) func(row []string) string {
+	switch {
+	case strings.HasPrefix(key, GroupByTagPrefix):
+		name := curColumnName(key[len(GroupByTagPrefix):])
+		// User-defined tags are prefixed with "user:" in CUR
+		return s.mapKey(curResourceTags, []string{"user" + name, name}, UntaggedBucket)
+	case strings.HasPrefix(key, GroupByCostCategoryPrefix):
+		name := curColumnName(key[len(GroupByCostCategoryPrefix):])
+		return s.mapKey(curCostCategory, []string{name}, UncategorizedBucket)
+	default:
+		columns := curDimensions[key]
+		return func(row []string) string {
+			for _, column := range columns {
+				if value := s.value(row, column); value != "" {
+					return value
+				}
+			}
+			return NoValueBucket
+		}
+	}
+}
+
+// mapKey reads a tag or cost category, either from a column per key such as
+// "resourceTags/user:team" or from the keys of a map column
+func (s *curSchema) mapKey(prefix string, names []string, missing string) func(row []string) string {
+	for _, name := range names {
+		if i, ok := s.columns[prefix+name]; ok {
+			return func(row []string) string {
+				if i < len(row) && row[i] != "" {
+					return row[i]
+				}
+				return missing
+			}
+		}
+	}
+
+	return func(row []string) string {
+		raw := s.value(row, prefix)
+		if raw == "" || raw == "{}" {
+			return missing
+		}
+		values := make(map[string]string)
+		if err := json.Unmarshal([]byte(raw), &values); err != nil {
+			return missing
+		}
+		for key, value := range values {
+			for _, name := range names {
+				if value != "" && curColumnName(key) == name {
+					return value
+				}
+			}
+		}
+		return missing
+	}
+}
+
+// value returns a column of a row, or "" if the file does not have it
+func (s *curSchema) value(row []string, column string) string {
+	i, ok := s.columns[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+// number parses a numeric column, treating a missing value as zero
+func (s *curSchema) number(row []string, column string) (float64, error) {
+	value := s.value(row, column)
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", column, value)
+	}
+	return f, nil
+}
+
+// day returns the usage start day of a row
+func (s *curSchema) day(row []string) (string, error) {
+	value := s.value(row, curUsageStartDate)
+	if len(value) < len(costDateLayout) {
+		return "", fmt.Errorf("invalid usage start date %q", value)
+	}
+	day := value[:len(costDateLayout)]
+	if _, err := time.Parse(costDateLayout, day); err != nil {
+		return "", fmt.Errorf("invalid usage start date %q", value)
+	}
+	return day, nil
+}
+
+// amount returns the cost, or usage quantity, of a row in the schema's metric
+func (s *curSchema) amount(row []string) (float64, error) {
+	if s.metric == MetricAmortizedCost {
+		return s.amortizedCost(row)
+	}
+	return s.number(row, curMetricColumns[s.metric])
+}
+
+// amortizedCost spreads Savings Plans and reservation fees over the usage they
+// cover, like the AmortizedCost metric of Cost Explorer
+func (s *curSchema) amortizedCost(row []string) (float64, error) {
+	switch s.value(row, curLineItemType) {
+	case "SavingsPlanCoveredUsage":
+		return s.number(row, curSavingsPlanEffectiveCost)
+	case "SavingsPlanRecurringFee":
+		total, err := s.number(row, curSavingsPlanTotalCommitment)
+		if err != nil {
+			return 0, err
+		}
+		used, err := s.number(row, curSavingsPlanUsedCommitment)
+		if err != nil {
+			return 0, err
+		}
+		return total - used, nil
+	case "SavingsPlanNegation", "SavingsPlanUpfrontFee":
+		return 0, nil
+	case "DiscountedUsage":
+		return s.number(row, curReservationEffectiveCost)
+	case "RIFee":
+		upfront, err := s.number(row, curReservationUnusedUpfront)
+		if err != nil {
+			return 0, err
+		}
+		recurring, err := s.number(row, curReservationUnusedRecurring)
+		if err != nil {
+			return 0, err
+		}
+		return upfront + recurring, nil
+	case "Fee":
+		// Upfront reservation fees are amortized through DiscountedUsage
+		if s.value(row, curReservationARN) != "" {
+			return 0, nil
+		}
+	}
+	return s.number(row, curUnblendedCost)
+}
+
+// readCURFile streams a CSV, gzipped CSV or Parquet file, calling header once
+// with the column names and row for each line item. The row slice is reused.
+func readCURFile(path string, header func([]string) error, row func([]string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buffered := bufio.NewReaderSize(f, 1<<20)
+	magic, _ := buffered.Peek(4)
+	switch {
+	case bytes.Equal(magic, []byte("PAR1")):
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return readParquetCUR(f, info.Size(), header, row)
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return readCSVCUR(gz, header, row)
+	default:
+		return readCSVCUR(buffered, header, row)
+	}
+}
+
+func readCSVCUR(r io.Reader, header func([]string) error, row func([]string) error) error {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	reader.FieldsPerRecord = -1
+
+	names, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("file is empty")
+		}
+		return err
+	}
+	if err := header(names); err != nil {
+		return err
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := row(record); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+// parquetColumn is where a Parquet leaf column goes in a row
+type parquetColumn struct {
+	index int
+	// mapKey and mapValue are set for the keys and values of a map column
+	mapKey    bool
+	mapValue  bool
+	timestamp time.Duration
+}
+
+func readParquetCUR(r io.ReaderAt, size int64, header func([]string) error, row func([]string) error) error {
+	file, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return err
+	}
+
+	// Top-level fields become columns; map fields such as resource_tags are
+	// converted to JSON, as in CUR 2.0 CSV exports
+	schema := file.Schema()
+	names := make([]string, 0)
+	positions := make(map[string]int)
+	leaves := make([]parquetColumn, 0)
+	for _, path := range schema.Columns() {
+		column := parquetColumn{index: -1}
+		last := path[len(path)-1]
+		if len(path) == 1 || last == "key" || last == "value" {
+			position, ok := positions[path[0]]
+			if !ok {
+				position = len(names)
+				positions[path[0]] = position
+				names = append(names, path[0])
+			}
+			column.index = position
+			column.mapKey = len(path) > 1 && last == "key"
+			column.mapValue = len(path) > 1 && last == "value"
+		}
+		if leaf, ok := schema.Lookup(path...); ok {
+			column.timestamp = parquetTimestampUnit(leaf.Node.Type())
+		}
+		leaves = append(leaves, column)
+	}
+	if err := header(names); err != nil {
+		return err
+	}
+
+	values := make([]string, len(names))
+	mapKeys := make([][]string, len(names))
+	mapValues := make([][]string, len(names))
+	rows := make([]parquet.Row, curRowBatch)
+	number := 0
+
+	for _, rowGroup := range file.RowGroups() {
+		reader := rowGroup.Rows()
+		for {
+			n, readErr := reader.ReadRows(rows)
+			for _, parquetRow := range rows[:n] {
+				number++
+				for i := range values {
+					values[i] = ""
+					mapKeys[i] = mapKeys[i][:0]
+					mapValues[i] = mapValues[i][:0]
+				}
+				for _, value := range parquetRow {
+					if value.Column() >= len(leaves) || value.IsNull() {
+						continue
+					}
+					column := leaves[value.Column()]
+					switch {
+					case column.index < 0:
+					case column.mapKey:
+						mapKeys[column.index] = append(mapKeys[column.index], parquetString(value, column.timestamp))
+					case column.mapValue:
+						mapValues[column.index] = append(mapValues[column.index], parquetString(value, column.timestamp))
+					default:
+						values[column.index] = parquetString(value, column.timestamp)
+					}
+				}
+				for i, keys := range mapKeys {
+					if len(keys) == 0 || len(keys) != len(mapValues[i]) {
+						continue
+					}
+					entries := make(map[string]string, len(keys))
+					for j, key := range keys {
+						entries[key] = mapValues[i][j]
+					}
+					encoded, err := json.Marshal(entries)
+					if err != nil {
+						reader.Close()
+						return err
+					}
+					values[i] = string(encoded)
+				}
+
+				if err := row(values); err != nil {
+					reader.Close()
+					return fmt.Errorf("row %d: %w", number, err)
+				}
+			}
+			if readErr == io.EOF {
+				break
+			}
+			if readErr != nil {
+				reader.Close()
+				return readErr
+			}
+		}
+		reader.Close()
+	}
+	return nil
+}
+
+// parquetTimestampUnit returns the unit of a timestamp column, or 0 if the
+// column is not a timestamp
+func parquetTimestampUnit(t parquet.Type) time.Duration {
+	if logical := t.LogicalType(); logical != nil && logical.Timestamp != nil {
+		switch unit := logical.Timestamp.Unit; {
+		case unit.Millis != nil:
+			return time.Millisecond
+		case unit.Micros != nil:
+			return time.Microsecond
+		case unit.Nanos != nil:
+			return time.Nanosecond
+		}
+	}
+	if converted := t.ConvertedType(); converted != nil {
+		switch *converted {
+		case deprecated.TimestampMillis:
+			return time.Millisecond
+		case deprecated.TimestampMicros:
+			return time.Microsecond
+		}
+	}
+	return 0
+}
+
+// parquetString formats a Parquet value like the CSV export would
+func parquetString(value parquet.Value, timestamp time.Duration) string {
+	switch value.Kind() {
+	case parquet.Double:
+		return strconv.FormatFloat(value.Double(), 'f', -1, 64)
+	case parquet.Float:
+		return strconv.FormatFloat(float64(value.Float()), 'f', -1, 32)
+	case parquet.Int64:
+		if timestamp > 0 {
+			return time.Unix(0, value.Int64()*int64(timestamp)).UTC().Format(time.RFC3339)
+		}
+	}
+	return value.String()
+}
+
+// curColumnName normalizes a CUR column name or key: lower case letters and
+// digits only
+func curColumnName(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func curDimensionNames() []string {
+	names := make([]string, 0, len(curDimensions))
+	for name := range curDimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
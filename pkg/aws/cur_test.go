@@ -0,0 +1,209 @@
+package aws
+
+import (
+	"compress/gzip"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const legacyCURCSV = `identity/LineItemId,lineItem/UsageStartDate,lineItem/UsageAccountId,lineItem/LineItemType,lineItem/ProductCode,product/ProductName,product/region,lineItem/ResourceId,lineItem/UsageType,lineItem/UnblendedCost,lineItem/CurrencyCode,savingsPlan/SavingsPlanEffectiveCost,resourceTags/user:team
+1,2024-11-01T00:00:00Z,111111111111,Usage,AmazonEC2,Amazon Elastic Compute Cloud,us-east-1,i-0aaa,BoxUsage:m5.large,10.5,USD,,platform
+2,2024-11-01T01:00:00Z,111111111111,SavingsPlanCoveredUsage,AmazonEC2,Amazon Elastic Compute Cloud,us-east-1,i-0bbb,BoxUsage:m5.large,20,USD,12,payments
+3,2024-11-02T00:00:00Z,222222222222,Usage,AmazonS3,Amazon Simple Storage Service,us-west-2,my-bucket,TimedStorage-ByteHrs,4.25,USD,,
+4,2024-11-02T00:00:00Z,111111111111,SavingsPlanNegation,AmazonEC2,Amazon Elastic Compute Cloud,us-east-1,i-0bbb,BoxUsage:m5.large,-20,USD,,payments
+5,2024-11-03T00:00:00Z,222222222222,Tax,AWSSupportBusiness,,,,,1.75,USD,,
+`
+
+func writeCURFile(t *testing.T, name, content string, gzipped bool) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if !gzipped {
+		if _, err := f.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAnalyzeCURFiles(t *testing.T) {
+	csvPath := writeCURFile(t, "cur.csv", legacyCURCSV, false)
+	gzPath := writeCURFile(t, "cur.csv.gz", legacyCURCSV, true)
+
+	tests := []struct {
+		name      string
+		paths     []string
+		opts      CUROptions
+		wantTotal float64
+		wantItems string
+		wantErr   bool
+	}{
+		{
+			name:      "by service",
+			paths:     []string{csvPath},
+			opts:      CUROptions{GroupBy: "SERVICE"},
+			wantTotal: 16.5,
+			wantItems: "Amazon Elastic Compute Cloud=10.50,Amazon Simple Storage Service=4.25,AWSSupportBusiness=1.75",
+		},
+		{
+			name:      "gzipped by resource ID",
+			paths:     []string{gzPath},
+			opts:      CUROptions{GroupBy: "RESOURCE_ID"},
+			wantTotal: 16.5,
+			wantItems: "i-0aaa=10.50,my-bucket=4.25,none=1.75,i-0bbb=0.00",
+		},
+		{
+			name:      "amortized by tag",
+			paths:     []string{csvPath},
+			opts:      CUROptions{GroupBy: "TAG:team", Metric: "amortized"},
+			wantTotal: 28.5,
+			wantItems: "payments=12.00,platform=10.50,untagged=6.00",
+		},
+		{
+			name:      "two files",
+			paths:     []string{csvPath, gzPath},
+			opts:      CUROptions{GroupBy: "LINKED_ACCOUNT"},
+			wantTotal: 33,
+			wantItems: "111111111111=21.00,222222222222=12.00",
+		},
+		{name: "dimension not in CUR", paths: []string{csvPath}, opts: CUROptions{GroupBy: "AZ"}, wantErr: true},
+		{name: "metric not in file", paths: []string{csvPath}, opts: CUROptions{GroupBy: "SERVICE", Metric: "blended"}, wantErr: true},
+		{name: "net amortized", paths: []string{csvPath}, opts: CUROptions{GroupBy: "SERVICE", Metric: "net-amortized"}, wantErr: true},
+		{name: "missing file", paths: []string{filepath.Join(t.TempDir(), "missing.csv")}, opts: CUROptions{GroupBy: "SERVICE"}, wantErr: true},
+		{name: "no files", opts: CUROptions{GroupBy: "SERVICE"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := AnalyzeCURFiles(tt.paths, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AnalyzeCURFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if results.TotalCost != tt.wantTotal {
+				t.Errorf("TotalCost = %.2f, want %.2f", results.TotalCost, tt.wantTotal)
+			}
+			if got := curItems(results.Items); got != tt.wantItems {
+				t.Errorf("Items = %s, want %s", got, tt.wantItems)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCURFilesPeriod(t *testing.T) {
+	results, err := AnalyzeCURFiles([]string{writeCURFile(t, "cur.csv", legacyCURCSV, false)}, CUROptions{GroupBy: "REGION,SERVICE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := results.StartDate.Format(costDateLayout); got != "2024-11-01" {
+		t.Errorf("StartDate = %s, want 2024-11-01", got)
+	}
+	if got := results.EndDate.Format(costDateLayout); got != "2024-11-04" {
+		t.Errorf("EndDate = %s, want 2024-11-04", got)
+	}
+	if len(results.DailyTrend) != 3 || results.DailyTrend[0].Amount != 30.5 {
+		t.Errorf("DailyTrend = %+v, want 3 days starting with 30.50", results.DailyTrend)
+	}
+	if results.Items[0].Name != "us-east-1" || len(results.Items[0].Children) != 1 {
+		t.Errorf("Items[0] = %+v, want us-east-1 broken down by service", results.Items[0])
+	}
+}
+
+func TestAnalyzeCURFilesInvalidRow(t *testing.T) {
+	content := "lineItem/UsageStartDate,lineItem/UnblendedCost,lineItem/ProductCode\n2024-11-01T00:00:00Z,1,AmazonEC2\n2024-11-01T00:00:00Z,abc,AmazonEC2\n"
+	_, err := AnalyzeCURFiles([]string{writeCURFile(t, "cur.csv", content, false)}, CUROptions{GroupBy: "SERVICE"})
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("AnalyzeCURFiles() error = %v, want an error for line 3", err)
+	}
+}
+
+// curParquetRow is a CUR 2.0 line item with map columns
+type curParquetRow struct {
+	UsageStartDate time.Time         `parquet:"line_item_usage_start_date,timestamp(millisecond)"`
+	AccountID      string            `parquet:"line_item_usage_account_id"`
+	ProductCode    string            `parquet:"line_item_product_code"`
+	RegionCode     string            `parquet:"product_region_code"`
+	UnblendedCost  float64           `parquet:"line_item_unblended_cost"`
+	ResourceTags   map[string]string `parquet:"resource_tags"`
+	CostCategory   map[string]string `parquet:"cost_category"`
+}
+
+func TestAnalyzeCURFilesParquet(t *testing.T) {
+	day := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	rows := []curParquetRow{
+		{UsageStartDate: day, AccountID: "111111111111", ProductCode: "AmazonEC2", RegionCode: "us-east-1", UnblendedCost: 0.1234567891, ResourceTags: map[string]string{"user_team": "platform"}, CostCategory: map[string]string{"Business Unit": "core"}},
+		{UsageStartDate: day.Add(time.Hour), AccountID: "111111111111", ProductCode: "AmazonRDS", RegionCode: "us-east-1", UnblendedCost: 5, ResourceTags: map[string]string{}},
+		{UsageStartDate: day.AddDate(0, 0, 1), AccountID: "222222222222", ProductCode: "AmazonEC2", RegionCode: "eu-west-1", UnblendedCost: 3, ResourceTags: map[string]string{"user_team": "platform", "user_env": "prod"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "cur.parquet")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parquet.Write(f, rows); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tests := []struct {
+		groupBy   string
+		wantItems string
+	}{
+		{groupBy: "SERVICE", wantItems: "AmazonRDS=5.00,AmazonEC2=3.12"},
+		{groupBy: "TAG:team", wantItems: "untagged=5.00,platform=3.12"},
+		{groupBy: "COST_CATEGORY:Business Unit", wantItems: "uncategorized=8.00,core=0.12"},
+		{groupBy: "REGION", wantItems: "us-east-1=5.12,eu-west-1=3.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			results, err := AnalyzeCURFiles([]string{path}, CUROptions{GroupBy: tt.groupBy})
+			if err != nil {
+				t.Fatalf("AnalyzeCURFiles() error = %v", err)
+			}
+			if got := curItems(results.Items); got != tt.wantItems {
+				t.Errorf("Items = %s, want %s", got, tt.wantItems)
+			}
+			// Doubles must not be read with float32 precision
+			if math.Abs(results.TotalCost-8.1234567891) > 1e-9 {
+				t.Errorf("TotalCost = %v, want 8.1234567891", results.TotalCost)
+			}
+			if len(results.DailyTrend) != 2 || results.DailyTrend[0].Date != "2024-11-01" {
+				t.Errorf("DailyTrend = %+v", results.DailyTrend)
+			}
+		})
+	}
+}
+
+func curItems(items []CostItem) string {
+	got := make([]string, 0, len(items))
+	for _, item := range items {
+		got = append(got, fmt.Sprintf("%s=%.2f", item.Name, item.Amount))
+	}
+	return strings.Join(got, ",")
+}
@@ -0,0 +1,420 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ResourceTypeDNSRecord is the resource type reported for Route 53 record findings
+const ResourceTypeDNSRecord = "Route 53 Record"
+
+// AWSIPRangesURL is the published list of AWS public IP ranges
+const AWSIPRangesURL = "https://ip-ranges.amazonaws.com/ip-ranges.json"
+
+// s3EndpointPattern matches S3 REST and website endpoints, capturing the bucket
+// name when it is part of the hostname
+var s3EndpointPattern = regexp.MustCompile(`^(?:(.+)\.)?s3(?:-website)?(?:[.-][a-z0-9-]+)?\.amazonaws\.com$`)
+
+// DNSInventory holds the resources in the account that DNS records may point at
+type DNSInventory struct {
+	Region            string
+	PublicIPs         map[string]bool
+	EC2Ranges         []*net.IPNet
+	LoadBalancers     map[string]bool
+	CloudFrontDomains map[string]bool
+	BeanstalkCNAMEs   map[string]bool
+	S3Buckets         map[string]bool
+}
+
+// externalTargets checks targets that are missing from the inventory. Those
+// may belong to another account, such as a SaaS provider's CloudFront
+// distribution, so they are only dangling when known not to exist at all.
+type externalTargets interface {
+	hostDeleted(hostname string) bool
+	bucketDeleted(bucket string) bool
+}
+
+// liveTargets checks external targets against public DNS and S3
+type liveTargets struct {
+	ctx      context.Context
+	s3Client *s3.Client
+}
+
+// hostDeleted reports whether a CloudFront or Elastic Beanstalk hostname no
+// longer resolves, as happens once its distribution or environment is deleted
+func (t liveTargets) hostDeleted(hostname string) bool {
+	_, err := net.DefaultResolver.LookupHost(t.ctx, hostname)
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// bucketDeleted reports whether no account owns an S3 bucket. A bucket owned
+// by another account answers with access denied rather than not found.
+func (t liveTargets) bucketDeleted(bucket string) bool {
+	_, err := t.s3Client.HeadBucket(t.ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	var notFound *s3types.NotFound
+	return errors.As(err, &notFound)
+}
+
+// GlobalDNSInventory holds the account-wide data the dangling DNS check needs:
+// the records of public hosted zones, CloudFront distributions, S3 buckets and
+// the EC2 IP ranges of every region. It is loaded once and shared by the
+// auditors of every audited region.
+type GlobalDNSInventory struct {
+	loaded bool
+	err    error
+
+	records           []route53types.ResourceRecordSet
+	cloudFrontDomains map[string]bool
+	s3Buckets         map[string]bool
+	ec2Ranges         map[string][]*net.IPNet
+}
+
+// NewGlobalDNSInventory creates an empty inventory, loaded by the first
+// dangling DNS check that uses it
+func NewGlobalDNSInventory() *GlobalDNSInventory {
+	return &GlobalDNSInventory{}
+}
+
+// SetGlobalDNSInventory shares a global DNS inventory between auditors, so
+// that a multi-region audit lists Route 53, CloudFront and S3 only once
+func (s *SecurityAuditor) SetGlobalDNSInventory(inventory *GlobalDNSInventory) {
+	s.globalDNS = inventory
+}
+
+// CheckDanglingDNS finds Route 53 records pointing at AWS resources that no
+// longer exist, which can allow a subdomain takeover
+func (s *SecurityAuditor) CheckDanglingDNS(ctx context.Context) ([]SecurityFinding, error) {
+	if err := s.globalDNS.load(ctx, s); err != nil {
+		return nil, err
+	}
+
+	inventory, err := s.buildDNSInventory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	external := liveTargets{ctx: ctx, s3Client: s.s3Client}
+
+	findings := make([]SecurityFinding, 0)
+	for _, record := range s.globalDNS.records {
+		for _, reason := range evaluateDNSRecord(record, inventory, external) {
+			findings = append(findings, SecurityFinding{
+				CheckID:      CheckIDDanglingDNS,
+				ResourceType: ResourceTypeDNSRecord,
+				ResourceID:   normalizeDNSName(aws.ToString(record.Name)),
+				Region:       s.region,
+				Severity:     SeverityCritical,
+				Description:  fmt.Sprintf("%s (subdomain takeover risk)", reason),
+				Key:          reason,
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// load lists the records of public hosted zones, CloudFront distributions and
+// S3 buckets, and downloads the AWS IP ranges. Only the first call does the
+// work; later calls return its result.
+func (g *GlobalDNSInventory) load(ctx context.Context, s *SecurityAuditor) error {
+	if g.loaded {
+		return g.err
+	}
+	g.loaded = true
+	g.err = g.fetch(ctx, s)
+	return g.err
+}
+
+func (g *GlobalDNSInventory) fetch(ctx context.Context, s *SecurityAuditor) error {
+	g.records = make([]route53types.ResourceRecordSet, 0)
+	g.cloudFrontDomains = make(map[string]bool)
+	g.s3Buckets = make(map[string]bool)
+
+	ranges, err := loadEC2Ranges(ctx)
+	if err != nil {
+		return err
+	}
+	g.ec2Ranges = ranges
+
+	zones := route53.NewListHostedZonesPaginator(s.route53Client, &route53.ListHostedZonesInput{})
+	for zones.HasMorePages() {
+		page, err := zones.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list hosted zones: %w", err)
+		}
+
+		for _, zone := range page.HostedZones {
+			// Private zones are not resolvable from the internet
+			if zone.Config != nil && zone.Config.PrivateZone {
+				continue
+			}
+
+			records := route53.NewListResourceRecordSetsPaginator(s.route53Client, &route53.ListResourceRecordSetsInput{
+				HostedZoneId: zone.Id,
+			})
+			for records.HasMorePages() {
+				recordPage, err := records.NextPage(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to list records for hosted zone %s: %w", aws.ToString(zone.Name), err)
+				}
+				g.records = append(g.records, recordPage.ResourceRecordSets...)
+			}
+		}
+	}
+
+	distributions := cloudfront.NewListDistributionsPaginator(s.cloudfrontClient, &cloudfront.ListDistributionsInput{})
+	for distributions.HasMorePages() {
+		page, err := distributions.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list CloudFront distributions: %w", err)
+		}
+		if page.DistributionList == nil {
+			continue
+		}
+		for _, dist := range page.DistributionList.Items {
+			g.cloudFrontDomains[normalizeDNSName(aws.ToString(dist.DomainName))] = true
+		}
+	}
+
+	buckets, err := s.s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return fmt.Errorf("failed to list S3 buckets: %w", err)
+	}
+	for _, bucket := range buckets.Buckets {
+		g.s3Buckets[aws.ToString(bucket.Name)] = true
+	}
+
+	return nil
+}
+
+// buildDNSInventory combines the global inventory with the public IPs, load
+// balancers and Elastic Beanstalk environments of the auditor's region
+func (s *SecurityAuditor) buildDNSInventory(ctx context.Context) (*DNSInventory, error) {
+	inventory := &DNSInventory{
+		Region:            s.region,
+		PublicIPs:         make(map[string]bool),
+		EC2Ranges:         s.globalDNS.ec2Ranges[s.region],
+		LoadBalancers:     make(map[string]bool),
+		CloudFrontDomains: s.globalDNS.cloudFrontDomains,
+		BeanstalkCNAMEs:   make(map[string]bool),
+		S3Buckets:         s.globalDNS.s3Buckets,
+	}
+
+	// Elastic IPs, including those not associated with anything
+	addresses, err := describeAddresses(ctx, s.ec2Client)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addresses {
+		inventory.PublicIPs[aws.ToString(addr.PublicIp)] = true
+	}
+
+	// Auto-assigned public IPs on instances, NAT gateways, load balancers and tasks
+	interfaces := ec2.NewDescribeNetworkInterfacesPaginator(s.ec2Client, &ec2.DescribeNetworkInterfacesInput{})
+	for interfaces.HasMorePages() {
+		page, err := interfaces.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe network interfaces: %w", err)
+		}
+		for _, eni := range page.NetworkInterfaces {
+			if eni.Association != nil {
+				inventory.PublicIPs[aws.ToString(eni.Association.PublicIp)] = true
+			}
+		}
+	}
+
+	loadBalancers, err := s.describeLoadBalancers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, lb := range loadBalancers {
+		inventory.LoadBalancers[normalizeDNSName(aws.ToString(lb.DNSName))] = true
+	}
+
+	// Classic load balancers share the same DNS naming scheme
+	classicLoadBalancers := elasticloadbalancing.NewDescribeLoadBalancersPaginator(s.elbClient, &elasticloadbalancing.DescribeLoadBalancersInput{})
+	for classicLoadBalancers.HasMorePages() {
+		page, err := classicLoadBalancers.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe classic load balancers: %w", err)
+		}
+		for _, lb := range page.LoadBalancerDescriptions {
+			inventory.LoadBalancers[normalizeDNSName(aws.ToString(lb.DNSName))] = true
+		}
+	}
+
+	// DescribeEnvironments has no paginator in the SDK
+	input := &elasticbeanstalk.DescribeEnvironmentsInput{IncludeDeleted: aws.Bool(false)}
+	for {
+		environments, err := s.beanstalkClient.DescribeEnvironments(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe Elastic Beanstalk environments: %w", err)
+		}
+		for _, env := range environments.Environments {
+			inventory.BeanstalkCNAMEs[normalizeDNSName(aws.ToString(env.CNAME))] = true
+		}
+
+		if aws.ToString(environments.NextToken) == "" {
+			break
+		}
+		input.NextToken = environments.NextToken
+	}
+
+	return inventory, nil
+}
+
+// evaluateDNSRecord returns the reasons a record points at a missing resource
+func evaluateDNSRecord(record route53types.ResourceRecordSet, inventory *DNSInventory, external externalTargets) []string {
+	reasons := make([]string, 0)
+	name := normalizeDNSName(aws.ToString(record.Name))
+
+	if record.AliasTarget != nil {
+		target := strings.TrimPrefix(normalizeDNSName(aws.ToString(record.AliasTarget.DNSName)), "dualstack.")
+		if reason, dangling := danglingTarget(name, target, inventory, external); dangling {
+			reasons = append(reasons, "Alias "+reason)
+		}
+		return reasons
+	}
+
+	for _, rr := range record.ResourceRecords {
+		value := aws.ToString(rr.Value)
+
+		switch record.Type {
+		case route53types.RRTypeA:
+			if inventory.isUnallocatedIP(value) {
+				reasons = append(reasons, fmt.Sprintf("A record points to unallocated AWS IP %s", value))
+			}
+		case route53types.RRTypeCname:
+			if reason, dangling := danglingTarget(name, normalizeDNSName(value), inventory, external); dangling {
+				reasons = append(reasons, "CNAME "+reason)
+			}
+		}
+	}
+
+	return reasons
+}
+
+// danglingTarget reports whether a hostname points at an AWS resource that is
+// missing from the inventory and, for resources that other accounts may own,
+// no longer exists. Regional targets in other regions are skipped.
+func danglingTarget(name, target string, inventory *DNSInventory, external externalTargets) (string, bool) {
+	switch {
+	case strings.HasSuffix(target, ".cloudfront.net"):
+		if !inventory.CloudFrontDomains[target] && external.hostDeleted(target) {
+			return fmt.Sprintf("points to deleted CloudFront distribution %s", target), true
+		}
+
+	case strings.HasSuffix(target, ".elasticbeanstalk.com"):
+		if inventory.inRegion(target) && !inventory.BeanstalkCNAMEs[target] && external.hostDeleted(target) {
+			return fmt.Sprintf("points to deleted Elastic Beanstalk environment %s", target), true
+		}
+
+	case strings.Contains(target, ".elb.") && strings.HasSuffix(target, ".amazonaws.com"):
+		if inventory.inRegion(target) && !inventory.LoadBalancers[target] {
+			return fmt.Sprintf("points to deleted load balancer %s", target), true
+		}
+
+	case s3EndpointPattern.MatchString(target):
+		// Alias records to S3 website endpoints serve the bucket named after the record
+		bucket := s3EndpointPattern.FindStringSubmatch(target)[1]
+		if bucket == "" {
+			bucket = name
+		}
+		if !inventory.S3Buckets[bucket] && external.bucketDeleted(bucket) {
+			return fmt.Sprintf("points to deleted S3 bucket %s", bucket), true
+		}
+	}
+
+	return "", false
+}
+
+// isUnallocatedIP reports whether an IP is in the region's EC2 address space
+// but not allocated to this account
+func (inv *DNSInventory) isUnallocatedIP(value string) bool {
+	ip := net.ParseIP(value)
+	if ip == nil || inv.PublicIPs[value] {
+		return false
+	}
+	for _, cidr := range inv.EC2Ranges {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// inRegion reports whether a regional AWS hostname belongs to the inventory's region
+func (inv *DNSInventory) inRegion(hostname string) bool {
+	return strings.Contains(hostname, "."+inv.Region+".")
+}
+
+// normalizeDNSName lowercases a DNS name and removes the trailing dot
+func normalizeDNSName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
+
+// loadEC2Ranges downloads the published AWS IP ranges and returns the EC2
+// ranges of every region
+func loadEC2Ranges(ctx context.Context) (map[string][]*net.IPNet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, AWSIPRangesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IP ranges request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download AWS IP ranges: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download AWS IP ranges: status %d", resp.StatusCode)
+	}
+
+	return parseEC2Ranges(resp.Body)
+}
+
+// parseEC2Ranges parses ip-ranges.json and returns the IPv4 EC2 ranges by region
+func parseEC2Ranges(r io.Reader) (map[string][]*net.IPNet, error) {
+	var ipRanges struct {
+		Prefixes []struct {
+			IPPrefix string `json:"ip_prefix"`
+			Region   string `json:"region"`
+			Service  string `json:"service"`
+		} `json:"prefixes"`
+	}
+	if err := json.NewDecoder(r).Decode(&ipRanges); err != nil {
+		return nil, fmt.Errorf("failed to parse AWS IP ranges: %w", err)
+	}
+
+	ranges := make(map[string][]*net.IPNet)
+	for _, prefix := range ipRanges.Prefixes {
+		if prefix.Service != "EC2" {
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(prefix.IPPrefix)
+		if err != nil {
+			continue
+		}
+		ranges[prefix.Region] = append(ranges[prefix.Region], cidr)
+	}
+
+	return ranges, nil
+}
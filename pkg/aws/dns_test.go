@@ -0,0 +1,181 @@
+package aws
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+func testDNSInventory() *DNSInventory {
+	_, ec2Range, _ := net.ParseCIDR("3.80.0.0/12")
+
+	return &DNSInventory{
+		Region:            "us-east-1",
+		PublicIPs:         map[string]bool{"3.85.1.1": true},
+		EC2Ranges:         []*net.IPNet{ec2Range},
+		LoadBalancers:     map[string]bool{"web-123.us-east-1.elb.amazonaws.com": true},
+		CloudFrontDomains: map[string]bool{"d111.cloudfront.net": true},
+		BeanstalkCNAMEs:   map[string]bool{"app.us-east-1.elasticbeanstalk.com": true},
+		S3Buckets:         map[string]bool{"static.example.com": true, "assets-bucket": true},
+	}
+}
+
+// fakeExternalTargets reports the listed hostnames and buckets as deleted;
+// every other external target exists in another account
+type fakeExternalTargets struct {
+	deletedHosts   map[string]bool
+	deletedBuckets map[string]bool
+}
+
+func (f fakeExternalTargets) hostDeleted(hostname string) bool { return f.deletedHosts[hostname] }
+func (f fakeExternalTargets) bucketDeleted(bucket string) bool { return f.deletedBuckets[bucket] }
+
+func TestEvaluateDNSRecord(t *testing.T) {
+	tests := []struct {
+		name       string
+		record     route53types.ResourceRecordSet
+		wantReason string
+	}{
+		{
+			name:   "A record to allocated elastic IP",
+			record: simpleRecord("api.example.com.", route53types.RRTypeA, "3.85.1.1"),
+		},
+		{
+			name:       "A record to unallocated AWS IP",
+			record:     simpleRecord("old.example.com.", route53types.RRTypeA, "3.85.9.9"),
+			wantReason: "A record points to unallocated AWS IP 3.85.9.9",
+		},
+		{
+			name:   "A record outside AWS ranges",
+			record: simpleRecord("vendor.example.com.", route53types.RRTypeA, "203.0.113.10"),
+		},
+		{
+			name:   "CNAME to existing CloudFront distribution",
+			record: simpleRecord("cdn.example.com.", route53types.RRTypeCname, "d111.cloudfront.net"),
+		},
+		{
+			name:       "CNAME to deleted CloudFront distribution",
+			record:     simpleRecord("cdn2.example.com.", route53types.RRTypeCname, "d999.cloudfront.net."),
+			wantReason: "CNAME points to deleted CloudFront distribution d999.cloudfront.net",
+		},
+		{
+			name:   "CNAME to CloudFront distribution in another account",
+			record: simpleRecord("help.example.com.", route53types.RRTypeCname, "d777.cloudfront.net"),
+		},
+		{
+			name:       "CNAME to deleted S3 website bucket",
+			record:     simpleRecord("docs.example.com.", route53types.RRTypeCname, "old-docs.s3-website-us-east-1.amazonaws.com"),
+			wantReason: "CNAME points to deleted S3 bucket old-docs",
+		},
+		{
+			name:   "CNAME to S3 bucket in another account",
+			record: simpleRecord("partner.example.com.", route53types.RRTypeCname, "partner-assets.s3.amazonaws.com"),
+		},
+		{
+			name:   "CNAME to existing S3 bucket",
+			record: simpleRecord("assets.example.com.", route53types.RRTypeCname, "assets-bucket.s3.amazonaws.com"),
+		},
+		{
+			name:       "CNAME to deleted Elastic Beanstalk environment",
+			record:     simpleRecord("beta.example.com.", route53types.RRTypeCname, "beta-app.us-east-1.elasticbeanstalk.com"),
+			wantReason: "CNAME points to deleted Elastic Beanstalk environment beta-app.us-east-1.elasticbeanstalk.com",
+		},
+		{
+			name:   "CNAME to Elastic Beanstalk environment in another account",
+			record: simpleRecord("vendor-app.example.com.", route53types.RRTypeCname, "vendor.us-east-1.elasticbeanstalk.com"),
+		},
+		{
+			name:   "CNAME to Elastic Beanstalk in another region",
+			record: simpleRecord("eu.example.com.", route53types.RRTypeCname, "eu-app.eu-west-1.elasticbeanstalk.com"),
+		},
+		{
+			name:   "alias to existing load balancer",
+			record: aliasRecord("www.example.com.", "dualstack.web-123.us-east-1.elb.amazonaws.com."),
+		},
+		{
+			name:       "alias to deleted load balancer",
+			record:     aliasRecord("shop.example.com.", "dualstack.shop-456.us-east-1.elb.amazonaws.com."),
+			wantReason: "Alias points to deleted load balancer shop-456.us-east-1.elb.amazonaws.com",
+		},
+		{
+			name:   "alias to S3 website endpoint with matching bucket",
+			record: aliasRecord("static.example.com.", "s3-website-us-east-1.amazonaws.com."),
+		},
+		{
+			name:       "alias to S3 website endpoint without bucket",
+			record:     aliasRecord("blog.example.com.", "s3-website-us-east-1.amazonaws.com."),
+			wantReason: "Alias points to deleted S3 bucket blog.example.com",
+		},
+		{
+			name:   "TXT record is ignored",
+			record: simpleRecord("example.com.", route53types.RRTypeTxt, `"v=spf1 include:d999.cloudfront.net"`),
+		},
+	}
+
+	inventory := testDNSInventory()
+	external := fakeExternalTargets{
+		deletedHosts:   map[string]bool{"d999.cloudfront.net": true, "beta-app.us-east-1.elasticbeanstalk.com": true},
+		deletedBuckets: map[string]bool{"old-docs": true, "blog.example.com": true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons := evaluateDNSRecord(tt.record, inventory, external)
+
+			if tt.wantReason == "" {
+				if len(reasons) != 0 {
+					t.Errorf("evaluateDNSRecord() = %v, want no findings", reasons)
+				}
+				return
+			}
+			if len(reasons) != 1 || reasons[0] != tt.wantReason {
+				t.Errorf("evaluateDNSRecord() = %v, want [%q]", reasons, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestParseEC2Ranges(t *testing.T) {
+	data := `{
+  "prefixes": [
+    {"ip_prefix": "3.80.0.0/12", "region": "us-east-1", "service": "EC2"},
+    {"ip_prefix": "3.80.0.0/12", "region": "us-east-1", "service": "AMAZON"},
+    {"ip_prefix": "18.200.0.0/16", "region": "eu-west-1", "service": "EC2"},
+    {"ip_prefix": "not-a-cidr", "region": "us-east-1", "service": "EC2"}
+  ]
+}`
+
+	ranges, err := parseEC2Ranges(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parseEC2Ranges() unexpected error: %v", err)
+	}
+	if east := ranges["us-east-1"]; len(east) != 1 || east[0].String() != "3.80.0.0/12" {
+		t.Errorf("parseEC2Ranges()[us-east-1] = %v, want [3.80.0.0/12]", east)
+	}
+	if west := ranges["eu-west-1"]; len(west) != 1 || west[0].String() != "18.200.0.0/16" {
+		t.Errorf("parseEC2Ranges()[eu-west-1] = %v, want [18.200.0.0/16]", west)
+	}
+
+	if _, err := parseEC2Ranges(strings.NewReader("{")); err == nil {
+		t.Error("parseEC2Ranges() with invalid JSON returned nil error")
+	}
+}
+
+func simpleRecord(name string, recordType route53types.RRType, value string) route53types.ResourceRecordSet {
+	return route53types.ResourceRecordSet{
+		Name:            aws.String(name),
+		Type:            recordType,
+		ResourceRecords: []route53types.ResourceRecord{{Value: aws.String(value)}},
+	}
+}
+
+func aliasRecord(name, target string) route53types.ResourceRecordSet {
+	return route53types.ResourceRecordSet{
+		Name:        aws.String(name),
+		Type:        route53types.RRTypeA,
+		AliasTarget: &route53types.AliasTarget{DNSName: aws.String(target)},
+	}
+}
@@ -0,0 +1,348 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+// Resource types reported in SecurityFinding
+const (
+	ResourceTypeS3Bucket      = "S3 Bucket"
+	ResourceTypeSecurityGroup = "Security Group"
+	ResourceTypeEBSVolume     = "EBS Volume"
+	ResourceTypeEBSSnapshot   = "EBS Snapshot"
+	ResourceTypeRDSInstance   = "RDS Instance"
+	ResourceTypeRDSSnapshot   = "RDS Snapshot"
+	ResourceTypeAMI           = "AMI"
+)
+
+// SnapshotSharing describes who a snapshot has been shared with
+type SnapshotSharing struct {
+	Public          bool
+	UnknownAccounts []string
+}
+
+// SetTrustedAccounts sets the AWS account IDs that snapshots may be shared with
+// without being reported
+func (s *SecurityAuditor) SetTrustedAccounts(accountIDs []string) {
+	s.trustedAccounts = make(map[string]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		s.trustedAccounts[id] = true
+	}
+}
+
+// CheckUnencryptedVolumes finds EBS volumes without encryption at rest
+func (s *SecurityAuditor) CheckUnencryptedVolumes(ctx context.Context) ([]SecurityFinding, error) {
+	findings := make([]SecurityFinding, 0)
+
+	paginator := ec2.NewDescribeVolumesPaginator(s.ec2Client, &ec2.DescribeVolumesInput{
+		Filters: []ec2types.Filter{
+			{
+				Name:   aws.String("encrypted"),
+				Values: []string{"false"},
+			},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe volumes: %w", err)
+		}
+
+		for _, vol := range page.Volumes {
+			findings = append(findings, SecurityFinding{
+				CheckID:      CheckIDUnencryptedVolumes,
+				ResourceType: ResourceTypeEBSVolume,
+				ResourceID:   aws.ToString(vol.VolumeId),
+				Region:       s.region,
+				Severity:     SeverityMedium,
+				Description:  fmt.Sprintf("Volume (%dGB %s) is not encrypted at rest", aws.ToInt32(vol.Size), vol.VolumeType),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// CheckUnencryptedSnapshots finds EBS snapshots owned by this account without encryption
+func (s *SecurityAuditor) CheckUnencryptedSnapshots(ctx context.Context) ([]SecurityFinding, error) {
+	findings := make([]SecurityFinding, 0)
+
+	paginator := ec2.NewDescribeSnapshotsPaginator(s.ec2Client, &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{"self"},
+		Filters: []ec2types.Filter{
+			{
+				Name:   aws.String("encrypted"),
+				Values: []string{"false"},
+			},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe snapshots: %w", err)
+		}
+
+		for _, snap := range page.Snapshots {
+			findings = append(findings, SecurityFinding{
+				CheckID:      CheckIDUnencryptedSnapshots,
+				ResourceType: ResourceTypeEBSSnapshot,
+				ResourceID:   aws.ToString(snap.SnapshotId),
+				Region:       s.region,
+				Severity:     SeverityMedium,
+				Description:  fmt.Sprintf("Snapshot of %s (%dGB) is not encrypted at rest", aws.ToString(snap.VolumeId), aws.ToInt32(snap.VolumeSize)),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// CheckRDSInstances finds RDS instances that are unencrypted or publicly accessible
+func (s *SecurityAuditor) CheckRDSInstances(ctx context.Context) ([]SecurityFinding, error) {
+	findings := make([]SecurityFinding, 0)
+	count := 0
+
+	paginator := rds.NewDescribeDBInstancesPaginator(s.rdsClient, &rds.DescribeDBInstancesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe RDS instances: %w", err)
+		}
+
+		for _, db := range page.DBInstances {
+			findings = append(findings, evaluateRDSInstance(db, s.region)...)
+		}
+		count += len(page.DBInstances)
+	}
+
+	s.countResources(count, CheckIDRDSPublicAccess, CheckIDRDSEncryption)
+	return findings, nil
+}
+
+// CheckSharedSnapshots finds EBS and RDS snapshots that are public or shared
+// with accounts outside the trusted list
+func (s *SecurityAuditor) CheckSharedSnapshots(ctx context.Context) ([]SecurityFinding, error) {
+	findings, err := s.checkSharedEBSSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rdsFindings, err := s.checkSharedRDSSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(findings, rdsFindings...), nil
+}
+
+// checkSharedEBSSnapshots checks the create-volume permissions of every EBS
+// snapshot owned by this account
+func (s *SecurityAuditor) checkSharedEBSSnapshots(ctx context.Context) ([]SecurityFinding, error) {
+	findings := make([]SecurityFinding, 0)
+
+	paginator := ec2.NewDescribeSnapshotsPaginator(s.ec2Client, &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{"self"},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe snapshots: %w", err)
+		}
+
+		for _, snap := range page.Snapshots {
+			snapshotID := aws.ToString(snap.SnapshotId)
+
+			attr, err := s.ec2Client.DescribeSnapshotAttribute(ctx, &ec2.DescribeSnapshotAttributeInput{
+				SnapshotId: snap.SnapshotId,
+				Attribute:  ec2types.SnapshotAttributeNameCreateVolumePermission,
+			})
+			if err != nil {
+				// Skip snapshots we can't inspect
+				continue
+			}
+
+			public := false
+			accounts := make([]string, 0)
+			for _, perm := range attr.CreateVolumePermissions {
+				if perm.Group == ec2types.PermissionGroupAll {
+					public = true
+				}
+				if perm.UserId != nil {
+					accounts = append(accounts, aws.ToString(perm.UserId))
+				}
+			}
+
+			sharing := evaluateSnapshotSharing(public, accounts, s.trustedAccounts)
+			findings = append(findings, sharingFindings(ResourceTypeEBSSnapshot, snapshotID, s.region, sharing)...)
+		}
+	}
+
+	return findings, nil
+}
+
+// checkSharedRDSSnapshots checks the restore permissions of every manual RDS
+// snapshot (automated snapshots cannot be shared)
+func (s *SecurityAuditor) checkSharedRDSSnapshots(ctx context.Context) ([]SecurityFinding, error) {
+	findings := make([]SecurityFinding, 0)
+
+	paginator := rds.NewDescribeDBSnapshotsPaginator(s.rdsClient, &rds.DescribeDBSnapshotsInput{
+		SnapshotType: aws.String("manual"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe RDS snapshots: %w", err)
+		}
+
+		for _, dbSnap := range page.DBSnapshots {
+			snapshotID := aws.ToString(dbSnap.DBSnapshotIdentifier)
+
+			attrResult, err := s.rdsClient.DescribeDBSnapshotAttributes(ctx, &rds.DescribeDBSnapshotAttributesInput{
+				DBSnapshotIdentifier: dbSnap.DBSnapshotIdentifier,
+			})
+			if err != nil || attrResult.DBSnapshotAttributesResult == nil {
+				// Skip snapshots we can't inspect
+				continue
+			}
+
+			public := false
+			accounts := make([]string, 0)
+			for _, attr := range attrResult.DBSnapshotAttributesResult.DBSnapshotAttributes {
+				if aws.ToString(attr.AttributeName) != "restore" {
+					continue
+				}
+				for _, value := range attr.AttributeValues {
+					if value == "all" {
+						public = true
+					} else {
+						accounts = append(accounts, value)
+					}
+				}
+			}
+
+			sharing := evaluateSnapshotSharing(public, accounts, s.trustedAccounts)
+			findings = append(findings, sharingFindings(ResourceTypeRDSSnapshot, snapshotID, s.region, sharing)...)
+		}
+	}
+
+	return findings, nil
+}
+
+// CheckPublicAMIs finds AMIs owned by this account that are publicly launchable
+func (s *SecurityAuditor) CheckPublicAMIs(ctx context.Context) ([]SecurityFinding, error) {
+	findings := make([]SecurityFinding, 0)
+
+	paginator := ec2.NewDescribeImagesPaginator(s.ec2Client, &ec2.DescribeImagesInput{
+		Owners: []string{"self"},
+		Filters: []ec2types.Filter{
+			{
+				Name:   aws.String("is-public"),
+				Values: []string{"true"},
+			},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe images: %w", err)
+		}
+
+		for _, image := range page.Images {
+			findings = append(findings, SecurityFinding{
+				CheckID:      CheckIDPublicAMIs,
+				ResourceType: ResourceTypeAMI,
+				ResourceID:   aws.ToString(image.ImageId),
+				Region:       s.region,
+				Severity:     SeverityHigh,
+				Description:  fmt.Sprintf("AMI %q is public", aws.ToString(image.Name)),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// evaluateRDSInstance checks a single RDS instance for encryption and exposure issues
+func evaluateRDSInstance(db rdstypes.DBInstance, region string) []SecurityFinding {
+	findings := make([]SecurityFinding, 0)
+	instanceID := aws.ToString(db.DBInstanceIdentifier)
+
+	if aws.ToBool(db.PubliclyAccessible) {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDRDSPublicAccess,
+			ResourceType: ResourceTypeRDSInstance,
+			ResourceID:   instanceID,
+			Region:       region,
+			Severity:     SeverityCritical,
+			Description:  fmt.Sprintf("%s instance is publicly accessible", aws.ToString(db.Engine)),
+		})
+	}
+
+	if !aws.ToBool(db.StorageEncrypted) {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDRDSEncryption,
+			ResourceType: ResourceTypeRDSInstance,
+			ResourceID:   instanceID,
+			Region:       region,
+			Severity:     SeverityHigh,
+			Description:  fmt.Sprintf("%s instance storage is not encrypted at rest", aws.ToString(db.Engine)),
+		})
+	}
+
+	return findings
+}
+
+// evaluateSnapshotSharing determines whether a snapshot is public and which
+// accounts it is shared with that are not trusted
+func evaluateSnapshotSharing(public bool, accountIDs []string, trusted map[string]bool) SnapshotSharing {
+	sharing := SnapshotSharing{
+		Public:          public,
+		UnknownAccounts: make([]string, 0),
+	}
+
+	for _, id := range accountIDs {
+		if !trusted[id] {
+			sharing.UnknownAccounts = append(sharing.UnknownAccounts, id)
+		}
+	}
+
+	return sharing
+}
+
+// sharingFindings converts snapshot sharing state into security findings
+func sharingFindings(resourceType, resourceID, region string, sharing SnapshotSharing) []SecurityFinding {
+	findings := make([]SecurityFinding, 0)
+
+	if sharing.Public {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDSharedSnapshots,
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			Region:       region,
+			Severity:     SeverityCritical,
+			Description:  "Snapshot is shared publicly",
+			Key:          "public",
+		})
+	}
+
+	for _, account := range sharing.UnknownAccounts {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDSharedSnapshots,
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			Region:       region,
+			Severity:     SeverityHigh,
+			Description:  fmt.Sprintf("Snapshot is shared with unknown account %s", account),
+			Key:          account,
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,230 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+func TestEvaluateRDSInstance(t *testing.T) {
+	tests := []struct {
+		name           string
+		instance       rdstypes.DBInstance
+		wantSeverities []Severity
+	}{
+		{
+			name: "encrypted private instance",
+			instance: rdstypes.DBInstance{
+				DBInstanceIdentifier: aws.String("db-ok"),
+				Engine:               aws.String("postgres"),
+				StorageEncrypted:     aws.Bool(true),
+				PubliclyAccessible:   aws.Bool(false),
+			},
+			wantSeverities: nil,
+		},
+		{
+			name: "unencrypted private instance",
+			instance: rdstypes.DBInstance{
+				DBInstanceIdentifier: aws.String("db-plain"),
+				Engine:               aws.String("mysql"),
+				StorageEncrypted:     aws.Bool(false),
+				PubliclyAccessible:   aws.Bool(false),
+			},
+			wantSeverities: []Severity{SeverityHigh},
+		},
+		{
+			name: "encrypted public instance",
+			instance: rdstypes.DBInstance{
+				DBInstanceIdentifier: aws.String("db-public"),
+				Engine:               aws.String("postgres"),
+				StorageEncrypted:     aws.Bool(true),
+				PubliclyAccessible:   aws.Bool(true),
+			},
+			wantSeverities: []Severity{SeverityCritical},
+		},
+		{
+			name: "unencrypted public instance",
+			instance: rdstypes.DBInstance{
+				DBInstanceIdentifier: aws.String("db-worst"),
+				Engine:               aws.String("mysql"),
+				StorageEncrypted:     aws.Bool(false),
+				PubliclyAccessible:   aws.Bool(true),
+			},
+			wantSeverities: []Severity{SeverityCritical, SeverityHigh},
+		},
+		{
+			name: "missing fields treated as unencrypted and private",
+			instance: rdstypes.DBInstance{
+				DBInstanceIdentifier: aws.String("db-unknown"),
+			},
+			wantSeverities: []Severity{SeverityHigh},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := evaluateRDSInstance(tt.instance, "us-east-1")
+
+			if len(findings) != len(tt.wantSeverities) {
+				t.Fatalf("evaluateRDSInstance() returned %d findings, want %d", len(findings), len(tt.wantSeverities))
+			}
+
+			for i, f := range findings {
+				if f.Severity != tt.wantSeverities[i] {
+					t.Errorf("finding[%d].Severity = %v, want %v", i, f.Severity, tt.wantSeverities[i])
+				}
+				if f.ResourceType != ResourceTypeRDSInstance {
+					t.Errorf("finding[%d].ResourceType = %q, want %q", i, f.ResourceType, ResourceTypeRDSInstance)
+				}
+				if f.ResourceID != aws.ToString(tt.instance.DBInstanceIdentifier) {
+					t.Errorf("finding[%d].ResourceID = %q, want %q", i, f.ResourceID, aws.ToString(tt.instance.DBInstanceIdentifier))
+				}
+				if f.Region != "us-east-1" {
+					t.Errorf("finding[%d].Region = %q, want us-east-1", i, f.Region)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateSnapshotSharing(t *testing.T) {
+	trusted := map[string]bool{
+		"111111111111": true,
+		"222222222222": true,
+	}
+
+	tests := []struct {
+		name        string
+		public      bool
+		accountIDs  []string
+		wantPublic  bool
+		wantUnknown []string
+	}{
+		{
+			name:        "private snapshot",
+			public:      false,
+			accountIDs:  nil,
+			wantPublic:  false,
+			wantUnknown: []string{},
+		},
+		{
+			name:        "public snapshot",
+			public:      true,
+			accountIDs:  nil,
+			wantPublic:  true,
+			wantUnknown: []string{},
+		},
+		{
+			name:        "shared with trusted accounts only",
+			public:      false,
+			accountIDs:  []string{"111111111111", "222222222222"},
+			wantPublic:  false,
+			wantUnknown: []string{},
+		},
+		{
+			name:        "shared with unknown account",
+			public:      false,
+			accountIDs:  []string{"111111111111", "999999999999"},
+			wantPublic:  false,
+			wantUnknown: []string{"999999999999"},
+		},
+		{
+			name:        "public and shared with unknown accounts",
+			public:      true,
+			accountIDs:  []string{"333333333333", "444444444444"},
+			wantPublic:  true,
+			wantUnknown: []string{"333333333333", "444444444444"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateSnapshotSharing(tt.public, tt.accountIDs, trusted)
+
+			if got.Public != tt.wantPublic {
+				t.Errorf("Public = %v, want %v", got.Public, tt.wantPublic)
+			}
+
+			if len(got.UnknownAccounts) != len(tt.wantUnknown) {
+				t.Fatalf("UnknownAccounts = %v, want %v", got.UnknownAccounts, tt.wantUnknown)
+			}
+
+			for i, id := range tt.wantUnknown {
+				if got.UnknownAccounts[i] != id {
+					t.Errorf("UnknownAccounts[%d] = %q, want %q", i, got.UnknownAccounts[i], id)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateSnapshotSharingNoTrustedAccounts(t *testing.T) {
+	got := evaluateSnapshotSharing(false, []string{"111111111111"}, nil)
+
+	if len(got.UnknownAccounts) != 1 {
+		t.Errorf("UnknownAccounts = %v, want 1 entry when no accounts are trusted", got.UnknownAccounts)
+	}
+}
+
+func TestSharingFindings(t *testing.T) {
+	sharing := SnapshotSharing{
+		Public:          true,
+		UnknownAccounts: []string{"999999999999"},
+	}
+
+	findings := sharingFindings(ResourceTypeEBSSnapshot, "snap-123", "eu-west-1", sharing)
+
+	if len(findings) != 2 {
+		t.Fatalf("sharingFindings() returned %d findings, want 2", len(findings))
+	}
+
+	if findings[0].Severity != SeverityCritical {
+		t.Errorf("public finding Severity = %v, want %v", findings[0].Severity, SeverityCritical)
+	}
+
+	if findings[1].Severity != SeverityHigh {
+		t.Errorf("unknown account finding Severity = %v, want %v", findings[1].Severity, SeverityHigh)
+	}
+
+	for _, f := range findings {
+		if f.ResourceType != ResourceTypeEBSSnapshot {
+			t.Errorf("ResourceType = %q, want %q", f.ResourceType, ResourceTypeEBSSnapshot)
+		}
+		if f.ResourceID != "snap-123" {
+			t.Errorf("ResourceID = %q, want snap-123", f.ResourceID)
+		}
+		if f.Region != "eu-west-1" {
+			t.Errorf("Region = %q, want eu-west-1", f.Region)
+		}
+	}
+
+	if empty := sharingFindings(ResourceTypeRDSSnapshot, "rds-snap", "eu-west-1", SnapshotSharing{}); len(empty) != 0 {
+		t.Errorf("sharingFindings() for private snapshot returned %d findings, want 0", len(empty))
+	}
+}
+
+func TestSecurityResultsCountBySeverityIncludesFindings(t *testing.T) {
+	results := &SecurityResults{
+		PublicS3Buckets: []PublicS3Bucket{
+			{BucketName: "bucket1", Severity: SeverityCritical},
+		},
+		Findings: []SecurityFinding{
+			{ResourceID: "vol-1", Severity: SeverityMedium},
+			{ResourceID: "db-1", Severity: SeverityCritical},
+			{ResourceID: "ami-1", Severity: SeverityHigh},
+		},
+	}
+
+	counts := results.CountBySeverity()
+
+	if counts[SeverityCritical] != 2 {
+		t.Errorf("Critical count = %d, want 2", counts[SeverityCritical])
+	}
+	if counts[SeverityHigh] != 1 {
+		t.Errorf("High count = %d, want 1", counts[SeverityHigh])
+	}
+	if counts[SeverityMedium] != 1 {
+		t.Errorf("Medium count = %d, want 1", counts[SeverityMedium])
+	}
+}
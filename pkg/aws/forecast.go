@@ -0,0 +1,497 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/budgets"
+	budgetstypes "github.com/aws/aws-sdk-go-v2/service/budgets/types"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Budget periods, matching the AWS Budgets time units
+const (
+	BudgetPeriodMonthly   = "MONTHLY"
+	BudgetPeriodQuarterly = "QUARTERLY"
+)
+
+// Budget sources
+const (
+	BudgetSourceAWS         = "AWS Budgets"
+	BudgetSourceCommandLine = "command line"
+)
+
+// costDateLayout is the date format used by Cost Explorer
+const costDateLayout = "2006-01-02"
+
+// CostBudget is a spending limit for a month or quarter
+type CostBudget struct {
+	Name     string
+	Source   string
+	TimeUnit string
+	Limit    float64
+	// Metric is the Cost Explorer metric the budget tracks, unblended if empty
+	Metric string
+	// ExcludedRecordTypes are the charges the budget leaves out, such as credits
+	ExcludedRecordTypes []string
+}
+
+// BudgetStatus compares a period's spend and forecast against a budget
+type BudgetStatus struct {
+	Name             string
+	Source           string
+	Period           string
+	Limit            float64
+	PercentUsed      float64
+	ProjectedPercent float64
+	// ProjectedOverrun is how far the projected total exceeds the limit, or 0
+	ProjectedOverrun float64
+	// ExhaustionDate is the day spend reaches the limit, empty if the budget lasts the period
+	ExhaustionDate string
+	OverBudget     bool
+
+	// Metric and ExcludedRecordTypes are how the budget measures spend
+	Metric              string
+	ExcludedRecordTypes []string
+}
+
+// ForecastPeriod holds spend to date and the forecast to the end of a month or quarter
+type ForecastPeriod struct {
+	Name          string
+	TimeUnit      string
+	Start         time.Time
+	End           time.Time // exclusive
+	DaysElapsed   int
+	DaysRemaining int
+	ActualSpend   float64
+	// The forecast covers today until the end of the period
+	ForecastMean   float64
+	ForecastLower  float64
+	ForecastUpper  float64
+	ProjectedTotal float64
+	ProjectedLower float64
+	ProjectedUpper float64
+	// BurnRate is the average daily spend so far and ProjectedBurnRate the
+	// forecast average for the rest of the period
+	BurnRate          float64
+	ProjectedBurnRate float64
+	DailySpend        []DailyCost
+	Budgets           []BudgetStatus
+}
+
+// ForecastResults holds the month-end and quarter-end forecasts
+type ForecastResults struct {
+	GeneratedAt     time.Time
+	Currency        string
+	ConfidenceLevel int32
+	Periods         []ForecastPeriod
+}
+
+// GetCostForecast forecasts spend to the end of the current month and quarter.
+// confidence is the prediction interval level, between 51 and 99.
+func (c *CostAnalyzer) GetCostForecast(ctx context.Context, now time.Time, confidence int32) (*ForecastResults, error) {
+	if confidence < 51 || confidence > 99 {
+		return nil, fmt.Errorf("confidence level must be between 51 and 99, got %d", confidence)
+	}
+
+	periods, currency, err := c.projectPeriods(ctx, now, confidence, CostBudget{}.basis())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ForecastResults{
+		GeneratedAt:     now,
+		Currency:        currency,
+		ConfidenceLevel: confidence,
+		Periods:         periods,
+	}, nil
+}
+
+// projectPeriods returns the current month and quarter with spend to date and
+// the forecast measured on a basis, and their currency
+func (c *CostAnalyzer) projectPeriods(ctx context.Context, now time.Time, confidence int32, basis spendBasis) ([]ForecastPeriod, string, error) {
+	today := truncateToDay(now)
+	periods := forecastPeriods(now)
+
+	// The quarter contains the month, so one query covers the spend of both
+	daily, currency, err := c.dailySpend(ctx, basis, periods[len(periods)-1].Start, today)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for i := range periods {
+		unit, err := c.forecastSpend(ctx, basis, &periods[i], today, confidence)
+		if err != nil {
+			return nil, "", err
+		}
+		if unit != "" {
+			currency = unit
+		}
+		summarizePeriod(&periods[i], daily, today)
+	}
+
+	return periods, currency, nil
+}
+
+// GetBudgets returns the account's monthly and quarterly cost budgets from
+// AWS Budgets. Budgets with cost filters are skipped since the forecast
+// covers the whole account.
+func (c *CostAnalyzer) GetBudgets(ctx context.Context) ([]CostBudget, error) {
+	identity, err := c.stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller identity: %w", err)
+	}
+
+	costBudgets := make([]CostBudget, 0)
+	paginator := budgets.NewDescribeBudgetsPaginator(c.budgetsClient, &budgets.DescribeBudgetsInput{
+		AccountId: identity.Account,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			// AWS Budgets reports an account without budgets as not found
+			var notFound *budgetstypes.NotFoundException
+			if errors.As(err, &notFound) {
+				return costBudgets, nil
+			}
+			return nil, fmt.Errorf("failed to describe budgets: %w", err)
+		}
+		for _, budget := range page.Budgets {
+			if costBudget, ok := toCostBudget(budget); ok {
+				costBudgets = append(costBudgets, costBudget)
+			}
+		}
+	}
+
+	return costBudgets, nil
+}
+
+// ApplyBudgets compares each period against the budgets with a matching time
+// unit. A budget that tracks another metric or leaves out credits or refunds
+// is compared against spend and a forecast measured the same way.
+func (c *CostAnalyzer) ApplyBudgets(ctx context.Context, results *ForecastResults, costBudgets []CostBudget) error {
+	measured := map[string][]ForecastPeriod{
+		CostBudget{}.basis().key(): results.Periods,
+	}
+	for _, budget := range costBudgets {
+		basis := budget.basis()
+		if _, ok := measured[basis.key()]; ok {
+			continue
+		}
+		periods, _, err := c.projectPeriods(ctx, results.GeneratedAt, results.ConfidenceLevel, basis)
+		if err != nil {
+			return fmt.Errorf("budget %s: %w", budget.Name, err)
+		}
+		measured[basis.key()] = periods
+	}
+
+	results.applyBudgets(costBudgets, measured)
+	return nil
+}
+
+// applyBudgets compares each period against the budgets with a matching time
+// unit. measured holds the periods measured on each budget's basis; periods
+// on a basis missing from it are compared as they are.
+func (r *ForecastResults) applyBudgets(costBudgets []CostBudget, measured map[string][]ForecastPeriod) {
+	today := truncateToDay(r.GeneratedAt)
+	for i := range r.Periods {
+		period := &r.Periods[i]
+		for _, budget := range costBudgets {
+			if budget.TimeUnit != period.TimeUnit {
+				continue
+			}
+			spend := period
+			if periods, ok := measured[budget.basis().key()]; ok && i < len(periods) {
+				spend = &periods[i]
+			}
+			period.Budgets = append(period.Budgets, evaluateBudget(spend, budget, today))
+		}
+	}
+}
+
+// OverBudget returns the budgets the projected spend exceeds
+func (r *ForecastResults) OverBudget() []BudgetStatus {
+	over := make([]BudgetStatus, 0)
+	for _, period := range r.Periods {
+		for _, status := range period.Budgets {
+			if status.OverBudget {
+				over = append(over, status)
+			}
+		}
+	}
+	return over
+}
+
+// dailySpend returns the daily spend on a basis from start until end (exclusive)
+func (c *CostAnalyzer) dailySpend(ctx context.Context, basis spendBasis, start, end time.Time) ([]DailyCost, string, error) {
+	daily := make([]DailyCost, 0)
+	currency := "USD"
+	if !start.Before(end) {
+		// First day of the period, nothing has been billed yet
+		return daily, currency, nil
+	}
+
+	periods, err := c.costPeriods(ctx, &costexplorer.GetCostAndUsageInput{
+		Granularity: cetypes.GranularityDaily,
+		Metrics:     []string{basis.metric},
+		Filter:      basis.filter(),
+	}, start, end)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, period := range periods {
+		day := DailyCost{Date: period.Start}
+		for _, group := range period.Groups {
+			day.Amount += group.Amount
+			if group.Unit != "" {
+				currency = group.Unit
+			}
+		}
+		daily = append(daily, day)
+	}
+
+	return daily, currency, nil
+}
+
+// forecastSpend sets the forecast on a basis from today until the end of the
+// period and returns its currency
+func (c *CostAnalyzer) forecastSpend(ctx context.Context, basis spendBasis, period *ForecastPeriod, today time.Time, confidence int32) (string, error) {
+	result, err := c.client.GetCostForecast(ctx, &costexplorer.GetCostForecastInput{
+		TimePeriod: &cetypes.DateInterval{
+			Start: aws.String(today.Format(costDateLayout)),
+			End:   aws.String(period.End.Format(costDateLayout)),
+		},
+		Granularity:             cetypes.GranularityMonthly,
+		Metric:                  forecastMetrics[basis.metric],
+		Filter:                  basis.filter(),
+		PredictionIntervalLevel: aws.Int32(confidence),
+	})
+	c.stats.Requests++
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s-end cost forecast: %w", period.Name, err)
+	}
+
+	if result.Total != nil {
+		period.ForecastMean = parseFloat(aws.ToString(result.Total.Amount))
+	}
+
+	// The total has no prediction interval, so the monthly bounds are summed
+	for _, forecast := range result.ForecastResultsByTime {
+		period.ForecastLower += parseFloat(aws.ToString(forecast.PredictionIntervalLowerBound))
+		period.ForecastUpper += parseFloat(aws.ToString(forecast.PredictionIntervalUpperBound))
+	}
+
+	if result.Total == nil {
+		return "", nil
+	}
+	return aws.ToString(result.Total.Unit), nil
+}
+
+// forecastPeriods returns the current calendar month and quarter in UTC,
+// which is how Cost Explorer and AWS Budgets define them
+func forecastPeriods(now time.Time) []ForecastPeriod {
+	today := truncateToDay(now)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	quarterStart := time.Date(today.Year(), (today.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+
+	return []ForecastPeriod{
+		{
+			Name:     "month",
+			TimeUnit: BudgetPeriodMonthly,
+			Start:    monthStart,
+			End:      monthStart.AddDate(0, 1, 0),
+		},
+		{
+			Name:     "quarter",
+			TimeUnit: BudgetPeriodQuarterly,
+			Start:    quarterStart,
+			End:      quarterStart.AddDate(0, 3, 0),
+		},
+	}
+}
+
+// summarizePeriod sets the spend to date, burn rates and projected totals of
+// a period whose forecast is already set
+func summarizePeriod(period *ForecastPeriod, daily []DailyCost, today time.Time) {
+	period.DaysElapsed = daysBetween(period.Start, today)
+	period.DaysRemaining = daysBetween(today, period.End)
+
+	period.DailySpend = make([]DailyCost, 0, period.DaysElapsed)
+	period.ActualSpend = 0
+	for _, day := range daily {
+		date, err := time.Parse(costDateLayout, day.Date)
+		if err != nil || date.Before(period.Start) || !date.Before(today) {
+			continue
+		}
+		period.DailySpend = append(period.DailySpend, day)
+		period.ActualSpend += day.Amount
+	}
+
+	if period.DaysElapsed > 0 {
+		period.BurnRate = period.ActualSpend / float64(period.DaysElapsed)
+	}
+	if period.DaysRemaining > 0 {
+		period.ProjectedBurnRate = period.ForecastMean / float64(period.DaysRemaining)
+	}
+
+	period.ProjectedTotal = period.ActualSpend + period.ForecastMean
+	period.ProjectedLower = period.ActualSpend + period.ForecastLower
+	period.ProjectedUpper = period.ActualSpend + period.ForecastUpper
+}
+
+// evaluateBudget compares a period's spend and projection against a budget
+func evaluateBudget(period *ForecastPeriod, budget CostBudget, today time.Time) BudgetStatus {
+	basis := budget.basis()
+	status := BudgetStatus{
+		Name:                budget.Name,
+		Source:              budget.Source,
+		Period:              period.Name,
+		Limit:               budget.Limit,
+		Metric:              basis.metric,
+		ExcludedRecordTypes: basis.excluded,
+	}
+
+	if budget.Limit > 0 {
+		status.PercentUsed = period.ActualSpend / budget.Limit * 100
+		status.ProjectedPercent = period.ProjectedTotal / budget.Limit * 100
+	}
+	if period.ProjectedTotal > budget.Limit {
+		status.OverBudget = true
+		status.ProjectedOverrun = period.ProjectedTotal - budget.Limit
+	}
+	status.ExhaustionDate = exhaustionDate(period, budget.Limit, today)
+
+	return status
+}
+
+// exhaustionDate returns the day cumulative spend reaches the limit. If it
+// hasn't been reached yet, the date is extrapolated at the projected burn
+// rate. It returns an empty string if the budget lasts until the end of the period.
+func exhaustionDate(period *ForecastPeriod, limit float64, today time.Time) string {
+	spent := 0.0
+	for _, day := range period.DailySpend {
+		spent += day.Amount
+		if spent >= limit {
+			return day.Date
+		}
+	}
+
+	if period.ProjectedBurnRate <= 0 {
+		return ""
+	}
+
+	// Today's spend counts towards the first projected day
+	days := int(math.Ceil((limit - spent) / period.ProjectedBurnRate))
+	if days < 1 {
+		days = 1
+	}
+	date := today.AddDate(0, 0, days-1)
+	if !date.Before(period.End) {
+		return ""
+	}
+	return date.Format(costDateLayout)
+}
+
+// toCostBudget converts an account-wide monthly or quarterly cost budget
+func toCostBudget(budget budgetstypes.Budget) (CostBudget, bool) {
+	if budget.BudgetType != budgetstypes.BudgetTypeCost || budget.BudgetLimit == nil {
+		return CostBudget{}, false
+	}
+	if budget.TimeUnit != budgetstypes.TimeUnitMonthly && budget.TimeUnit != budgetstypes.TimeUnitQuarterly {
+		return CostBudget{}, false
+	}
+	if len(budget.CostFilters) > 0 || budget.FilterExpression != nil {
+		return CostBudget{}, false
+	}
+
+	limit := parseFloat(aws.ToString(budget.BudgetLimit.Amount))
+	if limit <= 0 {
+		return CostBudget{}, false
+	}
+
+	metric, excluded := budgetCostTypes(budget.CostTypes)
+	return CostBudget{
+		Name:                aws.ToString(budget.BudgetName),
+		Source:              BudgetSourceAWS,
+		TimeUnit:            string(budget.TimeUnit),
+		Limit:               limit,
+		Metric:              metric,
+		ExcludedRecordTypes: excluded,
+	}, true
+}
+
+// budgetCostTypes returns the Cost Explorer metric and the record types left
+// out by a budget's cost types. Budgets include credits and refunds unless
+// told otherwise.
+func budgetCostTypes(costTypes *budgetstypes.CostTypes) (string, []string) {
+	if costTypes == nil {
+		return MetricUnblendedCost, nil
+	}
+
+	metric := MetricUnblendedCost
+	switch {
+	case aws.ToBool(costTypes.UseAmortized):
+		metric = MetricAmortizedCost
+	case aws.ToBool(costTypes.UseBlended):
+		metric = MetricBlendedCost
+	}
+
+	var excluded []string
+	if costTypes.IncludeCredit != nil && !*costTypes.IncludeCredit {
+		excluded = append(excluded, "Credit")
+	}
+	if costTypes.IncludeRefund != nil && !*costTypes.IncludeRefund {
+		excluded = append(excluded, "Refund")
+	}
+	return metric, excluded
+}
+
+// spendBasis is how a budget measures spend: a Cost Explorer metric and the
+// record types it leaves out
+type spendBasis struct {
+	metric   string
+	excluded []string
+}
+
+// basis returns how the budget measures spend
+func (b CostBudget) basis() spendBasis {
+	metric := b.Metric
+	if metric == "" {
+		metric = MetricUnblendedCost
+	}
+	return spendBasis{metric: metric, excluded: b.ExcludedRecordTypes}
+}
+
+// key identifies a basis, so budgets measured the same way share one query
+func (b spendBasis) key() string {
+	return b.metric + "|" + strings.Join(b.excluded, ",")
+}
+
+// filter returns the Cost Explorer filter of a basis, or nil if it keeps
+// every record type
+func (b spendBasis) filter() *cetypes.Expression {
+	if len(b.excluded) == 0 {
+		return nil
+	}
+	expression := excludeRecordTypes(b.excluded)
+	return &expression
+}
+
+// truncateToDay returns the start of the UTC day containing t
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of whole days between two UTC dates
+func daysBetween(start, end time.Time) int {
+	return int(math.Round(end.Sub(start).Hours() / 24))
+}
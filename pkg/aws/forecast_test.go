@@ -0,0 +1,246 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	budgetstypes "github.com/aws/aws-sdk-go-v2/service/budgets/types"
+)
+
+func TestForecastPeriods(t *testing.T) {
+	tests := []struct {
+		name         string
+		now          time.Time
+		monthStart   string
+		monthEnd     string
+		quarterStart string
+		quarterEnd   string
+	}{
+		{
+			name:         "mid quarter",
+			now:          time.Date(2026, 8, 14, 15, 30, 0, 0, time.UTC),
+			monthStart:   "2026-08-01",
+			monthEnd:     "2026-09-01",
+			quarterStart: "2026-07-01",
+			quarterEnd:   "2026-10-01",
+		},
+		{
+			name:         "last quarter of the year",
+			now:          time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC),
+			monthStart:   "2026-12-01",
+			monthEnd:     "2027-01-01",
+			quarterStart: "2026-10-01",
+			quarterEnd:   "2027-01-01",
+		},
+		{
+			name:         "local time is converted to UTC",
+			now:          time.Date(2026, 4, 1, 1, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+			monthStart:   "2026-03-01",
+			monthEnd:     "2026-04-01",
+			quarterStart: "2026-01-01",
+			quarterEnd:   "2026-04-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods := forecastPeriods(tt.now)
+			if len(periods) != 2 {
+				t.Fatalf("forecastPeriods() returned %d periods, want 2", len(periods))
+			}
+
+			month, quarter := periods[0], periods[1]
+			if got := month.Start.Format(costDateLayout) + " " + month.End.Format(costDateLayout); got != tt.monthStart+" "+tt.monthEnd {
+				t.Errorf("month = %s, want %s %s", got, tt.monthStart, tt.monthEnd)
+			}
+			if got := quarter.Start.Format(costDateLayout) + " " + quarter.End.Format(costDateLayout); got != tt.quarterStart+" "+tt.quarterEnd {
+				t.Errorf("quarter = %s, want %s %s", got, tt.quarterStart, tt.quarterEnd)
+			}
+		})
+	}
+}
+
+func TestSummarizePeriod(t *testing.T) {
+	today := time.Date(2026, 6, 11, 0, 0, 0, 0, time.UTC)
+	period := ForecastPeriod{
+		Name:          "month",
+		TimeUnit:      BudgetPeriodMonthly,
+		Start:         time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		End:           time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		ForecastMean:  2000,
+		ForecastLower: 1800,
+		ForecastUpper: 2400,
+	}
+	daily := []DailyCost{
+		{Date: "2026-05-31", Amount: 500}, // previous month, part of the quarter
+	}
+	for day := 1; day <= 10; day++ {
+		daily = append(daily, DailyCost{Date: time.Date(2026, 6, day, 0, 0, 0, 0, time.UTC).Format(costDateLayout), Amount: 100})
+	}
+
+	summarizePeriod(&period, daily, today)
+
+	if period.DaysElapsed != 10 || period.DaysRemaining != 20 {
+		t.Errorf("days elapsed/remaining = %d/%d, want 10/20", period.DaysElapsed, period.DaysRemaining)
+	}
+	if period.ActualSpend != 1000 || len(period.DailySpend) != 10 {
+		t.Errorf("ActualSpend = %.2f over %d days, want 1000 over 10", period.ActualSpend, len(period.DailySpend))
+	}
+	if period.BurnRate != 100 || period.ProjectedBurnRate != 100 {
+		t.Errorf("burn rates = %.2f/%.2f, want 100/100", period.BurnRate, period.ProjectedBurnRate)
+	}
+	if period.ProjectedTotal != 3000 || period.ProjectedLower != 2800 || period.ProjectedUpper != 3400 {
+		t.Errorf("projected = %.2f (%.2f-%.2f), want 3000 (2800-3400)", period.ProjectedTotal, period.ProjectedLower, period.ProjectedUpper)
+	}
+}
+
+func TestEvaluateBudget(t *testing.T) {
+	today := time.Date(2026, 6, 11, 0, 0, 0, 0, time.UTC)
+	period := &ForecastPeriod{
+		Name:              "month",
+		Start:             time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		End:               time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		ActualSpend:       1000,
+		ProjectedTotal:    3000,
+		ProjectedBurnRate: 100,
+	}
+	for day := 1; day <= 10; day++ {
+		period.DailySpend = append(period.DailySpend, DailyCost{Date: time.Date(2026, 6, day, 0, 0, 0, 0, time.UTC).Format(costDateLayout), Amount: 100})
+	}
+
+	tests := []struct {
+		name           string
+		limit          float64
+		wantOver       bool
+		wantOverrun    float64
+		wantExhaustion string
+	}{
+		{name: "budget lasts the month", limit: 5000, wantOver: false, wantExhaustion: ""},
+		{name: "projected exactly on budget", limit: 3000, wantOver: false, wantExhaustion: "2026-06-30"},
+		{name: "exhausted later this month", limit: 2500, wantOver: true, wantOverrun: 500, wantExhaustion: "2026-06-25"},
+		{name: "already exhausted", limit: 450, wantOver: true, wantOverrun: 2550, wantExhaustion: "2026-06-05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := evaluateBudget(period, CostBudget{Name: "monthly", Source: BudgetSourceCommandLine, TimeUnit: BudgetPeriodMonthly, Limit: tt.limit}, today)
+
+			if status.OverBudget != tt.wantOver || status.ProjectedOverrun != tt.wantOverrun {
+				t.Errorf("OverBudget = %v, ProjectedOverrun = %.2f, want %v, %.2f", status.OverBudget, status.ProjectedOverrun, tt.wantOver, tt.wantOverrun)
+			}
+			if status.ExhaustionDate != tt.wantExhaustion {
+				t.Errorf("ExhaustionDate = %q, want %q", status.ExhaustionDate, tt.wantExhaustion)
+			}
+		})
+	}
+}
+
+func TestApplyBudgets(t *testing.T) {
+	results := &ForecastResults{
+		GeneratedAt: time.Date(2026, 6, 11, 9, 0, 0, 0, time.UTC),
+		Periods: []ForecastPeriod{
+			{Name: "month", TimeUnit: BudgetPeriodMonthly, ProjectedTotal: 3000},
+			{Name: "quarter", TimeUnit: BudgetPeriodQuarterly, ProjectedTotal: 9000},
+		},
+	}
+	amortized := CostBudget{Name: "amortized", TimeUnit: BudgetPeriodMonthly, Limit: 2500, Metric: MetricAmortizedCost, ExcludedRecordTypes: []string{"Credit"}}
+	measured := map[string][]ForecastPeriod{
+		amortized.basis().key(): {
+			{Name: "month", TimeUnit: BudgetPeriodMonthly, ProjectedTotal: 2000},
+			{Name: "quarter", TimeUnit: BudgetPeriodQuarterly, ProjectedTotal: 6000},
+		},
+	}
+
+	results.applyBudgets([]CostBudget{
+		{Name: "monthly", TimeUnit: BudgetPeriodMonthly, Limit: 2500},
+		{Name: "quarterly", TimeUnit: BudgetPeriodQuarterly, Limit: 10000},
+		amortized,
+	}, measured)
+
+	if len(results.Periods[0].Budgets) != 2 || len(results.Periods[1].Budgets) != 1 {
+		t.Fatalf("budgets per period = %d/%d, want 2/1", len(results.Periods[0].Budgets), len(results.Periods[1].Budgets))
+	}
+
+	// The amortized budget is compared against its own projection, which is under the limit
+	over := results.OverBudget()
+	if len(over) != 1 || over[0].Name != "monthly" || over[0].Period != "month" {
+		t.Errorf("OverBudget() = %+v, want the monthly budget", over)
+	}
+	if status := results.Periods[0].Budgets[1]; status.Metric != MetricAmortizedCost || status.ProjectedPercent != 80 {
+		t.Errorf("amortized budget = %+v, want AmortizedCost at 80%%", status)
+	}
+}
+
+func TestBudgetCostTypes(t *testing.T) {
+	tests := []struct {
+		name         string
+		costTypes    *budgetstypes.CostTypes
+		wantMetric   string
+		wantExcluded string
+	}{
+		{name: "defaults", costTypes: nil, wantMetric: MetricUnblendedCost},
+		{name: "amortized", costTypes: &budgetstypes.CostTypes{UseAmortized: aws.Bool(true)}, wantMetric: MetricAmortizedCost},
+		{name: "blended", costTypes: &budgetstypes.CostTypes{UseBlended: aws.Bool(true)}, wantMetric: MetricBlendedCost},
+		{
+			name:         "without credits and refunds",
+			costTypes:    &budgetstypes.CostTypes{IncludeCredit: aws.Bool(false), IncludeRefund: aws.Bool(false), IncludeTax: aws.Bool(true)},
+			wantMetric:   MetricUnblendedCost,
+			wantExcluded: "Credit,Refund",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric, excluded := budgetCostTypes(tt.costTypes)
+			if metric != tt.wantMetric || strings.Join(excluded, ",") != tt.wantExcluded {
+				t.Errorf("budgetCostTypes() = %s, %v, want %s, %s", metric, excluded, tt.wantMetric, tt.wantExcluded)
+			}
+		})
+	}
+}
+
+func TestToCostBudget(t *testing.T) {
+	limit := &budgetstypes.Spend{Amount: aws.String("5000.0"), Unit: aws.String("USD")}
+
+	tests := []struct {
+		name   string
+		budget budgetstypes.Budget
+		wantOK bool
+	}{
+		{
+			name:   "monthly cost budget",
+			budget: budgetstypes.Budget{BudgetName: aws.String("monthly"), BudgetType: budgetstypes.BudgetTypeCost, TimeUnit: budgetstypes.TimeUnitMonthly, BudgetLimit: limit},
+			wantOK: true,
+		},
+		{
+			name:   "usage budget",
+			budget: budgetstypes.Budget{BudgetName: aws.String("hours"), BudgetType: budgetstypes.BudgetTypeUsage, TimeUnit: budgetstypes.TimeUnitMonthly, BudgetLimit: limit},
+			wantOK: false,
+		},
+		{
+			name:   "annual budget",
+			budget: budgetstypes.Budget{BudgetName: aws.String("yearly"), BudgetType: budgetstypes.BudgetTypeCost, TimeUnit: budgetstypes.TimeUnitAnnually, BudgetLimit: limit},
+			wantOK: false,
+		},
+		{
+			name: "filtered to one service",
+			budget: budgetstypes.Budget{BudgetName: aws.String("ec2"), BudgetType: budgetstypes.BudgetTypeCost, TimeUnit: budgetstypes.TimeUnitMonthly, BudgetLimit: limit,
+				CostFilters: map[string][]string{"Service": {"Amazon Elastic Compute Cloud - Compute"}}},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget, ok := toCostBudget(tt.budget)
+			if ok != tt.wantOK {
+				t.Fatalf("toCostBudget() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && (budget.Limit != 5000 || budget.Source != BudgetSourceAWS || budget.TimeUnit != BudgetPeriodMonthly) {
+				t.Errorf("toCostBudget() = %+v", budget)
+			}
+		})
+	}
+}
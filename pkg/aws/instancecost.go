@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// HoursPerMonth is the number of hours the monthly instance estimates cover
+const HoursPerMonth = 730
+
+// describeInstancesBatch is the most instance IDs sent in one filter
+const describeInstancesBatch = 200
+
+// InstanceCost is the estimated on-demand price of a running EC2 instance
+type InstanceCost struct {
+	InstanceID   string
+	InstanceType string
+	HourlyCost   float64
+	// Priced is false when there is no price estimate for the instance type
+	Priced bool
+}
+
+// GetInstanceCosts looks up the type of each instance and estimates its hourly
+// cost with the same prices as the idle instance audit. Instances that no
+// longer exist are left out of the result.
+func (a *Auditor) GetInstanceCosts(ctx context.Context, instanceIDs []string) (map[string]InstanceCost, error) {
+	costs := make(map[string]InstanceCost, len(instanceIDs))
+
+	for start := 0; start < len(instanceIDs); start += describeInstancesBatch {
+		end := min(start+describeInstancesBatch, len(instanceIDs))
+
+		// A filter, unlike InstanceIds, does not fail on unknown instances
+		paginator := ec2.NewDescribeInstancesPaginator(a.ec2Client, &ec2.DescribeInstancesInput{
+			Filters: []ec2types.Filter{{
+				Name:   aws.String("instance-id"),
+				Values: instanceIDs[start:end],
+			}},
+		})
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe instances: %w", err)
+			}
+			for _, reservation := range output.Reservations {
+				for _, instance := range reservation.Instances {
+					instanceType := string(instance.InstanceType)
+					hourly, priced := InstanceHourlyCost(instanceType)
+					costs[aws.ToString(instance.InstanceId)] = InstanceCost{
+						InstanceID:   aws.ToString(instance.InstanceId),
+						InstanceType: instanceType,
+						HourlyCost:   hourly,
+						Priced:       priced,
+					}
+				}
+			}
+		}
+	}
+
+	return costs, nil
+}
+
+// InstanceHourlyCost returns the estimated on-demand hourly price of an
+// instance type, and false when there is no estimate for the type. Unlike the
+// idle instance audit, unknown types are not given a default price.
+func InstanceHourlyCost(instanceType string) (float64, bool) {
+	cost, ok := ec2MonthlyCosts[instanceType]
+	if !ok {
+		return 0, false
+	}
+	return cost / HoursPerMonth, true
+}
@@ -0,0 +1,233 @@
+package aws
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// ResourceTypeEC2Instance is the resource type reported for EC2 instance findings
+const ResourceTypeEC2Instance = "EC2 Instance"
+
+// maxIMDSHopLimit is the highest IMDS hop limit that keeps tokens from
+// reaching containers or other hops beyond the instance
+const maxIMDSHopLimit = 1
+
+// SecretPattern identifies a type of secret in instance user data
+type SecretPattern struct {
+	Type     string
+	Pattern  *regexp.Regexp
+	Severity Severity
+}
+
+// UserDataSecretPatterns lists the secret types detected in instance user data
+var UserDataSecretPatterns = []SecretPattern{
+	{
+		Type:     "AWS access key ID",
+		Pattern:  regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+		Severity: SeverityCritical,
+	},
+	{
+		Type:     "AWS secret access key",
+		Pattern:  regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}`),
+		Severity: SeverityCritical,
+	},
+	{
+		Type:     "private key",
+		Pattern:  regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH|ENCRYPTED) )?PRIVATE KEY-----`),
+		Severity: SeverityCritical,
+	},
+	{
+		// Values referencing a variable ($DB_PASSWORD) or a file path are not
+		// literal secrets, and "pwd" is left out as it matches $PWD in scripts
+		Type:     "password",
+		Pattern:  regexp.MustCompile(`(?i)(password|passwd)["']?\s*[:=]\s*["']?[^\s"'$/~][^\s"'$]{3,}`),
+		Severity: SeverityHigh,
+	},
+}
+
+// CheckInstanceMetadata finds instances that allow IMDSv1 or whose IMDS hop
+// limit lets tokens travel beyond the instance
+func (s *SecurityAuditor) CheckInstanceMetadata(ctx context.Context) ([]SecurityFinding, error) {
+	instances, err := s.activeInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]SecurityFinding, 0)
+	for _, instance := range instances {
+		findings = append(findings, evaluateInstanceMetadata(instance, s.region)...)
+	}
+
+	s.countResources(len(instances), CheckIDIMDSv2, CheckIDIMDSHopLimit)
+	return findings, nil
+}
+
+// CheckUserDataSecrets finds instances whose user data contains secrets.
+// Only the type of secret is reported, never its value. The check fails if
+// the user data of any instance cannot be read, so that unreadable instances
+// are not reported as clean.
+func (s *SecurityAuditor) CheckUserDataSecrets(ctx context.Context) ([]SecurityFinding, error) {
+	instances, err := s.activeInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]SecurityFinding, 0)
+	failed := 0
+	var firstErr error
+	for _, instance := range instances {
+		instanceID := aws.ToString(instance.InstanceId)
+
+		attr, err := s.ec2Client.DescribeInstanceAttribute(ctx, &ec2.DescribeInstanceAttributeInput{
+			InstanceId: instance.InstanceId,
+			Attribute:  ec2types.InstanceAttributeNameUserData,
+		})
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", instanceID, err)
+			}
+			continue
+		}
+		if attr.UserData == nil {
+			// No user data to scan
+			continue
+		}
+
+		userData, err := decodeUserData(aws.ToString(attr.UserData.Value))
+		if err != nil {
+			continue
+		}
+
+		for _, pattern := range scanForSecrets(userData) {
+			findings = append(findings, SecurityFinding{
+				CheckID:      CheckIDUserDataSecrets,
+				ResourceType: ResourceTypeEC2Instance,
+				ResourceID:   instanceID,
+				Region:       s.region,
+				Severity:     pattern.Severity,
+				Description:  fmt.Sprintf("User data contains a %s", pattern.Type),
+				Key:          pattern.Type,
+			})
+		}
+	}
+
+	if failed > 0 {
+		return nil, fmt.Errorf("failed to read user data of %d of %d instance(s): %w", failed, len(instances), firstErr)
+	}
+
+	return findings, nil
+}
+
+// activeInstances returns instances that are not terminated. They are listed
+// once per auditor and shared by the instance checks.
+func (s *SecurityAuditor) activeInstances(ctx context.Context) ([]ec2types.Instance, error) {
+	if s.instances != nil {
+		return s.instances, nil
+	}
+
+	instances := make([]ec2types.Instance, 0)
+
+	paginator := ec2.NewDescribeInstancesPaginator(s.ec2Client, &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: []string{"pending", "running", "stopping", "stopped"},
+			},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe instances: %w", err)
+		}
+
+		for _, reservation := range page.Reservations {
+			instances = append(instances, reservation.Instances...)
+		}
+	}
+
+	s.instances = instances
+	return instances, nil
+}
+
+// evaluateInstanceMetadata checks the instance metadata options of a single instance
+func evaluateInstanceMetadata(instance ec2types.Instance, region string) []SecurityFinding {
+	findings := make([]SecurityFinding, 0)
+
+	options := instance.MetadataOptions
+	if options == nil || options.HttpEndpoint == ec2types.InstanceMetadataEndpointStateDisabled {
+		// No metadata endpoint, nothing to exploit
+		return findings
+	}
+
+	instanceID := aws.ToString(instance.InstanceId)
+
+	if options.HttpTokens != ec2types.HttpTokensStateRequired {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDIMDSv2,
+			ResourceType: ResourceTypeEC2Instance,
+			ResourceID:   instanceID,
+			Region:       region,
+			Severity:     SeverityHigh,
+			Description:  "IMDSv2 is not enforced (HttpTokens=optional)",
+		})
+	}
+
+	if hopLimit := aws.ToInt32(options.HttpPutResponseHopLimit); hopLimit > maxIMDSHopLimit {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDIMDSHopLimit,
+			ResourceType: ResourceTypeEC2Instance,
+			ResourceID:   instanceID,
+			Region:       region,
+			Severity:     SeverityMedium,
+			Description:  fmt.Sprintf("IMDS hop limit is %d (expected %d)", hopLimit, maxIMDSHopLimit),
+		})
+	}
+
+	return findings
+}
+
+// decodeUserData base64-decodes user data, decompressing it when gzipped
+func decodeUserData(encoded string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode user data: %w", err)
+	}
+
+	// cloud-init accepts gzip-compressed user data
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress user data: %w", err)
+		}
+		defer reader.Close()
+
+		data, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress user data: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// scanForSecrets returns the secret patterns that match the user data
+func scanForSecrets(userData []byte) []SecretPattern {
+	matches := make([]SecretPattern, 0)
+	for _, pattern := range UserDataSecretPatterns {
+		if pattern.Pattern.Match(userData) {
+			matches = append(matches, pattern)
+		}
+	}
+	return matches
+}
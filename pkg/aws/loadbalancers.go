@@ -0,0 +1,415 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	cftypes "github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+)
+
+// Resource types reported for load balancer and CloudFront findings
+const (
+	ResourceTypeLoadBalancer           = "Load Balancer"
+	ResourceTypeListener               = "Load Balancer Listener"
+	ResourceTypeCloudFrontDistribution = "CloudFront Distribution"
+)
+
+// OutdatedTLSProtocols lists protocol versions that should no longer be
+// negotiated by internet-facing endpoints
+var OutdatedTLSProtocols = map[string]bool{
+	"SSLv3":   true,
+	"TLSv1":   true,
+	"TLSv1.1": true,
+}
+
+// outdatedCloudFrontProtocols lists CloudFront security policies that still
+// allow SSLv3, TLS 1.0 or TLS 1.1
+var outdatedCloudFrontProtocols = map[cftypes.MinimumProtocolVersion]bool{
+	cftypes.MinimumProtocolVersionSSLv3:      true,
+	cftypes.MinimumProtocolVersionTLSv1:      true,
+	cftypes.MinimumProtocolVersionTLSv12016:  true,
+	cftypes.MinimumProtocolVersionTLSv112016: true,
+}
+
+// CheckLoadBalancers finds ELBv2 listeners that use outdated SSL policies,
+// serve plain HTTP without redirecting to HTTPS, or present certificates that
+// are expired or about to expire
+func (s *SecurityAuditor) CheckLoadBalancers(ctx context.Context) ([]SecurityFinding, error) {
+	loadBalancers, err := s.describeLoadBalancers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	certs, err := certificatesByARN(ctx, s.certificates)
+	if err != nil {
+		return nil, err
+	}
+
+	// Many listeners share a handful of policies, so look each one up once
+	policies := make(map[string][]string)
+
+	findings := make([]SecurityFinding, 0)
+	for _, lb := range loadBalancers {
+		listeners := elasticloadbalancingv2.NewDescribeListenersPaginator(s.elbv2Client, &elasticloadbalancingv2.DescribeListenersInput{
+			LoadBalancerArn: lb.LoadBalancerArn,
+		})
+		for listeners.HasMorePages() {
+			page, err := listeners.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe listeners for %s: %w", aws.ToString(lb.LoadBalancerName), err)
+			}
+
+			for _, listener := range page.Listeners {
+				var protocols []string
+				if policy := aws.ToString(listener.SslPolicy); policy != "" {
+					if _, ok := policies[policy]; !ok {
+						policies[policy], err = s.describeSSLPolicy(ctx, policy)
+						if err != nil {
+							return nil, err
+						}
+					}
+					protocols = policies[policy]
+				}
+				findings = append(findings, evaluateListener(lb, listener, protocols, s.region)...)
+
+				if listener.Protocol != elbv2types.ProtocolEnumHttps && listener.Protocol != elbv2types.ProtocolEnumTls {
+					continue
+				}
+				arns, err := s.listenerCertificateARNs(ctx, listener)
+				if err != nil {
+					return nil, err
+				}
+				findings = append(findings, certificateFindings(CheckIDELBExpiringCertificate, ResourceTypeListener,
+					aws.ToString(listener.ListenerArn), s.region, arns, certs)...)
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// CheckLoadBalancerWAF finds internet-facing application load balancers that
+// have no WAF web ACL associated
+func (s *SecurityAuditor) CheckLoadBalancerWAF(ctx context.Context) ([]SecurityFinding, error) {
+	loadBalancers, err := s.describeLoadBalancers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]SecurityFinding, 0)
+	for _, lb := range loadBalancers {
+		// WAF can only be attached to application load balancers
+		if lb.Type != elbv2types.LoadBalancerTypeEnumApplication || lb.Scheme != elbv2types.LoadBalancerSchemeEnumInternetFacing {
+			continue
+		}
+
+		result, err := s.wafClient.GetWebACLForResource(ctx, &wafv2.GetWebACLForResourceInput{
+			ResourceArn: lb.LoadBalancerArn,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get web ACL for %s: %w", aws.ToString(lb.LoadBalancerName), err)
+		}
+		if result.WebACL != nil {
+			continue
+		}
+
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDELBNoWAF,
+			ResourceType: ResourceTypeLoadBalancer,
+			ResourceID:   aws.ToString(lb.LoadBalancerArn),
+			Region:       s.region,
+			Severity:     SeverityMedium,
+			Description:  fmt.Sprintf("Internet-facing load balancer %s has no WAF web ACL", aws.ToString(lb.LoadBalancerName)),
+		})
+	}
+
+	return findings, nil
+}
+
+// CheckCloudFrontDistributions finds distributions that allow outdated TLS
+// versions or plain HTTP, use expiring certificates, or have no WAF web ACL
+func (s *SecurityAuditor) CheckCloudFrontDistributions(ctx context.Context) ([]SecurityFinding, error) {
+	certs, err := certificatesByARN(ctx, s.cloudfrontCertificates)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]SecurityFinding, 0)
+	paginator := cloudfront.NewListDistributionsPaginator(s.cloudfrontClient, &cloudfront.ListDistributionsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list CloudFront distributions: %w", err)
+		}
+		if page.DistributionList == nil {
+			continue
+		}
+		for _, dist := range page.DistributionList.Items {
+			findings = append(findings, evaluateDistribution(dist, s.region, certs)...)
+		}
+	}
+
+	return findings, nil
+}
+
+// describeLoadBalancers returns all application, network and gateway load
+// balancers. They are listed once per auditor and shared by the checks.
+func (s *SecurityAuditor) describeLoadBalancers(ctx context.Context) ([]elbv2types.LoadBalancer, error) {
+	if s.loadBalancers != nil {
+		return s.loadBalancers, nil
+	}
+
+	paginator := elasticloadbalancingv2.NewDescribeLoadBalancersPaginator(s.elbv2Client, &elasticloadbalancingv2.DescribeLoadBalancersInput{})
+
+	loadBalancers := make([]elbv2types.LoadBalancer, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe load balancers: %w", err)
+		}
+		loadBalancers = append(loadBalancers, page.LoadBalancers...)
+	}
+
+	s.loadBalancers = loadBalancers
+	return loadBalancers, nil
+}
+
+// describeSSLPolicy returns the protocol versions an ELBv2 SSL policy allows
+func (s *SecurityAuditor) describeSSLPolicy(ctx context.Context, name string) ([]string, error) {
+	result, err := s.elbv2Client.DescribeSSLPolicies(ctx, &elasticloadbalancingv2.DescribeSSLPoliciesInput{
+		Names: []string{name},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe SSL policy %s: %w", name, err)
+	}
+
+	protocols := make([]string, 0)
+	for _, policy := range result.SslPolicies {
+		protocols = append(protocols, policy.SslProtocols...)
+	}
+	return protocols, nil
+}
+
+// listenerCertificateARNs returns the default and SNI certificates of a listener
+func (s *SecurityAuditor) listenerCertificateARNs(ctx context.Context, listener elbv2types.Listener) ([]string, error) {
+	paginator := elasticloadbalancingv2.NewDescribeListenerCertificatesPaginator(s.elbv2Client, &elasticloadbalancingv2.DescribeListenerCertificatesInput{
+		ListenerArn: listener.ListenerArn,
+	})
+
+	arns := make([]string, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe listener certificates: %w", err)
+		}
+		for _, cert := range page.Certificates {
+			arns = append(arns, aws.ToString(cert.CertificateArn))
+		}
+	}
+
+	return arns, nil
+}
+
+// certificatesByARN indexes the ACM and IAM server certificates visible to the
+// auditor by ARN so listener and distribution certificates can be looked up
+func certificatesByARN(ctx context.Context, auditor *CertificateAuditor) (map[string]Certificate, error) {
+	now := time.Now()
+
+	acmCerts, err := auditor.ListACMCertificates(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	iamCerts, err := auditor.ListIAMServerCertificates(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := make(map[string]Certificate, len(acmCerts)+len(iamCerts))
+	for _, cert := range append(acmCerts, iamCerts...) {
+		certs[cert.ARN] = cert
+	}
+	return certs, nil
+}
+
+// evaluateListener checks a listener's SSL policy and, for plain HTTP
+// listeners on internet-facing load balancers, its redirect to HTTPS
+func evaluateListener(lb elbv2types.LoadBalancer, listener elbv2types.Listener, policyProtocols []string, region string) []SecurityFinding {
+	findings := make([]SecurityFinding, 0)
+	listenerID := aws.ToString(listener.ListenerArn)
+	name := fmt.Sprintf("%s:%d", aws.ToString(lb.LoadBalancerName), aws.ToInt32(listener.Port))
+
+	if outdated := outdatedProtocols(policyProtocols); len(outdated) > 0 {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDELBOutdatedTLSPolicy,
+			ResourceType: ResourceTypeListener,
+			ResourceID:   listenerID,
+			Region:       region,
+			Severity:     SeverityHigh,
+			Description: fmt.Sprintf("Listener %s uses SSL policy %s which allows %s",
+				name, aws.ToString(listener.SslPolicy), strings.Join(outdated, ", ")),
+		})
+	}
+
+	// Internal listeners commonly terminate plain HTTP behind a proxy
+	if listener.Protocol == elbv2types.ProtocolEnumHttp &&
+		lb.Scheme == elbv2types.LoadBalancerSchemeEnumInternetFacing &&
+		!redirectsToHTTPS(listener.DefaultActions) {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDELBHTTPNoRedirect,
+			ResourceType: ResourceTypeListener,
+			ResourceID:   listenerID,
+			Region:       region,
+			Severity:     SeverityMedium,
+			Description:  fmt.Sprintf("HTTP listener %s does not redirect to HTTPS", name),
+		})
+	}
+
+	return findings
+}
+
+// evaluateDistribution checks a CloudFront distribution's TLS settings,
+// viewer protocol policies, certificate expiry and WAF association
+func evaluateDistribution(dist cftypes.DistributionSummary, region string, certs map[string]Certificate) []SecurityFinding {
+	findings := make([]SecurityFinding, 0)
+	distID := aws.ToString(dist.Id)
+
+	// Distributions using the default *.cloudfront.net certificate can't change
+	// their security policy
+	if viewer := dist.ViewerCertificate; viewer != nil && !aws.ToBool(viewer.CloudFrontDefaultCertificate) {
+		if outdatedCloudFrontProtocols[viewer.MinimumProtocolVersion] {
+			findings = append(findings, SecurityFinding{
+				CheckID:      CheckIDCloudFrontOutdatedTLS,
+				ResourceType: ResourceTypeCloudFrontDistribution,
+				ResourceID:   distID,
+				Region:       region,
+				Severity:     SeverityHigh,
+				Description:  fmt.Sprintf("Distribution uses security policy %s which allows TLS below 1.2", viewer.MinimumProtocolVersion),
+			})
+		}
+
+		if arn := aws.ToString(viewer.ACMCertificateArn); arn != "" {
+			findings = append(findings, certificateFindings(CheckIDCloudFrontExpiringCertificate, ResourceTypeCloudFrontDistribution,
+				distID, region, []string{arn}, certs)...)
+		}
+	}
+
+	for _, path := range allowAllPaths(dist) {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDCloudFrontHTTPAllowed,
+			ResourceType: ResourceTypeCloudFrontDistribution,
+			ResourceID:   distID,
+			Region:       region,
+			Severity:     SeverityMedium,
+			Description:  fmt.Sprintf("Cache behavior %s allows viewers to use plain HTTP", path),
+			Key:          path,
+		})
+	}
+
+	if aws.ToString(dist.WebACLId) == "" {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDCloudFrontNoWAF,
+			ResourceType: ResourceTypeCloudFrontDistribution,
+			ResourceID:   distID,
+			Region:       region,
+			Severity:     SeverityMedium,
+			Description:  fmt.Sprintf("Distribution %s has no WAF web ACL", aws.ToString(dist.DomainName)),
+		})
+	}
+
+	return findings
+}
+
+// certificateFindings reports certificates that are expired or expire soon.
+// Certificates not found in certs, such as those in other accounts, are skipped.
+func certificateFindings(checkID, resourceType, resourceID, region string, arns []string, certs map[string]Certificate) []SecurityFinding {
+	findings := make([]SecurityFinding, 0)
+	for _, arn := range arns {
+		cert, ok := certs[arn]
+		if !ok {
+			continue
+		}
+
+		severity, ok := certificateSeverity(cert.Status)
+		if !ok {
+			continue
+		}
+
+		// The expiry date rather than the days remaining keeps the description
+		// the same from one run to the next
+		description := fmt.Sprintf("Certificate %s expires on %s", cert.Name, cert.ExpiryDate.Format("2006-01-02"))
+		if cert.Status == CertificateExpired {
+			description = fmt.Sprintf("Certificate %s expired on %s", cert.Name, cert.ExpiryDate.Format("2006-01-02"))
+		}
+
+		findings = append(findings, SecurityFinding{
+			CheckID:      checkID,
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			Region:       region,
+			Severity:     severity,
+			Description:  description,
+			Key:          arn,
+		})
+	}
+	return findings
+}
+
+// certificateSeverity maps a certificate expiry status to a finding severity.
+// It returns false for certificates that are still valid.
+func certificateSeverity(status string) (Severity, bool) {
+	switch status {
+	case CertificateExpired:
+		return SeverityCritical, true
+	case CertificateCritical:
+		return SeverityHigh, true
+	case CertificateExpiringSoon:
+		return SeverityMedium, true
+	default:
+		return "", false
+	}
+}
+
+// outdatedProtocols returns the outdated protocol versions in the list
+func outdatedProtocols(protocols []string) []string {
+	outdated := make([]string, 0)
+	for _, protocol := range protocols {
+		if OutdatedTLSProtocols[protocol] {
+			outdated = append(outdated, protocol)
+		}
+	}
+	return outdated
+}
+
+// redirectsToHTTPS reports whether the listener's default actions redirect to HTTPS
+func redirectsToHTTPS(actions []elbv2types.Action) bool {
+	for _, action := range actions {
+		if action.Type == elbv2types.ActionTypeEnumRedirect && action.RedirectConfig != nil &&
+			strings.EqualFold(aws.ToString(action.RedirectConfig.Protocol), "HTTPS") {
+			return true
+		}
+	}
+	return false
+}
+
+// allowAllPaths returns the path patterns of cache behaviors that accept plain HTTP
+func allowAllPaths(dist cftypes.DistributionSummary) []string {
+	paths := make([]string, 0)
+	if dist.DefaultCacheBehavior != nil && dist.DefaultCacheBehavior.ViewerProtocolPolicy == cftypes.ViewerProtocolPolicyAllowAll {
+		paths = append(paths, "(default)")
+	}
+	if dist.CacheBehaviors != nil {
+		for _, behavior := range dist.CacheBehaviors.Items {
+			if behavior.ViewerProtocolPolicy == cftypes.ViewerProtocolPolicyAllowAll {
+				paths = append(paths, aws.ToString(behavior.PathPattern))
+			}
+		}
+	}
+	return paths
+}
@@ -0,0 +1,189 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cftypes "github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+)
+
+func TestEvaluateListener(t *testing.T) {
+	internetFacing := elbv2types.LoadBalancer{
+		LoadBalancerName: aws.String("web"),
+		Scheme:           elbv2types.LoadBalancerSchemeEnumInternetFacing,
+	}
+	internal := elbv2types.LoadBalancer{
+		LoadBalancerName: aws.String("api"),
+		Scheme:           elbv2types.LoadBalancerSchemeEnumInternal,
+	}
+	redirect := []elbv2types.Action{{
+		Type:           elbv2types.ActionTypeEnumRedirect,
+		RedirectConfig: &elbv2types.RedirectActionConfig{Protocol: aws.String("HTTPS")},
+	}}
+	forward := []elbv2types.Action{{Type: elbv2types.ActionTypeEnumForward}}
+
+	tests := []struct {
+		name      string
+		lb        elbv2types.LoadBalancer
+		listener  elbv2types.Listener
+		protocols []string
+		wantCheck []string
+	}{
+		{
+			name:      "https with modern policy",
+			lb:        internetFacing,
+			listener:  elbv2types.Listener{Protocol: elbv2types.ProtocolEnumHttps, Port: aws.Int32(443), SslPolicy: aws.String("ELBSecurityPolicy-TLS13-1-2-2021-06")},
+			protocols: []string{"TLSv1.2", "TLSv1.3"},
+			wantCheck: []string{},
+		},
+		{
+			name:      "https allowing tls 1.0",
+			lb:        internetFacing,
+			listener:  elbv2types.Listener{Protocol: elbv2types.ProtocolEnumHttps, Port: aws.Int32(443), SslPolicy: aws.String("ELBSecurityPolicy-2016-08")},
+			protocols: []string{"TLSv1", "TLSv1.1", "TLSv1.2"},
+			wantCheck: []string{CheckIDELBOutdatedTLSPolicy},
+		},
+		{
+			name:      "http redirecting to https",
+			lb:        internetFacing,
+			listener:  elbv2types.Listener{Protocol: elbv2types.ProtocolEnumHttp, Port: aws.Int32(80), DefaultActions: redirect},
+			wantCheck: []string{},
+		},
+		{
+			name:      "http forwarding on internet-facing",
+			lb:        internetFacing,
+			listener:  elbv2types.Listener{Protocol: elbv2types.ProtocolEnumHttp, Port: aws.Int32(80), DefaultActions: forward},
+			wantCheck: []string{CheckIDELBHTTPNoRedirect},
+		},
+		{
+			name:      "http forwarding on internal",
+			lb:        internal,
+			listener:  elbv2types.Listener{Protocol: elbv2types.ProtocolEnumHttp, Port: aws.Int32(80), DefaultActions: forward},
+			wantCheck: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := evaluateListener(tt.lb, tt.listener, tt.protocols, "us-east-1")
+
+			if len(findings) != len(tt.wantCheck) {
+				t.Fatalf("evaluateListener() returned %d findings, want %d: %+v", len(findings), len(tt.wantCheck), findings)
+			}
+			for i, check := range tt.wantCheck {
+				if findings[i].CheckID != check {
+					t.Errorf("findings[%d].CheckID = %q, want %q", i, findings[i].CheckID, check)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateDistribution(t *testing.T) {
+	certs := map[string]Certificate{
+		"arn:expired": {Name: "old.example.com", DaysRemaining: -3, Status: "expired"},
+		"arn:valid":   {Name: "www.example.com", DaysRemaining: 200, Status: "valid"},
+	}
+
+	tests := []struct {
+		name      string
+		dist      cftypes.DistributionSummary
+		wantCheck []string
+	}{
+		{
+			name: "secure distribution",
+			dist: cftypes.DistributionSummary{
+				ViewerCertificate: &cftypes.ViewerCertificate{
+					ACMCertificateArn:      aws.String("arn:valid"),
+					MinimumProtocolVersion: cftypes.MinimumProtocolVersionTLSv122021,
+				},
+				DefaultCacheBehavior: &cftypes.DefaultCacheBehavior{ViewerProtocolPolicy: cftypes.ViewerProtocolPolicyRedirectToHttps},
+				WebACLId:             aws.String("arn:aws:wafv2:us-east-1:111111111111:global/webacl/edge/abc"),
+			},
+			wantCheck: []string{},
+		},
+		{
+			name: "default certificate is not flagged for tls",
+			dist: cftypes.DistributionSummary{
+				ViewerCertificate: &cftypes.ViewerCertificate{
+					CloudFrontDefaultCertificate: aws.Bool(true),
+					MinimumProtocolVersion:       cftypes.MinimumProtocolVersionTLSv1,
+				},
+				DefaultCacheBehavior: &cftypes.DefaultCacheBehavior{ViewerProtocolPolicy: cftypes.ViewerProtocolPolicyHttpsOnly},
+				WebACLId:             aws.String("web-acl"),
+			},
+			wantCheck: []string{},
+		},
+		{
+			name: "insecure distribution",
+			dist: cftypes.DistributionSummary{
+				ViewerCertificate: &cftypes.ViewerCertificate{
+					ACMCertificateArn:      aws.String("arn:expired"),
+					MinimumProtocolVersion: cftypes.MinimumProtocolVersionTLSv12016,
+				},
+				DefaultCacheBehavior: &cftypes.DefaultCacheBehavior{ViewerProtocolPolicy: cftypes.ViewerProtocolPolicyAllowAll},
+				CacheBehaviors: &cftypes.CacheBehaviors{Items: []cftypes.CacheBehavior{
+					{PathPattern: aws.String("/static/*"), ViewerProtocolPolicy: cftypes.ViewerProtocolPolicyAllowAll},
+					{PathPattern: aws.String("/api/*"), ViewerProtocolPolicy: cftypes.ViewerProtocolPolicyHttpsOnly},
+				}},
+			},
+			wantCheck: []string{
+				CheckIDCloudFrontOutdatedTLS,
+				CheckIDCloudFrontExpiringCertificate,
+				CheckIDCloudFrontHTTPAllowed,
+				CheckIDCloudFrontHTTPAllowed,
+				CheckIDCloudFrontNoWAF,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.dist.Id = aws.String("E2ABC123")
+			findings := evaluateDistribution(tt.dist, "us-east-1", certs)
+
+			got := make([]string, 0, len(findings))
+			for _, f := range findings {
+				got = append(got, f.CheckID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantCheck, ",") {
+				t.Errorf("evaluateDistribution() = %v, want %v", got, tt.wantCheck)
+			}
+		})
+	}
+}
+
+func TestCertificateFindings(t *testing.T) {
+	certs := map[string]Certificate{
+		"arn:expired":  {Name: "old.example.com", ExpiryDate: time.Date(2025, 5, 29, 0, 0, 0, 0, time.UTC), DaysRemaining: -3, Status: "expired"},
+		"arn:critical": {Name: "soon.example.com", DaysRemaining: 5, Status: "critical"},
+		"arn:expiring": {Name: "later.example.com", DaysRemaining: 20, Status: "expiring-soon"},
+		"arn:valid":    {Name: "www.example.com", DaysRemaining: 200, Status: "valid"},
+	}
+	arns := []string{"arn:expired", "arn:critical", "arn:expiring", "arn:valid", "arn:other-account"}
+
+	findings := certificateFindings(CheckIDELBExpiringCertificate, ResourceTypeListener, "arn:listener", "us-east-1", arns, certs)
+
+	wantSeverities := []Severity{SeverityCritical, SeverityHigh, SeverityMedium}
+	if len(findings) != len(wantSeverities) {
+		t.Fatalf("certificateFindings() returned %d findings, want %d: %+v", len(findings), len(wantSeverities), findings)
+	}
+	for i, severity := range wantSeverities {
+		if findings[i].Severity != severity {
+			t.Errorf("findings[%d].Severity = %q, want %q", i, findings[i].Severity, severity)
+		}
+	}
+	if findings[0].Description != "Certificate old.example.com expired on 2025-05-29" {
+		t.Errorf("findings[0].Description = %q", findings[0].Description)
+	}
+}
+
+func TestOutdatedProtocols(t *testing.T) {
+	got := outdatedProtocols([]string{"SSLv3", "TLSv1", "TLSv1.1", "TLSv1.2", "TLSv1.3"})
+	if strings.Join(got, ",") != "SSLv3,TLSv1,TLSv1.1" {
+		t.Errorf("outdatedProtocols() = %v, want [SSLv3 TLSv1 TLSv1.1]", got)
+	}
+}
@@ -0,0 +1,176 @@
+package aws
+
+// SecurityRule describes a security check for export formats such as SARIF and ASFF
+type SecurityRule struct {
+	ID          string
+	Title       string
+	Description string
+	Severity    Severity
+}
+
+// SecurityRules lists the rule metadata for every security check, keyed by check ID
+var SecurityRules = map[string]SecurityRule{
+	CheckIDPublicS3Buckets: {
+		ID:          CheckIDPublicS3Buckets,
+		Title:       "S3 bucket allows public access",
+		Description: "The bucket has a public ACL or its public access block is not fully enabled.",
+		Severity:    SeverityCritical,
+	},
+	CheckIDOpenSecurityGroups: {
+		ID:          CheckIDOpenSecurityGroups,
+		Title:       "Security group exposes a risky port to the internet",
+		Description: "An ingress rule allows 0.0.0.0/0 or ::/0 to reach SSH, RDP or a database port.",
+		Severity:    SeverityCritical,
+	},
+	CheckIDUnencryptedVolumes: {
+		ID:          CheckIDUnencryptedVolumes,
+		Title:       "EBS volume is not encrypted",
+		Description: "The EBS volume is not encrypted at rest.",
+		Severity:    SeverityMedium,
+	},
+	CheckIDUnencryptedSnapshots: {
+		ID:          CheckIDUnencryptedSnapshots,
+		Title:       "EBS snapshot is not encrypted",
+		Description: "The EBS snapshot is not encrypted at rest.",
+		Severity:    SeverityMedium,
+	},
+	CheckIDRDSPublicAccess: {
+		ID:          CheckIDRDSPublicAccess,
+		Title:       "RDS instance is publicly accessible",
+		Description: "The RDS instance has a public endpoint reachable from the internet.",
+		Severity:    SeverityCritical,
+	},
+	CheckIDRDSEncryption: {
+		ID:          CheckIDRDSEncryption,
+		Title:       "RDS instance is not encrypted",
+		Description: "The RDS instance storage is not encrypted at rest.",
+		Severity:    SeverityHigh,
+	},
+	CheckIDSharedSnapshots: {
+		ID:          CheckIDSharedSnapshots,
+		Title:       "Snapshot is shared outside trusted accounts",
+		Description: "The EBS or RDS snapshot is public or shared with an account that is not trusted.",
+		Severity:    SeverityHigh,
+	},
+	CheckIDPublicAMIs: {
+		ID:          CheckIDPublicAMIs,
+		Title:       "AMI is public",
+		Description: "The AMI owned by this account can be launched by any AWS account.",
+		Severity:    SeverityHigh,
+	},
+	CheckIDIMDSv2: {
+		ID:          CheckIDIMDSv2,
+		Title:       "EC2 instance does not enforce IMDSv2",
+		Description: "The instance metadata service accepts IMDSv1 requests, which are exposed to SSRF credential theft.",
+		Severity:    SeverityHigh,
+	},
+	CheckIDIMDSHopLimit: {
+		ID:          CheckIDIMDSHopLimit,
+		Title:       "EC2 instance IMDS hop limit above 1",
+		Description: "The metadata response hop limit lets tokens reach containers or hosts beyond the instance.",
+		Severity:    SeverityMedium,
+	},
+	CheckIDUserDataSecrets: {
+		ID:          CheckIDUserDataSecrets,
+		Title:       "EC2 user data contains secrets",
+		Description: "The instance user data contains an AWS key, private key or password readable by anyone with ec2:DescribeInstanceAttribute.",
+		Severity:    SeverityCritical,
+	},
+	CheckIDDanglingDNS: {
+		ID:          CheckIDDanglingDNS,
+		Title:       "Route 53 record points to a deleted resource",
+		Description: "The DNS record points at an IP, S3 bucket, CloudFront distribution, Elastic Beanstalk environment or load balancer that no longer exists, allowing a subdomain takeover.",
+		Severity:    SeverityCritical,
+	},
+	CheckIDELBOutdatedTLSPolicy: {
+		ID:          CheckIDELBOutdatedTLSPolicy,
+		Title:       "Load balancer listener uses an outdated SSL policy",
+		Description: "The listener's SSL policy still negotiates SSLv3, TLS 1.0 or TLS 1.1.",
+		Severity:    SeverityHigh,
+	},
+	CheckIDELBHTTPNoRedirect: {
+		ID:          CheckIDELBHTTPNoRedirect,
+		Title:       "HTTP listener does not redirect to HTTPS",
+		Description: "An internet-facing load balancer serves plain HTTP instead of redirecting clients to HTTPS.",
+		Severity:    SeverityMedium,
+	},
+	CheckIDELBExpiringCertificate: {
+		ID:          CheckIDELBExpiringCertificate,
+		Title:       "Load balancer listener certificate is expiring",
+		Description: "A certificate served by the listener has expired or expires within 30 days.",
+		Severity:    SeverityHigh,
+	},
+	CheckIDELBNoWAF: {
+		ID:          CheckIDELBNoWAF,
+		Title:       "Internet-facing load balancer has no WAF",
+		Description: "The internet-facing application load balancer is not associated with a WAF web ACL.",
+		Severity:    SeverityMedium,
+	},
+	CheckIDCloudFrontOutdatedTLS: {
+		ID:          CheckIDCloudFrontOutdatedTLS,
+		Title:       "CloudFront distribution allows outdated TLS",
+		Description: "The distribution's security policy allows viewers to connect with SSLv3, TLS 1.0 or TLS 1.1.",
+		Severity:    SeverityHigh,
+	},
+	CheckIDCloudFrontHTTPAllowed: {
+		ID:          CheckIDCloudFrontHTTPAllowed,
+		Title:       "CloudFront distribution allows plain HTTP",
+		Description: "A cache behavior's viewer protocol policy allows HTTP instead of redirecting to or requiring HTTPS.",
+		Severity:    SeverityMedium,
+	},
+	CheckIDCloudFrontExpiringCertificate: {
+		ID:          CheckIDCloudFrontExpiringCertificate,
+		Title:       "CloudFront certificate is expiring",
+		Description: "The distribution's ACM certificate has expired or expires within 30 days.",
+		Severity:    SeverityHigh,
+	},
+	CheckIDCloudFrontNoWAF: {
+		ID:          CheckIDCloudFrontNoWAF,
+		Title:       "CloudFront distribution has no WAF",
+		Description: "The distribution is not associated with a WAF web ACL.",
+		Severity:    SeverityMedium,
+	},
+	CheckIDUnusedSecurityGroups: {
+		ID:          CheckIDUnusedSecurityGroups,
+		Title:       "Security group is not in use",
+		Description: "The security group is not attached to any network interface. Unused groups accumulate rules nobody reviews and can be attached to new resources by mistake.",
+		Severity:    SeverityMedium,
+	},
+	CheckIDDefaultSecurityGroupRules: {
+		ID:          CheckIDDefaultSecurityGroupRules,
+		Title:       "Default security group allows traffic",
+		Description: "The VPC's default security group has inbound or outbound rules. Resources launched without an explicit group get this access automatically.",
+		Severity:    SeverityHigh,
+	},
+	CheckIDStaleSecurityGroupRefs: {
+		ID:          CheckIDStaleSecurityGroupRefs,
+		Title:       "Security group references deleted groups",
+		Description: "Rules reference security groups in a peered VPC that have been deleted or whose peering connection is gone.",
+		Severity:    SeverityMedium,
+	},
+	CheckIDDuplicateSecurityGroups: {
+		ID:          CheckIDDuplicateSecurityGroups,
+		Title:       "Security groups have identical rules",
+		Description: "Several groups in the same VPC have identical inbound and outbound rules and could be consolidated.",
+		Severity:    SeverityMedium,
+	},
+	CheckIDExpiredSuppression: {
+		ID:          CheckIDExpiredSuppression,
+		Title:       "Suppression has expired",
+		Description: "An accepted-risk suppression has passed its expiry date and needs review.",
+		Severity:    SeverityMedium,
+	},
+}
+
+// RuleForCheck returns the rule metadata for a check ID, falling back to a
+// generic rule for unknown checks
+func RuleForCheck(checkID string) SecurityRule {
+	if rule, ok := SecurityRules[checkID]; ok {
+		return rule
+	}
+	return SecurityRule{
+		ID:       checkID,
+		Title:    checkID,
+		Severity: SeverityMedium,
+	}
+}
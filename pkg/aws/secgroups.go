@@ -0,0 +1,410 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+)
+
+// defaultSecurityGroupName is the name of the security group AWS creates in every VPC
+const defaultSecurityGroupName = "default"
+
+// UnusedSecurityGroup is a security group not attached to any network interface
+type UnusedSecurityGroup struct {
+	GroupID   string
+	GroupName string
+	VpcID     string
+	// ReferencedBy lists groups whose rules reference this group. AWS refuses
+	// to delete a group while it is referenced.
+	ReferencedBy []string
+	// UsedByLaunch lists launch templates and launch configurations that
+	// start instances in this group. The group has no network interface
+	// until one of them launches an instance.
+	UsedByLaunch []string
+}
+
+// SecurityGroupInventory holds the security groups in a region and the groups
+// attached to network interfaces
+type SecurityGroupInventory struct {
+	Groups   []ec2types.SecurityGroup
+	Attached map[string]bool
+	// Stale maps a group ID to the groups its rules reference that no longer exist
+	Stale map[string][]string
+}
+
+// CheckSecurityGroupHygiene finds unused security groups, default groups that
+// still have rules, rules referencing deleted groups and groups with identical rules
+func (s *SecurityAuditor) CheckSecurityGroupHygiene(ctx context.Context) ([]SecurityFinding, error) {
+	inventory, err := s.securityGroupInventory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateSecurityGroupHygiene(inventory, s.region), nil
+}
+
+// FindUnusedSecurityGroups returns security groups that are not attached to
+// any network interface, along with the launch templates and launch
+// configurations that still use them. Default groups are never returned
+// since they can't be deleted.
+func (s *SecurityAuditor) FindUnusedSecurityGroups(ctx context.Context) ([]UnusedSecurityGroup, error) {
+	inventory, err := s.securityGroupInventory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	launch, err := s.launchReferences(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return unusedSecurityGroups(inventory.Groups, inventory.Attached, launch), nil
+}
+
+// DeleteSecurityGroup deletes a security group. With dryRun set, EC2 only
+// checks that the caller is allowed to delete it.
+func (s *SecurityAuditor) DeleteSecurityGroup(ctx context.Context, groupID string, dryRun bool) error {
+	_, err := s.ec2Client.DeleteSecurityGroup(ctx, &ec2.DeleteSecurityGroupInput{
+		GroupId: aws.String(groupID),
+		DryRun:  aws.Bool(dryRun),
+	})
+	if err != nil {
+		// A successful dry run is reported as a DryRunOperation error
+		var apiErr smithy.APIError
+		if dryRun && errors.As(err, &apiErr) && apiErr.ErrorCode() == "DryRunOperation" {
+			return nil
+		}
+		return fmt.Errorf("failed to delete security group %s: %w", groupID, err)
+	}
+	return nil
+}
+
+// securityGroupInventory collects security groups, their network interface
+// attachments and stale rule references
+func (s *SecurityAuditor) securityGroupInventory(ctx context.Context) (*SecurityGroupInventory, error) {
+	inventory := &SecurityGroupInventory{
+		Groups:   make([]ec2types.SecurityGroup, 0),
+		Attached: make(map[string]bool),
+		Stale:    make(map[string][]string),
+	}
+
+	groups := ec2.NewDescribeSecurityGroupsPaginator(s.ec2Client, &ec2.DescribeSecurityGroupsInput{})
+	for groups.HasMorePages() {
+		page, err := groups.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe security groups: %w", err)
+		}
+		inventory.Groups = append(inventory.Groups, page.SecurityGroups...)
+	}
+
+	// Instances, load balancers, RDS, Lambda and ECS tasks all attach groups through ENIs
+	interfaces := ec2.NewDescribeNetworkInterfacesPaginator(s.ec2Client, &ec2.DescribeNetworkInterfacesInput{})
+	for interfaces.HasMorePages() {
+		page, err := interfaces.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe network interfaces: %w", err)
+		}
+		for _, eni := range page.NetworkInterfaces {
+			for _, group := range eni.Groups {
+				inventory.Attached[aws.ToString(group.GroupId)] = true
+			}
+		}
+	}
+
+	// Stale references are reported per VPC
+	vpcs := make(map[string]bool)
+	for _, group := range inventory.Groups {
+		if vpcID := aws.ToString(group.VpcId); vpcID != "" {
+			vpcs[vpcID] = true
+		}
+	}
+	for vpcID := range vpcs {
+		stale := ec2.NewDescribeStaleSecurityGroupsPaginator(s.ec2Client, &ec2.DescribeStaleSecurityGroupsInput{
+			VpcId: aws.String(vpcID),
+		})
+		for stale.HasMorePages() {
+			page, err := stale.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe stale security groups in %s: %w", vpcID, err)
+			}
+			for _, group := range page.StaleSecurityGroupSet {
+				groupID := aws.ToString(group.GroupId)
+				inventory.Stale[groupID] = append(inventory.Stale[groupID], staleReferences(group)...)
+			}
+		}
+	}
+
+	return inventory, nil
+}
+
+// launchReferences maps the security group IDs and names used by every launch
+// template version and launch configuration to the templates and
+// configurations that use them. Older template versions are included since
+// Auto Scaling groups can pin them.
+func (s *SecurityAuditor) launchReferences(ctx context.Context) (map[string][]string, error) {
+	references := make(map[string][]string)
+	seen := make(map[string]bool)
+	add := func(source string, groups ...string) {
+		for _, group := range groups {
+			if key := group + "|" + source; !seen[key] {
+				seen[key] = true
+				references[group] = append(references[group], source)
+			}
+		}
+	}
+
+	templates := ec2.NewDescribeLaunchTemplatesPaginator(s.ec2Client, &ec2.DescribeLaunchTemplatesInput{})
+	for templates.HasMorePages() {
+		page, err := templates.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe launch templates: %w", err)
+		}
+		for _, template := range page.LaunchTemplates {
+			source := fmt.Sprintf("launch template %s", aws.ToString(template.LaunchTemplateName))
+			versions := ec2.NewDescribeLaunchTemplateVersionsPaginator(s.ec2Client, &ec2.DescribeLaunchTemplateVersionsInput{
+				LaunchTemplateId: template.LaunchTemplateId,
+			})
+			for versions.HasMorePages() {
+				versionPage, err := versions.NextPage(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to describe versions of launch template %s: %w", aws.ToString(template.LaunchTemplateId), err)
+				}
+				for _, version := range versionPage.LaunchTemplateVersions {
+					data := version.LaunchTemplateData
+					if data == nil {
+						continue
+					}
+					add(source, data.SecurityGroupIds...)
+					add(source, data.SecurityGroups...)
+					for _, eni := range data.NetworkInterfaces {
+						add(source, eni.Groups...)
+					}
+				}
+			}
+		}
+	}
+
+	configurations := autoscaling.NewDescribeLaunchConfigurationsPaginator(s.asgClient, &autoscaling.DescribeLaunchConfigurationsInput{})
+	for configurations.HasMorePages() {
+		page, err := configurations.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe launch configurations: %w", err)
+		}
+		for _, configuration := range page.LaunchConfigurations {
+			// Launch configurations list groups by ID or, outside a VPC, by name
+			add(fmt.Sprintf("launch configuration %s", aws.ToString(configuration.LaunchConfigurationName)), configuration.SecurityGroups...)
+		}
+	}
+
+	return references, nil
+}
+
+// evaluateSecurityGroupHygiene reports unused, default, stale and duplicate security groups
+func evaluateSecurityGroupHygiene(inventory *SecurityGroupInventory, region string) []SecurityFinding {
+	findings := make([]SecurityFinding, 0)
+
+	for _, unused := range unusedSecurityGroups(inventory.Groups, inventory.Attached, nil) {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDUnusedSecurityGroups,
+			ResourceType: ResourceTypeSecurityGroup,
+			ResourceID:   unused.GroupID,
+			Region:       region,
+			Severity:     SeverityMedium,
+			Description:  fmt.Sprintf("Security group %s is not attached to any network interface", unused.GroupName),
+		})
+	}
+
+	for _, group := range inventory.Groups {
+		if aws.ToString(group.GroupName) != defaultSecurityGroupName {
+			continue
+		}
+		rules := len(group.IpPermissions) + len(group.IpPermissionsEgress)
+		if rules == 0 {
+			continue
+		}
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDDefaultSecurityGroupRules,
+			ResourceType: ResourceTypeSecurityGroup,
+			ResourceID:   aws.ToString(group.GroupId),
+			Region:       region,
+			Severity:     SeverityHigh,
+			Description:  fmt.Sprintf("Default security group of %s has %d rule(s); it should restrict all traffic", aws.ToString(group.VpcId), rules),
+		})
+	}
+
+	staleIDs := make([]string, 0, len(inventory.Stale))
+	for groupID := range inventory.Stale {
+		staleIDs = append(staleIDs, groupID)
+	}
+	sort.Strings(staleIDs)
+	for _, groupID := range staleIDs {
+		findings = append(findings, SecurityFinding{
+			CheckID:      CheckIDStaleSecurityGroupRefs,
+			ResourceType: ResourceTypeSecurityGroup,
+			ResourceID:   groupID,
+			Region:       region,
+			Severity:     SeverityMedium,
+			Description:  fmt.Sprintf("Rules reference deleted security groups: %s", strings.Join(inventory.Stale[groupID], ", ")),
+		})
+	}
+
+	for _, duplicates := range duplicateSecurityGroups(inventory.Groups) {
+		for i, groupID := range duplicates {
+			others := make([]string, 0, len(duplicates)-1)
+			others = append(others, duplicates[:i]...)
+			others = append(others, duplicates[i+1:]...)
+
+			findings = append(findings, SecurityFinding{
+				CheckID:      CheckIDDuplicateSecurityGroups,
+				ResourceType: ResourceTypeSecurityGroup,
+				ResourceID:   groupID,
+				Region:       region,
+				Severity:     SeverityMedium,
+				Description:  fmt.Sprintf("Rules are identical to %s", strings.Join(others, ", ")),
+			})
+		}
+	}
+
+	return findings
+}
+
+// unusedSecurityGroups returns non-default groups that aren't attached to a
+// network interface, along with the groups that still reference them and the
+// launch templates and configurations in launch that use them by ID or name
+func unusedSecurityGroups(groups []ec2types.SecurityGroup, attached map[string]bool, launch map[string][]string) []UnusedSecurityGroup {
+	referencedBy := make(map[string][]string)
+	for _, group := range groups {
+		groupID := aws.ToString(group.GroupId)
+		seen := make(map[string]bool)
+		for _, permissions := range [][]ec2types.IpPermission{group.IpPermissions, group.IpPermissionsEgress} {
+			for _, permission := range permissions {
+				for _, pair := range permission.UserIdGroupPairs {
+					ref := aws.ToString(pair.GroupId)
+					if ref == groupID || seen[ref] {
+						continue
+					}
+					seen[ref] = true
+					referencedBy[ref] = append(referencedBy[ref], groupID)
+				}
+			}
+		}
+	}
+
+	unused := make([]UnusedSecurityGroup, 0)
+	for _, group := range groups {
+		groupID := aws.ToString(group.GroupId)
+		if attached[groupID] || aws.ToString(group.GroupName) == defaultSecurityGroupName {
+			continue
+		}
+		// Names are only unique per VPC, so a launch configuration naming a
+		// group keeps every group with that name
+		var usedByLaunch []string
+		seen := make(map[string]bool)
+		for _, sources := range [][]string{launch[groupID], launch[aws.ToString(group.GroupName)]} {
+			for _, source := range sources {
+				if !seen[source] {
+					seen[source] = true
+					usedByLaunch = append(usedByLaunch, source)
+				}
+			}
+		}
+		unused = append(unused, UnusedSecurityGroup{
+			GroupID:      groupID,
+			GroupName:    aws.ToString(group.GroupName),
+			VpcID:        aws.ToString(group.VpcId),
+			ReferencedBy: referencedBy[groupID],
+			UsedByLaunch: usedByLaunch,
+		})
+	}
+
+	return unused
+}
+
+// duplicateSecurityGroups returns sets of groups in the same VPC with
+// identical ingress and egress rules. Groups without ingress rules are
+// ignored since freshly created groups all look alike.
+func duplicateSecurityGroups(groups []ec2types.SecurityGroup) [][]string {
+	bySignature := make(map[string][]string)
+	for _, group := range groups {
+		if len(group.IpPermissions) == 0 {
+			continue
+		}
+		groupID := aws.ToString(group.GroupId)
+		signature := strings.Join([]string{
+			aws.ToString(group.VpcId),
+			strings.Join(ruleSignatures(group.IpPermissions, groupID), ";"),
+			strings.Join(ruleSignatures(group.IpPermissionsEgress, groupID), ";"),
+		}, "|")
+		bySignature[signature] = append(bySignature[signature], groupID)
+	}
+
+	duplicates := make([][]string, 0)
+	for _, groupIDs := range bySignature {
+		if len(groupIDs) > 1 {
+			sort.Strings(groupIDs)
+			duplicates = append(duplicates, groupIDs)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i][0] < duplicates[j][0]
+	})
+
+	return duplicates
+}
+
+// ruleSignatures expands permissions into one sorted entry per source so
+// rules can be compared regardless of how they were grouped or described.
+// References to the group itself compare equal across groups.
+func ruleSignatures(permissions []ec2types.IpPermission, groupID string) []string {
+	signatures := make([]string, 0)
+	for _, permission := range permissions {
+		rule := fmt.Sprintf("%s:%d-%d", normalizeProtocol(aws.ToString(permission.IpProtocol)),
+			aws.ToInt32(permission.FromPort), aws.ToInt32(permission.ToPort))
+
+		for _, r := range permission.IpRanges {
+			signatures = append(signatures, rule+"<"+aws.ToString(r.CidrIp))
+		}
+		for _, r := range permission.Ipv6Ranges {
+			signatures = append(signatures, rule+"<"+aws.ToString(r.CidrIpv6))
+		}
+		for _, p := range permission.PrefixListIds {
+			signatures = append(signatures, rule+"<"+aws.ToString(p.PrefixListId))
+		}
+		for _, pair := range permission.UserIdGroupPairs {
+			source := aws.ToString(pair.GroupId)
+			if source == groupID {
+				source = "self"
+			}
+			signatures = append(signatures, rule+"<"+source)
+		}
+	}
+	sort.Strings(signatures)
+	return signatures
+}
+
+// staleReferences returns the deleted groups referenced by a stale group's rules
+func staleReferences(group ec2types.StaleSecurityGroup) []string {
+	refs := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, permissions := range [][]ec2types.StaleIpPermission{group.StaleIpPermissions, group.StaleIpPermissionsEgress} {
+		for _, permission := range permissions {
+			for _, pair := range permission.UserIdGroupPairs {
+				ref := aws.ToString(pair.GroupId)
+				if ref == "" || seen[ref] {
+					continue
+				}
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,152 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func tcpRule(port int32, cidr string) ec2types.IpPermission {
+	return ec2types.IpPermission{
+		IpProtocol: aws.String("tcp"),
+		FromPort:   aws.Int32(port),
+		ToPort:     aws.Int32(port),
+		IpRanges:   []ec2types.IpRange{{CidrIp: aws.String(cidr)}},
+	}
+}
+
+func groupRule(port int32, groupID string) ec2types.IpPermission {
+	return ec2types.IpPermission{
+		IpProtocol:       aws.String("tcp"),
+		FromPort:         aws.Int32(port),
+		ToPort:           aws.Int32(port),
+		UserIdGroupPairs: []ec2types.UserIdGroupPair{{GroupId: aws.String(groupID)}},
+	}
+}
+
+func securityGroup(id, name, vpc string, ingress ...ec2types.IpPermission) ec2types.SecurityGroup {
+	return ec2types.SecurityGroup{
+		GroupId:       aws.String(id),
+		GroupName:     aws.String(name),
+		VpcId:         aws.String(vpc),
+		IpPermissions: ingress,
+	}
+}
+
+func testSecurityGroupInventory() *SecurityGroupInventory {
+	return &SecurityGroupInventory{
+		Groups: []ec2types.SecurityGroup{
+			securityGroup("sg-default", "default", "vpc-1", groupRule(0, "sg-default")),
+			securityGroup("sg-default-2", "default", "vpc-2"),
+			securityGroup("sg-web", "web", "vpc-1", tcpRule(443, "0.0.0.0/0")),
+			securityGroup("sg-web-copy", "web-copy", "vpc-1", tcpRule(443, "0.0.0.0/0")),
+			securityGroup("sg-web-other-vpc", "web", "vpc-2", tcpRule(443, "0.0.0.0/0")),
+			securityGroup("sg-app", "app", "vpc-1", groupRule(8080, "sg-web")),
+			securityGroup("sg-old", "old", "vpc-1", groupRule(5432, "sg-app")),
+			securityGroup("sg-empty", "empty", "vpc-1"),
+		},
+		Attached: map[string]bool{
+			"sg-default":       true,
+			"sg-web":           true,
+			"sg-web-other-vpc": true,
+			"sg-app":           true,
+		},
+		Stale: map[string][]string{
+			"sg-app": {"sg-peer-deleted"},
+		},
+	}
+}
+
+func TestEvaluateSecurityGroupHygiene(t *testing.T) {
+	findings := evaluateSecurityGroupHygiene(testSecurityGroupInventory(), "us-east-1")
+
+	got := make([]string, 0, len(findings))
+	for _, f := range findings {
+		got = append(got, f.CheckID+":"+f.ResourceID)
+	}
+
+	want := []string{
+		CheckIDUnusedSecurityGroups + ":sg-web-copy",
+		CheckIDUnusedSecurityGroups + ":sg-old",
+		CheckIDUnusedSecurityGroups + ":sg-empty",
+		CheckIDDefaultSecurityGroupRules + ":sg-default",
+		CheckIDStaleSecurityGroupRefs + ":sg-app",
+		CheckIDDuplicateSecurityGroups + ":sg-web",
+		CheckIDDuplicateSecurityGroups + ":sg-web-copy",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("evaluateSecurityGroupHygiene() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestUnusedSecurityGroupsReferences(t *testing.T) {
+	inventory := testSecurityGroupInventory()
+	// sg-web is referenced by sg-app; detach it to check the reference is reported
+	delete(inventory.Attached, "sg-web")
+
+	unused := unusedSecurityGroups(inventory.Groups, inventory.Attached, nil)
+
+	for _, group := range unused {
+		if group.GroupID == "sg-default" || group.GroupID == "sg-default-2" {
+			t.Errorf("unusedSecurityGroups() returned default group %s", group.GroupID)
+		}
+		if group.GroupID == "sg-web" && strings.Join(group.ReferencedBy, ",") != "sg-app" {
+			t.Errorf("sg-web ReferencedBy = %v, want [sg-app]", group.ReferencedBy)
+		}
+		if group.GroupID == "sg-empty" && len(group.ReferencedBy) != 0 {
+			t.Errorf("sg-empty ReferencedBy = %v, want none", group.ReferencedBy)
+		}
+	}
+}
+
+func TestUnusedSecurityGroupsLaunchReferences(t *testing.T) {
+	inventory := testSecurityGroupInventory()
+	launch := map[string][]string{
+		"sg-old":   {"launch template batch"},
+		"empty":    {"launch configuration legacy", "launch template batch"},
+		"sg-empty": {"launch template batch"},
+	}
+
+	unused := unusedSecurityGroups(inventory.Groups, inventory.Attached, launch)
+
+	got := make(map[string]string)
+	for _, group := range unused {
+		got[group.GroupID] = strings.Join(group.UsedByLaunch, ",")
+	}
+	want := map[string]string{
+		"sg-web-copy": "",
+		"sg-old":      "launch template batch",
+		"sg-empty":    "launch template batch,launch configuration legacy",
+	}
+	for groupID, sources := range want {
+		if got[groupID] != sources {
+			t.Errorf("%s UsedByLaunch = %q, want %q", groupID, got[groupID], sources)
+		}
+	}
+}
+
+func TestRuleSignaturesIgnoreGrouping(t *testing.T) {
+	combined := []ec2types.IpPermission{{
+		IpProtocol: aws.String("6"),
+		FromPort:   aws.Int32(22),
+		ToPort:     aws.Int32(22),
+		IpRanges: []ec2types.IpRange{
+			{CidrIp: aws.String("10.0.0.0/8"), Description: aws.String("office")},
+			{CidrIp: aws.String("192.168.0.0/16")},
+		},
+		UserIdGroupPairs: []ec2types.UserIdGroupPair{{GroupId: aws.String("sg-a")}},
+	}}
+	split := []ec2types.IpPermission{
+		groupRule(22, "sg-b"),
+		tcpRule(22, "192.168.0.0/16"),
+		tcpRule(22, "10.0.0.0/8"),
+	}
+
+	a := strings.Join(ruleSignatures(combined, "sg-a"), ";")
+	b := strings.Join(ruleSignatures(split, "sg-b"), ";")
+	if a != b {
+		t.Errorf("ruleSignatures() differ:\n%s\n%s", a, b)
+	}
+}
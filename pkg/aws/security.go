@@ -3,12 +3,26 @@ package aws
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
 )
 
 // Severity levels for security findings
@@ -20,19 +34,55 @@ const (
 	SeverityMedium   Severity = "medium"
 )
 
+// Check identifiers for the security audit checks
+const (
+	CheckIDPublicS3Buckets      = "s3-public-buckets"
+	CheckIDOpenSecurityGroups   = "open-security-groups"
+	CheckIDUnencryptedVolumes   = "ebs-unencrypted-volumes"
+	CheckIDUnencryptedSnapshots = "ebs-unencrypted-snapshots"
+	CheckIDRDSPublicAccess      = "rds-public-access"
+	CheckIDRDSEncryption        = "rds-unencrypted"
+	CheckIDSharedSnapshots      = "shared-snapshots"
+	CheckIDPublicAMIs           = "public-amis"
+	CheckIDIMDSv2               = "ec2-imdsv2-not-enforced"
+	CheckIDIMDSHopLimit         = "ec2-imds-hop-limit"
+	CheckIDUserDataSecrets      = "ec2-user-data-secrets"
+	CheckIDDanglingDNS          = "route53-dangling-dns"
+
+	CheckIDELBOutdatedTLSPolicy          = "elb-outdated-tls-policy"
+	CheckIDELBHTTPNoRedirect             = "elb-http-no-redirect"
+	CheckIDELBExpiringCertificate        = "elb-expiring-certificate"
+	CheckIDELBNoWAF                      = "elb-no-waf"
+	CheckIDCloudFrontOutdatedTLS         = "cloudfront-outdated-tls"
+	CheckIDCloudFrontHTTPAllowed         = "cloudfront-http-allowed"
+	CheckIDCloudFrontExpiringCertificate = "cloudfront-expiring-certificate"
+	CheckIDCloudFrontNoWAF               = "cloudfront-no-waf"
+
+	CheckIDUnusedSecurityGroups      = "sg-unused"
+	CheckIDDefaultSecurityGroupRules = "sg-default-has-rules"
+	CheckIDStaleSecurityGroupRefs    = "sg-stale-references"
+	CheckIDDuplicateSecurityGroups   = "sg-duplicate-rules"
+)
+
 // SecurityFinding represents a security issue found during audit
 type SecurityFinding struct {
+	CheckID      string
 	ResourceType string
 	ResourceID   string
 	Region       string
 	Severity     Severity
 	Description  string
+	// Key tells apart findings of one check on the same resource, such as the
+	// port and source of an open rule. Unlike Description it never changes
+	// between runs, so it is part of the finding's fingerprint.
+	Key string
 }
 
 // OpenSecurityGroup represents a security group with risky open ports
 type OpenSecurityGroup struct {
 	GroupID   string
 	GroupName string
+	Region    string
 	Port      int32
 	Protocol  string
 	Source    string
@@ -42,24 +92,84 @@ type OpenSecurityGroup struct {
 // PublicS3Bucket represents an S3 bucket with public access
 type PublicS3Bucket struct {
 	BucketName   string
+	Region       string
 	PublicAccess string
 	Severity     Severity
 }
 
+// Finding converts the public bucket into a SecurityFinding
+func (b PublicS3Bucket) Finding() SecurityFinding {
+	return SecurityFinding{
+		CheckID:      CheckIDPublicS3Buckets,
+		ResourceType: ResourceTypeS3Bucket,
+		ResourceID:   b.BucketName,
+		Region:       b.Region,
+		Severity:     b.Severity,
+		Description:  b.PublicAccess,
+	}
+}
+
+// Finding converts the open security group rule into a SecurityFinding
+func (sg OpenSecurityGroup) Finding() SecurityFinding {
+	return SecurityFinding{
+		CheckID:      CheckIDOpenSecurityGroups,
+		ResourceType: ResourceTypeSecurityGroup,
+		ResourceID:   sg.GroupID,
+		Region:       sg.Region,
+		Severity:     sg.Severity,
+		Description:  fmt.Sprintf("Port %d (%s) open to %s", sg.Port, RiskyPorts[sg.Port], sg.Source),
+		Key:          fmt.Sprintf("%s/%d/%s", sg.Protocol, sg.Port, sg.Source),
+	}
+}
+
 // SecurityResults holds all security audit findings
 type SecurityResults struct {
+	Regions            []string
 	PublicS3Buckets    []PublicS3Bucket
 	OpenSecurityGroups []OpenSecurityGroup
 	Findings           []SecurityFinding
+	Suppressed         []SuppressedFinding
+	CompletedChecks    []string
+	// ResourceCounts is the number of resources evaluated by each completed
+	// check that counts them, by check ID
+	ResourceCounts map[string]int
 }
 
 // SecurityAuditor handles AWS security auditing
 type SecurityAuditor struct {
-	ec2Client *ec2.Client
-	s3Client  *s3.Client
-	region    string
+	ec2Client        *ec2.Client
+	s3Client         *s3.Client
+	rdsClient        *rds.Client
+	stsClient        *sts.Client
+	hubClient        *securityhub.Client
+	route53Client    *route53.Client
+	elbv2Client      *elasticloadbalancingv2.Client
+	elbClient        *elasticloadbalancing.Client
+	cloudfrontClient *cloudfront.Client
+	beanstalkClient  *elasticbeanstalk.Client
+	wafClient        *wafv2.Client
+	asgClient        *autoscaling.Client
+	region           string
+	trustedAccounts  map[string]bool
+
+	certificates           *CertificateAuditor
+	cloudfrontCertificates *CertificateAuditor
+
+	// instances caches the active instances shared by the instance checks
+	instances []ec2types.Instance
+	// loadBalancers caches the ELBv2 load balancers shared by the load
+	// balancer and dangling DNS checks
+	loadBalancers []elbv2types.LoadBalancer
+	// globalDNS holds the Route 53, CloudFront and S3 inventory, which may be
+	// shared with the auditors of other regions
+	globalDNS *GlobalDNSInventory
+	// resourceCounts is the number of resources each check evaluated
+	resourceCounts map[string]int
 }
 
+// cloudfrontCertificateRegion is the only region CloudFront reads ACM certificates from
+const cloudfrontCertificateRegion = "us-east-1"
+
 // RiskyPorts defines ports that are considered risky when exposed to the internet
 var RiskyPorts = map[int32]string{
 	22:    "SSH",
@@ -77,12 +187,59 @@ func NewSecurityAuditor(ctx context.Context, region string) (*SecurityAuditor, e
 	}
 
 	return &SecurityAuditor{
-		ec2Client: ec2.NewFromConfig(cfg),
-		s3Client:  s3.NewFromConfig(cfg),
-		region:    region,
+		ec2Client:        ec2.NewFromConfig(cfg),
+		s3Client:         s3.NewFromConfig(cfg),
+		rdsClient:        rds.NewFromConfig(cfg),
+		stsClient:        sts.NewFromConfig(cfg),
+		hubClient:        securityhub.NewFromConfig(cfg),
+		route53Client:    route53.NewFromConfig(cfg),
+		elbv2Client:      elasticloadbalancingv2.NewFromConfig(cfg),
+		elbClient:        elasticloadbalancing.NewFromConfig(cfg),
+		cloudfrontClient: cloudfront.NewFromConfig(cfg),
+		beanstalkClient:  elasticbeanstalk.NewFromConfig(cfg),
+		wafClient:        wafv2.NewFromConfig(cfg),
+		asgClient:        autoscaling.NewFromConfig(cfg),
+		region:           region,
+		trustedAccounts:  make(map[string]bool),
+		resourceCounts:   make(map[string]int),
+		globalDNS:        NewGlobalDNSInventory(),
+		certificates: &CertificateAuditor{
+			acmClient: acm.NewFromConfig(cfg),
+			iamClient: iam.NewFromConfig(cfg),
+			region:    region,
+		},
+		cloudfrontCertificates: &CertificateAuditor{
+			acmClient: acm.NewFromConfig(cfg, func(o *acm.Options) {
+				o.Region = cloudfrontCertificateRegion
+			}),
+			iamClient: iam.NewFromConfig(cfg),
+			region:    cloudfrontCertificateRegion,
+		},
 	}, nil
 }
 
+// AccountID returns the AWS account ID of the audited credentials
+func (s *SecurityAuditor) AccountID(ctx context.Context) (string, error) {
+	identity, err := s.stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get caller identity: %w", err)
+	}
+	return aws.ToString(identity.Account), nil
+}
+
+// ResourceCounts returns the number of resources evaluated by each check that
+// counts them, by check ID
+func (s *SecurityAuditor) ResourceCounts() map[string]int {
+	return s.resourceCounts
+}
+
+// countResources records the number of resources evaluated by the given checks
+func (s *SecurityAuditor) countResources(count int, checkIDs ...string) {
+	for _, id := range checkIDs {
+		s.resourceCounts[id] = count
+	}
+}
+
 // CheckPublicS3Buckets finds S3 buckets with public access enabled
 func (s *SecurityAuditor) CheckPublicS3Buckets(ctx context.Context) ([]PublicS3Bucket, error) {
 	// List all buckets
@@ -158,6 +315,7 @@ func (s *SecurityAuditor) CheckPublicS3Buckets(ctx context.Context) ([]PublicS3B
 		if isPublic {
 			publicBuckets = append(publicBuckets, PublicS3Bucket{
 				BucketName:   bucketName,
+				Region:       s.region,
 				PublicAccess: publicReason,
 				Severity:     SeverityCritical,
 			})
@@ -183,8 +341,10 @@ func (s *SecurityAuditor) CheckOpenSecurityGroups(ctx context.Context) ([]OpenSe
 
 		// Check ingress rules
 		for _, permission := range sg.IpPermissions {
-			findings := evaluateSecurityGroupRule(permission, groupID, groupName)
-			openGroups = append(openGroups, findings...)
+			for _, finding := range evaluateSecurityGroupRule(permission, groupID, groupName) {
+				finding.Region = s.region
+				openGroups = append(openGroups, finding)
+			}
 		}
 	}
 
@@ -338,5 +498,138 @@ func (r *SecurityResults) CountBySeverity() map[Severity]int {
 		counts[sg.Severity]++
 	}
 
+	for _, finding := range r.Findings {
+		counts[finding.Severity]++
+	}
+
 	return counts
 }
+
+// MarkCompleted records that the given checks ran successfully
+func (r *SecurityResults) MarkCompleted(checkIDs ...string) {
+	for _, id := range checkIDs {
+		if !r.IsCompleted(id) {
+			r.CompletedChecks = append(r.CompletedChecks, id)
+		}
+	}
+}
+
+// ResourceCount returns the number of resources a check evaluated, and false
+// when the check does not count them
+func (r *SecurityResults) ResourceCount(checkID string) (int, bool) {
+	count, ok := r.ResourceCounts[checkID]
+	return count, ok
+}
+
+// IsCompleted reports whether the given check ran successfully
+func (r *SecurityResults) IsCompleted(checkID string) bool {
+	for _, id := range r.CompletedChecks {
+		if id == checkID {
+			return true
+		}
+	}
+	return false
+}
+
+// AllFindings returns every active finding, including public buckets and open
+// security groups, as SecurityFindings
+func (r *SecurityResults) AllFindings() []SecurityFinding {
+	findings := make([]SecurityFinding, 0, len(r.PublicS3Buckets)+len(r.OpenSecurityGroups)+len(r.Findings))
+	for _, bucket := range r.PublicS3Buckets {
+		findings = append(findings, bucket.Finding())
+	}
+	for _, sg := range r.OpenSecurityGroups {
+		findings = append(findings, sg.Finding())
+	}
+	return append(findings, r.Findings...)
+}
+
+// FindingsForCheck returns the findings reported by the given check
+func (r *SecurityResults) FindingsForCheck(checkID string) []SecurityFinding {
+	findings := make([]SecurityFinding, 0)
+	for _, finding := range r.Findings {
+		if finding.CheckID == checkID {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// globalResourceTypes are resources that aren't tied to a region, so every
+// regional audit reports the same findings for them
+var globalResourceTypes = map[string]bool{
+	ResourceTypeCloudFrontDistribution: true,
+	ResourceTypeDNSRecord:              true,
+	ResourceTypeSuppression:            true,
+}
+
+// MergeSecurityResults combines the results of auditing several regions.
+// Findings on global resources are kept once, from the first region that
+// reported them, and a check only counts as completed if it completed in
+// every region.
+func MergeSecurityResults(regional ...*SecurityResults) *SecurityResults {
+	merged := &SecurityResults{
+		PublicS3Buckets:    make([]PublicS3Bucket, 0),
+		OpenSecurityGroups: make([]OpenSecurityGroup, 0),
+		Findings:           make([]SecurityFinding, 0),
+		Suppressed:         make([]SuppressedFinding, 0),
+		CompletedChecks:    make([]string, 0),
+		ResourceCounts:     make(map[string]int),
+	}
+
+	seen := make(map[string]bool)
+	seenSuppressed := make(map[string]bool)
+
+	for i, results := range regional {
+		merged.Regions = append(merged.Regions, results.Regions...)
+		merged.PublicS3Buckets = append(merged.PublicS3Buckets, results.PublicS3Buckets...)
+		merged.OpenSecurityGroups = append(merged.OpenSecurityGroups, results.OpenSecurityGroups...)
+		for checkID, count := range results.ResourceCounts {
+			merged.ResourceCounts[checkID] += count
+		}
+
+		for _, finding := range results.Findings {
+			if key, global := globalFindingKey(finding); global {
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			merged.Findings = append(merged.Findings, finding)
+		}
+
+		for _, suppressed := range results.Suppressed {
+			if key, global := globalFindingKey(suppressed.Finding); global {
+				if seenSuppressed[key] {
+					continue
+				}
+				seenSuppressed[key] = true
+			}
+			merged.Suppressed = append(merged.Suppressed, suppressed)
+		}
+
+		if i == 0 {
+			merged.CompletedChecks = append(merged.CompletedChecks, results.CompletedChecks...)
+			continue
+		}
+		completed := make([]string, 0, len(merged.CompletedChecks))
+		for _, id := range merged.CompletedChecks {
+			if results.IsCompleted(id) {
+				completed = append(completed, id)
+			}
+		}
+		merged.CompletedChecks = completed
+	}
+
+	return merged
+}
+
+// globalFindingKey identifies a finding on a global resource independently of
+// the region that reported it and of its wording. It returns false for
+// regional resources.
+func globalFindingKey(finding SecurityFinding) (string, bool) {
+	if !globalResourceTypes[finding.ResourceType] {
+		return "", false
+	}
+	return strings.Join([]string{finding.CheckID, finding.ResourceType, finding.ResourceID, finding.Key}, "|"), true
+}
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -399,6 +400,68 @@ func TestSecurityResultsCountBySeverity(t *testing.T) {
 	}
 }
 
+func TestMergeSecurityResults(t *testing.T) {
+	cdn := SecurityFinding{CheckID: CheckIDCloudFrontNoWAF, ResourceType: ResourceTypeCloudFrontDistribution, ResourceID: "E2ABC", Description: "no WAF"}
+	dns := SecurityFinding{CheckID: CheckIDDanglingDNS, ResourceType: ResourceTypeDNSRecord, ResourceID: "old.example.com", Description: "CNAME points to deleted S3 bucket old", Key: "deleted S3 bucket"}
+	// The same global finding worded differently by another region is still one finding
+	reworded := dns
+	reworded.Description = "CNAME target S3 bucket old no longer exists"
+
+	east := &SecurityResults{
+		Regions:            []string{"us-east-1"},
+		PublicS3Buckets:    []PublicS3Bucket{{BucketName: "east-bucket", Region: "us-east-1", Severity: SeverityCritical}},
+		OpenSecurityGroups: []OpenSecurityGroup{{GroupID: "sg-east", Region: "us-east-1", Port: 22, Severity: SeverityCritical}},
+		Findings: []SecurityFinding{
+			{CheckID: CheckIDRDSEncryption, ResourceType: ResourceTypeRDSInstance, ResourceID: "db-prod", Region: "us-east-1", Description: "not encrypted"},
+			withRegion(cdn, "us-east-1"),
+			withRegion(dns, "us-east-1"),
+		},
+		CompletedChecks: []string{CheckIDPublicS3Buckets, CheckIDOpenSecurityGroups, CheckIDRDSEncryption},
+	}
+	west := &SecurityResults{
+		Regions:            []string{"eu-west-1"},
+		OpenSecurityGroups: []OpenSecurityGroup{{GroupID: "sg-west", Region: "eu-west-1", Port: 3389, Severity: SeverityCritical}},
+		Findings: []SecurityFinding{
+			// Regional resources with the same ID in another region are distinct
+			{CheckID: CheckIDRDSEncryption, ResourceType: ResourceTypeRDSInstance, ResourceID: "db-prod", Region: "eu-west-1", Description: "not encrypted"},
+			withRegion(cdn, "eu-west-1"),
+			withRegion(reworded, "eu-west-1"),
+		},
+		CompletedChecks: []string{CheckIDOpenSecurityGroups, CheckIDRDSEncryption},
+	}
+
+	merged := MergeSecurityResults(east, west)
+
+	if strings.Join(merged.Regions, ",") != "us-east-1,eu-west-1" {
+		t.Errorf("Regions = %v, want [us-east-1 eu-west-1]", merged.Regions)
+	}
+	if len(merged.PublicS3Buckets) != 1 || len(merged.OpenSecurityGroups) != 2 {
+		t.Errorf("PublicS3Buckets = %d, OpenSecurityGroups = %d, want 1 and 2", len(merged.PublicS3Buckets), len(merged.OpenSecurityGroups))
+	}
+
+	got := make([]string, 0, len(merged.Findings))
+	for _, f := range merged.Findings {
+		got = append(got, f.ResourceID+"@"+f.Region)
+	}
+	want := "db-prod@us-east-1,E2ABC@us-east-1,old.example.com@us-east-1,db-prod@eu-west-1"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Findings = %v, want %s", got, want)
+	}
+
+	// S3 did not complete in eu-west-1, so it isn't complete overall
+	if merged.IsCompleted(CheckIDPublicS3Buckets) {
+		t.Error("IsCompleted(s3-public-buckets) = true, want false")
+	}
+	if !merged.IsCompleted(CheckIDOpenSecurityGroups) || !merged.IsCompleted(CheckIDRDSEncryption) {
+		t.Errorf("CompletedChecks = %v, want checks completed in both regions", merged.CompletedChecks)
+	}
+}
+
+func withRegion(finding SecurityFinding, region string) SecurityFinding {
+	finding.Region = region
+	return finding
+}
+
 func TestGetSeverityColor(t *testing.T) {
 	tests := []struct {
 		severity Severity
@@ -0,0 +1,297 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	hubtypes "github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+)
+
+// ASFFSchemaVersion is the AWS Security Finding Format version produced by dtk
+const ASFFSchemaVersion = "2018-10-08"
+
+// asffFindingType is the ASFF finding type used for every dtk check
+const asffFindingType = "Software and Configuration Checks/AWS Security Best Practices"
+
+// securityHubBatchSize is the maximum number of findings per BatchImportFindings call
+const securityHubBatchSize = 100
+
+// securityHubFilterValues is the maximum number of values in a GetFindings filter
+const securityHubFilterValues = 20
+
+// ASFFFinding is a finding in the AWS Security Finding Format
+type ASFFFinding struct {
+	SchemaVersion string
+	ID            string `json:"Id"`
+	ProductArn    string
+	GeneratorID   string `json:"GeneratorId"`
+	AwsAccountID  string `json:"AwsAccountId"`
+	Types         []string
+	CreatedAt     string
+	UpdatedAt     string
+	Severity      ASFFSeverity
+	Title         string
+	Description   string
+	Resources     []ASFFResource
+	ProductFields map[string]string
+}
+
+// ASFFSeverity holds the severity label of an ASFF finding
+type ASFFSeverity struct {
+	Label string
+}
+
+// ASFFResource identifies the resource an ASFF finding applies to
+type ASFFResource struct {
+	Type      string
+	ID        string `json:"Id"`
+	Partition string
+	Region    string
+}
+
+// asffResourceTypes maps finding resource types to ASFF resource types
+var asffResourceTypes = map[string]string{
+	ResourceTypeS3Bucket:      "AwsS3Bucket",
+	ResourceTypeSecurityGroup: "AwsEc2SecurityGroup",
+	ResourceTypeEBSVolume:     "AwsEc2Volume",
+	ResourceTypeEC2Instance:   "AwsEc2Instance",
+	ResourceTypeRDSInstance:   "AwsRdsDbInstance",
+	ResourceTypeRDSSnapshot:   "AwsRdsDbSnapshot",
+
+	ResourceTypeLoadBalancer:           "AwsElbv2LoadBalancer",
+	ResourceTypeCloudFrontDistribution: "AwsCloudFrontDistribution",
+}
+
+// Partition returns the AWS partition a region belongs to
+func Partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	default:
+		return "aws"
+	}
+}
+
+// ResourceARN returns the ARN of the resource a finding applies to. Resources
+// identified by their ARN, such as load balancers, and resources without an
+// ARN, such as suppressions, return their plain ID.
+func ResourceARN(finding SecurityFinding, accountID string) string {
+	partition := Partition(finding.Region)
+	region := finding.Region
+	id := finding.ResourceID
+
+	switch finding.ResourceType {
+	case ResourceTypeS3Bucket:
+		return fmt.Sprintf("arn:%s:s3:::%s", partition, id)
+	case ResourceTypeSecurityGroup:
+		return fmt.Sprintf("arn:%s:ec2:%s:%s:security-group/%s", partition, region, accountID, id)
+	case ResourceTypeEBSVolume:
+		return fmt.Sprintf("arn:%s:ec2:%s:%s:volume/%s", partition, region, accountID, id)
+	case ResourceTypeEC2Instance:
+		return fmt.Sprintf("arn:%s:ec2:%s:%s:instance/%s", partition, region, accountID, id)
+	case ResourceTypeEBSSnapshot:
+		return fmt.Sprintf("arn:%s:ec2:%s::snapshot/%s", partition, region, id)
+	case ResourceTypeAMI:
+		return fmt.Sprintf("arn:%s:ec2:%s::image/%s", partition, region, id)
+	case ResourceTypeRDSInstance:
+		return fmt.Sprintf("arn:%s:rds:%s:%s:db:%s", partition, region, accountID, id)
+	case ResourceTypeRDSSnapshot:
+		return fmt.Sprintf("arn:%s:rds:%s:%s:snapshot:%s", partition, region, accountID, id)
+	case ResourceTypeCloudFrontDistribution:
+		return fmt.Sprintf("arn:%s:cloudfront::%s:distribution/%s", partition, accountID, id)
+	default:
+		return id
+	}
+}
+
+// FindingFingerprint returns a stable identifier for a finding so repeated
+// runs update the same result instead of creating duplicates. The description
+// is left out as it may change between runs.
+func FindingFingerprint(finding SecurityFinding) string {
+	key := strings.Join([]string{finding.CheckID, finding.Region, finding.ResourceID, finding.Key}, "|")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// BuildASFFFindings converts security results into AWS Security Finding Format findings
+func BuildASFFFindings(results *SecurityResults, region, accountID string, now time.Time) []ASFFFinding {
+	timestamp := now.UTC().Format(time.RFC3339)
+	productArn := fmt.Sprintf("arn:%s:securityhub:%s:%s:product/%s/default", Partition(region), region, accountID, accountID)
+
+	findings := results.AllFindings()
+	asff := make([]ASFFFinding, 0, len(findings))
+	for _, finding := range findings {
+		rule := RuleForCheck(finding.CheckID)
+
+		resourceType, ok := asffResourceTypes[finding.ResourceType]
+		if !ok {
+			resourceType = "Other"
+		}
+
+		asff = append(asff, ASFFFinding{
+			SchemaVersion: ASFFSchemaVersion,
+			ID:            fmt.Sprintf("dtk/%s/%s/%s/%s", finding.Region, finding.CheckID, finding.ResourceID, FindingFingerprint(finding)),
+			ProductArn:    productArn,
+			GeneratorID:   "dtk/" + finding.CheckID,
+			AwsAccountID:  accountID,
+			Types:         []string{asffFindingType},
+			CreatedAt:     timestamp,
+			UpdatedAt:     timestamp,
+			Severity:      ASFFSeverity{Label: strings.ToUpper(string(finding.Severity))},
+			Title:         rule.Title,
+			Description:   finding.Description,
+			Resources: []ASFFResource{
+				{
+					Type:      resourceType,
+					ID:        ResourceARN(finding, accountID),
+					Partition: Partition(finding.Region),
+					Region:    finding.Region,
+				},
+			},
+			ProductFields: map[string]string{
+				"dtk/CheckId":      finding.CheckID,
+				"dtk/ResourceType": finding.ResourceType,
+			},
+		})
+	}
+
+	return asff
+}
+
+// ImportToSecurityHub sends findings to Security Hub with BatchImportFindings
+// and returns the number of findings imported. Findings already in Security
+// Hub keep the time they were first created.
+func (s *SecurityAuditor) ImportToSecurityHub(ctx context.Context, findings []ASFFFinding) (int, error) {
+	ids := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		ids = append(ids, finding.ID)
+	}
+
+	createdAt, err := s.existingFindingTimes(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	findings = preserveCreatedAt(findings, createdAt)
+
+	imported := 0
+	failures := make([]string, 0)
+
+	for start := 0; start < len(findings); start += securityHubBatchSize {
+		end := start + securityHubBatchSize
+		if end > len(findings) {
+			end = len(findings)
+		}
+
+		batch := make([]hubtypes.AwsSecurityFinding, 0, end-start)
+		for _, finding := range findings[start:end] {
+			batch = append(batch, finding.toSecurityHub())
+		}
+
+		result, err := s.hubClient.BatchImportFindings(ctx, &securityhub.BatchImportFindingsInput{
+			Findings: batch,
+		})
+		if err != nil {
+			return imported, fmt.Errorf("failed to import findings to Security Hub: %w", err)
+		}
+
+		imported += int(aws.ToInt32(result.SuccessCount))
+		for _, failed := range result.FailedFindings {
+			failures = append(failures, fmt.Sprintf("%s (%s)", aws.ToString(failed.Id), aws.ToString(failed.ErrorMessage)))
+		}
+	}
+
+	if len(failures) > 0 {
+		return imported, fmt.Errorf("%d findings failed to import to Security Hub: %s", len(failures), strings.Join(failures, ", "))
+	}
+
+	return imported, nil
+}
+
+// existingFindingTimes returns the CreatedAt time of the findings already in
+// Security Hub, keyed by finding ID
+func (s *SecurityAuditor) existingFindingTimes(ctx context.Context, ids []string) (map[string]string, error) {
+	createdAt := make(map[string]string)
+
+	for start := 0; start < len(ids); start += securityHubFilterValues {
+		end := start + securityHubFilterValues
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		filters := make([]hubtypes.StringFilter, 0, end-start)
+		for _, id := range ids[start:end] {
+			filters = append(filters, hubtypes.StringFilter{
+				Comparison: hubtypes.StringFilterComparisonEquals,
+				Value:      aws.String(id),
+			})
+		}
+
+		paginator := securityhub.NewGetFindingsPaginator(s.hubClient, &securityhub.GetFindingsInput{
+			Filters: &hubtypes.AwsSecurityFindingFilters{Id: filters},
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get existing Security Hub findings: %w", err)
+			}
+
+			for _, finding := range page.Findings {
+				if created := aws.ToString(finding.CreatedAt); created != "" {
+					createdAt[aws.ToString(finding.Id)] = created
+				}
+			}
+		}
+	}
+
+	return createdAt, nil
+}
+
+// preserveCreatedAt sets the CreatedAt of findings that already exist to the
+// time they were first imported
+func preserveCreatedAt(findings []ASFFFinding, createdAt map[string]string) []ASFFFinding {
+	preserved := make([]ASFFFinding, 0, len(findings))
+	for _, finding := range findings {
+		if created, ok := createdAt[finding.ID]; ok {
+			finding.CreatedAt = created
+		}
+		preserved = append(preserved, finding)
+	}
+	return preserved
+}
+
+// toSecurityHub converts the finding into the Security Hub API type
+func (f ASFFFinding) toSecurityHub() hubtypes.AwsSecurityFinding {
+	resources := make([]hubtypes.Resource, 0, len(f.Resources))
+	for _, r := range f.Resources {
+		resources = append(resources, hubtypes.Resource{
+			Type:      aws.String(r.Type),
+			Id:        aws.String(r.ID),
+			Partition: hubtypes.Partition(r.Partition),
+			Region:    aws.String(r.Region),
+		})
+	}
+
+	return hubtypes.AwsSecurityFinding{
+		SchemaVersion: aws.String(f.SchemaVersion),
+		Id:            aws.String(f.ID),
+		ProductArn:    aws.String(f.ProductArn),
+		GeneratorId:   aws.String(f.GeneratorID),
+		AwsAccountId:  aws.String(f.AwsAccountID),
+		Types:         f.Types,
+		CreatedAt:     aws.String(f.CreatedAt),
+		UpdatedAt:     aws.String(f.UpdatedAt),
+		Severity:      &hubtypes.Severity{Label: hubtypes.SeverityLabel(f.Severity.Label)},
+		Title:         aws.String(f.Title),
+		Description:   aws.String(f.Description),
+		Resources:     resources,
+		ProductFields: f.ProductFields,
+	}
+}
@@ -0,0 +1,199 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSecurityRulesCoverAllChecks(t *testing.T) {
+	checkIDs := append(allChecksCompleted(), CheckIDExpiredSuppression)
+
+	for _, id := range checkIDs {
+		rule, ok := SecurityRules[id]
+		if !ok {
+			t.Errorf("no rule registered for check %q", id)
+			continue
+		}
+		if rule.ID != id {
+			t.Errorf("rule for %q has ID %q", id, rule.ID)
+		}
+		if rule.Title == "" || rule.Description == "" {
+			t.Errorf("rule for %q is missing a title or description", id)
+		}
+	}
+
+	if rule := RuleForCheck("unknown-check"); rule.ID != "unknown-check" || rule.Severity != SeverityMedium {
+		t.Errorf("RuleForCheck(unknown) = %+v, want generic medium rule", rule)
+	}
+}
+
+func TestResourceARN(t *testing.T) {
+	const account = "111111111111"
+
+	tests := []struct {
+		name    string
+		finding SecurityFinding
+		want    string
+	}{
+		{
+			name:    "s3 bucket",
+			finding: SecurityFinding{ResourceType: ResourceTypeS3Bucket, ResourceID: "my-bucket", Region: "us-east-1"},
+			want:    "arn:aws:s3:::my-bucket",
+		},
+		{
+			name:    "security group",
+			finding: SecurityFinding{ResourceType: ResourceTypeSecurityGroup, ResourceID: "sg-123", Region: "eu-west-1"},
+			want:    "arn:aws:ec2:eu-west-1:111111111111:security-group/sg-123",
+		},
+		{
+			name:    "ebs volume",
+			finding: SecurityFinding{ResourceType: ResourceTypeEBSVolume, ResourceID: "vol-123", Region: "us-east-1"},
+			want:    "arn:aws:ec2:us-east-1:111111111111:volume/vol-123",
+		},
+		{
+			name:    "ebs snapshot",
+			finding: SecurityFinding{ResourceType: ResourceTypeEBSSnapshot, ResourceID: "snap-123", Region: "us-east-1"},
+			want:    "arn:aws:ec2:us-east-1::snapshot/snap-123",
+		},
+		{
+			name:    "ami",
+			finding: SecurityFinding{ResourceType: ResourceTypeAMI, ResourceID: "ami-123", Region: "us-east-1"},
+			want:    "arn:aws:ec2:us-east-1::image/ami-123",
+		},
+		{
+			name:    "rds instance",
+			finding: SecurityFinding{ResourceType: ResourceTypeRDSInstance, ResourceID: "prod-db", Region: "us-east-1"},
+			want:    "arn:aws:rds:us-east-1:111111111111:db:prod-db",
+		},
+		{
+			name:    "rds snapshot",
+			finding: SecurityFinding{ResourceType: ResourceTypeRDSSnapshot, ResourceID: "prod-snap", Region: "us-east-1"},
+			want:    "arn:aws:rds:us-east-1:111111111111:snapshot:prod-snap",
+		},
+		{
+			name:    "cloudfront distribution",
+			finding: SecurityFinding{ResourceType: ResourceTypeCloudFrontDistribution, ResourceID: "E2ABC123", Region: "us-east-1"},
+			want:    "arn:aws:cloudfront::111111111111:distribution/E2ABC123",
+		},
+		{
+			name:    "load balancer is identified by its arn",
+			finding: SecurityFinding{ResourceType: ResourceTypeLoadBalancer, ResourceID: "arn:aws:elasticloadbalancing:us-east-1:111111111111:loadbalancer/app/web/abc", Region: "us-east-1"},
+			want:    "arn:aws:elasticloadbalancing:us-east-1:111111111111:loadbalancer/app/web/abc",
+		},
+		{
+			name:    "china partition",
+			finding: SecurityFinding{ResourceType: ResourceTypeEBSVolume, ResourceID: "vol-123", Region: "cn-north-1"},
+			want:    "arn:aws-cn:ec2:cn-north-1:111111111111:volume/vol-123",
+		},
+		{
+			name:    "suppression has no arn",
+			finding: SecurityFinding{ResourceType: ResourceTypeSuppression, ResourceID: "db-legacy", Region: "us-east-1"},
+			want:    "db-legacy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResourceARN(tt.finding, account); got != tt.want {
+				t.Errorf("ResourceARN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindingFingerprint(t *testing.T) {
+	sg := OpenSecurityGroup{GroupID: "sg-1", Region: "us-east-1", Port: 22, Source: "0.0.0.0/0", Severity: SeverityCritical}
+	other := OpenSecurityGroup{GroupID: "sg-1", Region: "us-east-1", Port: 3389, Source: "0.0.0.0/0", Severity: SeverityCritical}
+
+	first := FindingFingerprint(sg.Finding())
+	if first != FindingFingerprint(sg.Finding()) {
+		t.Error("FindingFingerprint() is not stable for the same finding")
+	}
+	if first == FindingFingerprint(other.Finding()) {
+		t.Error("FindingFingerprint() collides for different ports on the same group")
+	}
+	sg.Region = "eu-west-1"
+	if first == FindingFingerprint(sg.Finding()) {
+		t.Error("FindingFingerprint() collides for the same group in different regions")
+	}
+
+	// Descriptions such as days until a certificate expires change between runs
+	cert := SecurityFinding{CheckID: CheckIDELBExpiringCertificate, Region: "us-east-1", ResourceID: "listener-1", Key: "arn:cert-1", Description: "expires in 20 days"}
+	tomorrow := cert
+	tomorrow.Description = "expires in 19 days"
+	if FindingFingerprint(cert) != FindingFingerprint(tomorrow) {
+		t.Error("FindingFingerprint() changes when only the description changes")
+	}
+}
+
+func TestPreserveCreatedAt(t *testing.T) {
+	findings := []ASFFFinding{
+		{ID: "dtk/us-east-1/check/a/1", CreatedAt: "2025-06-02T12:00:00Z"},
+		{ID: "dtk/us-east-1/check/b/2", CreatedAt: "2025-06-02T12:00:00Z"},
+	}
+	existing := map[string]string{"dtk/us-east-1/check/a/1": "2025-05-01T08:00:00Z"}
+
+	preserved := preserveCreatedAt(findings, existing)
+
+	if preserved[0].CreatedAt != "2025-05-01T08:00:00Z" {
+		t.Errorf("existing finding CreatedAt = %q, want first import time", preserved[0].CreatedAt)
+	}
+	if preserved[1].CreatedAt != "2025-06-02T12:00:00Z" {
+		t.Errorf("new finding CreatedAt = %q, want now", preserved[1].CreatedAt)
+	}
+	if findings[0].CreatedAt != "2025-06-02T12:00:00Z" {
+		t.Error("preserveCreatedAt() modified its input")
+	}
+}
+
+func TestBuildASFFFindings(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	results := &SecurityResults{
+		PublicS3Buckets: []PublicS3Bucket{
+			{BucketName: "public-site", Region: "us-east-1", PublicAccess: "Public ACL", Severity: SeverityCritical},
+		},
+		OpenSecurityGroups: []OpenSecurityGroup{
+			{GroupID: "sg-1", Region: "us-east-1", Port: 22, Source: "0.0.0.0/0", Severity: SeverityCritical},
+		},
+		Findings: []SecurityFinding{
+			{CheckID: CheckIDPublicAMIs, ResourceType: ResourceTypeAMI, ResourceID: "ami-1", Region: "us-east-1", Severity: SeverityHigh, Description: "AMI is public"},
+		},
+	}
+
+	findings := BuildASFFFindings(results, "us-east-1", "111111111111", now)
+
+	if len(findings) != 3 {
+		t.Fatalf("BuildASFFFindings() returned %d findings, want 3", len(findings))
+	}
+
+	bucket := findings[0]
+	if bucket.SchemaVersion != ASFFSchemaVersion {
+		t.Errorf("SchemaVersion = %q, want %q", bucket.SchemaVersion, ASFFSchemaVersion)
+	}
+	if bucket.ProductArn != "arn:aws:securityhub:us-east-1:111111111111:product/111111111111/default" {
+		t.Errorf("ProductArn = %q", bucket.ProductArn)
+	}
+	if bucket.GeneratorID != "dtk/"+CheckIDPublicS3Buckets {
+		t.Errorf("GeneratorID = %q", bucket.GeneratorID)
+	}
+	if bucket.Severity.Label != "CRITICAL" {
+		t.Errorf("Severity.Label = %q, want CRITICAL", bucket.Severity.Label)
+	}
+	if bucket.CreatedAt != "2025-06-01T12:00:00Z" {
+		t.Errorf("CreatedAt = %q", bucket.CreatedAt)
+	}
+	if bucket.Resources[0].Type != "AwsS3Bucket" || bucket.Resources[0].ID != "arn:aws:s3:::public-site" {
+		t.Errorf("Resources[0] = %+v", bucket.Resources[0])
+	}
+	if !strings.HasPrefix(bucket.ID, "dtk/us-east-1/s3-public-buckets/public-site/") {
+		t.Errorf("ID = %q, want dtk/<region>/<check>/<resource>/<fingerprint>", bucket.ID)
+	}
+
+	if findings[1].Resources[0].Type != "AwsEc2SecurityGroup" {
+		t.Errorf("security group resource type = %q", findings[1].Resources[0].Type)
+	}
+	if findings[2].Resources[0].Type != "Other" || findings[2].Severity.Label != "HIGH" {
+		t.Errorf("AMI finding = %+v", findings[2])
+	}
+}
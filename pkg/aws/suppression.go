@@ -0,0 +1,198 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CheckIDExpiredSuppression identifies findings raised for expired suppressions
+const CheckIDExpiredSuppression = "expired-suppression"
+
+// ResourceTypeSuppression is the resource type reported for expired suppressions
+const ResourceTypeSuppression = "Suppression"
+
+// suppressionDateFormat is the date layout used for suppression expiry dates
+const suppressionDateFormat = "2006-01-02"
+
+// Suppression marks a finding as an accepted risk until it expires
+type Suppression struct {
+	ResourceID string `yaml:"resource_id" json:"resource_id"`
+	Check      string `yaml:"check" json:"check"`
+	Region     string `yaml:"region,omitempty" json:"region,omitempty"`
+	Account    string `yaml:"account,omitempty" json:"account,omitempty"`
+	Reason     string `yaml:"reason" json:"reason"`
+	Owner      string `yaml:"owner" json:"owner"`
+	Expires    string `yaml:"expires" json:"expires"`
+}
+
+// SuppressionFile is the on-disk format of a suppressions file
+type SuppressionFile struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+}
+
+// SuppressedFinding pairs a finding with the suppression that hid it
+type SuppressedFinding struct {
+	Finding     SecurityFinding
+	Suppression Suppression
+}
+
+// LoadSuppressions reads and validates a suppressions file
+func LoadSuppressions(path string) ([]Suppression, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suppressions file: %w", err)
+	}
+
+	return parseSuppressions(data)
+}
+
+// parseSuppressions parses suppressions from YAML and validates every entry
+func parseSuppressions(data []byte) ([]Suppression, error) {
+	var file SuppressionFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse suppressions file: %w", err)
+	}
+
+	for i, s := range file.Suppressions {
+		if err := s.Validate(); err != nil {
+			return nil, fmt.Errorf("suppression %d: %w", i+1, err)
+		}
+	}
+
+	return file.Suppressions, nil
+}
+
+// Validate checks that all mandatory fields are set and the expiry date is valid
+func (s Suppression) Validate() error {
+	missing := make([]string, 0)
+	if s.ResourceID == "" {
+		missing = append(missing, "resource_id")
+	}
+	if s.Check == "" {
+		missing = append(missing, "check")
+	}
+	if s.Reason == "" {
+		missing = append(missing, "reason")
+	}
+	if s.Owner == "" {
+		missing = append(missing, "owner")
+	}
+	if s.Expires == "" {
+		missing = append(missing, "expires")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required fields: %v", missing)
+	}
+
+	if _, err := time.Parse(suppressionDateFormat, s.Expires); err != nil {
+		return fmt.Errorf("invalid expires date %q (expected YYYY-MM-DD)", s.Expires)
+	}
+
+	return nil
+}
+
+// IsExpired reports whether the suppression has expired. A suppression is
+// valid through the end of its expiry date.
+func (s Suppression) IsExpired(now time.Time) bool {
+	expiry, err := time.Parse(suppressionDateFormat, s.Expires)
+	if err != nil {
+		return true
+	}
+	return !now.Before(expiry.AddDate(0, 0, 1))
+}
+
+// inScope reports whether the suppression applies to the given region and account
+func (s Suppression) inScope(region, accountID string) bool {
+	if s.Region != "" && s.Region != region {
+		return false
+	}
+	if s.Account != "" && s.Account != accountID {
+		return false
+	}
+	return true
+}
+
+// Matches reports whether the suppression applies to a finding
+func (s Suppression) Matches(checkID, resourceID, region, accountID string) bool {
+	return s.Check == checkID && s.ResourceID == resourceID && s.inScope(region, accountID)
+}
+
+// ApplySuppressions moves findings covered by an active suppression into
+// Suppressed and reports expired suppressions as findings
+func (r *SecurityResults) ApplySuppressions(suppressions []Suppression, region, accountID string, now time.Time) {
+	active := make([]Suppression, 0, len(suppressions))
+	for _, s := range suppressions {
+		if !s.inScope(region, accountID) {
+			continue
+		}
+		if s.IsExpired(now) {
+			r.Findings = append(r.Findings, expiredSuppressionFinding(s, region))
+			continue
+		}
+		active = append(active, s)
+	}
+
+	match := func(checkID, resourceID string) (Suppression, bool) {
+		for _, s := range active {
+			if s.Matches(checkID, resourceID, region, accountID) {
+				return s, true
+			}
+		}
+		return Suppression{}, false
+	}
+
+	buckets := make([]PublicS3Bucket, 0, len(r.PublicS3Buckets))
+	for _, bucket := range r.PublicS3Buckets {
+		if s, ok := match(CheckIDPublicS3Buckets, bucket.BucketName); ok {
+			r.Suppressed = append(r.Suppressed, SuppressedFinding{
+				Finding:     bucket.Finding(),
+				Suppression: s,
+			})
+			continue
+		}
+		buckets = append(buckets, bucket)
+	}
+	r.PublicS3Buckets = buckets
+
+	groups := make([]OpenSecurityGroup, 0, len(r.OpenSecurityGroups))
+	for _, sg := range r.OpenSecurityGroups {
+		if s, ok := match(CheckIDOpenSecurityGroups, sg.GroupID); ok {
+			r.Suppressed = append(r.Suppressed, SuppressedFinding{
+				Finding:     sg.Finding(),
+				Suppression: s,
+			})
+			continue
+		}
+		groups = append(groups, sg)
+	}
+	r.OpenSecurityGroups = groups
+
+	findings := make([]SecurityFinding, 0, len(r.Findings))
+	for _, finding := range r.Findings {
+		if s, ok := match(finding.CheckID, finding.ResourceID); ok {
+			r.Suppressed = append(r.Suppressed, SuppressedFinding{
+				Finding:     finding,
+				Suppression: s,
+			})
+			continue
+		}
+		findings = append(findings, finding)
+	}
+	r.Findings = findings
+}
+
+// expiredSuppressionFinding reports an expired suppression so it gets reviewed
+func expiredSuppressionFinding(s Suppression, region string) SecurityFinding {
+	return SecurityFinding{
+		CheckID:      CheckIDExpiredSuppression,
+		ResourceType: ResourceTypeSuppression,
+		ResourceID:   s.ResourceID,
+		Region:       region,
+		Severity:     SeverityMedium,
+		Description:  fmt.Sprintf("Suppression for %s expired on %s (owner: %s)", s.Check, s.Expires, s.Owner),
+		Key:          s.Check,
+	}
+}
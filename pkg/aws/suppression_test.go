@@ -0,0 +1,285 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSuppressions(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		wantCount int
+		wantErr   string
+	}{
+		{
+			name: "valid file",
+			yaml: `
+suppressions:
+  - resource_id: public-website
+    check: s3-public-buckets
+    reason: Static website bucket
+    owner: web-team
+    expires: 2030-01-31
+  - resource_id: sg-bastion
+    check: open-security-groups
+    region: eu-west-1
+    account: "111111111111"
+    reason: Bastion host
+    owner: platform
+    expires: 2030-06-30
+`,
+			wantCount: 2,
+		},
+		{
+			name:      "empty file",
+			yaml:      ``,
+			wantCount: 0,
+		},
+		{
+			name: "missing reason and owner",
+			yaml: `
+suppressions:
+  - resource_id: public-website
+    check: s3-public-buckets
+    expires: 2030-01-31
+`,
+			wantErr: "reason owner",
+		},
+		{
+			name: "missing expiry",
+			yaml: `
+suppressions:
+  - resource_id: public-website
+    check: s3-public-buckets
+    reason: Static website bucket
+    owner: web-team
+`,
+			wantErr: "expires",
+		},
+		{
+			name: "invalid expiry date",
+			yaml: `
+suppressions:
+  - resource_id: public-website
+    check: s3-public-buckets
+    reason: Static website bucket
+    owner: web-team
+    expires: 31/01/2030
+`,
+			wantErr: "invalid expires date",
+		},
+		{
+			name:    "malformed yaml",
+			yaml:    "suppressions: [",
+			wantErr: "failed to parse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suppressions, err := parseSuppressions([]byte(tt.yaml))
+
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("parseSuppressions() error = nil, want error containing %q", tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseSuppressions() error = %q, want it to contain %q", err.Error(), tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseSuppressions() unexpected error: %v", err)
+			}
+			if len(suppressions) != tt.wantCount {
+				t.Errorf("parseSuppressions() returned %d suppressions, want %d", len(suppressions), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestLoadSuppressions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suppressions.yaml")
+	content := `
+suppressions:
+  - resource_id: public-website
+    check: s3-public-buckets
+    reason: Static website bucket
+    owner: web-team
+    expires: 2030-01-31
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	suppressions, err := LoadSuppressions(path)
+	if err != nil {
+		t.Fatalf("LoadSuppressions() unexpected error: %v", err)
+	}
+	if len(suppressions) != 1 || suppressions[0].Owner != "web-team" {
+		t.Errorf("LoadSuppressions() = %+v, want one suppression owned by web-team", suppressions)
+	}
+
+	if _, err := LoadSuppressions(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadSuppressions() with missing file returned nil error")
+	}
+}
+
+func TestSuppressionIsExpired(t *testing.T) {
+	s := Suppression{Expires: "2025-03-15"}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{"day before expiry", time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC), false},
+		{"on expiry date", time.Date(2025, 3, 15, 23, 59, 0, 0, time.UTC), false},
+		{"day after expiry", time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.IsExpired(tt.now); got != tt.want {
+				t.Errorf("IsExpired(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuppressionMatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		suppression Suppression
+		checkID     string
+		resourceID  string
+		region      string
+		accountID   string
+		want        bool
+	}{
+		{
+			name:        "resource and check match",
+			suppression: Suppression{ResourceID: "bucket", Check: CheckIDPublicS3Buckets},
+			checkID:     CheckIDPublicS3Buckets,
+			resourceID:  "bucket",
+			region:      "us-east-1",
+			want:        true,
+		},
+		{
+			name:        "different check",
+			suppression: Suppression{ResourceID: "bucket", Check: CheckIDPublicS3Buckets},
+			checkID:     CheckIDPublicAMIs,
+			resourceID:  "bucket",
+			region:      "us-east-1",
+			want:        false,
+		},
+		{
+			name:        "region scoped match",
+			suppression: Suppression{ResourceID: "sg-1", Check: CheckIDOpenSecurityGroups, Region: "eu-west-1"},
+			checkID:     CheckIDOpenSecurityGroups,
+			resourceID:  "sg-1",
+			region:      "eu-west-1",
+			want:        true,
+		},
+		{
+			name:        "region scoped mismatch",
+			suppression: Suppression{ResourceID: "sg-1", Check: CheckIDOpenSecurityGroups, Region: "eu-west-1"},
+			checkID:     CheckIDOpenSecurityGroups,
+			resourceID:  "sg-1",
+			region:      "us-east-1",
+			want:        false,
+		},
+		{
+			name:        "account scoped mismatch",
+			suppression: Suppression{ResourceID: "sg-1", Check: CheckIDOpenSecurityGroups, Account: "111111111111"},
+			checkID:     CheckIDOpenSecurityGroups,
+			resourceID:  "sg-1",
+			region:      "us-east-1",
+			accountID:   "222222222222",
+			want:        false,
+		},
+		{
+			name:        "account scoped with unknown account",
+			suppression: Suppression{ResourceID: "sg-1", Check: CheckIDOpenSecurityGroups, Account: "111111111111"},
+			checkID:     CheckIDOpenSecurityGroups,
+			resourceID:  "sg-1",
+			region:      "us-east-1",
+			accountID:   "",
+			want:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.suppression.Matches(tt.checkID, tt.resourceID, tt.region, tt.accountID)
+			if got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplySuppressions(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	results := &SecurityResults{
+		PublicS3Buckets: []PublicS3Bucket{
+			{BucketName: "public-website", Region: "us-east-1", PublicAccess: "Public ACL", Severity: SeverityCritical},
+			{BucketName: "leaky-bucket", Region: "us-east-1", PublicAccess: "Public ACL", Severity: SeverityCritical},
+		},
+		OpenSecurityGroups: []OpenSecurityGroup{
+			{GroupID: "sg-bastion", Region: "us-east-1", Port: 22, Source: "0.0.0.0/0", Severity: SeverityCritical},
+			{GroupID: "sg-db", Region: "us-east-1", Port: 5432, Source: "0.0.0.0/0", Severity: SeverityCritical},
+		},
+		Findings: []SecurityFinding{
+			{CheckID: CheckIDPublicAMIs, ResourceID: "ami-123", Severity: SeverityHigh},
+			{CheckID: CheckIDRDSEncryption, ResourceID: "db-legacy", Severity: SeverityHigh},
+		},
+	}
+
+	suppressions := []Suppression{
+		{ResourceID: "public-website", Check: CheckIDPublicS3Buckets, Reason: "website", Owner: "web", Expires: "2025-12-31"},
+		{ResourceID: "sg-bastion", Check: CheckIDOpenSecurityGroups, Region: "us-east-1", Reason: "bastion", Owner: "platform", Expires: "2025-12-31"},
+		{ResourceID: "ami-123", Check: CheckIDPublicAMIs, Reason: "marketplace", Owner: "product", Expires: "2025-12-31"},
+		// Expired: the finding stays active and the suppression is reported
+		{ResourceID: "db-legacy", Check: CheckIDRDSEncryption, Reason: "migration", Owner: "data", Expires: "2025-05-01"},
+		// Out of scope for this region: ignored entirely
+		{ResourceID: "sg-db", Check: CheckIDOpenSecurityGroups, Region: "eu-west-1", Reason: "n/a", Owner: "x", Expires: "2025-01-01"},
+	}
+
+	results.ApplySuppressions(suppressions, "us-east-1", "111111111111", now)
+
+	if len(results.PublicS3Buckets) != 1 || results.PublicS3Buckets[0].BucketName != "leaky-bucket" {
+		t.Errorf("PublicS3Buckets = %+v, want only leaky-bucket", results.PublicS3Buckets)
+	}
+	if len(results.OpenSecurityGroups) != 1 || results.OpenSecurityGroups[0].GroupID != "sg-db" {
+		t.Errorf("OpenSecurityGroups = %+v, want only sg-db", results.OpenSecurityGroups)
+	}
+	if len(results.Suppressed) != 3 {
+		t.Fatalf("Suppressed = %d findings, want 3", len(results.Suppressed))
+	}
+
+	expired := results.FindingsForCheck(CheckIDExpiredSuppression)
+	if len(expired) != 1 {
+		t.Fatalf("expired suppression findings = %d, want 1", len(expired))
+	}
+	if expired[0].ResourceID != "db-legacy" {
+		t.Errorf("expired suppression ResourceID = %q, want db-legacy", expired[0].ResourceID)
+	}
+	if len(results.FindingsForCheck(CheckIDRDSEncryption)) != 1 {
+		t.Error("finding covered by an expired suppression should remain active")
+	}
+	if len(results.FindingsForCheck(CheckIDPublicAMIs)) != 0 {
+		t.Error("suppressed AMI finding should be removed from Findings")
+	}
+
+	counts := results.CountBySeverity()
+	if counts[SeverityCritical] != 2 {
+		t.Errorf("Critical count = %d, want 2 (suppressed findings excluded)", counts[SeverityCritical])
+	}
+}
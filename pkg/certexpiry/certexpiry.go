@@ -0,0 +1,52 @@
+// Package certexpiry holds the certificate expiry status buckets shared by the
+// Kubernetes and AWS certificate checks
+package certexpiry
+
+import "time"
+
+// Certificate expiry statuses
+const (
+	Valid        = "valid"
+	ExpiringSoon = "expiring-soon"
+	Critical     = "critical"
+	Expired      = "expired"
+)
+
+// Days before expiry at which a certificate becomes critical or expiring soon
+const (
+	CriticalDays     = 7
+	ExpiringSoonDays = 30
+)
+
+const day = 24 * time.Hour
+
+// Status returns the expiry status of a certificate that is valid until
+// notAfter. A certificate is expired as soon as notAfter has passed.
+func Status(notAfter, now time.Time) string {
+	remaining := notAfter.Sub(now)
+	switch {
+	case remaining <= 0:
+		return Expired
+	case remaining < CriticalDays*day:
+		return Critical
+	case remaining < ExpiringSoonDays*day:
+		return ExpiringSoon
+	default:
+		return Valid
+	}
+}
+
+// StatusForDays returns the expiry status of a certificate with the given
+// whole days remaining
+func StatusForDays(daysRemaining int) string {
+	switch {
+	case daysRemaining < 0:
+		return Expired
+	case daysRemaining < CriticalDays:
+		return Critical
+	case daysRemaining < ExpiringSoonDays:
+		return ExpiringSoon
+	default:
+		return Valid
+	}
+}
@@ -0,0 +1,52 @@
+package certexpiry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		want     string
+	}{
+		{"expired an hour ago", now.Add(-time.Hour), Expired},
+		{"expires now", now, Expired},
+		{"expires in an hour", now.Add(time.Hour), Critical},
+		{"expires in 6 days 23 hours", now.Add(7*24*time.Hour - time.Hour), Critical},
+		{"expires in 7 days", now.AddDate(0, 0, 7), ExpiringSoon},
+		{"expires in 29 days", now.AddDate(0, 0, 29), ExpiringSoon},
+		{"expires in 30 days", now.AddDate(0, 0, 30), Valid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Status(tt.notAfter, now); got != tt.want {
+				t.Errorf("Status() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusForDays(t *testing.T) {
+	tests := []struct {
+		days int
+		want string
+	}{
+		{-1, Expired},
+		{0, Critical},
+		{6, Critical},
+		{7, ExpiringSoon},
+		{29, ExpiringSoon},
+		{30, Valid},
+	}
+
+	for _, tt := range tests {
+		if got := StatusForDays(tt.days); got != tt.want {
+			t.Errorf("StatusForDays(%d) = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}
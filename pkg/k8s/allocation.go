@@ -0,0 +1,350 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IdleAllocation is the name of the cost of node capacity no pod requested
+const IdleAllocation = "idle"
+
+// NoLabelValue groups pods whose label is set neither on the pod nor on its
+// namespace
+const NoLabelValue = "unlabeled"
+
+// cpuCostWeight is the share of a node's cost attributed to CPU, the rest being
+// attributed to memory
+const cpuCostWeight = 0.5
+
+const bytesPerGiB = 1024 * 1024 * 1024
+
+// Node labels used to price nodes
+const (
+	instanceTypeLabel = "node.kubernetes.io/instance-type"
+	regionLabel       = "topology.kubernetes.io/region"
+)
+
+// PodRequests is the CPU (cores) and memory (bytes) a running pod requests
+type PodRequests struct {
+	Namespace string
+	Name      string
+	Labels    map[string]string
+	CPU       float64
+	Memory    float64
+}
+
+// NodeRequests is a node's allocatable capacity and the pods scheduled on it
+type NodeRequests struct {
+	Name string
+	// InstanceID is the EC2 instance behind the node, empty for nodes that are
+	// not EC2 instances, such as Fargate
+	InstanceID        string
+	InstanceType      string
+	Region            string
+	CPUAllocatable    float64
+	MemoryAllocatable float64
+	Pods              []PodRequests
+}
+
+// ClusterRequests holds the requests of every node and the namespace labels
+// pods fall back to
+type ClusterRequests struct {
+	Nodes           []NodeRequests
+	NamespaceLabels map[string]map[string]string
+}
+
+// AllocationOptions sets the period and labels costs are allocated over
+type AllocationOptions struct {
+	Days      int
+	LabelKeys []string
+}
+
+// CostAllocation is the cost allocated to a namespace or label value
+type CostAllocation struct {
+	Name string
+	Pods int
+	// CPURequest is in cores and MemoryRequest in GiB
+	CPURequest    float64
+	MemoryRequest float64
+	CPUCost       float64
+	MemoryCost    float64
+	Cost          float64
+}
+
+// NodeCost is the cost of one node and how much of it pods requested
+type NodeCost struct {
+	Name              string
+	InstanceID        string
+	InstanceType      string
+	Priced            bool
+	HourlyCost        float64
+	Cost              float64
+	AllocatedCost     float64
+	IdleCost          float64
+	CPUAllocatable    float64
+	CPURequested      float64
+	MemoryAllocatable float64
+	MemoryRequested   float64
+}
+
+// LabelAllocation is the cost allocated to each value of one label
+type LabelAllocation struct {
+	Key    string
+	Values []CostAllocation
+}
+
+// AllocationResults is node cost over a period, split between namespaces and
+// label values by requests, with unrequested capacity reported as idle
+type AllocationResults struct {
+	Days          int
+	TotalCost     float64
+	AllocatedCost float64
+	IdleCost      float64
+	Nodes         []NodeCost
+	Namespaces    []CostAllocation
+	Labels        []LabelAllocation
+	// UnpricedNodes are left out of the totals, as their cost is unknown
+	UnpricedNodes []string
+}
+
+// GetClusterRequests returns the CPU and memory requests of the pods running
+// on each node
+func (h *HealthChecker) GetClusterRequests(ctx context.Context) (*ClusterRequests, error) {
+	nodes, err := h.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	pods, err := h.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	namespaces, err := h.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	cluster := &ClusterRequests{
+		Nodes:           make([]NodeRequests, 0, len(nodes.Items)),
+		NamespaceLabels: make(map[string]map[string]string, len(namespaces.Items)),
+	}
+	for _, ns := range namespaces.Items {
+		cluster.NamespaceLabels[ns.Name] = ns.Labels
+	}
+
+	byNode := make(map[string]int, len(nodes.Items))
+	for _, node := range nodes.Items {
+		byNode[node.Name] = len(cluster.Nodes)
+		cluster.Nodes = append(cluster.Nodes, NodeRequests{
+			Name:              node.Name,
+			InstanceID:        parseProviderID(node.Spec.ProviderID),
+			InstanceType:      node.Labels[instanceTypeLabel],
+			Region:            node.Labels[regionLabel],
+			CPUAllocatable:    node.Status.Allocatable.Cpu().AsApproximateFloat64(),
+			MemoryAllocatable: node.Status.Allocatable.Memory().AsApproximateFloat64(),
+			Pods:              make([]PodRequests, 0),
+		})
+	}
+
+	for _, pod := range pods.Items {
+		// Finished pods no longer hold their requests
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		i, ok := byNode[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		cpu, memory := podRequests(pod)
+		cluster.Nodes[i].Pods = append(cluster.Nodes[i].Pods, PodRequests{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Labels:    pod.Labels,
+			CPU:       cpu,
+			Memory:    memory,
+		})
+	}
+
+	return cluster, nil
+}
+
+// AllocateCosts splits the cost of each node over the period between the pods
+// on it. Half of a node's cost is CPU and half memory, and each pod is charged
+// its share of the node's allocatable CPU and memory. hourlyCosts is keyed by
+// node name; nodes without a cost are listed as unpriced.
+func AllocateCosts(cluster *ClusterRequests, hourlyCosts map[string]float64, opts AllocationOptions) *AllocationResults {
+	hours := float64(opts.Days * 24)
+	results := &AllocationResults{
+		Days:          opts.Days,
+		Nodes:         make([]NodeCost, 0, len(cluster.Nodes)),
+		Namespaces:    make([]CostAllocation, 0),
+		Labels:        make([]LabelAllocation, 0, len(opts.LabelKeys)),
+		UnpricedNodes: make([]string, 0),
+	}
+
+	namespaces := make(map[string]*CostAllocation)
+	labels := make([]map[string]*CostAllocation, len(opts.LabelKeys))
+	for i := range labels {
+		labels[i] = make(map[string]*CostAllocation)
+	}
+
+	for _, node := range cluster.Nodes {
+		nodeCost := NodeCost{
+			Name:              node.Name,
+			InstanceID:        node.InstanceID,
+			InstanceType:      node.InstanceType,
+			CPUAllocatable:    node.CPUAllocatable,
+			MemoryAllocatable: node.MemoryAllocatable / bytesPerGiB,
+		}
+		for _, pod := range node.Pods {
+			nodeCost.CPURequested += pod.CPU
+			nodeCost.MemoryRequested += pod.Memory
+		}
+
+		hourly, priced := hourlyCosts[node.Name]
+		if !priced {
+			nodeCost.MemoryRequested /= bytesPerGiB
+			results.Nodes = append(results.Nodes, nodeCost)
+			results.UnpricedNodes = append(results.UnpricedNodes, node.Name)
+			continue
+		}
+		nodeCost.Priced = true
+		nodeCost.HourlyCost = hourly
+		nodeCost.Cost = hourly * hours
+
+		// Requests above allocatable capacity share the node's cost
+		cpuCapacity := max(node.CPUAllocatable, nodeCost.CPURequested)
+		memoryCapacity := max(node.MemoryAllocatable, nodeCost.MemoryRequested)
+
+		for _, pod := range node.Pods {
+			cpuCost, memoryCost := 0.0, 0.0
+			if cpuCapacity > 0 {
+				cpuCost = nodeCost.Cost * cpuCostWeight * pod.CPU / cpuCapacity
+			}
+			if memoryCapacity > 0 {
+				memoryCost = nodeCost.Cost * (1 - cpuCostWeight) * pod.Memory / memoryCapacity
+			}
+			nodeCost.AllocatedCost += cpuCost + memoryCost
+
+			allocate(namespaces, pod.Namespace, pod, cpuCost, memoryCost)
+			for i, key := range opts.LabelKeys {
+				allocate(labels[i], labelValue(pod, key, cluster.NamespaceLabels), pod, cpuCost, memoryCost)
+			}
+		}
+
+		nodeCost.IdleCost = nodeCost.Cost - nodeCost.AllocatedCost
+		nodeCost.MemoryRequested /= bytesPerGiB
+		results.TotalCost += nodeCost.Cost
+		results.AllocatedCost += nodeCost.AllocatedCost
+		results.IdleCost += nodeCost.IdleCost
+		results.Nodes = append(results.Nodes, nodeCost)
+	}
+
+	results.Namespaces = sortedAllocations(namespaces)
+	for i, key := range opts.LabelKeys {
+		results.Labels = append(results.Labels, LabelAllocation{Key: key, Values: sortedAllocations(labels[i])})
+	}
+	sort.SliceStable(results.Nodes, func(i, j int) bool {
+		if results.Nodes[i].Cost != results.Nodes[j].Cost {
+			return results.Nodes[i].Cost > results.Nodes[j].Cost
+		}
+		return results.Nodes[i].Name < results.Nodes[j].Name
+	})
+
+	return results
+}
+
+func allocate(allocations map[string]*CostAllocation, name string, pod PodRequests, cpuCost, memoryCost float64) {
+	allocation, ok := allocations[name]
+	if !ok {
+		allocation = &CostAllocation{Name: name}
+		allocations[name] = allocation
+	}
+	allocation.Pods++
+	allocation.CPURequest += pod.CPU
+	allocation.MemoryRequest += pod.Memory / bytesPerGiB
+	allocation.CPUCost += cpuCost
+	allocation.MemoryCost += memoryCost
+	allocation.Cost += cpuCost + memoryCost
+}
+
+// sortedAllocations returns allocations by cost, highest first
+func sortedAllocations(allocations map[string]*CostAllocation) []CostAllocation {
+	sorted := make([]CostAllocation, 0, len(allocations))
+	for _, allocation := range allocations {
+		sorted = append(sorted, *allocation)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Cost != sorted[j].Cost {
+			return sorted[i].Cost > sorted[j].Cost
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// labelValue returns a pod's label, falling back to its namespace's label
+func labelValue(pod PodRequests, key string, namespaceLabels map[string]map[string]string) string {
+	if value, ok := pod.Labels[key]; ok && value != "" {
+		return value
+	}
+	if value, ok := namespaceLabels[pod.Namespace][key]; ok && value != "" {
+		return value
+	}
+	return NoLabelValue
+}
+
+// podRequests returns the CPU (cores) and memory (bytes) a pod reserves on its
+// node, the way the scheduler counts them: the larger of its containers and
+// any one init container, plus sidecars and pod overhead
+func podRequests(pod corev1.Pod) (float64, float64) {
+	var cpu, memory float64
+	for _, container := range pod.Spec.Containers {
+		cpu += container.Resources.Requests.Cpu().AsApproximateFloat64()
+		memory += container.Resources.Requests.Memory().AsApproximateFloat64()
+	}
+
+	// Sidecars keep running, so they add to the containers and to every init
+	// container started after them
+	var sidecarCPU, sidecarMemory, initCPU, initMemory float64
+	for _, container := range pod.Spec.InitContainers {
+		containerCPU := container.Resources.Requests.Cpu().AsApproximateFloat64()
+		containerMemory := container.Resources.Requests.Memory().AsApproximateFloat64()
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			sidecarCPU += containerCPU
+			sidecarMemory += containerMemory
+			initCPU = max(initCPU, sidecarCPU)
+			initMemory = max(initMemory, sidecarMemory)
+			continue
+		}
+		initCPU = max(initCPU, sidecarCPU+containerCPU)
+		initMemory = max(initMemory, sidecarMemory+containerMemory)
+	}
+	cpu = max(cpu+sidecarCPU, initCPU)
+	memory = max(memory+sidecarMemory, initMemory)
+
+	if pod.Spec.Overhead != nil {
+		cpu += pod.Spec.Overhead.Cpu().AsApproximateFloat64()
+		memory += pod.Spec.Overhead.Memory().AsApproximateFloat64()
+	}
+	return cpu, memory
+}
+
+// parseProviderID returns the EC2 instance ID of an aws:///<zone>/<instance-id>
+// provider ID, or an empty string for other providers and Fargate nodes
+func parseProviderID(providerID string) string {
+	if !strings.HasPrefix(providerID, "aws://") {
+		return ""
+	}
+	id := providerID[strings.LastIndex(providerID, "/")+1:]
+	if !strings.HasPrefix(id, "i-") {
+		return ""
+	}
+	return id
+}
@@ -0,0 +1,198 @@
+package k8s
+
+import (
+	"math"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const gib = 1024 * 1024 * 1024
+
+func TestAllocateCosts(t *testing.T) {
+	cluster := &ClusterRequests{
+		Nodes: []NodeRequests{
+			{
+				Name:              "node-a",
+				InstanceID:        "i-0aaa",
+				CPUAllocatable:    4,
+				MemoryAllocatable: 16 * gib,
+				Pods: []PodRequests{
+					{Namespace: "payments", Name: "api", Labels: map[string]string{"team": "billing"}, CPU: 2, Memory: 4 * gib},
+					{Namespace: "search", Name: "indexer", CPU: 1, Memory: 8 * gib},
+				},
+			},
+			{
+				Name:              "node-b",
+				InstanceID:        "i-0bbb",
+				CPUAllocatable:    2,
+				MemoryAllocatable: 8 * gib,
+				Pods: []PodRequests{
+					{Namespace: "payments", Name: "worker", CPU: 2, Memory: 8 * gib},
+				},
+			},
+			{Name: "fargate-ip-10-0-0-1", CPUAllocatable: 1, MemoryAllocatable: 2 * gib},
+		},
+		NamespaceLabels: map[string]map[string]string{
+			"payments": {"team": "checkout"},
+		},
+	}
+	hourlyCosts := map[string]float64{"node-a": 1, "node-b": 0.5}
+
+	results := AllocateCosts(cluster, hourlyCosts, AllocationOptions{Days: 1, LabelKeys: []string{"team"}})
+
+	// node-a costs $24: api gets $6 CPU + $3 memory, indexer $3 + $6
+	// node-b costs $12 and is fully requested by worker
+	assertCost(t, "TotalCost", results.TotalCost, 36)
+	assertCost(t, "AllocatedCost", results.AllocatedCost, 30)
+	assertCost(t, "IdleCost", results.IdleCost, 6)
+
+	if len(results.Namespaces) != 2 || results.Namespaces[0].Name != "payments" || results.Namespaces[0].Pods != 2 {
+		t.Fatalf("Namespaces = %+v, want payments first with 2 pods", results.Namespaces)
+	}
+	assertCost(t, "payments", results.Namespaces[0].Cost, 21)
+	assertCost(t, "payments CPU", results.Namespaces[0].CPUCost, 12)
+	assertCost(t, "payments memory", results.Namespaces[0].MemoryRequest, 12)
+	assertCost(t, "search", results.Namespaces[1].Cost, 9)
+
+	// The pod label wins over the namespace label
+	want := map[string]float64{"checkout": 12, "billing": 9, NoLabelValue: 9}
+	if len(results.Labels) != 1 || results.Labels[0].Key != "team" || len(results.Labels[0].Values) != len(want) {
+		t.Fatalf("Labels = %+v", results.Labels)
+	}
+	for _, value := range results.Labels[0].Values {
+		assertCost(t, "team="+value.Name, value.Cost, want[value.Name])
+	}
+
+	if len(results.UnpricedNodes) != 1 || results.UnpricedNodes[0] != "fargate-ip-10-0-0-1" {
+		t.Errorf("UnpricedNodes = %v", results.UnpricedNodes)
+	}
+	if results.Nodes[0].Name != "node-a" || results.Nodes[0].MemoryRequested != 12 || results.Nodes[0].MemoryAllocatable != 16 {
+		t.Errorf("Nodes[0] = %+v, want node-a with 12 of 16 GiB requested", results.Nodes[0])
+	}
+}
+
+func TestAllocateCostsOvercommitted(t *testing.T) {
+	// Requests above allocatable capacity split the node with nothing idle
+	cluster := &ClusterRequests{
+		Nodes: []NodeRequests{{
+			Name:              "node",
+			CPUAllocatable:    1,
+			MemoryAllocatable: 1 * gib,
+			Pods: []PodRequests{
+				{Namespace: "a", CPU: 1, Memory: 1 * gib},
+				{Namespace: "b", CPU: 1, Memory: 1 * gib},
+			},
+		}},
+	}
+
+	results := AllocateCosts(cluster, map[string]float64{"node": 1}, AllocationOptions{Days: 1})
+	assertCost(t, "IdleCost", results.IdleCost, 0)
+	for _, namespace := range results.Namespaces {
+		assertCost(t, namespace.Name, namespace.Cost, 12)
+	}
+}
+
+func TestPodRequests(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	tests := []struct {
+		name       string
+		spec       corev1.PodSpec
+		wantCPU    float64
+		wantMemory float64
+	}{
+		{
+			name:       "containers are summed",
+			spec:       corev1.PodSpec{Containers: []corev1.Container{container("250m", "128Mi"), container("750m", "384Mi")}},
+			wantCPU:    1,
+			wantMemory: 512 * 1024 * 1024,
+		},
+		{
+			name: "larger init container wins",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{container("2", "64Mi")},
+				Containers:     []corev1.Container{container("500m", "256Mi")},
+			},
+			wantCPU:    2,
+			wantMemory: 256 * 1024 * 1024,
+		},
+		{
+			name: "sidecars add to containers and later init containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					sidecar(container("100m", "64Mi"), &always),
+					container("1", "64Mi"),
+				},
+				Containers: []corev1.Container{container("500m", "256Mi")},
+			},
+			wantCPU:    1.1,
+			wantMemory: 320 * 1024 * 1024,
+		},
+		{
+			name: "overhead is added",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{container("1", "1Gi")},
+				Overhead: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("250m"),
+					corev1.ResourceMemory: resource.MustParse("120Mi"),
+				},
+			},
+			wantCPU:    1.25,
+			wantMemory: (1024 + 120) * 1024 * 1024,
+		},
+		{
+			name: "no requests",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "best-effort"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, memory := podRequests(corev1.Pod{Spec: tt.spec})
+			assertCost(t, "cpu", cpu, tt.wantCPU)
+			assertCost(t, "memory", memory, tt.wantMemory)
+		})
+	}
+}
+
+func TestParseProviderID(t *testing.T) {
+	tests := []struct {
+		providerID string
+		want       string
+	}{
+		{"aws:///us-east-1a/i-0123456789abcdef0", "i-0123456789abcdef0"},
+		{"aws:///eu-west-1b/a1b2c3/fargate-ip-10-0-1-2.eu-west-1.compute.internal", ""},
+		{"gce://project/zone/instance", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseProviderID(tt.providerID); got != tt.want {
+			t.Errorf("parseProviderID(%q) = %q, want %q", tt.providerID, got, tt.want)
+		}
+	}
+}
+
+func container(cpu, memory string) corev1.Container {
+	return corev1.Container{
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func sidecar(c corev1.Container, policy *corev1.ContainerRestartPolicy) corev1.Container {
+	c.RestartPolicy = policy
+	return c
+}
+
+func assertCost(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-6 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
@@ -8,11 +8,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ahmedfawzy/devops-toolkit/pkg/certexpiry"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // CertificateInfo holds information about a TLS certificate
 type CertificateInfo struct {
+	Cluster       string
 	SecretName    string
 	Namespace     string
 	DNSNames      []string
@@ -25,6 +27,9 @@ type CertificateInfo struct {
 
 // CertificateResults holds the results of certificate scanning
 type CertificateResults struct {
+	// Clusters are the kubeconfig contexts the results cover
+	Clusters       []string
+	FailedClusters []ClusterFailure
 	Certificates   []CertificateInfo
 	TotalScanned   int
 	ExpiringCount  int
@@ -41,6 +46,7 @@ func (h *HealthChecker) CheckCertificateExpiry(ctx context.Context, namespace st
 	}
 
 	results := &CertificateResults{
+		Clusters:     []string{h.cluster},
 		Certificates: make([]CertificateInfo, 0),
 	}
 
@@ -69,6 +75,7 @@ func (h *HealthChecker) CheckCertificateExpiry(ctx context.Context, namespace st
 		}
 
 		// Set secret metadata
+		certInfo.Cluster = h.cluster
 		certInfo.SecretName = secret.Name
 		certInfo.Namespace = secret.Namespace
 
@@ -134,14 +141,7 @@ func parseCertificate(certData []byte) (CertificateInfo, error) {
 
 // getCertificateStatus determines the status based on days remaining
 func getCertificateStatus(daysRemaining int) string {
-	if daysRemaining < 0 {
-		return "expired"
-	} else if daysRemaining < 7 {
-		return "critical"
-	} else if daysRemaining < 30 {
-		return "expiring-soon"
-	}
-	return "valid"
+	return certexpiry.StatusForDays(daysRemaining)
 }
 
 // FormatDNSNames formats the DNS names as a comma-separated string
@@ -162,7 +162,12 @@ func (c *CertificateInfo) FormatDNSNames() string {
 
 // GetColorCode returns the color code for terminal output based on status
 func (c *CertificateInfo) GetColorCode() string {
-	switch c.Status {
+	return StatusColorCode(c.Status)
+}
+
+// StatusColorCode returns the terminal color code for a certificate status
+func StatusColorCode(status string) string {
+	switch status {
 	case "expired", "critical":
 		return "\033[31m" // Red
 	case "expiring-soon":
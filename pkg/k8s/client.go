@@ -0,0 +1,121 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ClientOptions selects the cluster to connect to and tunes the client
+type ClientOptions struct {
+	// Kubeconfig is the kubeconfig file to load. When empty, $KUBECONFIG and
+	// then ~/.kube/config are used, falling back to the in-cluster service
+	// account when neither exists
+	Kubeconfig string
+	// Context is the kubeconfig context to use instead of the current one
+	Context string
+	// QPS and Burst limit requests to the API server; zero keeps the
+	// client-go defaults
+	QPS   float32
+	Burst int
+	// Timeout bounds each request to the API server; zero means no timeout
+	Timeout time.Duration
+}
+
+// InClusterName is the cluster name used for the in-cluster config, which has
+// no kubeconfig context
+const InClusterName = "in-cluster"
+
+// LoadConfig builds the REST config for the cluster selected by opts
+func LoadConfig(opts ClientOptions) (*rest.Config, error) {
+	config, _, err := loadConfig(opts)
+	return config, err
+}
+
+// loadConfig builds the REST config for the cluster selected by opts and
+// returns the name of the context it came from
+func loadConfig(opts ClientOptions) (*rest.Config, string, error) {
+	config, name, err := loadBaseConfig(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if opts.QPS > 0 {
+		config.QPS = opts.QPS
+	}
+	if opts.Burst > 0 {
+		config.Burst = opts.Burst
+	}
+	if opts.Timeout > 0 {
+		config.Timeout = opts.Timeout
+	}
+
+	return config, name, nil
+}
+
+func loadBaseConfig(opts ClientOptions) (*rest.Config, string, error) {
+	clientConfig := newClientConfig(opts)
+
+	raw, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	// Without any kubeconfig we are most likely running as a pod
+	if opts.Kubeconfig == "" && opts.Context == "" && len(raw.Contexts) == 0 {
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			if errors.Is(err, rest.ErrNotInCluster) {
+				return nil, "", fmt.Errorf("no kubeconfig found and not running in a cluster: set --kubeconfig or $KUBECONFIG")
+			}
+			return nil, "", fmt.Errorf("failed to load in-cluster config: %w", err)
+		}
+		return config, InClusterName, nil
+	}
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	name := opts.Context
+	if name == "" {
+		name = raw.CurrentContext
+	}
+
+	return config, name, nil
+}
+
+// ListContexts returns the names of the contexts in the kubeconfig selected
+// by opts, sorted by name
+func ListContexts(opts ClientOptions) ([]string, error) {
+	raw, err := newClientConfig(opts).RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	contexts := make([]string, 0, len(raw.Contexts))
+	for name := range raw.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+
+	return contexts, nil
+}
+
+// newClientConfig returns the kubeconfig loader for opts, honouring
+// $KUBECONFIG when no file is given
+func newClientConfig(opts ClientOptions) clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if opts.Kubeconfig != "" {
+		rules.ExplicitPath = opts.Kubeconfig
+	}
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: opts.Context}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+}
@@ -0,0 +1,152 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+- name: staging
+  cluster:
+    server: https://staging.example.com
+- name: prod
+  cluster:
+    server: https://prod.example.com
+contexts:
+- name: staging
+  context:
+    cluster: staging
+    user: admin
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+func writeKubeconfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeKubeconfig(t, testKubeconfig)
+
+	tests := []struct {
+		name           string
+		opts           ClientOptions
+		kubeconfigEnv  string
+		expectedHost   string
+		expectedErrMsg string
+	}{
+		{
+			name:         "Explicit kubeconfig uses current context",
+			opts:         ClientOptions{Kubeconfig: path},
+			expectedHost: "https://staging.example.com",
+		},
+		{
+			name:         "Context overrides current context",
+			opts:         ClientOptions{Kubeconfig: path, Context: "prod"},
+			expectedHost: "https://prod.example.com",
+		},
+		{
+			name:          "KUBECONFIG is honoured",
+			opts:          ClientOptions{Context: "prod"},
+			kubeconfigEnv: path,
+			expectedHost:  "https://prod.example.com",
+		},
+		{
+			name:           "Unknown context",
+			opts:           ClientOptions{Kubeconfig: path, Context: "dev"},
+			expectedErrMsg: `context "dev" does not exist`,
+		},
+		{
+			name:           "Missing kubeconfig file",
+			opts:           ClientOptions{Kubeconfig: filepath.Join(t.TempDir(), "missing")},
+			expectedErrMsg: "failed to load kubeconfig",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KUBECONFIG", tt.kubeconfigEnv)
+
+			config, err := LoadConfig(tt.opts)
+			if tt.expectedErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErrMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErrMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.Host != tt.expectedHost {
+				t.Errorf("expected host %s, got %s", tt.expectedHost, config.Host)
+			}
+		})
+	}
+}
+
+func TestLoadConfigClientSettings(t *testing.T) {
+	path := writeKubeconfig(t, testKubeconfig)
+
+	config, err := LoadConfig(ClientOptions{
+		Kubeconfig: path,
+		QPS:        50,
+		Burst:      100,
+		Timeout:    30 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.QPS != 50 {
+		t.Errorf("expected QPS 50, got %v", config.QPS)
+	}
+	if config.Burst != 100 {
+		t.Errorf("expected burst 100, got %d", config.Burst)
+	}
+	if config.Timeout != 30*time.Second {
+		t.Errorf("expected timeout 30s, got %v", config.Timeout)
+	}
+}
+
+func TestLoadConfigOutsideCluster(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+	_, err := LoadConfig(ClientOptions{})
+	if err == nil || !strings.Contains(err.Error(), "not running in a cluster") {
+		t.Fatalf("expected not-in-cluster error, got %v", err)
+	}
+}
+
+func TestListContexts(t *testing.T) {
+	path := writeKubeconfig(t, testKubeconfig)
+
+	contexts, err := ListContexts(ClientOptions{Kubeconfig: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(contexts) != 2 || contexts[0] != "prod" || contexts[1] != "staging" {
+		t.Errorf("expected [prod staging], got %v", contexts)
+	}
+}
@@ -8,19 +8,23 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
-	"path/filepath"
 )
 
 type HealthChecker struct {
 	clientset *kubernetes.Clientset
+	cluster   string
 }
 
 type PodHealth struct {
+	Cluster        string
 	Namespace      string
 	Name           string
+	// Status is the pod status as kubectl shows it, such as CrashLoopBackOff
 	Status         string
+	Phase          string
+	// Health is Healthy, Degraded or Failing, explained by Reason
+	Health         string
+	Reason         string
 	Ready          string
 	Restarts       int32
 	Age            time.Duration
@@ -28,15 +32,18 @@ type PodHealth struct {
 }
 
 type DeploymentHealth struct {
+	Cluster   string
 	Namespace string
 	Name      string
 	Ready     string
+	Desired   int32
 	UpToDate  int32
 	Available int32
 	Age       time.Duration
 }
 
 type NodeHealth struct {
+	Cluster           string
 	Name              string
 	Status            string
 	Roles             string
@@ -48,6 +55,7 @@ type NodeHealth struct {
 }
 
 type EventInfo struct {
+	Cluster   string
 	Namespace string
 	Kind      string
 	Name      string
@@ -58,19 +66,29 @@ type EventInfo struct {
 }
 
 type HealthResults struct {
-	Pods          []PodHealth
-	Deployments   []DeploymentHealth
-	Nodes         []NodeHealth
-	WarningEvents []EventInfo
+	// Clusters are the kubeconfig contexts the results cover
+	Clusters       []string
+	FailedClusters []ClusterFailure
+	// Summaries count the problems in each cluster, including the healthy
+	// pods and workloads hidden from the results
+	Summaries      []ClusterHealthSummary
+	// HiddenHealthy is the number of healthy pods and workloads left out
+	HiddenHealthy  int
+	Pods           []PodHealth
+	Deployments    []DeploymentHealth
+	StatefulSets   []StatefulSetHealth
+	DaemonSets     []DaemonSetHealth
+	Jobs           []JobHealth
+	CronJobs       []CronJobHealth
+	Nodes          []NodeHealth
+	WarningEvents  []EventInfo
 }
 
-func NewHealthChecker() (*HealthChecker, error) {
-	// Try to load kubeconfig from default location
-	kubeconfig := filepath.Join(homedir.HomeDir(), ".kube", "config")
-	
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+// NewHealthChecker connects to the cluster selected by opts
+func NewHealthChecker(opts ClientOptions) (*HealthChecker, error) {
+	config, cluster, err := loadConfig(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+		return nil, err
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
@@ -80,9 +98,16 @@ func NewHealthChecker() (*HealthChecker, error) {
 
 	return &HealthChecker{
 		clientset: clientset,
+		cluster:   cluster,
 	}, nil
 }
 
+// Cluster returns the name of the kubeconfig context the checker is
+// connected to
+func (h *HealthChecker) Cluster() string {
+	return h.cluster
+}
+
 func (h *HealthChecker) CheckPods(ctx context.Context, namespace string) ([]PodHealth, error) {
 	pods, err := h.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -104,11 +129,16 @@ func (h *HealthChecker) CheckPods(ctx context.Context, namespace string) ([]PodH
 		}
 
 		age := time.Since(pod.CreationTimestamp.Time)
+		diagnosis := DiagnosePod(pod, time.Now())
 
 		podHealths = append(podHealths, PodHealth{
+			Cluster:   h.cluster,
 			Namespace: pod.Namespace,
 			Name:      pod.Name,
-			Status:    string(pod.Status.Phase),
+			Status:    diagnosis.Status,
+			Phase:     string(pod.Status.Phase),
+			Health:    diagnosis.Health,
+			Reason:    diagnosis.Reason,
 			Ready:     fmt.Sprintf("%d/%d", readyCount, totalContainers),
 			Restarts:  restarts,
 			Age:       age,
@@ -136,9 +166,11 @@ func (h *HealthChecker) CheckDeployments(ctx context.Context, namespace string)
 		age := time.Since(dep.CreationTimestamp.Time)
 
 		depHealths = append(depHealths, DeploymentHealth{
+			Cluster:   h.cluster,
 			Namespace: dep.Namespace,
 			Name:      dep.Name,
 			Ready:     fmt.Sprintf("%d/%d", dep.Status.ReadyReplicas, desired),
+			Desired:   desired,
 			UpToDate:  dep.Status.UpdatedReplicas,
 			Available: dep.Status.AvailableReplicas,
 			Age:       age,
@@ -173,6 +205,7 @@ func (h *HealthChecker) CheckNodes(ctx context.Context) ([]NodeHealth, error) {
 		age := time.Since(node.CreationTimestamp.Time)
 
 		nodeHealths = append(nodeHealths, NodeHealth{
+			Cluster:           h.cluster,
 			Name:              node.Name,
 			Status:            status,
 			Roles:             roles,
@@ -200,6 +233,7 @@ func (h *HealthChecker) GetRecentWarningEvents(ctx context.Context, namespace st
 	for _, event := range events.Items {
 		if event.Type == corev1.EventTypeWarning && event.LastTimestamp.After(oneHourAgo) {
 			eventInfos = append(eventInfos, EventInfo{
+				Cluster:   h.cluster,
 				Namespace: event.Namespace,
 				Kind:      event.InvolvedObject.Kind,
 				Name:      event.InvolvedObject.Name,
@@ -0,0 +1,259 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+)
+
+// maxConcurrentClusters bounds how many clusters are checked at once
+const maxConcurrentClusters = 8
+
+// ClusterFailure is a cluster that could not be checked
+type ClusterFailure struct {
+	Cluster string
+	Error   string
+}
+
+// CheckClusters connects to each kubeconfig context and runs check against
+// them concurrently. Results are returned in context order; clusters that
+// fail to connect or check are returned as failures instead.
+func CheckClusters[T any](ctx context.Context, opts ClientOptions, contexts []string, check func(context.Context, *HealthChecker) (T, error)) ([]T, []ClusterFailure) {
+	return fanOut(ctx, contexts, func(ctx context.Context, name string) (T, error) {
+		clusterOpts := opts
+		clusterOpts.Context = name
+
+		checker, err := NewHealthChecker(clusterOpts)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		return check(ctx, checker)
+	})
+}
+
+// fanOut runs run for each context with at most maxConcurrentClusters at a
+// time, keeping the results in context order
+func fanOut[T any](ctx context.Context, contexts []string, run func(context.Context, string) (T, error)) ([]T, []ClusterFailure) {
+	values := make([]T, len(contexts))
+	errs := make([]error, len(contexts))
+
+	sem := make(chan struct{}, maxConcurrentClusters)
+	var wg sync.WaitGroup
+
+	for i, name := range contexts {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			values[i], errs[i] = run(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	results := make([]T, 0, len(contexts))
+	var failures []ClusterFailure
+	for i, name := range contexts {
+		if errs[i] != nil {
+			failures = append(failures, ClusterFailure{Cluster: name, Error: errs[i].Error()})
+			continue
+		}
+		results = append(results, values[i])
+	}
+
+	return results, failures
+}
+
+// MergeHealthResults combines the health results of several clusters
+func MergeHealthResults(clusters ...*HealthResults) *HealthResults {
+	merged := &HealthResults{
+		Pods:          make([]PodHealth, 0),
+		Deployments:   make([]DeploymentHealth, 0),
+		StatefulSets:  make([]StatefulSetHealth, 0),
+		DaemonSets:    make([]DaemonSetHealth, 0),
+		Jobs:          make([]JobHealth, 0),
+		CronJobs:      make([]CronJobHealth, 0),
+		Nodes:         make([]NodeHealth, 0),
+		WarningEvents: make([]EventInfo, 0),
+	}
+
+	for _, results := range clusters {
+		merged.Clusters = append(merged.Clusters, results.Clusters...)
+		merged.FailedClusters = append(merged.FailedClusters, results.FailedClusters...)
+		merged.Summaries = append(merged.Summaries, results.Summaries...)
+		merged.HiddenHealthy += results.HiddenHealthy
+		merged.Pods = append(merged.Pods, results.Pods...)
+		merged.Deployments = append(merged.Deployments, results.Deployments...)
+		merged.StatefulSets = append(merged.StatefulSets, results.StatefulSets...)
+		merged.DaemonSets = append(merged.DaemonSets, results.DaemonSets...)
+		merged.Jobs = append(merged.Jobs, results.Jobs...)
+		merged.CronJobs = append(merged.CronJobs, results.CronJobs...)
+		merged.Nodes = append(merged.Nodes, results.Nodes...)
+		merged.WarningEvents = append(merged.WarningEvents, results.WarningEvents...)
+	}
+
+	return merged
+}
+
+// MergeCertificateResults combines the certificate results of several clusters
+func MergeCertificateResults(clusters ...*CertificateResults) *CertificateResults {
+	merged := &CertificateResults{
+		Certificates: make([]CertificateInfo, 0),
+	}
+
+	for _, results := range clusters {
+		merged.Clusters = append(merged.Clusters, results.Clusters...)
+		merged.FailedClusters = append(merged.FailedClusters, results.FailedClusters...)
+		merged.Certificates = append(merged.Certificates, results.Certificates...)
+		merged.TotalScanned += results.TotalScanned
+		merged.ExpiringCount += results.ExpiringCount
+		merged.CriticalCount += results.CriticalCount
+		merged.ExpiredCount += results.ExpiredCount
+	}
+
+	return merged
+}
+
+// MergePDBResults combines the PodDisruptionBudget results of several clusters
+func MergePDBResults(clusters ...*PDBResults) *PDBResults {
+	merged := &PDBResults{
+		PDBs: make([]PDBInfo, 0),
+	}
+
+	for _, results := range clusters {
+		merged.Clusters = append(merged.Clusters, results.Clusters...)
+		merged.FailedClusters = append(merged.FailedClusters, results.FailedClusters...)
+		merged.PDBs = append(merged.PDBs, results.PDBs...)
+		merged.TotalScanned += results.TotalScanned
+		merged.HealthyCount += results.HealthyCount
+		merged.AtRiskCount += results.AtRiskCount
+		merged.CriticalCount += results.CriticalCount
+		merged.NoPodsCount += results.NoPodsCount
+	}
+
+	return merged
+}
+
+// ClusterHealthSummary counts the problems found in one cluster
+type ClusterHealthSummary struct {
+	Cluster                string
+	Pods                   int
+	PodsDegraded           int
+	PodsFailing            int
+	Deployments            int
+	DeploymentsUnavailable int
+	// Workloads are StatefulSets, DaemonSets, Jobs and CronJobs
+	Workloads         int
+	WorkloadsDegraded int
+	WorkloadsFailing  int
+	Nodes             int
+	NodesNotReady     int
+	WarningEvents     int
+}
+
+// HasProblems reports whether any pod, deployment or node in the cluster is
+// unhealthy
+func (s ClusterHealthSummary) HasProblems() bool {
+	return s.PodsDegraded > 0 || s.PodsFailing > 0 || s.DeploymentsUnavailable > 0 ||
+		s.WorkloadsDegraded > 0 || s.WorkloadsFailing > 0 || s.NodesNotReady > 0
+}
+
+// ClusterSummaries counts the problems found in each cluster, in the order of
+// Clusters
+func (r *HealthResults) ClusterSummaries() []ClusterHealthSummary {
+	summaries := make([]ClusterHealthSummary, len(r.Clusters))
+	index := make(map[string]int, len(r.Clusters))
+	for i, cluster := range r.Clusters {
+		summaries[i].Cluster = cluster
+		index[cluster] = i
+	}
+
+	for _, pod := range r.Pods {
+		if i, ok := index[pod.Cluster]; ok {
+			summaries[i].Pods++
+			switch pod.Health {
+			case Degraded:
+				summaries[i].PodsDegraded++
+			case Failing:
+				summaries[i].PodsFailing++
+			}
+		}
+	}
+	for _, dep := range r.Deployments {
+		if i, ok := index[dep.Cluster]; ok {
+			summaries[i].Deployments++
+			if dep.Available < dep.Desired {
+				summaries[i].DeploymentsUnavailable++
+			}
+		}
+	}
+	countWorkload := func(cluster, health string) {
+		if i, ok := index[cluster]; ok {
+			summaries[i].Workloads++
+			switch health {
+			case Degraded:
+				summaries[i].WorkloadsDegraded++
+			case Failing:
+				summaries[i].WorkloadsFailing++
+			}
+		}
+	}
+	for _, sts := range r.StatefulSets {
+		countWorkload(sts.Cluster, sts.Health)
+	}
+	for _, ds := range r.DaemonSets {
+		countWorkload(ds.Cluster, ds.Health)
+	}
+	for _, job := range r.Jobs {
+		countWorkload(job.Cluster, job.Health)
+	}
+	for _, cronJob := range r.CronJobs {
+		countWorkload(cronJob.Cluster, cronJob.Health)
+	}
+	for _, node := range r.Nodes {
+		if i, ok := index[node.Cluster]; ok {
+			summaries[i].Nodes++
+			if node.Status != "Ready" {
+				summaries[i].NodesNotReady++
+			}
+		}
+	}
+	for _, event := range r.WarningEvents {
+		if i, ok := index[event.Cluster]; ok {
+			summaries[i].WarningEvents++
+		}
+	}
+
+	return summaries
+}
+
+// HideHealthy summarizes the results and then drops the healthy pods and
+// workloads, so the summaries still count everything
+func (r *HealthResults) HideHealthy() {
+	if r.Summaries == nil {
+		r.Summaries = r.ClusterSummaries()
+	}
+
+	before := len(r.Pods) + len(r.StatefulSets) + len(r.DaemonSets) + len(r.Jobs) + len(r.CronJobs)
+
+	r.Pods = ProblemPods(r.Pods)
+	r.StatefulSets = filterUnhealthy(r.StatefulSets, func(s StatefulSetHealth) string { return s.Health })
+	r.DaemonSets = filterUnhealthy(r.DaemonSets, func(d DaemonSetHealth) string { return d.Health })
+	r.Jobs = filterUnhealthy(r.Jobs, func(j JobHealth) string { return j.Health })
+	r.CronJobs = filterUnhealthy(r.CronJobs, func(c CronJobHealth) string { return c.Health })
+
+	after := len(r.Pods) + len(r.StatefulSets) + len(r.DaemonSets) + len(r.Jobs) + len(r.CronJobs)
+	r.HiddenHealthy += before - after
+}
+
+// filterUnhealthy returns the items whose health is not Healthy
+func filterUnhealthy[T any](items []T, health func(T) string) []T {
+	unhealthy := make([]T, 0)
+	for _, item := range items {
+		if health(item) != Healthy {
+			unhealthy = append(unhealthy, item)
+		}
+	}
+	return unhealthy
+}
@@ -0,0 +1,196 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFanOut(t *testing.T) {
+	contexts := []string{"prod-eu", "broken", "prod-us", "staging"}
+
+	results, failures := fanOut(context.Background(), contexts, func(ctx context.Context, name string) (string, error) {
+		if name == "broken" {
+			return "", errors.New("connection refused")
+		}
+		return name + "-checked", nil
+	})
+
+	expected := []string{"prod-eu-checked", "prod-us-checked", "staging-checked"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected results %v in context order, got %v", expected, results)
+	}
+
+	expectedFailures := []ClusterFailure{{Cluster: "broken", Error: "connection refused"}}
+	if !reflect.DeepEqual(failures, expectedFailures) {
+		t.Errorf("expected failures %v, got %v", expectedFailures, failures)
+	}
+}
+
+func TestFanOutLimitsConcurrency(t *testing.T) {
+	contexts := make([]string, 3*maxConcurrentClusters)
+	for i := range contexts {
+		contexts[i] = string(rune('a' + i))
+	}
+
+	var running, peak int32
+	release := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		fanOut(context.Background(), contexts, func(ctx context.Context, name string) (int, error) {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&running, -1)
+			return 0, nil
+		})
+		close(done)
+	}()
+
+	// Hold the first batch until it is full, then let every check finish
+	for atomic.LoadInt32(&running) < maxConcurrentClusters {
+		runtime.Gosched()
+	}
+	close(release)
+	<-done
+
+	if peak > maxConcurrentClusters {
+		t.Errorf("expected at most %d concurrent checks, got %d", maxConcurrentClusters, peak)
+	}
+}
+
+func TestMergeCertificateResults(t *testing.T) {
+	merged := MergeCertificateResults(
+		&CertificateResults{
+			Clusters:      []string{"prod"},
+			Certificates:  []CertificateInfo{{Cluster: "prod", SecretName: "api-tls", Status: "critical"}},
+			TotalScanned:  4,
+			CriticalCount: 1,
+		},
+		&CertificateResults{
+			Clusters:      []string{"staging"},
+			Certificates:  []CertificateInfo{{Cluster: "staging", SecretName: "web-tls", Status: "expired"}},
+			TotalScanned:  2,
+			ExpiredCount:  1,
+			ExpiringCount: 3,
+		},
+	)
+
+	if !reflect.DeepEqual(merged.Clusters, []string{"prod", "staging"}) {
+		t.Errorf("expected clusters [prod staging], got %v", merged.Clusters)
+	}
+	if len(merged.Certificates) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(merged.Certificates))
+	}
+	if merged.Certificates[1].Cluster != "staging" {
+		t.Errorf("expected second certificate from staging, got %s", merged.Certificates[1].Cluster)
+	}
+	if merged.TotalScanned != 6 || merged.CriticalCount != 1 || merged.ExpiredCount != 1 || merged.ExpiringCount != 3 {
+		t.Errorf("unexpected counts: scanned %d, critical %d, expired %d, expiring %d",
+			merged.TotalScanned, merged.CriticalCount, merged.ExpiredCount, merged.ExpiringCount)
+	}
+}
+
+func TestMergePDBResults(t *testing.T) {
+	merged := MergePDBResults(
+		&PDBResults{
+			Clusters:     []string{"prod"},
+			PDBs:         []PDBInfo{{Cluster: "prod", Name: "api", Status: "healthy"}},
+			TotalScanned: 1,
+			HealthyCount: 1,
+		},
+		&PDBResults{
+			Clusters:      []string{"staging"},
+			PDBs:          []PDBInfo{{Cluster: "staging", Name: "db", Status: "critical"}, {Cluster: "staging", Name: "web", Status: "no-pods"}},
+			TotalScanned:  2,
+			CriticalCount: 1,
+			NoPodsCount:   1,
+		},
+	)
+
+	if len(merged.PDBs) != 3 {
+		t.Fatalf("expected 3 PDBs, got %d", len(merged.PDBs))
+	}
+	if merged.TotalScanned != 3 || merged.HealthyCount != 1 || merged.CriticalCount != 1 || merged.NoPodsCount != 1 {
+		t.Errorf("unexpected counts: scanned %d, healthy %d, critical %d, no-pods %d",
+			merged.TotalScanned, merged.HealthyCount, merged.CriticalCount, merged.NoPodsCount)
+	}
+}
+
+func TestClusterSummaries(t *testing.T) {
+	results := MergeHealthResults(
+		&HealthResults{
+			Clusters: []string{"prod"},
+			Pods: []PodHealth{
+				{Cluster: "prod", Name: "api", Health: Healthy},
+				{Cluster: "prod", Name: "migrate", Health: Healthy},
+				{Cluster: "prod", Name: "worker", Health: Failing},
+				{Cluster: "prod", Name: "web", Health: Degraded},
+			},
+			Deployments: []DeploymentHealth{
+				{Cluster: "prod", Name: "api", Desired: 3, Available: 2},
+			},
+			Nodes: []NodeHealth{
+				{Cluster: "prod", Name: "node-1", Status: "Ready"},
+				{Cluster: "prod", Name: "node-2", Status: "NotReady"},
+			},
+			WarningEvents: []EventInfo{{Cluster: "prod", Reason: "BackOff"}},
+		},
+		&HealthResults{
+			Clusters: []string{"staging"},
+			Pods:     []PodHealth{{Cluster: "staging", Name: "api", Health: Healthy}},
+			Deployments: []DeploymentHealth{
+				{Cluster: "staging", Name: "api", Desired: 1, Available: 1},
+			},
+			Nodes: []NodeHealth{{Cluster: "staging", Name: "node-1", Status: "Ready"}},
+		},
+	)
+
+	summaries := results.ClusterSummaries()
+	expected := []ClusterHealthSummary{
+		{Cluster: "prod", Pods: 4, PodsDegraded: 1, PodsFailing: 1, Deployments: 1, DeploymentsUnavailable: 1, Nodes: 2, NodesNotReady: 1, WarningEvents: 1},
+		{Cluster: "staging", Pods: 1, Deployments: 1, Nodes: 1},
+	}
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Errorf("expected summaries %+v, got %+v", expected, summaries)
+	}
+
+	if !summaries[0].HasProblems() {
+		t.Error("expected prod to have problems")
+	}
+	if summaries[1].HasProblems() {
+		t.Error("expected staging to be healthy")
+	}
+}
+
+func TestHideHealthy(t *testing.T) {
+	results := &HealthResults{
+		Clusters: []string{"prod"},
+		Pods: []PodHealth{
+			{Cluster: "prod", Name: "api", Health: Healthy},
+			{Cluster: "prod", Name: "worker", Health: Failing},
+			{Cluster: "prod", Name: "web", Health: Degraded},
+		},
+	}
+
+	results.HideHealthy()
+
+	if len(results.Pods) != 2 || results.Pods[0].Name != "worker" || results.Pods[1].Name != "web" {
+		t.Errorf("expected only worker and web to remain, got %+v", results.Pods)
+	}
+	if results.HiddenHealthy != 1 {
+		t.Errorf("expected 1 hidden pod, got %d", results.HiddenHealthy)
+	}
+	if len(results.Summaries) != 1 || results.Summaries[0].Pods != 3 {
+		t.Errorf("expected the summary to still count 3 pods, got %+v", results.Summaries)
+	}
+}
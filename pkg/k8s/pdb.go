@@ -10,6 +10,7 @@ import (
 
 // PDBInfo holds information about a PodDisruptionBudget
 type PDBInfo struct {
+	Cluster             string
 	Name                string
 	Namespace           string
 	MinAvailable        string
@@ -24,6 +25,9 @@ type PDBInfo struct {
 
 // PDBResults holds the results of PDB checking
 type PDBResults struct {
+	// Clusters are the kubeconfig contexts the results cover
+	Clusters       []string
+	FailedClusters []ClusterFailure
 	PDBs           []PDBInfo
 	TotalScanned   int
 	HealthyCount   int
@@ -41,13 +45,15 @@ func (h *HealthChecker) CheckPDBStatus(ctx context.Context, namespace string) (*
 	}
 
 	results := &PDBResults{
-		PDBs: make([]PDBInfo, 0, len(pdbs.Items)),
+		Clusters: []string{h.cluster},
+		PDBs:     make([]PDBInfo, 0, len(pdbs.Items)),
 	}
 
 	for _, pdb := range pdbs.Items {
 		results.TotalScanned++
 
 		pdbInfo := analyzePDB(pdb)
+		pdbInfo.Cluster = h.cluster
 
 		// Count by status
 		switch pdbInfo.Status {
@@ -0,0 +1,245 @@
+package k8s
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Health classes of pods and workloads, from best to worst
+const (
+	Healthy  = "healthy"
+	Degraded = "degraded"
+	Failing  = "failing"
+)
+
+// recentRestartWindow is how long after a container restart the pod is still
+// reported as degraded
+const recentRestartWindow = time.Hour
+
+// failingWaitingReasons are container waiting reasons that need someone to
+// fix the pod; other waiting reasons, such as ContainerCreating, are expected
+// to clear by themselves
+var failingWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+	"PreStartHookError":          true,
+	"PostStartHookError":         true,
+}
+
+// PodDiagnosis is the health of a pod worked out from its container states
+type PodDiagnosis struct {
+	// Status is the pod status as kubectl shows it, such as CrashLoopBackOff
+	// or Init:ImagePullBackOff
+	Status string
+	Health string
+	// Reason explains a degraded or failing pod, naming the container at fault
+	Reason string
+}
+
+// DiagnosePod classifies a pod as healthy, degraded or failing from its phase,
+// conditions and the states of its init and app containers
+func DiagnosePod(pod corev1.Pod, now time.Time) PodDiagnosis {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return PodDiagnosis{Status: "Completed", Health: Healthy}
+	case corev1.PodFailed:
+		status := pod.Status.Reason
+		if status == "" {
+			status = string(corev1.PodFailed)
+		}
+		return PodDiagnosis{Status: status, Health: Failing, Reason: podFailureReason(pod)}
+	case corev1.PodUnknown:
+		return PodDiagnosis{Status: string(corev1.PodUnknown), Health: Failing, Reason: "pod state unknown, node may be unreachable"}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		return PodDiagnosis{Status: "Terminating", Health: Degraded, Reason: "pod is being deleted"}
+	}
+
+	// A pod the scheduler cannot place never starts any container
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			return PodDiagnosis{Status: "Pending", Health: Failing, Reason: "unschedulable: " + condition.Message}
+		}
+	}
+
+	if diagnosis, ok := diagnoseInitContainers(pod.Spec.InitContainers, pod.Status.InitContainerStatuses); ok {
+		return diagnosis
+	}
+
+	return diagnoseContainers(pod, now)
+}
+
+// diagnoseInitContainers reports the first init container that is failing or
+// still running, if any. Sidecars (init containers with restartPolicy Always)
+// keep running for the life of the pod, so they are complete once started
+func diagnoseInitContainers(containers []corev1.Container, statuses []corev1.ContainerStatus) (PodDiagnosis, bool) {
+	sidecars := make(map[string]bool)
+	for _, container := range containers {
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			sidecars[container.Name] = true
+		}
+	}
+
+	for i, cs := range statuses {
+		if terminated := cs.State.Terminated; terminated != nil {
+			if terminated.ExitCode == 0 {
+				continue
+			}
+			reason := terminated.Reason
+			if reason == "" {
+				reason = "Error"
+			}
+			return PodDiagnosis{
+				Status: "Init:" + reason,
+				Health: Failing,
+				Reason: fmt.Sprintf("init container %s exited with code %d", cs.Name, terminated.ExitCode),
+			}, true
+		}
+
+		if waiting := cs.State.Waiting; waiting != nil && failingWaitingReasons[waiting.Reason] {
+			return PodDiagnosis{
+				Status: "Init:" + waiting.Reason,
+				Health: Failing,
+				Reason: containerWaitingReason("init container", cs),
+			}, true
+		}
+
+		if sidecars[cs.Name] && (cs.State.Running != nil || (cs.Started != nil && *cs.Started)) {
+			continue
+		}
+
+		return PodDiagnosis{
+			Status: fmt.Sprintf("Init:%d/%d", i, len(statuses)),
+			Health: Degraded,
+			Reason: fmt.Sprintf("waiting for init container %s", cs.Name),
+		}, true
+	}
+
+	return PodDiagnosis{}, false
+}
+
+// diagnoseContainers classifies a pod whose init containers have all completed
+func diagnoseContainers(pod corev1.Pod, now time.Time) PodDiagnosis {
+	diagnosis := PodDiagnosis{Status: string(pod.Status.Phase), Health: Healthy}
+	if pod.Status.Phase == corev1.PodPending && len(pod.Status.ContainerStatuses) == 0 {
+		return PodDiagnosis{Status: string(corev1.PodPending), Health: Degraded, Reason: "waiting for containers to be created"}
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		switch {
+		case cs.State.Waiting != nil && failingWaitingReasons[cs.State.Waiting.Reason]:
+			// One crashing container makes the whole pod failing
+			return PodDiagnosis{
+				Status: cs.State.Waiting.Reason,
+				Health: Failing,
+				Reason: containerWaitingReason("container", cs),
+			}
+
+		case cs.State.Terminated != nil:
+			terminated := cs.State.Terminated
+			if terminated.ExitCode == 0 && terminated.Reason == "Completed" {
+				continue
+			}
+			status := terminated.Reason
+			if status == "" {
+				status = "Error"
+			}
+			return PodDiagnosis{
+				Status: status,
+				Health: Failing,
+				Reason: fmt.Sprintf("container %s exited with code %d", cs.Name, terminated.ExitCode),
+			}
+
+		case diagnosis.Health != Healthy:
+			continue
+
+		case cs.State.Waiting != nil:
+			diagnosis = PodDiagnosis{
+				Status: cs.State.Waiting.Reason,
+				Health: Degraded,
+				Reason: fmt.Sprintf("container %s is %s", cs.Name, cs.State.Waiting.Reason),
+			}
+
+		case !cs.Ready:
+			diagnosis.Health = Degraded
+			diagnosis.Reason = fmt.Sprintf("container %s is not ready", cs.Name)
+
+		case restartedRecently(cs, now):
+			diagnosis.Health = Degraded
+			diagnosis.Reason = fmt.Sprintf("container %s restarted %d time(s), last %s",
+				cs.Name, cs.RestartCount, lastTermination(cs))
+		}
+	}
+
+	return diagnosis
+}
+
+// podFailureReason explains why a pod failed, such as an eviction
+func podFailureReason(pod corev1.Pod) string {
+	if pod.Status.Message != "" {
+		return pod.Status.Message
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if terminated := cs.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return fmt.Sprintf("container %s exited with code %d (%s)", cs.Name, terminated.ExitCode, terminated.Reason)
+		}
+	}
+
+	return "pod failed"
+}
+
+// containerWaitingReason explains why a container is stuck waiting, with the
+// way it last terminated for crash loops
+func containerWaitingReason(kind string, cs corev1.ContainerStatus) string {
+	waiting := cs.State.Waiting
+
+	if waiting.Reason == "CrashLoopBackOff" && cs.LastTerminationState.Terminated != nil {
+		return fmt.Sprintf("%s %s is crash looping (%d restarts), last %s",
+			kind, cs.Name, cs.RestartCount, lastTermination(cs))
+	}
+
+	if waiting.Message != "" {
+		return fmt.Sprintf("%s %s: %s", kind, cs.Name, waiting.Message)
+	}
+	return fmt.Sprintf("%s %s is %s", kind, cs.Name, waiting.Reason)
+}
+
+// restartedRecently reports whether a running container restarted within
+// recentRestartWindow
+func restartedRecently(cs corev1.ContainerStatus, now time.Time) bool {
+	terminated := cs.LastTerminationState.Terminated
+	if cs.RestartCount == 0 || terminated == nil {
+		return false
+	}
+	return now.Sub(terminated.FinishedAt.Time) < recentRestartWindow
+}
+
+// lastTermination describes how a container last terminated, such as
+// "OOMKilled (exit 137)"
+func lastTermination(cs corev1.ContainerStatus) string {
+	terminated := cs.LastTerminationState.Terminated
+	if terminated == nil {
+		return "termination unknown"
+	}
+
+	reason := terminated.Reason
+	if reason == "" {
+		reason = "Error"
+	}
+	return fmt.Sprintf("%s (exit %d)", reason, terminated.ExitCode)
+}
+
+// ProblemPods returns the pods that are not healthy
+func ProblemPods(pods []PodHealth) []PodHealth {
+	return filterUnhealthy(pods, func(p PodHealth) string { return p.Health })
+}
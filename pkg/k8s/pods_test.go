@@ -0,0 +1,311 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDiagnosePod(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	waiting := func(reason, message string) corev1.ContainerState {
+		return corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: message}}
+	}
+	terminated := func(reason string, exitCode int32, finishedAt time.Time) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			Reason:     reason,
+			ExitCode:   exitCode,
+			FinishedAt: metav1.NewTime(finishedAt),
+		}}
+	}
+
+	always := corev1.ContainerRestartPolicyAlways
+	started := true
+
+	tests := []struct {
+		name           string
+		spec           corev1.PodSpec
+		status         corev1.PodStatus
+		expectedStatus string
+		expectedHealth string
+		expectedReason string
+	}{
+		{
+			name: "Running and ready",
+			status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "api", Ready: true, State: running}},
+			},
+			expectedStatus: "Running",
+			expectedHealth: Healthy,
+		},
+		{
+			name:           "Completed job pod",
+			status:         corev1.PodStatus{Phase: corev1.PodSucceeded},
+			expectedStatus: "Completed",
+			expectedHealth: Healthy,
+		},
+		{
+			name: "CrashLoopBackOff after OOMKilled",
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:                 "api",
+					RestartCount:         7,
+					State:                waiting("CrashLoopBackOff", "back-off 5m0s restarting failed container"),
+					LastTerminationState: terminated("OOMKilled", 137, now.Add(-2*time.Minute)),
+				}},
+			},
+			expectedStatus: "CrashLoopBackOff",
+			expectedHealth: Failing,
+			expectedReason: "container api is crash looping (7 restarts), last OOMKilled (exit 137)",
+		},
+		{
+			name: "ImagePullBackOff",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "api",
+					State: waiting("ImagePullBackOff", `Back-off pulling image "api:v2"`),
+				}},
+			},
+			expectedStatus: "ImagePullBackOff",
+			expectedHealth: Failing,
+			expectedReason: `container api: Back-off pulling image "api:v2"`,
+		},
+		{
+			name: "CreateContainerConfigError",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "api",
+					State: waiting("CreateContainerConfigError", `secret "db-creds" not found`),
+				}},
+			},
+			expectedStatus: "CreateContainerConfigError",
+			expectedHealth: Failing,
+			expectedReason: `secret "db-creds" not found`,
+		},
+		{
+			name: "Failing sidecar outranks an unready container",
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "api", State: running},
+					{Name: "proxy", State: waiting("CrashLoopBackOff", "")},
+				},
+			},
+			expectedStatus: "CrashLoopBackOff",
+			expectedHealth: Failing,
+			expectedReason: "container proxy",
+		},
+		{
+			name: "Init container pulling image fails",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "migrate",
+					State: waiting("ImagePullBackOff", ""),
+				}},
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "api", State: waiting("PodInitializing", "")}},
+			},
+			expectedStatus: "Init:ImagePullBackOff",
+			expectedHealth: Failing,
+			expectedReason: "init container migrate is ImagePullBackOff",
+		},
+		{
+			name: "Init container exited with an error",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "migrate",
+					State: terminated("Error", 1, now),
+				}},
+			},
+			expectedStatus: "Init:Error",
+			expectedHealth: Failing,
+			expectedReason: "init container migrate exited with code 1",
+		},
+		{
+			name: "Init container still running",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{
+					{Name: "wait-for-db", State: terminated("Completed", 0, now)},
+					{Name: "migrate", State: running},
+				},
+			},
+			expectedStatus: "Init:1/2",
+			expectedHealth: Degraded,
+			expectedReason: "waiting for init container migrate",
+		},
+		{
+			name: "Running sidecar init container",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "proxy", RestartPolicy: &always}},
+			},
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name:    "proxy",
+					Ready:   true,
+					Started: &started,
+					State:   running,
+				}},
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "api", Ready: true, State: running}},
+			},
+			expectedStatus: "Running",
+			expectedHealth: Healthy,
+		},
+		{
+			name: "Sidecar init container crash looping",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "proxy", RestartPolicy: &always}},
+			},
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "proxy",
+					State: waiting("CrashLoopBackOff", ""),
+				}},
+			},
+			expectedStatus: "Init:CrashLoopBackOff",
+			expectedHealth: Failing,
+			expectedReason: "init container proxy is CrashLoopBackOff",
+		},
+		{
+			name: "Pending unschedulable",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: "0/3 nodes are available: 3 Insufficient memory.",
+				}},
+			},
+			expectedStatus: "Pending",
+			expectedHealth: Failing,
+			expectedReason: "unschedulable: 0/3 nodes are available: 3 Insufficient memory.",
+		},
+		{
+			name: "Evicted",
+			status: corev1.PodStatus{
+				Phase:   corev1.PodFailed,
+				Reason:  "Evicted",
+				Message: "The node was low on resource: memory.",
+			},
+			expectedStatus: "Evicted",
+			expectedHealth: Failing,
+			expectedReason: "The node was low on resource: memory.",
+		},
+		{
+			name: "OOMKilled and not restarted",
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "api",
+					State: terminated("OOMKilled", 137, now),
+				}},
+			},
+			expectedStatus: "OOMKilled",
+			expectedHealth: Failing,
+			expectedReason: "container api exited with code 137",
+		},
+		{
+			name: "Running but not ready",
+			status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "api", State: running}},
+			},
+			expectedStatus: "Running",
+			expectedHealth: Degraded,
+			expectedReason: "container api is not ready",
+		},
+		{
+			name: "Recently restarted after OOMKilled",
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:                 "api",
+					Ready:                true,
+					RestartCount:         2,
+					State:                running,
+					LastTerminationState: terminated("OOMKilled", 137, now.Add(-10*time.Minute)),
+				}},
+			},
+			expectedStatus: "Running",
+			expectedHealth: Degraded,
+			expectedReason: "container api restarted 2 time(s), last OOMKilled (exit 137)",
+		},
+		{
+			name: "Old restart is healthy",
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:                 "api",
+					Ready:                true,
+					RestartCount:         1,
+					State:                running,
+					LastTerminationState: terminated("Error", 1, now.Add(-24*time.Hour)),
+				}},
+			},
+			expectedStatus: "Running",
+			expectedHealth: Healthy,
+		},
+		{
+			name: "Container still creating",
+			status: corev1.PodStatus{
+				Phase:             corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "api", State: waiting("ContainerCreating", "")}},
+			},
+			expectedStatus: "ContainerCreating",
+			expectedHealth: Degraded,
+			expectedReason: "container api is ContainerCreating",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "api-7d9f", Namespace: "production"},
+				Spec:       tt.spec,
+				Status:     tt.status,
+			}
+
+			diagnosis := DiagnosePod(pod, now)
+
+			if diagnosis.Status != tt.expectedStatus {
+				t.Errorf("expected status %s, got %s", tt.expectedStatus, diagnosis.Status)
+			}
+			if diagnosis.Health != tt.expectedHealth {
+				t.Errorf("expected health %s, got %s", tt.expectedHealth, diagnosis.Health)
+			}
+			if !strings.Contains(diagnosis.Reason, tt.expectedReason) {
+				t.Errorf("expected reason containing %q, got %q", tt.expectedReason, diagnosis.Reason)
+			}
+			if tt.expectedHealth == Healthy && diagnosis.Reason != "" {
+				t.Errorf("expected no reason for a healthy pod, got %q", diagnosis.Reason)
+			}
+		})
+	}
+}
+
+func TestProblemPods(t *testing.T) {
+	pods := []PodHealth{
+		{Name: "api", Health: Healthy},
+		{Name: "worker", Health: Failing},
+		{Name: "web", Health: Degraded},
+	}
+
+	problems := ProblemPods(pods)
+
+	if len(problems) != 2 || problems[0].Name != "worker" || problems[1].Name != "web" {
+		t.Errorf("expected worker and web, got %+v", problems)
+	}
+}
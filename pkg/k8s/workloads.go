@@ -0,0 +1,421 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// missedScheduleGrace is how late a CronJob may start before the run counts
+// as missed, when the CronJob sets no starting deadline
+const missedScheduleGrace = 5 * time.Minute
+
+// staleSuccessRuns is how many scheduled runs may pass without a successful
+// one before a CronJob is failing
+const staleSuccessRuns = 2
+
+// maxCountedRuns caps the scheduled runs counted for a CronJob, so an
+// every-minute schedule that stopped long ago stays cheap to check
+const maxCountedRuns = 100
+
+// StatefulSetHealth is the rollout and readiness state of a StatefulSet
+type StatefulSetHealth struct {
+	Cluster   string
+	Namespace string
+	Name      string
+	Ready     string
+	Desired   int32
+	UpToDate  int32
+	Age       time.Duration
+	Health    string
+	Reason    string
+}
+
+// DaemonSetHealth is the scheduling and availability state of a DaemonSet
+type DaemonSetHealth struct {
+	Cluster      string
+	Namespace    string
+	Name         string
+	Desired      int32
+	Ready        int32
+	Available    int32
+	UpToDate     int32
+	Misscheduled int32
+	Age          time.Duration
+	Health       string
+	Reason       string
+}
+
+// JobHealth is the outcome of a Job
+type JobHealth struct {
+	Cluster     string
+	Namespace   string
+	Name        string
+	Status      string // "Running", "Complete", "Failed", "Suspended"
+	Completions string
+	Active      int32
+	Failed      int32
+	Duration    time.Duration
+	Age         time.Duration
+	Health      string
+	Reason      string
+}
+
+// CronJobHealth is the schedule state of a CronJob
+type CronJobHealth struct {
+	Cluster            string
+	Namespace          string
+	Name               string
+	Schedule           string
+	Suspended          bool
+	Active             int
+	LastScheduleTime   *time.Time
+	LastSuccessfulTime *time.Time
+	Age                time.Duration
+	Health             string
+	Reason             string
+}
+
+// CheckStatefulSets reports StatefulSets that are not ready or whose rollout
+// is stuck
+func (h *HealthChecker) CheckStatefulSets(ctx context.Context, namespace string) ([]StatefulSetHealth, error) {
+	statefulSets, err := h.clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	now := time.Now()
+	healths := make([]StatefulSetHealth, 0, len(statefulSets.Items))
+	for _, sts := range statefulSets.Items {
+		health := analyzeStatefulSet(sts, now)
+		health.Cluster = h.cluster
+		healths = append(healths, health)
+	}
+
+	return healths, nil
+}
+
+// CheckDaemonSets reports DaemonSets with unavailable or misscheduled pods
+func (h *HealthChecker) CheckDaemonSets(ctx context.Context, namespace string) ([]DaemonSetHealth, error) {
+	daemonSets, err := h.clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+
+	now := time.Now()
+	healths := make([]DaemonSetHealth, 0, len(daemonSets.Items))
+	for _, ds := range daemonSets.Items {
+		health := analyzeDaemonSet(ds, now)
+		health.Cluster = h.cluster
+		healths = append(healths, health)
+	}
+
+	return healths, nil
+}
+
+// CheckJobs reports Jobs that failed or run past their active deadline
+func (h *HealthChecker) CheckJobs(ctx context.Context, namespace string) ([]JobHealth, error) {
+	jobs, err := h.clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	now := time.Now()
+	healths := make([]JobHealth, 0, len(jobs.Items))
+	for _, job := range jobs.Items {
+		health := analyzeJob(job, now)
+		health.Cluster = h.cluster
+		healths = append(healths, health)
+	}
+
+	return healths, nil
+}
+
+// CheckCronJobs reports CronJobs that are suspended, missed their schedule or
+// have not succeeded recently
+func (h *HealthChecker) CheckCronJobs(ctx context.Context, namespace string) ([]CronJobHealth, error) {
+	cronJobs, err := h.clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+
+	now := time.Now()
+	healths := make([]CronJobHealth, 0, len(cronJobs.Items))
+	for _, cronJob := range cronJobs.Items {
+		health := analyzeCronJob(cronJob, now)
+		health.Cluster = h.cluster
+		healths = append(healths, health)
+	}
+
+	return healths, nil
+}
+
+// analyzeStatefulSet classifies a StatefulSet. Pods are replaced one at a
+// time, so a rollout with an unready pod does not progress.
+func analyzeStatefulSet(sts appsv1.StatefulSet, now time.Time) StatefulSetHealth {
+	desired := int32(1)
+	if sts.Spec.Replicas != nil {
+		desired = *sts.Spec.Replicas
+	}
+
+	health := StatefulSetHealth{
+		Namespace: sts.Namespace,
+		Name:      sts.Name,
+		Ready:     fmt.Sprintf("%d/%d", sts.Status.ReadyReplicas, desired),
+		Desired:   desired,
+		UpToDate:  sts.Status.UpdatedReplicas,
+		Age:       now.Sub(sts.CreationTimestamp.Time),
+		Health:    Healthy,
+	}
+
+	rollingOut := sts.Status.UpdateRevision != "" && sts.Status.CurrentRevision != sts.Status.UpdateRevision
+	onDelete := sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType
+
+	switch {
+	case desired > 0 && sts.Status.ReadyReplicas == 0:
+		health.Health = Failing
+		health.Reason = "no replicas ready"
+	case rollingOut && !onDelete && sts.Status.ReadyReplicas < desired:
+		health.Health = Failing
+		health.Reason = fmt.Sprintf("rollout stuck at %d/%d updated, waiting on unready pods", sts.Status.UpdatedReplicas, desired)
+	case sts.Status.ReadyReplicas < desired:
+		health.Health = Degraded
+		health.Reason = fmt.Sprintf("%d of %d replicas ready", sts.Status.ReadyReplicas, desired)
+	case rollingOut && onDelete:
+		health.Health = Degraded
+		health.Reason = fmt.Sprintf("%d/%d replicas updated, OnDelete pods must be deleted to roll out", sts.Status.UpdatedReplicas, desired)
+	case rollingOut:
+		health.Health = Degraded
+		health.Reason = fmt.Sprintf("rollout in progress, %d/%d updated", sts.Status.UpdatedReplicas, desired)
+	}
+
+	return health
+}
+
+// analyzeDaemonSet classifies a DaemonSet by its unavailable, misscheduled
+// and outdated pods
+func analyzeDaemonSet(ds appsv1.DaemonSet, now time.Time) DaemonSetHealth {
+	status := ds.Status
+	health := DaemonSetHealth{
+		Namespace:    ds.Namespace,
+		Name:         ds.Name,
+		Desired:      status.DesiredNumberScheduled,
+		Ready:        status.NumberReady,
+		Available:    status.NumberAvailable,
+		UpToDate:     status.UpdatedNumberScheduled,
+		Misscheduled: status.NumberMisscheduled,
+		Age:          now.Sub(ds.CreationTimestamp.Time),
+		Health:       Healthy,
+	}
+
+	switch {
+	case status.DesiredNumberScheduled > 0 && status.NumberAvailable == 0:
+		health.Health = Failing
+		health.Reason = fmt.Sprintf("no pods available on %d node(s)", status.DesiredNumberScheduled)
+	case status.NumberUnavailable > 0:
+		health.Health = Degraded
+		health.Reason = fmt.Sprintf("%d of %d pods unavailable", status.NumberUnavailable, status.DesiredNumberScheduled)
+	case status.NumberMisscheduled > 0:
+		health.Health = Degraded
+		health.Reason = fmt.Sprintf("%d pod(s) running on nodes they should not run on", status.NumberMisscheduled)
+	case status.UpdatedNumberScheduled < status.DesiredNumberScheduled:
+		health.Health = Degraded
+		health.Reason = fmt.Sprintf("rollout in progress, %d/%d updated", status.UpdatedNumberScheduled, status.DesiredNumberScheduled)
+	}
+
+	return health
+}
+
+// analyzeJob classifies a Job by its conditions and, while it runs, its
+// active deadline
+func analyzeJob(job batchv1.Job, now time.Time) JobHealth {
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+
+	health := JobHealth{
+		Namespace:   job.Namespace,
+		Name:        job.Name,
+		Status:      "Running",
+		Completions: fmt.Sprintf("%d/%d", job.Status.Succeeded, completions),
+		Active:      job.Status.Active,
+		Failed:      job.Status.Failed,
+		Age:         now.Sub(job.CreationTimestamp.Time),
+		Health:      Healthy,
+	}
+
+	if job.Status.StartTime != nil {
+		end := now
+		if job.Status.CompletionTime != nil {
+			end = job.Status.CompletionTime.Time
+		}
+		health.Duration = end.Sub(job.Status.StartTime.Time)
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			health.Status = "Complete"
+			return health
+		case batchv1.JobFailed:
+			health.Status = "Failed"
+			health.Health = Failing
+			health.Reason = condition.Reason
+			if condition.Message != "" {
+				health.Reason += ": " + condition.Message
+			}
+			return health
+		}
+	}
+
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		health.Status = "Suspended"
+		return health
+	}
+
+	switch {
+	case job.Spec.ActiveDeadlineSeconds != nil && job.Status.StartTime != nil &&
+		health.Duration > time.Duration(*job.Spec.ActiveDeadlineSeconds)*time.Second:
+		health.Health = Failing
+		health.Reason = fmt.Sprintf("running for %s, past its %ds active deadline",
+			health.Duration.Round(time.Second), *job.Spec.ActiveDeadlineSeconds)
+	case job.Status.Failed > 0:
+		health.Health = Degraded
+		health.Reason = fmt.Sprintf("%d failed attempt(s), retrying", job.Status.Failed)
+	}
+
+	return health
+}
+
+// analyzeCronJob classifies a CronJob by comparing its schedule with the last
+// time it was scheduled and the last time a run succeeded
+func analyzeCronJob(cronJob batchv1.CronJob, now time.Time) CronJobHealth {
+	health := CronJobHealth{
+		Namespace: cronJob.Namespace,
+		Name:      cronJob.Name,
+		Schedule:  cronJob.Spec.Schedule,
+		Suspended: cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		Active:    len(cronJob.Status.Active),
+		Age:       now.Sub(cronJob.CreationTimestamp.Time),
+		Health:    Healthy,
+	}
+	if t := cronJob.Status.LastScheduleTime; t != nil {
+		health.LastScheduleTime = &t.Time
+	}
+	if t := cronJob.Status.LastSuccessfulTime; t != nil {
+		health.LastSuccessfulTime = &t.Time
+	}
+
+	if health.Suspended {
+		health.Health = Degraded
+		health.Reason = "suspended"
+		return health
+	}
+
+	schedule, err := parseCronSchedule(cronJob.Spec)
+	if err != nil {
+		health.Health = Failing
+		health.Reason = fmt.Sprintf("invalid schedule: %v", err)
+		return health
+	}
+
+	grace := missedScheduleGrace
+	if cronJob.Spec.StartingDeadlineSeconds != nil {
+		grace = time.Duration(*cronJob.Spec.StartingDeadlineSeconds) * time.Second
+	}
+	deadline := now.Add(-grace)
+
+	// Runs due since the last one the controller started
+	lastScheduled := cronJob.CreationTimestamp.Time
+	if health.LastScheduleTime != nil {
+		lastScheduled = *health.LastScheduleTime
+	}
+
+	// With the Forbid policy the controller skips runs while a job is active,
+	// so runs due after the active job started are not missed
+	if cronJob.Spec.ConcurrencyPolicy == batchv1.ForbidConcurrent && health.Active > 0 && lastScheduled.Before(deadline) {
+		deadline = lastScheduled
+	}
+
+	if missed, first := countRuns(schedule, lastScheduled, deadline); missed > 0 {
+		health.Health = Failing
+		health.Reason = fmt.Sprintf("missed %s scheduled run(s) since %s", formatRunCount(missed), first.Format("2006-01-02 15:04"))
+		return health
+	}
+
+	// Runs due since the last one that succeeded
+	lastSucceeded := cronJob.CreationTimestamp.Time
+	if health.LastSuccessfulTime != nil {
+		lastSucceeded = *health.LastSuccessfulTime
+	}
+	if runs, _ := countRuns(schedule, lastSucceeded, deadline); runs >= staleSuccessRuns {
+		health.Health = Failing
+		if health.LastSuccessfulTime == nil {
+			health.Reason = fmt.Sprintf("no successful run in %s scheduled run(s)", formatRunCount(runs))
+		} else {
+			health.Reason = fmt.Sprintf("last successful run %s ago, %s scheduled run(s) since",
+				formatDuration(now.Sub(lastSucceeded)), formatRunCount(runs))
+		}
+	}
+
+	return health
+}
+
+// parseCronSchedule parses a CronJob schedule in its time zone, as the
+// CronJob controller does
+func parseCronSchedule(spec batchv1.CronJobSpec) (cron.Schedule, error) {
+	expression := spec.Schedule
+	if spec.TimeZone != nil && *spec.TimeZone != "" {
+		expression = fmt.Sprintf("CRON_TZ=%s %s", *spec.TimeZone, spec.Schedule)
+	}
+	return cron.ParseStandard(expression)
+}
+
+// countRuns counts the runs schedule has due after since and up to until,
+// stopping at maxCountedRuns, and returns the first of them
+func countRuns(schedule cron.Schedule, since, until time.Time) (int, time.Time) {
+	var first time.Time
+	count := 0
+	for next := schedule.Next(since); !next.IsZero() && !next.After(until); next = schedule.Next(next) {
+		if count == 0 {
+			first = next
+		}
+		count++
+		if count == maxCountedRuns {
+			break
+		}
+	}
+	return count, first
+}
+
+// formatRunCount formats a run count, marking counts that hit maxCountedRuns
+func formatRunCount(count int) string {
+	if count >= maxCountedRuns {
+		return fmt.Sprintf("%d+", maxCountedRuns)
+	}
+	return fmt.Sprintf("%d", count)
+}
+
+// formatDuration formats a duration in days and hours, or minutes when it is
+// shorter than an hour
+func formatDuration(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days > 0 {
+		return fmt.Sprintf("%dd%dh", days, hours)
+	}
+	return fmt.Sprintf("%dh", hours)
+}
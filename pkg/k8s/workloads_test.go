@@ -0,0 +1,415 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(v int32) *int32    { return &v }
+func int64Ptr(v int64) *int64    { return &v }
+func boolPtr(v bool) *bool       { return &v }
+func stringPtr(v string) *string { return &v }
+
+func TestAnalyzeStatefulSet(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		strategy       appsv1.StatefulSetUpdateStrategyType
+		replicas       int32
+		status         appsv1.StatefulSetStatus
+		expectedHealth string
+		expectedReason string
+	}{
+		{
+			name:     "All replicas ready",
+			replicas: 3,
+			status: appsv1.StatefulSetStatus{
+				ReadyReplicas: 3, UpdatedReplicas: 3,
+				CurrentRevision: "db-1", UpdateRevision: "db-1",
+			},
+			expectedHealth: Healthy,
+		},
+		{
+			name:     "Some replicas not ready",
+			replicas: 3,
+			status: appsv1.StatefulSetStatus{
+				ReadyReplicas: 2, UpdatedReplicas: 3,
+				CurrentRevision: "db-1", UpdateRevision: "db-1",
+			},
+			expectedHealth: Degraded,
+			expectedReason: "2 of 3 replicas ready",
+		},
+		{
+			name:     "No replicas ready",
+			replicas: 3,
+			status: appsv1.StatefulSetStatus{
+				CurrentRevision: "db-1", UpdateRevision: "db-1",
+			},
+			expectedHealth: Failing,
+			expectedReason: "no replicas ready",
+		},
+		{
+			name:     "Rollout stuck on an unready pod",
+			replicas: 3,
+			status: appsv1.StatefulSetStatus{
+				ReadyReplicas: 2, UpdatedReplicas: 1,
+				CurrentRevision: "db-1", UpdateRevision: "db-2",
+			},
+			expectedHealth: Failing,
+			expectedReason: "rollout stuck at 1/3 updated",
+		},
+		{
+			name:     "Rollout progressing",
+			replicas: 3,
+			status: appsv1.StatefulSetStatus{
+				ReadyReplicas: 3, UpdatedReplicas: 2,
+				CurrentRevision: "db-1", UpdateRevision: "db-2",
+			},
+			expectedHealth: Degraded,
+			expectedReason: "rollout in progress, 2/3 updated",
+		},
+		{
+			name:     "OnDelete waiting for pods to be deleted",
+			strategy: appsv1.OnDeleteStatefulSetStrategyType,
+			replicas: 3,
+			status: appsv1.StatefulSetStatus{
+				ReadyReplicas: 2, UpdatedReplicas: 0,
+				CurrentRevision: "db-1", UpdateRevision: "db-2",
+			},
+			expectedHealth: Degraded,
+			expectedReason: "2 of 3 replicas ready",
+		},
+		{
+			name:           "Scaled to zero",
+			replicas:       0,
+			status:         appsv1.StatefulSetStatus{},
+			expectedHealth: Healthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sts := appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "production"},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas:       int32Ptr(tt.replicas),
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: tt.strategy},
+				},
+				Status: tt.status,
+			}
+
+			health := analyzeStatefulSet(sts, now)
+
+			if health.Health != tt.expectedHealth {
+				t.Errorf("expected health %s, got %s (%s)", tt.expectedHealth, health.Health, health.Reason)
+			}
+			if !strings.Contains(health.Reason, tt.expectedReason) {
+				t.Errorf("expected reason containing %q, got %q", tt.expectedReason, health.Reason)
+			}
+		})
+	}
+}
+
+func TestAnalyzeDaemonSet(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		status         appsv1.DaemonSetStatus
+		expectedHealth string
+		expectedReason string
+	}{
+		{
+			name: "Running on every node",
+			status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: 5, NumberReady: 5, NumberAvailable: 5, UpdatedNumberScheduled: 5,
+			},
+			expectedHealth: Healthy,
+		},
+		{
+			name: "Unavailable pods",
+			status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: 5, NumberReady: 3, NumberAvailable: 3, NumberUnavailable: 2, UpdatedNumberScheduled: 5,
+			},
+			expectedHealth: Degraded,
+			expectedReason: "2 of 5 pods unavailable",
+		},
+		{
+			name: "No pods available",
+			status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: 5, NumberUnavailable: 5, UpdatedNumberScheduled: 5,
+			},
+			expectedHealth: Failing,
+			expectedReason: "no pods available on 5 node(s)",
+		},
+		{
+			name: "Misscheduled pods",
+			status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: 5, NumberReady: 5, NumberAvailable: 5, UpdatedNumberScheduled: 5, NumberMisscheduled: 1,
+			},
+			expectedHealth: Degraded,
+			expectedReason: "1 pod(s) running on nodes they should not run on",
+		},
+		{
+			name: "Rollout in progress",
+			status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: 5, NumberReady: 5, NumberAvailable: 5, UpdatedNumberScheduled: 3,
+			},
+			expectedHealth: Degraded,
+			expectedReason: "rollout in progress, 3/5 updated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-exporter", Namespace: "monitoring"},
+				Status:     tt.status,
+			}
+
+			health := analyzeDaemonSet(ds, now)
+
+			if health.Health != tt.expectedHealth {
+				t.Errorf("expected health %s, got %s (%s)", tt.expectedHealth, health.Health, health.Reason)
+			}
+			if !strings.Contains(health.Reason, tt.expectedReason) {
+				t.Errorf("expected reason containing %q, got %q", tt.expectedReason, health.Reason)
+			}
+		})
+	}
+}
+
+func TestAnalyzeJob(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	startedAt := func(ago time.Duration) *metav1.Time {
+		start := metav1.NewTime(now.Add(-ago))
+		return &start
+	}
+
+	tests := []struct {
+		name           string
+		spec           batchv1.JobSpec
+		status         batchv1.JobStatus
+		expectedStatus string
+		expectedHealth string
+		expectedReason string
+	}{
+		{
+			name: "Complete",
+			status: batchv1.JobStatus{
+				Succeeded:  1,
+				StartTime:  startedAt(10 * time.Minute),
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+			},
+			expectedStatus: "Complete",
+			expectedHealth: Healthy,
+		},
+		{
+			name: "Backoff limit exceeded",
+			status: batchv1.JobStatus{
+				Failed:    7,
+				StartTime: startedAt(time.Hour),
+				Conditions: []batchv1.JobCondition{{
+					Type: batchv1.JobFailed, Status: corev1.ConditionTrue,
+					Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit",
+				}},
+			},
+			expectedStatus: "Failed",
+			expectedHealth: Failing,
+			expectedReason: "BackoffLimitExceeded: Job has reached the specified backoff limit",
+		},
+		{
+			name: "Running past its active deadline",
+			spec: batchv1.JobSpec{ActiveDeadlineSeconds: int64Ptr(600)},
+			status: batchv1.JobStatus{
+				Active:    1,
+				StartTime: startedAt(30 * time.Minute),
+			},
+			expectedStatus: "Running",
+			expectedHealth: Failing,
+			expectedReason: "running for 30m0s, past its 600s active deadline",
+		},
+		{
+			name: "Retrying after a failure",
+			status: batchv1.JobStatus{
+				Active:    1,
+				Failed:    2,
+				StartTime: startedAt(5 * time.Minute),
+			},
+			expectedStatus: "Running",
+			expectedHealth: Degraded,
+			expectedReason: "2 failed attempt(s), retrying",
+		},
+		{
+			name:           "Suspended",
+			spec:           batchv1.JobSpec{Suspend: boolPtr(true)},
+			expectedStatus: "Suspended",
+			expectedHealth: Healthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "ops"},
+				Spec:       tt.spec,
+				Status:     tt.status,
+			}
+
+			health := analyzeJob(job, now)
+
+			if health.Status != tt.expectedStatus {
+				t.Errorf("expected status %s, got %s", tt.expectedStatus, health.Status)
+			}
+			if health.Health != tt.expectedHealth {
+				t.Errorf("expected health %s, got %s (%s)", tt.expectedHealth, health.Health, health.Reason)
+			}
+			if !strings.Contains(health.Reason, tt.expectedReason) {
+				t.Errorf("expected reason containing %q, got %q", tt.expectedReason, health.Reason)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCronJob(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC)
+	at := func(t time.Time) *metav1.Time {
+		mt := metav1.NewTime(t)
+		return &mt
+	}
+	created := now.Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name           string
+		spec           batchv1.CronJobSpec
+		status         batchv1.CronJobStatus
+		expectedHealth string
+		expectedReason string
+	}{
+		{
+			name: "Ran and succeeded on schedule",
+			spec: batchv1.CronJobSpec{Schedule: "0 * * * *"},
+			status: batchv1.CronJobStatus{
+				LastScheduleTime:   at(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)),
+				LastSuccessfulTime: at(time.Date(2026, 10, 18, 12, 2, 0, 0, time.UTC)),
+			},
+			expectedHealth: Healthy,
+		},
+		{
+			name:           "Suspended",
+			spec:           batchv1.CronJobSpec{Schedule: "0 * * * *", Suspend: boolPtr(true)},
+			expectedHealth: Degraded,
+			expectedReason: "suspended",
+		},
+		{
+			name: "Missed schedules",
+			spec: batchv1.CronJobSpec{Schedule: "0 * * * *"},
+			status: batchv1.CronJobStatus{
+				LastScheduleTime:   at(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)),
+				LastSuccessfulTime: at(time.Date(2026, 10, 18, 9, 1, 0, 0, time.UTC)),
+			},
+			expectedHealth: Failing,
+			expectedReason: "missed 3 scheduled run(s) since 2026-10-18 10:00",
+		},
+		{
+			name: "Starting deadline allows a late start",
+			spec: batchv1.CronJobSpec{Schedule: "0 12 * * *", StartingDeadlineSeconds: int64Ptr(3600)},
+			status: batchv1.CronJobStatus{
+				LastScheduleTime:   at(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)),
+				LastSuccessfulTime: at(time.Date(2026, 10, 17, 12, 5, 0, 0, time.UTC)),
+			},
+			expectedHealth: Healthy,
+		},
+		{
+			name: "Forbid skips runs while a long job is active",
+			spec: batchv1.CronJobSpec{Schedule: "0 * * * *", ConcurrencyPolicy: batchv1.ForbidConcurrent},
+			status: batchv1.CronJobStatus{
+				Active:             []corev1.ObjectReference{{Name: "report-29001234"}},
+				LastScheduleTime:   at(time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)),
+				LastSuccessfulTime: at(time.Date(2026, 10, 18, 9, 1, 0, 0, time.UTC)),
+			},
+			expectedHealth: Healthy,
+		},
+		{
+			name: "Allow still misses runs while a job is active",
+			spec: batchv1.CronJobSpec{Schedule: "0 * * * *", ConcurrencyPolicy: batchv1.AllowConcurrent},
+			status: batchv1.CronJobStatus{
+				Active:             []corev1.ObjectReference{{Name: "report-29001234"}},
+				LastScheduleTime:   at(time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)),
+				LastSuccessfulTime: at(time.Date(2026, 10, 18, 9, 1, 0, 0, time.UTC)),
+			},
+			expectedHealth: Failing,
+			expectedReason: "missed 2 scheduled run(s) since 2026-10-18 11:00",
+		},
+		{
+			name: "Last successful run too old",
+			spec: batchv1.CronJobSpec{Schedule: "0 * * * *"},
+			status: batchv1.CronJobStatus{
+				LastScheduleTime:   at(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)),
+				LastSuccessfulTime: at(time.Date(2026, 10, 18, 9, 1, 0, 0, time.UTC)),
+			},
+			expectedHealth: Failing,
+			expectedReason: "last successful run 3h ago, 3 scheduled run(s) since",
+		},
+		{
+			name: "One failed run is tolerated",
+			spec: batchv1.CronJobSpec{Schedule: "0 * * * *"},
+			status: batchv1.CronJobStatus{
+				LastScheduleTime:   at(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)),
+				LastSuccessfulTime: at(time.Date(2026, 10, 18, 11, 1, 0, 0, time.UTC)),
+			},
+			expectedHealth: Healthy,
+		},
+		{
+			name: "Never succeeded",
+			spec: batchv1.CronJobSpec{Schedule: "0 * * * *"},
+			status: batchv1.CronJobStatus{
+				LastScheduleTime: at(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)),
+			},
+			expectedHealth: Failing,
+			expectedReason: "no successful run in 100+ scheduled run(s)",
+		},
+		{
+			name: "Schedule in a time zone",
+			spec: batchv1.CronJobSpec{Schedule: "0 14 * * *", TimeZone: stringPtr("Europe/Berlin")},
+			status: batchv1.CronJobStatus{
+				// 14:00 in Berlin is 12:00 UTC in October
+				LastScheduleTime:   at(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)),
+				LastSuccessfulTime: at(time.Date(2026, 10, 18, 12, 3, 0, 0, time.UTC)),
+			},
+			expectedHealth: Healthy,
+		},
+		{
+			name:           "Invalid schedule",
+			spec:           batchv1.CronJobSpec{Schedule: "every day"},
+			expectedHealth: Failing,
+			expectedReason: "invalid schedule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cronJob := batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "ops", CreationTimestamp: metav1.NewTime(created)},
+				Spec:       tt.spec,
+				Status:     tt.status,
+			}
+
+			health := analyzeCronJob(cronJob, now)
+
+			if health.Health != tt.expectedHealth {
+				t.Errorf("expected health %s, got %s (%s)", tt.expectedHealth, health.Health, health.Reason)
+			}
+			if !strings.Contains(health.Reason, tt.expectedReason) {
+				t.Errorf("expected reason containing %q, got %q", tt.expectedReason, health.Reason)
+			}
+		})
+	}
+}
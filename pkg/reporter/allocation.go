@@ -0,0 +1,114 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/k8s"
+	"github.com/olekukonko/tablewriter"
+)
+
+// RenderAllocationResults renders Kubernetes cost per namespace and label
+func (r *Reporter) RenderAllocationResults(results *k8s.AllocationResults) error {
+	switch r.format {
+	case "json":
+		return r.renderAllocationJSON(results)
+	case "table":
+		return r.renderAllocationTable(results)
+	default:
+		return fmt.Errorf("unsupported format: %s", r.format)
+	}
+}
+
+func (r *Reporter) renderAllocationJSON(results *k8s.AllocationResults) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+func (r *Reporter) renderAllocationTable(results *k8s.AllocationResults) error {
+	fmt.Printf("☸️  Kubernetes Cost Allocation (%d days)\n", results.Days)
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	fmt.Printf("Estimated as today's nodes and pod requests × %d days, not actual usage over the period\n\n", results.Days)
+	fmt.Printf("Node cost:  $%.2f\n", results.TotalCost)
+	fmt.Printf("Allocated:  $%.2f (%s)\n", results.AllocatedCost, allocationShare(results.AllocatedCost, results.TotalCost))
+	fmt.Printf("Idle:       $%.2f (%s)\n", results.IdleCost, allocationShare(results.IdleCost, results.TotalCost))
+	if len(results.UnpricedNodes) > 0 {
+		fmt.Printf("⚠️  %d node(s) without a price estimate for their instance type are excluded: %s\n", len(results.UnpricedNodes), formatResourceList(results.UnpricedNodes, 5))
+	}
+	fmt.Println()
+
+	fmt.Println("📦 Cost by Namespace")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	renderAllocations("Namespace", results.Namespaces, results)
+	fmt.Println()
+
+	for _, label := range results.Labels {
+		fmt.Printf("🏷️  Cost by Label %s\n", label.Key)
+		fmt.Println("─────────────────────────────────────────────────────────────")
+		renderAllocations(label.Key, label.Values, results)
+		fmt.Println()
+	}
+
+	fmt.Println("🖥️  Cost by Node")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Node", "Instance Type", "CPU Requested", "Memory Requested", "Hourly", "Cost", "Idle"})
+	table.SetBorder(false)
+
+	for _, node := range results.Nodes {
+		hourly, cost, idle := "-", "-", "-"
+		if node.Priced {
+			hourly = fmt.Sprintf("$%.4f", node.HourlyCost)
+			cost = fmt.Sprintf("$%.2f", node.Cost)
+			idle = fmt.Sprintf("$%.2f", node.IdleCost)
+		}
+		table.Append([]string{
+			node.Name,
+			valueOrDash(node.InstanceType),
+			fmt.Sprintf("%.2f / %.2f", node.CPURequested, node.CPUAllocatable),
+			fmt.Sprintf("%.1f / %.1f GiB", node.MemoryRequested, node.MemoryAllocatable),
+			hourly,
+			cost,
+			idle,
+		})
+	}
+	table.Render()
+
+	return nil
+}
+
+// renderAllocations renders costs by namespace or label value, with idle
+// capacity as the last row
+func renderAllocations(header string, allocations []k8s.CostAllocation, results *k8s.AllocationResults) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{header, "Pods", "CPU", "Memory", "CPU Cost", "Memory Cost", "Cost", "Share"})
+	table.SetBorder(false)
+
+	for _, allocation := range allocations {
+		table.Append([]string{
+			allocation.Name,
+			fmt.Sprintf("%d", allocation.Pods),
+			fmt.Sprintf("%.2f", allocation.CPURequest),
+			fmt.Sprintf("%.1f GiB", allocation.MemoryRequest),
+			fmt.Sprintf("$%.2f", allocation.CPUCost),
+			fmt.Sprintf("$%.2f", allocation.MemoryCost),
+			fmt.Sprintf("$%.2f", allocation.Cost),
+			allocationShare(allocation.Cost, results.TotalCost),
+		})
+	}
+	table.Append([]string{
+		k8s.IdleAllocation, "-", "-", "-", "-", "-",
+		fmt.Sprintf("$%.2f", results.IdleCost),
+		allocationShare(results.IdleCost, results.TotalCost),
+	})
+	table.Render()
+}
+
+func allocationShare(cost, total float64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", cost/total*100)
+}
@@ -0,0 +1,140 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+	"github.com/olekukonko/tablewriter"
+)
+
+// RenderAnomalyResults renders daily cost anomalies
+func (r *Reporter) RenderAnomalyResults(results *aws.AnomalyResults) error {
+	switch r.format {
+	case "json":
+		return r.renderAnomalyJSON(results)
+	case "table":
+		return r.renderAnomalyTable(results)
+	default:
+		return fmt.Errorf("unsupported format: %s", r.format)
+	}
+}
+
+func (r *Reporter) renderAnomalyJSON(results *aws.AnomalyResults) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+func (r *Reporter) renderAnomalyTable(results *aws.AnomalyResults) error {
+	fmt.Printf("🚨 Cost Anomalies (%s to %s)\n",
+		results.StartDate.Format("2006-01-02"),
+		results.EndDate.Format("2006-01-02"))
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	fmt.Printf("Baseline: %d days, threshold %.1fσ, minimum impact $%.2f\n\n",
+		results.Options.Window, results.Options.Threshold, results.Options.MinImpact)
+
+	// Anomalies in the total, with the groups that drove them
+	fmt.Println("📈 Total Daily Spend")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	if len(results.Total) == 0 {
+		fmt.Println("✅ No anomalies in total spend")
+	} else {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Date", "Cost", "Baseline", "Impact", "Score", "Top " + results.GroupBy})
+		table.SetBorder(false)
+
+		for _, anomaly := range results.Total {
+			drivers := make([]string, 0, len(anomaly.Drivers))
+			for _, driver := range anomaly.Drivers {
+				drivers = append(drivers, fmt.Sprintf("%s (+$%.2f)", driver.Name, driver.Impact))
+			}
+			table.Append([]string{
+				anomaly.Date,
+				fmt.Sprintf("$%.2f", anomaly.Amount),
+				fmt.Sprintf("$%.2f", anomaly.Baseline),
+				anomalyImpact(anomaly),
+				anomalyScore(anomaly),
+				strings.Join(drivers, ", "),
+			})
+		}
+		table.Render()
+	}
+	fmt.Println()
+
+	// Anomalies per group
+	fmt.Printf("🏷️  By %s\n", results.GroupBy)
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	if len(results.Groups) == 0 {
+		fmt.Printf("✅ No anomalies by %s\n", results.GroupBy)
+	} else {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Date", results.GroupBy, "Cost", "Baseline", "Impact", "Score"})
+		table.SetBorder(false)
+
+		for _, anomaly := range results.Groups {
+			table.Append([]string{
+				anomaly.Date,
+				anomaly.Scope,
+				fmt.Sprintf("$%.2f", anomaly.Amount),
+				fmt.Sprintf("$%.2f", anomaly.Baseline),
+				anomalyImpact(anomaly),
+				anomalyScore(anomaly),
+			})
+		}
+		table.Render()
+	}
+
+	// AWS Cost Anomaly Detection, when requested
+	if results.AWSAnomalies != nil {
+		fmt.Println()
+		fmt.Println("🔎 AWS Cost Anomaly Detection")
+		fmt.Println("─────────────────────────────────────────────────────────────")
+		if len(results.AWSAnomalies) == 0 {
+			fmt.Println("✅ No anomalies reported by AWS")
+			return nil
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Start", "End", "Dimension", "Impact", "Root Causes", "Detected"})
+		table.SetBorder(false)
+
+		for _, anomaly := range results.AWSAnomalies {
+			end := anomaly.EndDate
+			if end == "" {
+				end = "ongoing"
+			}
+			detected := "❌"
+			if anomaly.Detected {
+				detected = "✅"
+			}
+			table.Append([]string{
+				anomaly.StartDate,
+				end,
+				anomaly.DimensionValue,
+				fmt.Sprintf("$%.2f", anomaly.TotalImpact),
+				strings.Join(anomaly.RootCauses, "; "),
+				detected,
+			})
+		}
+		table.Render()
+	}
+
+	return nil
+}
+
+func anomalyImpact(anomaly aws.CostAnomaly) string {
+	if anomaly.Baseline == 0 {
+		return fmt.Sprintf("+$%.2f", anomaly.Impact)
+	}
+	return fmt.Sprintf("+$%.2f (+%.0f%%)", anomaly.Impact, anomaly.ImpactPercent)
+}
+
+func anomalyScore(anomaly aws.CostAnomaly) string {
+	if anomaly.StdDev == 0 {
+		return "flat baseline"
+	}
+	return fmt.Sprintf("%.1fσ", anomaly.Score)
+}
@@ -0,0 +1,89 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+	"github.com/olekukonko/tablewriter"
+)
+
+// RenderBudgetCheckResults renders the result of each budget in a budget file
+func (r *Reporter) RenderBudgetCheckResults(results *aws.BudgetCheckResults) error {
+	switch r.format {
+	case "json":
+		return r.renderBudgetCheckJSON(results)
+	case "table":
+		return r.renderBudgetCheckTable(results)
+	default:
+		return fmt.Errorf("unsupported format: %s", r.format)
+	}
+}
+
+func (r *Reporter) renderBudgetCheckJSON(results *aws.BudgetCheckResults) error {
+	output := struct {
+		*aws.BudgetCheckResults
+		Status string
+	}{
+		BudgetCheckResults: results,
+		Status:             results.Status(),
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+func (r *Reporter) renderBudgetCheckTable(results *aws.BudgetCheckResults) error {
+	fmt.Printf("🚦 Budget Check (%s)\n", results.GeneratedAt.UTC().Format("2006-01-02"))
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	if results.Metric != aws.MetricUnblendedCost {
+		fmt.Printf("Metric: %s\n", results.Metric)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Budget", "Period", "Scope", "Limit", "Actual", "Used", "Forecast", "Status"})
+	table.SetBorder(false)
+
+	for _, check := range results.Checks {
+		forecast := "-"
+		if check.HasForecast {
+			forecast = fmt.Sprintf("$%.2f (%.0f%%)", check.Forecast, check.ForecastPercent)
+		}
+		table.Append([]string{
+			check.Name,
+			check.Period,
+			check.Scope,
+			fmt.Sprintf("$%.2f", check.Limit),
+			fmt.Sprintf("$%.2f", check.Actual),
+			fmt.Sprintf("%.1f%%", check.ActualPercent),
+			forecast,
+			budgetCheckStatusLabel(check.Status),
+		})
+	}
+	table.Render()
+
+	failing := results.Failing()
+	if len(failing) == 0 {
+		fmt.Println("\n✅ All budgets are within their limits")
+		return nil
+	}
+
+	fmt.Println()
+	for _, check := range failing {
+		fmt.Printf("%s %s: %s\n", budgetCheckStatusLabel(check.Status), check.Name, check.Reason)
+	}
+	return nil
+}
+
+func budgetCheckStatusLabel(status string) string {
+	switch status {
+	case aws.BudgetCheckCritical:
+		return "🔴 CRITICAL"
+	case aws.BudgetCheckWarning:
+		return "🟡 WARNING"
+	default:
+		return "🟢 OK"
+	}
+}
@@ -0,0 +1,299 @@
+package reporter
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+	"golang.org/x/term"
+)
+
+// chartMaxGroups is the number of groups stacked in trend bars, the rest
+// being shown as Other
+const chartMaxGroups = 5
+
+// chartMinBarWidth is the narrowest bar worth drawing; narrower terminals
+// get the plain table
+const chartMinBarWidth = 10
+
+// chartAnomalyMarker flags days the rolling baseline finds anomalous
+const chartAnomalyMarker = "▲"
+
+// sparkTicks are the sparkline levels, lowest first
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// chartFills tell stacked groups apart without color, the last one being Other
+var chartFills = []rune("█▓▒░▚▞")
+
+// chartColors are the ANSI colors of stacked groups, matching chartFills
+var chartColors = []string{"\033[36m", "\033[33m", "\033[35m", "\033[32m", "\033[34m", "\033[90m"}
+
+const chartColorReset = "\033[0m"
+
+// chartSeries is the amount of one group in each period of a chart
+type chartSeries struct {
+	Name   string
+	Values []float64
+	// Fill is the index of the series in chartFills and chartColors
+	Fill int
+}
+
+// trendChart is a cost trend ready to draw, one row per period
+type trendChart struct {
+	Labels  []string
+	Totals  []float64
+	Amounts []string
+	// Markers are shown after the amount, such as the anomaly marker
+	Markers []string
+	// Series stack each total by group, empty for plain bars
+	Series []chartSeries
+}
+
+// terminalWidth returns the width of stdout, or false when stdout is not a
+// terminal. COLUMNS overrides the detected width.
+func terminalWidth() (int, bool) {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return 0, false
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns, true
+	}
+	width, _, err := term.GetSize(fd)
+	if err != nil || width <= 0 {
+		return 80, true
+	}
+	return width, true
+}
+
+// chartColorEnabled reports whether charts may use ANSI colors
+func chartColorEnabled() bool {
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return !noColor && os.Getenv("TERM") != "dumb"
+}
+
+// newTrendChart builds the trend chart of a cost report, stacking each
+// period by the top groups and marking anomalous days
+func newTrendChart(results *aws.CostResults) *trendChart {
+	chart := &trendChart{
+		Labels:  make([]string, 0, len(results.DailyTrend)),
+		Totals:  make([]float64, 0, len(results.DailyTrend)),
+		Amounts: make([]string, 0, len(results.DailyTrend)),
+		Markers: make([]string, len(results.DailyTrend)),
+		Series:  make([]chartSeries, 0),
+	}
+	for _, day := range results.DailyTrend {
+		chart.Labels = append(chart.Labels, day.Date)
+		chart.Totals = append(chart.Totals, day.Amount)
+		chart.Amounts = append(chart.Amounts, costAmount(results, day.Amount))
+	}
+
+	if results.Granularity != "MONTHLY" {
+		anomalies := make(map[string]aws.CostAnomaly)
+		for _, anomaly := range results.DetectAnomalies(aws.DefaultAnomalyOptions).Total {
+			anomalies[anomaly.Date] = anomaly
+		}
+		for i, day := range results.DailyTrend {
+			if anomaly, ok := anomalies[day.Date]; ok {
+				chart.Markers[i] = fmt.Sprintf("%s %s", chartAnomalyMarker, signedCostAmount(results, anomaly.Impact))
+			}
+		}
+	}
+
+	// Stack by the largest groups, which lead the items
+	stacked := make([]float64, len(results.DailyTrend))
+	for _, item := range results.Items {
+		if len(chart.Series) == chartMaxGroups {
+			break
+		}
+		daily, ok := results.DailyByGroup[item.Name]
+		if !ok || len(daily) != len(results.DailyTrend) {
+			continue
+		}
+		series := chartSeries{Name: item.Name, Values: make([]float64, len(daily)), Fill: len(chart.Series)}
+		for i, day := range daily {
+			series.Values[i] = day.Amount
+			stacked[i] += day.Amount
+		}
+		chart.Series = append(chart.Series, series)
+	}
+	if len(chart.Series) == 0 {
+		return chart
+	}
+
+	other := chartSeries{Name: "Other", Values: make([]float64, len(chart.Totals)), Fill: len(chartFills) - 1}
+	hasOther := false
+	for i, total := range chart.Totals {
+		if rest := total - stacked[i]; rest > 0.005 {
+			other.Values[i] = rest
+			hasOther = true
+		}
+	}
+	if hasOther {
+		chart.Series = append(chart.Series, other)
+	}
+	return chart
+}
+
+// render draws the chart in width columns: a sparkline, then one bar per
+// period. It returns false if the terminal is too narrow for bars.
+func (c *trendChart) render(width int, color bool) ([]string, bool) {
+	labelWidth, amountWidth, markerWidth := 0, 0, 0
+	for i := range c.Labels {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(c.Labels[i]))
+		amountWidth = max(amountWidth, utf8.RuneCountInString(c.Amounts[i]))
+		markerWidth = max(markerWidth, utf8.RuneCountInString(c.Markers[i]))
+	}
+	if markerWidth > 0 {
+		markerWidth++
+	}
+
+	// "<label> │<bar> <amount> <marker>"
+	barWidth := width - labelWidth - 3 - amountWidth - markerWidth - 1
+	if barWidth < chartMinBarWidth {
+		return nil, false
+	}
+
+	lines := make([]string, 0, len(c.Labels)+4)
+	lines = append(lines, sparklineSummary(c.Totals, c.Amounts, width-1))
+	lines = append(lines, "")
+
+	maxTotal := 0.0
+	for _, total := range c.Totals {
+		maxTotal = max(maxTotal, total)
+	}
+
+	for i, label := range c.Labels {
+		values, fills := []float64{c.Totals[i]}, []int{0}
+		if len(c.Series) > 0 {
+			values, fills = make([]float64, len(c.Series)), make([]int, len(c.Series))
+			for j, series := range c.Series {
+				values[j], fills[j] = series.Values[i], series.Fill
+			}
+		}
+		bar := stackedBar(values, fills, maxTotal, barWidth, color && len(c.Series) > 0)
+
+		line := fmt.Sprintf("%-*s │%s %*s", labelWidth, label, bar, amountWidth, c.Amounts[i])
+		if c.Markers[i] != "" {
+			line += " " + c.Markers[i]
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+
+	if len(c.Series) > 0 {
+		legend := make([]string, 0, len(c.Series))
+		for _, series := range c.Series {
+			legend = append(legend, chartFill(series.Fill, color)+" "+series.Name)
+		}
+		lines = append(lines, "", "Legend: "+strings.Join(legend, "  "))
+	}
+	if hasMarkers(c.Markers) {
+		lines = append(lines, fmt.Sprintf("%s anomaly: increase over the %d-day baseline", chartAnomalyMarker, aws.DefaultAnomalyOptions.Window))
+	}
+
+	return lines, true
+}
+
+// stackedBar draws values side by side, each with its fill, in a bar scaled
+// so maxTotal fills width. Segment edges are rounded from the running total,
+// so a stack is as long as a single bar of the same total.
+func stackedBar(values []float64, fills []int, maxTotal float64, width int, color bool) string {
+	var bar strings.Builder
+	cells, cumulative := 0, 0.0
+	for i, value := range values {
+		if value <= 0 || maxTotal <= 0 {
+			continue
+		}
+		cumulative += value
+		end := min(int(math.Round(cumulative/maxTotal*float64(width))), width)
+		if end <= cells {
+			continue
+		}
+		bar.WriteString(chartSegment(fills[i], end-cells, color))
+		cells = end
+	}
+	bar.WriteString(strings.Repeat(" ", width-cells))
+	return bar.String()
+}
+
+// chartFill returns the legend cell of a series, colored if enabled
+func chartFill(index int, color bool) string {
+	return chartSegment(index, 1, color)
+}
+
+// chartSegment returns cells cells of a series' fill, colored if enabled
+func chartSegment(index, cells int, color bool) string {
+	segment := strings.Repeat(string(chartFills[index]), cells)
+	if !color {
+		return segment
+	}
+	return chartColors[index] + segment + chartColorReset
+}
+
+// sparklineSummary draws values as a sparkline of at most width cells,
+// followed by the lowest and highest amounts
+func sparklineSummary(values []float64, amounts []string, width int) string {
+	lowest, highest := 0, 0
+	for i, value := range values {
+		if value < values[lowest] {
+			lowest = i
+		}
+		if value > values[highest] {
+			highest = i
+		}
+	}
+
+	summary := fmt.Sprintf("  min %s  max %s", amounts[lowest], amounts[highest])
+	return sparkline(values, max(width-utf8.RuneCountInString(summary), 1)) + summary
+}
+
+// sparkline draws values as one tick each, averaging neighbours when there
+// are more values than width
+func sparkline(values []float64, width int) string {
+	if len(values) == 0 || width < 1 {
+		return ""
+	}
+
+	buckets := values
+	if len(values) > width {
+		buckets = make([]float64, width)
+		for i := range buckets {
+			from := i * len(values) / width
+			to := (i + 1) * len(values) / width
+			sum := 0.0
+			for _, value := range values[from:to] {
+				sum += value
+			}
+			buckets[i] = sum / float64(to-from)
+		}
+	}
+
+	lowest, highest := buckets[0], buckets[0]
+	for _, value := range buckets {
+		lowest = min(lowest, value)
+		highest = max(highest, value)
+	}
+
+	var line strings.Builder
+	for _, value := range buckets {
+		level := len(sparkTicks) - 1
+		if highest > lowest {
+			level = int((value - lowest) / (highest - lowest) * float64(len(sparkTicks)-1))
+		}
+		line.WriteRune(sparkTicks[level])
+	}
+	return line.String()
+}
+
+func hasMarkers(markers []string) bool {
+	for _, marker := range markers {
+		if marker != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		width  int
+		want   string
+	}{
+		{name: "one tick per value", values: []float64{0, 1, 2, 3, 4, 5, 6, 7}, width: 20, want: "▁▂▃▄▅▆▇█"},
+		{name: "flat", values: []float64{5, 5, 5}, width: 20, want: "███"},
+		{name: "averaged to width", values: []float64{0, 0, 10, 10}, width: 2, want: "▁█"},
+		{name: "empty", values: nil, width: 10, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values, tt.width); got != tt.want {
+				t.Errorf("sparkline() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStackedBar(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		fills  []int
+		want   string
+	}{
+		{name: "full bar", values: []float64{100}, fills: []int{0}, want: "██████████"},
+		{name: "stacked", values: []float64{30, 20, 10}, fills: []int{0, 1, 5}, want: "███▓▓▞    "},
+		{name: "segments rounded from the running total", values: []float64{14, 14, 14}, fills: []int{0, 1, 2}, want: "█▓▓▒      "},
+		{name: "negative and zero skipped", values: []float64{-5, 0, 50}, fills: []int{0, 1, 2}, want: "▒▒▒▒▒     "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stackedBar(tt.values, tt.fills, 100, 10, false); got != tt.want {
+				t.Errorf("stackedBar() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	colored := stackedBar([]float64{50}, []int{1}, 100, 4, true)
+	if colored != chartColors[1]+"▓▓"+chartColorReset+"  " {
+		t.Errorf("colored stackedBar() = %q", colored)
+	}
+}
+
+func testTrendResults() *aws.CostResults {
+	results := &aws.CostResults{
+		Currency:     "USD",
+		Metric:       aws.MetricUnblendedCost,
+		Granularity:  "DAILY",
+		GroupByKeys:  []string{"SERVICE"},
+		DailyByGroup: map[string][]aws.DailyCost{},
+	}
+
+	// Ten quiet days, then a spike in EC2
+	ec2 := []float64{40, 41, 39, 40, 42, 40, 41, 39, 40, 41, 240}
+	for i, amount := range ec2 {
+		date := fmt.Sprintf("2024-11-%02d", i+1)
+		results.DailyTrend = append(results.DailyTrend, aws.DailyCost{Date: date, Amount: amount + 20})
+		results.DailyByGroup["EC2"] = append(results.DailyByGroup["EC2"], aws.DailyCost{Date: date, Amount: amount})
+		results.DailyByGroup["S3"] = append(results.DailyByGroup["S3"], aws.DailyCost{Date: date, Amount: 15})
+		results.TotalCost += amount + 20
+	}
+	results.Items = []aws.CostItem{{Name: "EC2", Amount: 643}, {Name: "S3", Amount: 165}, {Name: "Other", Amount: 55}}
+	return results
+}
+
+func TestNewTrendChart(t *testing.T) {
+	chart := newTrendChart(testTrendResults())
+
+	names := make([]string, 0, len(chart.Series))
+	for _, series := range chart.Series {
+		names = append(names, series.Name)
+	}
+	if strings.Join(names, ",") != "EC2,S3,Other" {
+		t.Fatalf("Series = %v, want EC2, S3 and the rest as Other", names)
+	}
+	if other := chart.Series[2]; other.Values[0] != 5 || other.Fill != len(chartFills)-1 {
+		t.Errorf("Other = %+v, want 5 a day with the Other fill", other)
+	}
+
+	for i, marker := range chart.Markers {
+		if (marker != "") != (i == 10) {
+			t.Errorf("Markers[%d] = %q, want a marker only on the spike", i, marker)
+		}
+	}
+	if !strings.HasPrefix(chart.Markers[10], chartAnomalyMarker+" +$") {
+		t.Errorf("spike marker = %q", chart.Markers[10])
+	}
+}
+
+func TestTrendChartRender(t *testing.T) {
+	chart := newTrendChart(testTrendResults())
+
+	lines, ok := chart.render(80, false)
+	if !ok {
+		t.Fatal("render() = false at 80 columns")
+	}
+	for _, line := range lines {
+		if width := utf8.RuneCountInString(line); width > 80 {
+			t.Errorf("line is %d columns wide: %q", width, line)
+		}
+	}
+	if !strings.HasPrefix(lines[2], "2024-11-01 │█") || !strings.Contains(lines[12], chartAnomalyMarker) {
+		t.Errorf("unexpected bars:\n%s", strings.Join(lines, "\n"))
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "Legend: █ EC2  ▓ S3  ▞ Other") {
+		t.Errorf("legend missing:\n%s", strings.Join(lines, "\n"))
+	}
+
+	if _, ok := chart.render(30, false); ok {
+		t.Error("render() = true at 30 columns, want the table fallback")
+	}
+}
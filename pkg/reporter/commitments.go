@@ -0,0 +1,172 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+	"github.com/olekukonko/tablewriter"
+)
+
+// RenderCommitmentResults renders Reserved Instance and Savings Plans
+// utilization, coverage, expirations and recommendations
+func (r *Reporter) RenderCommitmentResults(results *aws.CommitmentResults) error {
+	switch r.format {
+	case "json":
+		return r.renderCommitmentJSON(results)
+	case "table":
+		return r.renderCommitmentTable(results)
+	default:
+		return fmt.Errorf("unsupported format: %s", r.format)
+	}
+}
+
+func (r *Reporter) renderCommitmentJSON(results *aws.CommitmentResults) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+func (r *Reporter) renderCommitmentTable(results *aws.CommitmentResults) error {
+	fmt.Printf("🤝 Commitments (%s to %s)\n",
+		results.StartDate.Format("2006-01-02"),
+		results.EndDate.Format("2006-01-02"))
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	fmt.Printf("Minimum utilization: %.0f%%, expiring within %d days\n\n", results.MinUtilization, results.ExpiringWithin)
+
+	// Utilization and coverage per commitment type
+	fmt.Println("📊 Utilization and Coverage")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	if len(results.Utilization) == 0 && len(results.Coverage) == 0 {
+		fmt.Println("ℹ️  No Reserved Instances or Savings Plans found")
+	} else {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Type", "Utilization", "Commitment", "Unused", "Net Savings", "Coverage", "On-Demand Cost"})
+		table.SetBorder(false)
+
+		for _, commitmentType := range []string{aws.CommitmentTypeReservation, aws.CommitmentTypeSavingsPlan} {
+			row := []string{commitmentType, "-", "-", "-", "-", "-", "-"}
+			found := false
+			for _, utilization := range results.Utilization {
+				if utilization.Type != commitmentType {
+					continue
+				}
+				found = true
+				row[1] = utilizationPercent(utilization.UtilizationPercent, utilization.Low)
+				row[2] = fmt.Sprintf("$%.2f", utilization.TotalCommitment)
+				row[3] = fmt.Sprintf("$%.2f", utilization.UnusedCommitment)
+				row[4] = fmt.Sprintf("$%.2f", utilization.NetSavings)
+			}
+			for _, coverage := range results.Coverage {
+				if coverage.Type != commitmentType {
+					continue
+				}
+				found = true
+				row[5] = fmt.Sprintf("%.1f%%", coverage.CoveragePercent)
+				row[6] = fmt.Sprintf("$%.2f", coverage.OnDemandCost)
+			}
+			if found {
+				table.Append(row)
+			}
+		}
+		table.Render()
+	}
+	fmt.Println()
+
+	// Commitments expiring soon
+	expiring := results.Expiring()
+	fmt.Printf("⏳ Expiring Within %d Days\n", results.ExpiringWithin)
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	if len(expiring) == 0 {
+		fmt.Println("✅ No commitments expiring soon")
+	} else {
+		renderCommitments(expiring)
+	}
+	fmt.Println()
+
+	// Commitments used less than the minimum
+	low := results.LowUtilization()
+	fmt.Println("📉 Low Utilization")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	if len(low) == 0 {
+		fmt.Println("✅ All commitments are well utilized")
+	} else {
+		renderCommitments(low)
+	}
+	fmt.Println()
+
+	// Purchase recommendations from Cost Explorer
+	fmt.Println("💡 Purchase Recommendations")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	if len(results.Recommendations) == 0 {
+		fmt.Println("ℹ️  No purchase recommendations")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Type", "Service", "Purchase", "Account", "Upfront", "Monthly Savings", "Savings %"})
+	table.SetBorder(false)
+
+	totalSavings := 0.0
+	for _, recommendation := range results.Recommendations {
+		totalSavings += recommendation.EstimatedMonthlySavings
+		table.Append([]string{
+			recommendation.Type,
+			recommendation.Service,
+			recommendation.Description,
+			valueOrDash(recommendation.Account),
+			fmt.Sprintf("$%.2f", recommendation.UpfrontCost),
+			fmt.Sprintf("$%.2f", recommendation.EstimatedMonthlySavings),
+			fmt.Sprintf("%.1f%%", recommendation.EstimatedSavingsPercent),
+		})
+	}
+	table.Render()
+	fmt.Printf("\nEstimated monthly savings: $%.2f (Savings Plans and Reserved Instances can overlap)\n", totalSavings)
+
+	return nil
+}
+
+func renderCommitments(commitments []aws.Commitment) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Type", "ID", "Description", "Account", "Region", "Ends", "Days Left", "Utilization"})
+	table.SetBorder(false)
+
+	for _, commitment := range commitments {
+		ends, daysLeft := "-", "-"
+		if !commitment.End.IsZero() {
+			ends = commitment.End.Format("2006-01-02")
+			daysLeft = fmt.Sprintf("%d", commitment.DaysRemaining)
+		}
+		utilization := "-"
+		if commitment.HasUtilization {
+			utilization = utilizationPercent(commitment.UtilizationPercent, commitment.LowUtilization)
+		}
+		table.Append([]string{
+			commitment.Type,
+			commitment.ID,
+			valueOrDash(commitment.Description),
+			valueOrDash(commitment.Account),
+			valueOrDash(commitment.Region),
+			ends,
+			daysLeft,
+			utilization,
+		})
+	}
+	table.Render()
+}
+
+// utilizationPercent formats a utilization, marking it when it is low
+func utilizationPercent(percent float64, low bool) string {
+	if low {
+		return fmt.Sprintf("⚠️  %.1f%%", percent)
+	}
+	return fmt.Sprintf("%.1f%%", percent)
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
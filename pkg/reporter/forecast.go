@@ -0,0 +1,115 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+	"github.com/olekukonko/tablewriter"
+)
+
+// RenderForecastResults renders month-end and quarter-end cost forecasts
+func (r *Reporter) RenderForecastResults(results *aws.ForecastResults) error {
+	switch r.format {
+	case "json":
+		return r.renderForecastJSON(results)
+	case "table":
+		return r.renderForecastTable(results)
+	default:
+		return fmt.Errorf("unsupported format: %s", r.format)
+	}
+}
+
+func (r *Reporter) renderForecastJSON(results *aws.ForecastResults) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+func (r *Reporter) renderForecastTable(results *aws.ForecastResults) error {
+	fmt.Printf("🔮 Cost Forecast (%d%% confidence, %s)\n", results.ConfidenceLevel, results.Currency)
+	fmt.Println("─────────────────────────────────────────────────────────────")
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Period", "Ends", "Spent", "Burn Rate", "Forecast Rate", "Projected", "Range"})
+	table.SetBorder(false)
+
+	for _, period := range results.Periods {
+		table.Append([]string{
+			period.Name,
+			periodLastDay(period),
+			fmt.Sprintf("$%.2f", period.ActualSpend),
+			fmt.Sprintf("$%.2f/day", period.BurnRate),
+			fmt.Sprintf("$%.2f/day", period.ProjectedBurnRate),
+			fmt.Sprintf("$%.2f", period.ProjectedTotal),
+			fmt.Sprintf("$%.2f - $%.2f", period.ProjectedLower, period.ProjectedUpper),
+		})
+	}
+	table.Render()
+	fmt.Println()
+
+	fmt.Println("💰 Budgets")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+
+	budgetTable := tablewriter.NewWriter(os.Stdout)
+	budgetTable.SetHeader([]string{"Budget", "Period", "Source", "Limit", "Used", "Projected", "Overrun", "Exhausted On"})
+	budgetTable.SetBorder(false)
+
+	budgetCount := 0
+	for _, period := range results.Periods {
+		for _, status := range period.Budgets {
+			budgetCount++
+
+			overrun := "-"
+			if status.OverBudget {
+				overrun = fmt.Sprintf("🔴 $%.2f", status.ProjectedOverrun)
+			}
+			exhausted := "-"
+			if status.ExhaustionDate != "" {
+				exhausted = status.ExhaustionDate
+			}
+
+			budgetTable.Append([]string{
+				budgetLabel(status),
+				status.Period,
+				status.Source,
+				fmt.Sprintf("$%.2f", status.Limit),
+				fmt.Sprintf("%.1f%%", status.PercentUsed),
+				fmt.Sprintf("%.1f%%", status.ProjectedPercent),
+				overrun,
+				exhausted,
+			})
+		}
+	}
+
+	if budgetCount == 0 {
+		fmt.Println("ℹ️  No budgets to compare against (use --budget or create a cost budget in AWS Budgets)")
+		return nil
+	}
+	budgetTable.Render()
+
+	return nil
+}
+
+// periodLastDay returns the last day of a forecast period
+func periodLastDay(period aws.ForecastPeriod) string {
+	return period.End.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// budgetLabel returns the budget name, followed by how it measures spend when
+// that differs from the unblended costs in the forecast table
+func budgetLabel(status aws.BudgetStatus) string {
+	var basis []string
+	if status.Metric != "" && status.Metric != aws.MetricUnblendedCost {
+		basis = append(basis, status.Metric)
+	}
+	if len(status.ExcludedRecordTypes) > 0 {
+		basis = append(basis, "excl. "+strings.Join(status.ExcludedRecordTypes, ", "))
+	}
+	if len(basis) == 0 {
+		return status.Name
+	}
+	return fmt.Sprintf("%s (%s)", status.Name, strings.Join(basis, ", "))
+}
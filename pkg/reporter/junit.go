@@ -0,0 +1,103 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+)
+
+// JUnitTestSuites is the root element of a JUnit XML report
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite groups test cases
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase is one check, failed when Failure is set
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// JUnitFailure describes why a check failed
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// BuildBudgetJUnitReport converts budget checks into a JUnit report with one
+// test case per budget. Warnings and critical budgets are both failures, told
+// apart by their type.
+func BuildBudgetJUnitReport(results *aws.BudgetCheckResults) *JUnitTestSuites {
+	suite := JUnitTestSuite{
+		Name:      "dtk cost check",
+		Tests:     len(results.Checks),
+		Timestamp: results.GeneratedAt.UTC().Format("2006-01-02T15:04:05"),
+		Cases:     make([]JUnitTestCase, 0, len(results.Checks)),
+	}
+
+	for _, check := range results.Checks {
+		details := fmt.Sprintf("Scope: %s\nPeriod: %s to %s\nLimit: $%.2f\nActual: $%.2f (%.1f%%)",
+			check.Scope,
+			check.Start.Format("2006-01-02"),
+			check.End.Format("2006-01-02"),
+			check.Limit,
+			check.Actual,
+			check.ActualPercent)
+		if check.HasForecast {
+			details += fmt.Sprintf("\nForecast: $%.2f (%.1f%%)", check.Forecast, check.ForecastPercent)
+		}
+
+		testCase := JUnitTestCase{
+			Name:      check.Name,
+			ClassName: "budget." + check.Period,
+			SystemOut: details,
+		}
+		if check.Status != aws.BudgetCheckOK {
+			suite.Failures++
+			testCase.Failure = &JUnitFailure{
+				Message: check.Reason,
+				Type:    check.Status,
+				Text:    details,
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	return &JUnitTestSuites{
+		Name:     "dtk",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []JUnitTestSuite{suite},
+	}
+}
+
+// WriteBudgetJUnit writes budget checks as a JUnit XML report
+func WriteBudgetJUnit(w io.Writer, results *aws.BudgetCheckResults) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(BuildBudgetJUnitReport(results)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
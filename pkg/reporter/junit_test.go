@@ -0,0 +1,49 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+)
+
+func TestWriteBudgetJUnit(t *testing.T) {
+	results := &aws.BudgetCheckResults{
+		GeneratedAt: time.Date(2024, 11, 20, 8, 0, 0, 0, time.UTC),
+		Checks: []aws.BudgetCheck{
+			{Name: "total", Period: "monthly", Scope: "all spend", Limit: 1000, Actual: 400, Status: aws.BudgetCheckOK},
+			{Name: "ec2", Period: "daily", Scope: "SERVICE=EC2", Limit: 50, Actual: 45, Status: aws.BudgetCheckWarning, Reason: "spend is 90% of the limit"},
+			{Name: "team", Period: "monthly", Scope: "TAG:team=a&b", Limit: 100, Actual: 150, HasForecast: true, Forecast: 300, Status: aws.BudgetCheckCritical, Reason: "spend is 150% of the limit"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBudgetJUnit(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Errorf("report does not start with an XML header: %q", buf.String()[:20])
+	}
+
+	var report JUnitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid XML: %v", err)
+	}
+	if report.Tests != 3 || report.Failures != 2 || len(report.Suites) != 1 {
+		t.Fatalf("report = %+v, want 3 tests and 2 failures", report)
+	}
+
+	cases := report.Suites[0].Cases
+	if cases[0].Failure != nil {
+		t.Errorf("ok budget has a failure: %+v", cases[0].Failure)
+	}
+	if cases[1].Failure == nil || cases[1].Failure.Type != "warning" || cases[1].ClassName != "budget.daily" {
+		t.Errorf("warning case = %+v", cases[1])
+	}
+	if cases[2].Failure == nil || cases[2].Failure.Type != "critical" || !strings.Contains(cases[2].Failure.Text, "Forecast: $300.00") || !strings.Contains(cases[2].SystemOut, "TAG:team=a&b") {
+		t.Errorf("critical case = %+v", cases[2])
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
@@ -55,6 +56,19 @@ func (r *Reporter) RenderCostResults(results *aws.CostResults) error {
 	}
 }
 
+func (r *Reporter) RenderComplianceReport(report *aws.ComplianceReport) error {
+	switch r.format {
+	case "json":
+		return r.renderComplianceJSON(report)
+	case "table":
+		return r.renderComplianceTable(report)
+	case "csv":
+		return r.renderComplianceCSV(report)
+	default:
+		return fmt.Errorf("unsupported format: %s", r.format)
+	}
+}
+
 func (r *Reporter) renderAuditJSON(results *aws.AuditResults) error {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
@@ -245,27 +259,44 @@ func (r *Reporter) renderHealthJSON(results *k8s.HealthResults) error {
 }
 
 func (r *Reporter) renderHealthTable(results *k8s.HealthResults) error {
+	// Results from several clusters get a summary and a cluster column
+	multiCluster := len(results.Clusters) > 1
+	withCluster := func(cluster string, row []string) []string {
+		if !multiCluster {
+			return row
+		}
+		return append([]string{cluster}, row...)
+	}
+
+	if multiCluster {
+		renderClusterSummaries(results.Summaries)
+	}
+
 	// Pods
 	if len(results.Pods) > 0 {
 		fmt.Println("🔵 Pods Status")
 		fmt.Println("─────────────────────────────────────────────────────────────")
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Namespace", "Name", "Ready", "Status", "Restarts", "Age"})
+		table.SetHeader(withCluster("Cluster", []string{"Namespace", "Name", "Ready", "Status", "Restarts", "Age", "Health", "Reason"}))
 		table.SetBorder(false)
 
 		for _, pod := range results.Pods {
 			age := formatDuration(pod.Age)
-			table.Append([]string{
+			table.Append(withCluster(pod.Cluster, []string{
 				pod.Namespace,
 				pod.Name,
 				pod.Ready,
 				pod.Status,
 				fmt.Sprintf("%d", pod.Restarts),
 				age,
-			})
+				healthLabel(pod.Health),
+				pod.Reason,
+			}))
 		}
 		table.Render()
 		fmt.Println()
+	} else if results.HiddenHealthy > 0 {
+		fmt.Print("✅ All pods are healthy\n\n")
 	}
 
 	// Deployments
@@ -273,41 +304,143 @@ func (r *Reporter) renderHealthTable(results *k8s.HealthResults) error {
 		fmt.Println("📦 Deployments Status")
 		fmt.Println("─────────────────────────────────────────────────────────────")
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Namespace", "Name", "Ready", "Up-to-date", "Available", "Age"})
+		table.SetHeader(withCluster("Cluster", []string{"Namespace", "Name", "Ready", "Up-to-date", "Available", "Age"}))
 		table.SetBorder(false)
 
 		for _, dep := range results.Deployments {
 			age := formatDuration(dep.Age)
-			table.Append([]string{
+			table.Append(withCluster(dep.Cluster, []string{
 				dep.Namespace,
 				dep.Name,
 				dep.Ready,
 				fmt.Sprintf("%d", dep.UpToDate),
 				fmt.Sprintf("%d", dep.Available),
 				age,
-			})
+			}))
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	// StatefulSets
+	if len(results.StatefulSets) > 0 {
+		fmt.Println("🗄️  StatefulSets Status")
+		fmt.Println("─────────────────────────────────────────────────────────────")
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(withCluster("Cluster", []string{"Namespace", "Name", "Ready", "Up-to-date", "Age", "Health", "Reason"}))
+		table.SetBorder(false)
+
+		for _, sts := range results.StatefulSets {
+			table.Append(withCluster(sts.Cluster, []string{
+				sts.Namespace,
+				sts.Name,
+				sts.Ready,
+				fmt.Sprintf("%d", sts.UpToDate),
+				formatDuration(sts.Age),
+				healthLabel(sts.Health),
+				sts.Reason,
+			}))
 		}
 		table.Render()
 		fmt.Println()
 	}
 
+	// DaemonSets
+	if len(results.DaemonSets) > 0 {
+		fmt.Println("🧩 DaemonSets Status")
+		fmt.Println("─────────────────────────────────────────────────────────────")
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(withCluster("Cluster", []string{"Namespace", "Name", "Desired", "Ready", "Available", "Up-to-date", "Misscheduled", "Age", "Health", "Reason"}))
+		table.SetBorder(false)
+
+		for _, ds := range results.DaemonSets {
+			table.Append(withCluster(ds.Cluster, []string{
+				ds.Namespace,
+				ds.Name,
+				fmt.Sprintf("%d", ds.Desired),
+				fmt.Sprintf("%d", ds.Ready),
+				fmt.Sprintf("%d", ds.Available),
+				fmt.Sprintf("%d", ds.UpToDate),
+				fmt.Sprintf("%d", ds.Misscheduled),
+				formatDuration(ds.Age),
+				healthLabel(ds.Health),
+				ds.Reason,
+			}))
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	// Jobs
+	if len(results.Jobs) > 0 {
+		fmt.Println("⚙️  Jobs Status")
+		fmt.Println("─────────────────────────────────────────────────────────────")
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(withCluster("Cluster", []string{"Namespace", "Name", "Status", "Completions", "Failed", "Duration", "Age", "Health", "Reason"}))
+		table.SetBorder(false)
+
+		for _, job := range results.Jobs {
+			table.Append(withCluster(job.Cluster, []string{
+				job.Namespace,
+				job.Name,
+				job.Status,
+				job.Completions,
+				fmt.Sprintf("%d", job.Failed),
+				formatElapsed(job.Duration),
+				formatDuration(job.Age),
+				healthLabel(job.Health),
+				job.Reason,
+			}))
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	// CronJobs
+	if len(results.CronJobs) > 0 {
+		fmt.Println("⏰ CronJobs Status")
+		fmt.Println("─────────────────────────────────────────────────────────────")
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(withCluster("Cluster", []string{"Namespace", "Name", "Schedule", "Active", "Last Schedule", "Last Success", "Health", "Reason"}))
+		table.SetBorder(false)
+
+		for _, cronJob := range results.CronJobs {
+			table.Append(withCluster(cronJob.Cluster, []string{
+				cronJob.Namespace,
+				cronJob.Name,
+				cronJob.Schedule,
+				fmt.Sprintf("%d", cronJob.Active),
+				formatTimeAgo(cronJob.LastScheduleTime),
+				formatTimeAgo(cronJob.LastSuccessfulTime),
+				healthLabel(cronJob.Health),
+				cronJob.Reason,
+			}))
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	if results.HiddenHealthy > 0 {
+		fmt.Printf("ℹ️  %d healthy pod(s) and workload(s) hidden\n\n", results.HiddenHealthy)
+	}
+
 	// Nodes
 	if len(results.Nodes) > 0 {
 		fmt.Println("🖥️  Nodes Status")
 		fmt.Println("─────────────────────────────────────────────────────────────")
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Name", "Status", "Roles", "Version", "Age"})
+		table.SetHeader(withCluster("Cluster", []string{"Name", "Status", "Roles", "Version", "Age"}))
 		table.SetBorder(false)
 
 		for _, node := range results.Nodes {
 			age := formatDuration(node.Age)
-			table.Append([]string{
+			table.Append(withCluster(node.Cluster, []string{
 				node.Name,
 				node.Status,
 				node.Roles,
 				node.KubeletVersion,
 				age,
-			})
+			}))
 		}
 		table.Render()
 		fmt.Println()
@@ -318,18 +451,18 @@ func (r *Reporter) renderHealthTable(results *k8s.HealthResults) error {
 		fmt.Println("⚠️  Recent Warning Events (Last Hour)")
 		fmt.Println("─────────────────────────────────────────────────────────────")
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Namespace", "Kind", "Name", "Reason", "Count"})
+		table.SetHeader(withCluster("Cluster", []string{"Namespace", "Kind", "Name", "Reason", "Count"}))
 		table.SetBorder(false)
 		table.SetAutoWrapText(false)
 
 		for _, event := range results.WarningEvents {
-			table.Append([]string{
+			table.Append(withCluster(event.Cluster, []string{
 				event.Namespace,
 				event.Kind,
 				event.Name,
 				event.Reason,
 				fmt.Sprintf("%d", event.Count),
-			})
+			}))
 		}
 		table.Render()
 		fmt.Println()
@@ -340,6 +473,46 @@ func (r *Reporter) renderHealthTable(results *k8s.HealthResults) error {
 	return nil
 }
 
+// healthLabel returns the table label for a pod health class
+func healthLabel(health string) string {
+	switch health {
+	case k8s.Failing:
+		return "🔴 failing"
+	case k8s.Degraded:
+		return "🟡 degraded"
+	default:
+		return "🟢 healthy"
+	}
+}
+
+// renderClusterSummaries prints the problem counts of each cluster
+func renderClusterSummaries(summaries []k8s.ClusterHealthSummary) {
+	fmt.Println("☸️  Clusters")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Cluster", "Pods", "Failing", "Degraded", "Deployments", "Unavailable", "Workloads", "Failing", "Degraded", "Nodes", "Not Ready", "Warnings"})
+	table.SetBorder(false)
+
+	for _, summary := range summaries {
+		table.Append([]string{
+			summary.Cluster,
+			fmt.Sprintf("%d", summary.Pods),
+			fmt.Sprintf("%d", summary.PodsFailing),
+			fmt.Sprintf("%d", summary.PodsDegraded),
+			fmt.Sprintf("%d", summary.Deployments),
+			fmt.Sprintf("%d", summary.DeploymentsUnavailable),
+			fmt.Sprintf("%d", summary.Workloads),
+			fmt.Sprintf("%d", summary.WorkloadsFailing),
+			fmt.Sprintf("%d", summary.WorkloadsDegraded),
+			fmt.Sprintf("%d", summary.Nodes),
+			fmt.Sprintf("%d", summary.NodesNotReady),
+			fmt.Sprintf("%d", summary.WarningEvents),
+		})
+	}
+	table.Render()
+	fmt.Println()
+}
+
 func (r *Reporter) renderCostJSON(results *aws.CostResults) error {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
@@ -352,49 +525,306 @@ func (r *Reporter) renderCostTable(results *aws.CostResults) error {
 		results.StartDate.Format("2006-01-02"),
 		results.EndDate.Format("2006-01-02"))
 	fmt.Println("─────────────────────────────────────────────────────────────")
-	fmt.Printf("Total Cost: $%.2f %s\n\n", results.TotalCost, results.Currency)
+	if results.Metric != "" && results.Metric != aws.MetricUnblendedCost {
+		fmt.Printf("Metric: %s\n", results.Metric)
+	}
+	if results.Metric == aws.MetricUsageQuantity {
+		fmt.Printf("Total Usage: %s\n", usageTotal(results))
+	} else {
+		fmt.Printf("Total Cost: %s %s\n", costAmount(results, results.TotalCost), results.Currency)
+	}
+	if comparison := results.Comparison; comparison != nil {
+		fmt.Printf("Previous Period (%s to %s): %s (%s)\n",
+			comparison.PreviousStartDate.Format("2006-01-02"),
+			comparison.PreviousEndDate.Format("2006-01-02"),
+			costAmount(results, comparison.PreviousTotal),
+			costChange(results, comparison.Delta, comparison.PercentChange, comparison.PreviousTotal > 0))
+	}
+	fmt.Println()
 
 	// Cost breakdown
 	if len(results.Items) > 0 {
-		fmt.Printf("💵 Cost Breakdown by %s\n", results.GroupBy)
+		fmt.Printf("💵 Cost Breakdown by %s\n", strings.Join(results.GroupByLabels(), " > "))
 		fmt.Println("─────────────────────────────────────────────────────────────")
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{results.GroupBy, "Cost", "% of Total"})
+		label := strings.Join(results.GroupByLabels(), " / ")
+		if results.Comparison != nil {
+			table.SetHeader([]string{label, amountLabel(results), "Previous", "Change", "% Change", "% of Total"})
+		} else {
+			table.SetHeader([]string{label, amountLabel(results), "% of Total"})
+		}
 		table.SetBorder(false)
 
 		for _, item := range results.Items {
-			percentage := (item.Amount / results.TotalCost) * 100
-			table.Append([]string{
-				item.Name,
-				fmt.Sprintf("$%.2f", item.Amount),
-				fmt.Sprintf("%.1f%%", percentage),
-			})
+			table.Append(costItemRow(item.Name, item, results))
+			// Nested breakdown by the second group-by key
+			for _, child := range item.Children {
+				table.Append(costItemRow("  └ "+child.Name, child, results))
+			}
 		}
 		table.Render()
 		fmt.Println()
 	}
 
-	// Daily trend
+	if results.Comparison != nil {
+		groupBy := results.GroupByLabels()[0]
+		renderCostMovers("📈 Biggest Increases", groupBy, results.Comparison.Increases, results)
+		renderCostMovers("📉 Biggest Decreases", groupBy, results.Comparison.Decreases, results)
+		renderCostMovers("🆕 New Since Previous Period", groupBy, results.Comparison.NewItems, results)
+		renderCostMovers("🗑️  Disappeared Since Previous Period", groupBy, results.Comparison.RemovedItems, results)
+	}
+
+	// Daily trend, or monthly with monthly granularity
 	if len(results.DailyTrend) > 0 {
-		fmt.Println("📈 Daily Spending Trend")
+		period := "Daily"
+		if results.Granularity == "MONTHLY" {
+			period = "Monthly"
+		}
+		fmt.Printf("📈 %s Spending Trend\n", period)
 		fmt.Println("─────────────────────────────────────────────────────────────")
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Date", "Daily Cost"})
-		table.SetBorder(false)
 
-		for _, daily := range results.DailyTrend {
-			table.Append([]string{
-				daily.Date,
-				fmt.Sprintf("$%.2f", daily.Amount),
-			})
+		// Chart on a terminal, plain table when piped or too narrow
+		var chart []string
+		charted := false
+		if width, ok := terminalWidth(); ok {
+			chart, charted = newTrendChart(results).render(width, chartColorEnabled())
+		}
+
+		if charted {
+			for _, line := range chart {
+				fmt.Println(line)
+			}
+		} else {
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Date", period + " " + amountLabel(results)})
+			table.SetBorder(false)
+
+			for _, daily := range results.DailyTrend {
+				table.Append([]string{
+					daily.Date,
+					costAmount(results, daily.Amount),
+				})
+			}
+			table.Render()
 		}
-		table.Render()
 		fmt.Println()
 	}
 
 	return nil
 }
 
+// costItemRow returns the breakdown row of a cost item, with comparison
+// columns when the results were compared with the previous period
+func costItemRow(name string, item aws.CostItem, results *aws.CostResults) []string {
+	percentage := 0.0
+	if results.TotalCost > 0 {
+		percentage = (item.Amount / results.TotalCost) * 100
+	}
+
+	if results.Comparison == nil {
+		return []string{
+			name,
+			costAmount(results, item.Amount),
+			fmt.Sprintf("%.1f%%", percentage),
+		}
+	}
+	return []string{
+		name,
+		costAmount(results, item.Amount),
+		costAmount(results, item.PreviousAmount),
+		signedCostAmount(results, item.Delta),
+		itemPercentChange(item),
+		fmt.Sprintf("%.1f%%", percentage),
+	}
+}
+
+// renderCostMovers prints a section of changed cost items, if there are any
+func renderCostMovers(title, groupBy string, items []aws.CostItem, results *aws.CostResults) {
+	if len(items) == 0 {
+		return
+	}
+
+	fmt.Println(title)
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{groupBy, "Previous", "Current", "Change"})
+	table.SetBorder(false)
+
+	for _, item := range items {
+		table.Append([]string{
+			item.Name,
+			costAmount(results, item.PreviousAmount),
+			costAmount(results, item.Amount),
+			costChange(results, item.Delta, item.PercentChange, item.PreviousAmount > 0),
+		})
+	}
+	table.Render()
+	fmt.Println()
+}
+
+// itemPercentChange formats the percent change of a cost item, or its status
+// when it has no previous cost
+func itemPercentChange(item aws.CostItem) string {
+	if item.Status == aws.CostItemStatusNew {
+		return "new"
+	}
+	if item.Status == aws.CostItemStatusRemoved {
+		return "removed"
+	}
+	return fmt.Sprintf("%+.1f%%", item.PercentChange)
+}
+
+// costChange formats a delta with its percent change when there was a previous cost
+func costChange(results *aws.CostResults, delta, percent float64, hasPrevious bool) string {
+	if !hasPrevious {
+		return signedCostAmount(results, delta)
+	}
+	return fmt.Sprintf("%s (%+.1f%%)", signedCostAmount(results, delta), percent)
+}
+
+// costAmount formats an amount in dollars, or as a plain quantity for the usage metric
+func costAmount(results *aws.CostResults, amount float64) string {
+	if results.Metric == aws.MetricUsageQuantity {
+		return fmt.Sprintf("%.2f", amount)
+	}
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+// usageTotal formats the total of the usage metric with its unit. Usage types
+// with different units can't be added up, so no total is shown for them.
+func usageTotal(results *aws.CostResults) string {
+	if results.UsageUnit == "" {
+		return "- (usage types have different or unknown units)"
+	}
+	return fmt.Sprintf("%s %s", costAmount(results, results.TotalCost), results.UsageUnit)
+}
+
+// amountLabel names the amounts of the results, Cost or Usage
+func amountLabel(results *aws.CostResults) string {
+	if results.Metric == aws.MetricUsageQuantity {
+		return "Usage"
+	}
+	return "Cost"
+}
+
+// signedCostAmount formats an amount like costAmount, with an explicit sign
+func signedCostAmount(results *aws.CostResults, amount float64) string {
+	if results.Metric == aws.MetricUsageQuantity {
+		return fmt.Sprintf("%+.2f", amount)
+	}
+	return signedDollars(amount)
+}
+
+// signedDollars formats an amount with an explicit sign, like +$12.50
+func signedDollars(amount float64) string {
+	if amount < 0 {
+		return fmt.Sprintf("-$%.2f", -amount)
+	}
+	return fmt.Sprintf("+$%.2f", amount)
+}
+
+func (r *Reporter) renderComplianceJSON(report *aws.ComplianceReport) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func (r *Reporter) renderComplianceTable(report *aws.ComplianceReport) error {
+	fmt.Printf("📋 %s\n", report.Benchmark)
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	fmt.Printf("Regions: %s\n\n", strings.Join(report.Regions, ", "))
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Control", "Title", "Status", "Failing Resources"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, control := range report.Controls {
+		table.Append([]string{
+			control.ControlID,
+			control.Title,
+			controlStatusLabel(control),
+			formatControlResources(control),
+		})
+	}
+	table.Render()
+	fmt.Println()
+
+	// Summary
+	fmt.Println("📊 Compliance Summary")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	fmt.Printf("✅ Passed: %d\n", report.Passed)
+	fmt.Printf("❌ Failed: %d\n", report.Failed)
+	fmt.Printf("➖ Not applicable: %d\n", report.NotApplicable)
+	fmt.Printf("⚠️  Error: %d\n", report.Errors)
+	fmt.Printf("\nCompliance Score: %s\n", report.FormatScore())
+
+	return nil
+}
+
+func (r *Reporter) renderComplianceCSV(report *aws.ComplianceReport) error {
+	writer := csv.NewWriter(os.Stdout)
+
+	if err := writer.Write([]string{"ControlID", "Title", "Status", "FailingResources", "SuppressedResources"}); err != nil {
+		return err
+	}
+
+	for _, control := range report.Controls {
+		if err := writer.Write([]string{
+			control.ControlID,
+			control.Title,
+			string(control.Status),
+			strings.Join(control.FailingResources, ";"),
+			strings.Join(control.SuppressedResources, ";"),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func controlStatusLabel(control aws.ControlResult) string {
+	switch control.Status {
+	case aws.ControlPass:
+		return "✅ PASS"
+	case aws.ControlFail:
+		// Accepted risks are exceptions, the control still fails
+		if len(control.SuppressedResources) == len(control.FailingResources) {
+			return "❌ FAIL (suppressed)"
+		}
+		return "❌ FAIL"
+	case aws.ControlNotApplicable:
+		return "➖ N/A"
+	case aws.ControlError:
+		return "⚠️  ERROR"
+	default:
+		return string(control.Status)
+	}
+}
+
+// formatControlResources lists the failing resources of a control, noting how
+// many of them are suppressed
+func formatControlResources(control aws.ControlResult) string {
+	resources := formatResourceList(control.FailingResources, 3)
+	if len(control.SuppressedResources) > 0 {
+		resources += fmt.Sprintf(" (%d suppressed)", len(control.SuppressedResources))
+	}
+	return resources
+}
+
+// formatResourceList joins up to limit resource IDs, summarizing the rest
+func formatResourceList(resources []string, limit int) string {
+	if len(resources) == 0 {
+		return "-"
+	}
+	if len(resources) > limit {
+		return fmt.Sprintf("%s (+ %d more)", strings.Join(resources[:limit], ", "), len(resources)-limit)
+	}
+	return strings.Join(resources, ", ")
+}
+
 func formatDuration(d time.Duration) string {
 	days := int(d.Hours() / 24)
 	hours := int(d.Hours()) % 24
@@ -404,3 +834,24 @@ func formatDuration(d time.Duration) string {
 	}
 	return fmt.Sprintf("%dh", hours)
 }
+
+// formatElapsed formats a duration that may be shorter than an hour, such as
+// a job's run time
+func formatElapsed(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return formatDuration(d)
+	}
+}
+
+// formatTimeAgo formats how long ago t was, or "never" when it is unset
+func formatTimeAgo(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return formatElapsed(time.Since(*t)) + " ago"
+}
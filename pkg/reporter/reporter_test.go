@@ -1,7 +1,10 @@
 package reporter
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
 )
 
 func TestNewReporter(t *testing.T) {
@@ -94,3 +97,40 @@ func TestReporterFormats(t *testing.T) {
 		})
 	}
 }
+
+func TestUsageTotal(t *testing.T) {
+	results := &aws.CostResults{Metric: aws.MetricUsageQuantity, TotalCost: 1488, UsageUnit: "Hrs"}
+	if got := usageTotal(results); got != "1488.00 Hrs" {
+		t.Errorf("usageTotal() = %q, want 1488.00 Hrs", got)
+	}
+
+	results.UsageUnit = ""
+	if got := usageTotal(results); !strings.HasPrefix(got, "- ") {
+		t.Errorf("usageTotal() = %q, want no total for mixed units", got)
+	}
+}
+
+func TestCostChange(t *testing.T) {
+	tests := []struct {
+		name        string
+		metric      string
+		delta       float64
+		percent     float64
+		hasPrevious bool
+		want        string
+	}{
+		{name: "increase", delta: 200, percent: 50, hasPrevious: true, want: "+$200.00 (+50.0%)"},
+		{name: "decrease", delta: -20.5, percent: -20, hasPrevious: true, want: "-$20.50 (-20.0%)"},
+		{name: "no previous cost", delta: 25, hasPrevious: false, want: "+$25.00"},
+		{name: "usage quantity", metric: aws.MetricUsageQuantity, delta: -12, percent: -10, hasPrevious: true, want: "-12.00 (-10.0%)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := &aws.CostResults{Metric: tt.metric}
+			if got := costChange(results, tt.delta, tt.percent, tt.hasPrevious); got != tt.want {
+				t.Errorf("costChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
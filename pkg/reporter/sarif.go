@@ -0,0 +1,221 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+)
+
+// SARIF 2.1.0 schema identifiers
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFReport is the top-level SARIF log
+type SARIFReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is a single analysis run
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the analysis tool and its rules
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver holds the tool name and the rules it reports
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes a check that produces results
+type SARIFRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     SARIFMessage       `json:"shortDescription"`
+	FullDescription      SARIFMessage       `json:"fullDescription"`
+	DefaultConfiguration SARIFConfiguration `json:"defaultConfiguration"`
+	Properties           map[string]string  `json:"properties"`
+}
+
+// SARIFConfiguration holds the default level of a rule
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFMessage is a plain text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is a single finding
+type SARIFResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             SARIFMessage      `json:"message"`
+	Locations           []SARIFLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]string `json:"properties"`
+}
+
+// SARIFLocation points at the cloud resource a result applies to
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations"`
+}
+
+// SARIFPhysicalLocation holds the resource ARN as the artifact location
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+// SARIFArtifactLocation is the URI of the artifact a result applies to
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFLogicalLocation names the resource a result applies to
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func (r *Reporter) renderSecuritySARIF(results *aws.SecurityResults, accountID string) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(BuildSARIFReport(results, accountID))
+}
+
+func (r *Reporter) renderSecurityASFF(results *aws.SecurityResults, region, accountID string) error {
+	// Wrapped in Findings so the output can be passed to
+	// aws securityhub batch-import-findings --cli-input-json
+	output := struct {
+		Findings []aws.ASFFFinding
+	}{
+		Findings: aws.BuildASFFFindings(results, region, accountID, time.Now()),
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// BuildSARIFReport converts security results into a SARIF 2.1.0 log
+func BuildSARIFReport(results *aws.SecurityResults, accountID string) *SARIFReport {
+	findings := results.AllFindings()
+
+	// Only rules that produced results are listed, in a stable order
+	ruleIDs := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		if !seen[finding.CheckID] {
+			seen[finding.CheckID] = true
+			ruleIDs = append(ruleIDs, finding.CheckID)
+		}
+	}
+	sort.Strings(ruleIDs)
+
+	rules := make([]SARIFRule, 0, len(ruleIDs))
+	for _, id := range ruleIDs {
+		rule := aws.RuleForCheck(id)
+		rules = append(rules, SARIFRule{
+			ID:                   rule.ID,
+			Name:                 rule.Title,
+			ShortDescription:     SARIFMessage{Text: rule.Title},
+			FullDescription:      SARIFMessage{Text: rule.Description},
+			DefaultConfiguration: SARIFConfiguration{Level: sarifLevel(rule.Severity)},
+			Properties: map[string]string{
+				"security-severity": securitySeverityScore(rule.Severity),
+			},
+		})
+	}
+
+	sarifResults := make([]SARIFResult, 0, len(findings))
+	for _, finding := range findings {
+		arn := aws.ResourceARN(finding, accountID)
+		sarifResults = append(sarifResults, SARIFResult{
+			RuleID:  finding.CheckID,
+			Level:   sarifLevel(finding.Severity),
+			Message: SARIFMessage{Text: fmt.Sprintf("%s %s: %s", finding.ResourceType, finding.ResourceID, finding.Description)},
+			Locations: []SARIFLocation{
+				{
+					PhysicalLocation: SARIFPhysicalLocation{
+						ArtifactLocation: SARIFArtifactLocation{URI: arn},
+					},
+					LogicalLocations: []SARIFLogicalLocation{
+						{
+							Name:               finding.ResourceID,
+							FullyQualifiedName: arn,
+							Kind:               finding.ResourceType,
+						},
+					},
+				},
+			},
+			PartialFingerprints: map[string]string{
+				"dtkFinding/v1": aws.FindingFingerprint(finding),
+			},
+			Properties: map[string]string{
+				"severity": string(finding.Severity),
+				"region":   finding.Region,
+			},
+		})
+	}
+
+	return &SARIFReport{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []SARIFRun{
+			{
+				Tool: SARIFTool{
+					Driver: SARIFDriver{
+						Name:           "dtk",
+						InformationURI: "https://github.com/ahmedfawzy/devops-toolkit",
+						Rules:          rules,
+					},
+				},
+				Results: sarifResults,
+			},
+		},
+	}
+}
+
+// sarifLevel maps a finding severity to a SARIF result level
+func sarifLevel(severity aws.Severity) string {
+	switch severity {
+	case aws.SeverityCritical, aws.SeverityHigh:
+		return "error"
+	case aws.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverityScore maps a finding severity to the numeric score GitHub
+// code scanning uses to rank security results
+func securitySeverityScore(severity aws.Severity) string {
+	switch severity {
+	case aws.SeverityCritical:
+		return "9.0"
+	case aws.SeverityHigh:
+		return "7.0"
+	case aws.SeverityMedium:
+		return "5.0"
+	default:
+		return "0.0"
+	}
+}
@@ -0,0 +1,82 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+)
+
+func TestBuildSARIFReport(t *testing.T) {
+	results := &aws.SecurityResults{
+		OpenSecurityGroups: []aws.OpenSecurityGroup{
+			{GroupID: "sg-1", Region: "us-east-1", Port: 22, Source: "0.0.0.0/0", Severity: aws.SeverityCritical},
+			{GroupID: "sg-1", Region: "us-east-1", Port: 3389, Source: "0.0.0.0/0", Severity: aws.SeverityCritical},
+		},
+		Findings: []aws.SecurityFinding{
+			{CheckID: aws.CheckIDUnencryptedVolumes, ResourceType: aws.ResourceTypeEBSVolume, ResourceID: "vol-1", Region: "us-east-1", Severity: aws.SeverityMedium, Description: "not encrypted"},
+		},
+	}
+
+	report := BuildSARIFReport(results, "111111111111")
+
+	if report.Version != "2.1.0" || len(report.Runs) != 1 {
+		t.Fatalf("report version = %q with %d runs, want 2.1.0 with 1 run", report.Version, len(report.Runs))
+	}
+
+	run := report.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("rules = %d, want 2 (one per check with results)", len(run.Tool.Driver.Rules))
+	}
+	if run.Tool.Driver.Rules[0].ID != aws.CheckIDUnencryptedVolumes {
+		t.Errorf("rules[0].ID = %q, want rules sorted by ID", run.Tool.Driver.Rules[0].ID)
+	}
+	if got := run.Tool.Driver.Rules[1].Properties["security-severity"]; got != "9.0" {
+		t.Errorf("open security group security-severity = %q, want 9.0", got)
+	}
+
+	if len(run.Results) != 3 {
+		t.Fatalf("results = %d, want 3", len(run.Results))
+	}
+
+	sg := run.Results[0]
+	if sg.RuleID != aws.CheckIDOpenSecurityGroups || sg.Level != "error" {
+		t.Errorf("results[0] rule = %q level = %q", sg.RuleID, sg.Level)
+	}
+	if uri := sg.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "arn:aws:ec2:us-east-1:111111111111:security-group/sg-1" {
+		t.Errorf("results[0] location = %q", uri)
+	}
+	if sg.PartialFingerprints["dtkFinding/v1"] == run.Results[1].PartialFingerprints["dtkFinding/v1"] {
+		t.Error("different ports on the same group share a fingerprint")
+	}
+
+	if run.Results[2].Level != "warning" {
+		t.Errorf("medium finding level = %q, want warning", run.Results[2].Level)
+	}
+}
+
+func TestSarifLevel(t *testing.T) {
+	tests := []struct {
+		severity aws.Severity
+		want     string
+	}{
+		{aws.SeverityCritical, "error"},
+		{aws.SeverityHigh, "error"},
+		{aws.SeverityMedium, "warning"},
+		{aws.Severity("info"), "note"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.severity), func(t *testing.T) {
+			if got := sarifLevel(tt.severity); got != tt.want {
+				t.Errorf("sarifLevel(%q) = %q, want %q", tt.severity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSecurityResultsUnsupportedFormat(t *testing.T) {
+	err := NewReporter("xml").RenderSecurityResults(&aws.SecurityResults{}, "us-east-1", "111111111111")
+	if err == nil {
+		t.Error("RenderSecurityResults() with xml format returned nil error")
+	}
+}
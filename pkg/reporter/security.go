@@ -0,0 +1,196 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+	"github.com/olekukonko/tablewriter"
+)
+
+// RenderSecurityResults renders security results. The region is where
+// findings are imported into Security Hub and only affects ASFF output.
+func (r *Reporter) RenderSecurityResults(results *aws.SecurityResults, region, accountID string) error {
+	switch r.format {
+	case "table":
+		return r.renderSecurityTable(results)
+	case "json":
+		return r.renderSecurityJSON(results)
+	case "csv":
+		return r.renderSecurityCSV(results)
+	case "sarif":
+		return r.renderSecuritySARIF(results, accountID)
+	case "asff":
+		return r.renderSecurityASFF(results, region, accountID)
+	default:
+		return fmt.Errorf("unsupported format: %s", r.format)
+	}
+}
+
+func (r *Reporter) renderSecurityJSON(results *aws.SecurityResults) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+func (r *Reporter) renderSecurityTable(results *aws.SecurityResults) error {
+	fmt.Println("🔒 AWS Security Audit")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	fmt.Printf("Regions: %s\n\n", strings.Join(results.Regions, ", "))
+
+	// Public S3 buckets
+	fmt.Println("🪣 Public S3 Buckets")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	if !results.IsCompleted(aws.CheckIDPublicS3Buckets) {
+		fmt.Println("⚠️  S3 bucket check did not complete")
+	} else if len(results.PublicS3Buckets) == 0 {
+		fmt.Println("✅ No public buckets found")
+	} else {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Bucket", "Region", "Public Access", "Severity"})
+		table.SetBorder(false)
+
+		for _, bucket := range results.PublicS3Buckets {
+			table.Append([]string{
+				bucket.BucketName,
+				bucket.Region,
+				bucket.PublicAccess,
+				severityLabel(bucket.Severity),
+			})
+		}
+		table.Render()
+	}
+	fmt.Println()
+
+	// Open security groups
+	fmt.Println("🛡️  Open Security Groups (risky ports exposed to the internet)")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	if !results.IsCompleted(aws.CheckIDOpenSecurityGroups) {
+		fmt.Println("⚠️  Security group check did not complete")
+	} else if len(results.OpenSecurityGroups) == 0 {
+		fmt.Println("✅ No risky security groups found")
+	} else {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Security Group", "Name", "Region", "Port", "Protocol", "Source", "Severity"})
+		table.SetBorder(false)
+
+		for _, sg := range results.OpenSecurityGroups {
+			table.Append([]string{
+				sg.GroupID,
+				sg.GroupName,
+				sg.Region,
+				fmt.Sprintf("%d", sg.Port),
+				sg.Protocol,
+				sg.Source,
+				severityLabel(sg.Severity),
+			})
+		}
+		table.Render()
+	}
+	fmt.Println()
+
+	// Resource findings (encryption, exposure and other checks)
+	fmt.Println("🔐 Resource Findings")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	if len(results.Findings) == 0 {
+		fmt.Println("✅ No resource findings")
+	} else {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Region", "Type", "Resource", "Severity", "Description"})
+		table.SetBorder(false)
+
+		for _, finding := range results.Findings {
+			table.Append([]string{
+				finding.Region,
+				finding.ResourceType,
+				finding.ResourceID,
+				severityLabel(finding.Severity),
+				finding.Description,
+			})
+		}
+		table.Render()
+	}
+	fmt.Println()
+
+	// Suppressed findings
+	if len(results.Suppressed) > 0 {
+		fmt.Println("🔕 Suppressed Findings")
+		fmt.Println("─────────────────────────────────────────────────────────────")
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Region", "Resource", "Check", "Owner", "Expires", "Reason"})
+		table.SetBorder(false)
+
+		for _, suppressed := range results.Suppressed {
+			table.Append([]string{
+				suppressed.Finding.Region,
+				suppressed.Finding.ResourceID,
+				suppressed.Finding.CheckID,
+				suppressed.Suppression.Owner,
+				suppressed.Suppression.Expires,
+				suppressed.Suppression.Reason,
+			})
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	// Summary
+	counts := results.CountBySeverity()
+	fmt.Println("📊 Summary")
+	fmt.Println("─────────────────────────────────────────────────────────────")
+	fmt.Printf("🔴 Critical: %d\n", counts[aws.SeverityCritical])
+	fmt.Printf("🟡 High: %d\n", counts[aws.SeverityHigh])
+	fmt.Printf("🟠 Medium: %d\n", counts[aws.SeverityMedium])
+	if len(results.Suppressed) > 0 {
+		fmt.Printf("🔕 Suppressed: %d\n", len(results.Suppressed))
+	}
+
+	return nil
+}
+
+func (r *Reporter) renderSecurityCSV(results *aws.SecurityResults) error {
+	// WriteAll flushes the writer
+	return csv.NewWriter(os.Stdout).WriteAll(securityCSVRows(results))
+}
+
+// securityCSVRows returns one row per active or suppressed finding, with a header
+func securityCSVRows(results *aws.SecurityResults) [][]string {
+	rows := [][]string{{"Region", "CheckID", "Severity", "ResourceType", "ResourceID", "Description", "Status"}}
+
+	for _, finding := range results.AllFindings() {
+		rows = append(rows, securityCSVRow(finding, "open"))
+	}
+	for _, suppressed := range results.Suppressed {
+		rows = append(rows, securityCSVRow(suppressed.Finding, "suppressed"))
+	}
+
+	return rows
+}
+
+func securityCSVRow(finding aws.SecurityFinding, status string) []string {
+	return []string{
+		finding.Region,
+		finding.CheckID,
+		string(finding.Severity),
+		finding.ResourceType,
+		finding.ResourceID,
+		finding.Description,
+		status,
+	}
+}
+
+func severityLabel(severity aws.Severity) string {
+	switch severity {
+	case aws.SeverityCritical:
+		return "🔴 CRITICAL"
+	case aws.SeverityHigh:
+		return "🟡 HIGH"
+	case aws.SeverityMedium:
+		return "🟠 MEDIUM"
+	default:
+		return string(severity)
+	}
+}
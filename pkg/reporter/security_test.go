@@ -0,0 +1,54 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ahmedfawzy/devops-toolkit/pkg/aws"
+)
+
+func TestSecurityCSVRows(t *testing.T) {
+	results := &aws.SecurityResults{
+		Regions: []string{"us-east-1", "eu-west-1"},
+		PublicS3Buckets: []aws.PublicS3Bucket{
+			{BucketName: "assets", Region: "eu-west-1", PublicAccess: "ACL grants public read", Severity: aws.SeverityCritical},
+		},
+		OpenSecurityGroups: []aws.OpenSecurityGroup{
+			{GroupID: "sg-1", GroupName: "bastion", Region: "us-east-1", Port: 22, Protocol: "tcp", Source: "0.0.0.0/0", Severity: aws.SeverityCritical},
+		},
+		Findings: []aws.SecurityFinding{
+			{CheckID: aws.CheckIDUnencryptedVolumes, ResourceType: aws.ResourceTypeEBSVolume, ResourceID: "vol-1", Region: "us-east-1", Severity: aws.SeverityMedium, Description: "not encrypted"},
+		},
+		Suppressed: []aws.SuppressedFinding{
+			{
+				Finding:     aws.SecurityFinding{CheckID: aws.CheckIDUnencryptedVolumes, ResourceType: aws.ResourceTypeEBSVolume, ResourceID: "vol-2", Region: "eu-west-1", Severity: aws.SeverityMedium, Description: "not encrypted"},
+				Suppression: aws.Suppression{ResourceID: "vol-2", Check: aws.CheckIDUnencryptedVolumes, Reason: "scratch disk", Owner: "platform"},
+			},
+		},
+	}
+
+	rows := securityCSVRows(results)
+
+	if got := strings.Join(rows[0], ","); got != "Region,CheckID,Severity,ResourceType,ResourceID,Description,Status" {
+		t.Errorf("header = %q", got)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("securityCSVRows() returned %d rows, want header and 4 findings: %v", len(rows), rows)
+	}
+
+	wantRegions := map[string]string{"assets": "eu-west-1", "sg-1": "us-east-1", "vol-1": "us-east-1", "vol-2": "eu-west-1"}
+	for _, row := range rows[1:] {
+		resourceID := row[4]
+		if row[0] != wantRegions[resourceID] {
+			t.Errorf("%s region = %q, want %q", resourceID, row[0], wantRegions[resourceID])
+		}
+
+		wantStatus := "open"
+		if resourceID == "vol-2" {
+			wantStatus = "suppressed"
+		}
+		if row[6] != wantStatus {
+			t.Errorf("%s status = %q, want %q", resourceID, row[6], wantStatus)
+		}
+	}
+}